@@ -0,0 +1,179 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// allocationRuleRequest é o corpo aceito na criação e atualização de regras de rateio
+type allocationRuleRequest struct {
+	Name        string                   `json:"name"`
+	TagKey      string                   `json:"tag_key"`
+	SourceValue string                   `json:"source_value"`
+	Splits      []models.AllocationSplit `json:"splits"`
+	Active      *bool                    `json:"active"`
+}
+
+func (req allocationRuleRequest) toAllocationRule() models.AllocationRule {
+	rule := models.AllocationRule{
+		Name:        req.Name,
+		TagKey:      req.TagKey,
+		SourceValue: req.SourceValue,
+		Splits:      req.Splits,
+		Active:      true,
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	return rule
+}
+
+// ListAllocationRulesHandler lista as regras de rateio
+func (h *Handler) ListAllocationRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetAllocationRules(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar regras de rateio: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// GetAllocationRuleHandler retorna uma regra de rateio
+func (h *Handler) GetAllocationRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.GetAllocationRule(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar regra de rateio: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if rule == nil {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// CreateAllocationRuleHandler cria uma regra de rateio
+func (h *Handler) CreateAllocationRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var req allocationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	rule := req.toAllocationRule()
+	if err := h.service.CreateAllocationRule(r.Context(), &rule); err != nil {
+		writeEntityError(w, err, "criar regra de rateio")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateAllocationRuleHandler substitui uma regra de rateio
+func (h *Handler) UpdateAllocationRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req allocationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	rule := req.toAllocationRule()
+	rule.ID = id
+	found, err := h.service.UpdateAllocationRule(r.Context(), &rule)
+	if err != nil {
+		writeEntityError(w, err, "atualizar regra de rateio")
+		return
+	}
+	if !found {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteAllocationRuleHandler remove uma regra de rateio
+func (h *Handler) DeleteAllocationRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeleteAllocationRule(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao remover regra de rateio: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChargebackHandler retorna o custo por valor de uma tag (centro de custo) após o rateio
+func (h *Handler) ChargebackHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	from, err := parseQueryDate(q.Get("from"))
+	if err != nil {
+		http.Error(w, "Parâmetro from inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	to, err := parseQueryDate(q.Get("to"))
+	if err != nil {
+		http.Error(w, "Parâmetro to inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	asOf, err := parseAsOf(q.Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetChargeback(r.Context(), models.ChargebackQuery{
+		TagKey: q.Get("tag_key"),
+		From:   from,
+		To:     to,
+		AsOf:   asOf,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao gerar chargeback: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
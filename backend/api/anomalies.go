@@ -0,0 +1,107 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ListAnomaliesHandler lista as anomalias de gasto detectadas
+func (h *Handler) ListAnomaliesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := models.AnomalyFilter{Severity: q.Get("severity")}
+
+	if v := q.Get("acknowledged"); v != "" {
+		acknowledged, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Parâmetro acknowledged inválido", http.StatusBadRequest)
+			return
+		}
+		filter.Acknowledged = &acknowledged
+	}
+	if v := q.Get("customer_id"); v != "" {
+		customerID, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro customer_id inválido", http.StatusBadRequest)
+			return
+		}
+		filter.CustomerID = customerID
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro limit inválido", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	anomalies, err := h.service.ListAnomalies(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao buscar anomalias: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anomalies)
+}
+
+// AcknowledgeAnomalyHandler marca uma anomalia como reconhecida
+func (h *Handler) AcknowledgeAnomalyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da anomalia inválido", http.StatusBadRequest)
+		return
+	}
+
+	anomaly, err := h.service.AcknowledgeAnomaly(r.Context(), id, usernameFromRequest(r))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao reconhecer anomalia: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if anomaly == nil {
+		http.Error(w, "Anomalia não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anomaly)
+}
+
+// DetectAnomaliesHandler executa a detecção de anomalias sob demanda
+func (h *Handler) DetectAnomaliesHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := h.service.DetectAnomalies(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao detectar anomalias: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"anomalies": count,
+	})
+}
+
+// usernameFromRequest retorna o usuário autenticado adicionado pelo AuthMiddleware
+func usernameFromRequest(r *http.Request) string {
+	if username, ok := r.Context().Value("username").(string); ok {
+		return username
+	}
+	return ""
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// budgetRequest é o corpo aceito na criação e atualização de orçamentos
+type budgetRequest struct {
+	Name       string  `json:"name"`
+	Scope      string  `json:"scope"`
+	ScopeRef   string  `json:"scope_ref"`
+	Amount     float64 `json:"amount"`
+	Thresholds []int   `json:"thresholds"`
+	Active     *bool   `json:"active"`
+}
+
+func (req budgetRequest) toBudget() models.Budget {
+	budget := models.Budget{
+		Name:       req.Name,
+		Scope:      req.Scope,
+		ScopeRef:   req.ScopeRef,
+		Amount:     req.Amount,
+		Thresholds: req.Thresholds,
+		Active:     true,
+	}
+	if req.Active != nil {
+		budget.Active = *req.Active
+	}
+	return budget
+}
+
+// ListBudgetsHandler lista os orçamentos cadastrados
+func (h *Handler) ListBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	budgets, err := h.service.GetAllBudgets(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar orçamentos: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budgets)
+}
+
+// GetBudgetHandler retorna um orçamento
+func (h *Handler) GetBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do orçamento inválido", http.StatusBadRequest)
+		return
+	}
+
+	budget, err := h.service.GetBudget(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar orçamento: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if budget == nil {
+		http.Error(w, "Orçamento não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// CreateBudgetHandler cria um orçamento
+func (h *Handler) CreateBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	var req budgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	budget := req.toBudget()
+	if err := h.service.CreateBudget(r.Context(), &budget); err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao criar orçamento: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(budget)
+}
+
+// UpdateBudgetHandler substitui os dados de um orçamento
+func (h *Handler) UpdateBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do orçamento inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req budgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	budget := req.toBudget()
+	budget.ID = id
+	found, err := h.service.UpdateBudget(r.Context(), &budget)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao atualizar orçamento: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Orçamento não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// DeleteBudgetHandler remove um orçamento e seus alertas
+func (h *Handler) DeleteBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do orçamento inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeleteBudget(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao remover orçamento: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Orçamento não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListBudgetAlertsHandler lista os alertas gerados para um orçamento
+func (h *Handler) ListBudgetAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do orçamento inválido", http.StatusBadRequest)
+		return
+	}
+
+	budget, err := h.service.GetBudget(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar orçamento: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if budget == nil {
+		http.Error(w, "Orçamento não encontrado", http.StatusNotFound)
+		return
+	}
+
+	alerts, err := h.service.GetBudgetAlerts(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar alertas: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts)
+}
+
+// EvaluateBudgetsHandler avalia os orçamentos sob demanda; os alertas pendentes são entregues em segundo plano
+func (h *Handler) EvaluateBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := h.service.EvaluateBudgets(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao avaliar orçamentos: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"alerts":  count,
+	})
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// customerGroupRequest é o corpo aceito na criação e atualização de grupos de clientes
+type customerGroupRequest struct {
+	Name           string   `json:"name"`
+	DomainPatterns []string `json:"domain_patterns"`
+}
+
+func (req customerGroupRequest) toCustomerGroup() models.CustomerGroup {
+	return models.CustomerGroup{
+		Name:           req.Name,
+		DomainPatterns: req.DomainPatterns,
+	}
+}
+
+// ListCustomerGroupsHandler lista os grupos de clientes
+func (h *Handler) ListCustomerGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.service.GetCustomerGroups(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar grupos de clientes: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// GetCustomerGroupHandler retorna um grupo com seus clientes
+func (h *Handler) GetCustomerGroupHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do grupo inválido", http.StatusBadRequest)
+		return
+	}
+
+	group, err := h.service.GetCustomerGroup(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar grupo de clientes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		http.Error(w, "Grupo não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(group)
+}
+
+// CreateCustomerGroupHandler cria um grupo de clientes
+func (h *Handler) CreateCustomerGroupHandler(w http.ResponseWriter, r *http.Request) {
+	var req customerGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	group := req.toCustomerGroup()
+	if err := h.service.CreateCustomerGroup(r.Context(), &group); err != nil {
+		writeEntityError(w, err, "criar grupo de clientes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(group)
+}
+
+// UpdateCustomerGroupHandler substitui nome e padrões de um grupo de clientes
+func (h *Handler) UpdateCustomerGroupHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do grupo inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req customerGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	group := req.toCustomerGroup()
+	group.ID = id
+	found, err := h.service.UpdateCustomerGroup(r.Context(), &group)
+	if err != nil {
+		writeEntityError(w, err, "atualizar grupo de clientes")
+		return
+	}
+	if !found {
+		http.Error(w, "Grupo não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(group)
+}
+
+// DeleteCustomerGroupHandler remove um grupo de clientes
+func (h *Handler) DeleteCustomerGroupHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do grupo inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeleteCustomerGroup(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao remover grupo de clientes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Grupo não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ApplyCustomerGroupsHandler reaplica os padrões de domínio a todos os clientes
+func (h *Handler) ApplyCustomerGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	updated, err := h.service.ApplyCustomerGroupPatterns(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao aplicar grupos de clientes: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"updated": updated})
+}
+
+// SetCustomerGroupHandler associa manualmente um cliente a um grupo; group_id nulo desfaz a
+// associação manual
+func (h *Handler) SetCustomerGroupHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do cliente inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		GroupID *int `json:"group_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.SetCustomerGroup(r.Context(), id, req.GroupID)
+	if err != nil {
+		writeEntityError(w, err, "associar cliente ao grupo")
+		return
+	}
+	if !found {
+		http.Error(w, "Cliente não encontrado", http.StatusNotFound)
+		return
+	}
+
+	customer, err := h.service.GetCustomer(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar cliente: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PartnerVersionsHandler retorna o histórico de versões de um parceiro
+func (h *Handler) PartnerVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeDimensionVersions(w, r, "partner", "ID do parceiro inválido", "Parceiro não encontrado")
+}
+
+// CustomerVersionsHandler retorna o histórico de versões de um cliente
+func (h *Handler) CustomerVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeDimensionVersions(w, r, "customer", "ID do cliente inválido", "Cliente não encontrado")
+}
+
+// ProductVersionsHandler retorna o histórico de versões de um produto
+func (h *Handler) ProductVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeDimensionVersions(w, r, "product", "ID do produto inválido", "Produto não encontrado")
+}
+
+func (h *Handler) writeDimensionVersions(w http.ResponseWriter, r *http.Request, dimension, invalidID, notFound string) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, invalidID, http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.service.GetDimensionVersions(r.Context(), dimension, id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar versões: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(versions) == 0 {
+		http.Error(w, notFound, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
@@ -0,0 +1,493 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const updatedAtRequired = "updated_at é obrigatório: informe o valor retornado na última leitura"
+
+// presentFields lista os nomes dos campos informados no corpo. No PATCH só eles são validados, para que
+// dados importados fora das regras de edição não impeçam a alteração de outro atributo
+func presentFields(fields map[string]*string) []string {
+	names := []string{}
+	for name, value := range fields {
+		if value != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// setField aplica um campo do corpo: no PUT campos ausentes ficam vazios, no PATCH são mantidos
+func setField(dst *string, value *string, replace bool) {
+	if value != nil {
+		*dst = *value
+	} else if replace {
+		*dst = ""
+	}
+}
+
+// partnerRequest é o corpo aceito no PUT e PATCH de parceiros; a chave partner_id não é editável
+type partnerRequest struct {
+	PartnerName *string    `json:"partner_name"`
+	MpnID       *string    `json:"mpn_id"`
+	Tier2MpnID  *string    `json:"tier2_mpn_id"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+// fields lista os campos informados, validados no PATCH
+func (req partnerRequest) fields() []string {
+	return presentFields(map[string]*string{
+		"partner_name": req.PartnerName,
+		"mpn_id":       req.MpnID,
+		"tier2_mpn_id": req.Tier2MpnID,
+	})
+}
+
+func (req partnerRequest) apply(p *models.Partner, replace bool) {
+	setField(&p.PartnerName, req.PartnerName, replace)
+	setField(&p.MpnID, req.MpnID, replace)
+	setField(&p.Tier2MpnID, req.Tier2MpnID, replace)
+}
+
+// customerRequest é o corpo aceito no PUT e PATCH de clientes; a chave customer_id não é editável
+type customerRequest struct {
+	CustomerName       *string    `json:"customer_name"`
+	CustomerDomainName *string    `json:"customer_domain_name"`
+	Country            *string    `json:"country"`
+	UpdatedAt          *time.Time `json:"updated_at"`
+}
+
+// fields lista os campos informados, validados no PATCH
+func (req customerRequest) fields() []string {
+	return presentFields(map[string]*string{
+		"customer_name":        req.CustomerName,
+		"customer_domain_name": req.CustomerDomainName,
+		"country":              req.Country,
+	})
+}
+
+func (req customerRequest) apply(c *models.Customer, replace bool) {
+	setField(&c.CustomerName, req.CustomerName, replace)
+	setField(&c.CustomerDomainName, req.CustomerDomainName, replace)
+	setField(&c.Country, req.Country, replace)
+}
+
+// productRequest é o corpo aceito no PUT e PATCH de produtos; a chave product_id não é editável
+type productRequest struct {
+	SkuID       *string    `json:"sku_id"`
+	SkuName     *string    `json:"sku_name"`
+	ProductName *string    `json:"product_name"`
+	MeterType   *string    `json:"meter_type"`
+	Category    *string    `json:"category"`
+	SubCategory *string    `json:"sub_category"`
+	UnitType    *string    `json:"unit_type"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+// fields lista os campos informados, validados no PATCH
+func (req productRequest) fields() []string {
+	return presentFields(map[string]*string{
+		"sku_id":       req.SkuID,
+		"sku_name":     req.SkuName,
+		"product_name": req.ProductName,
+		"meter_type":   req.MeterType,
+		"category":     req.Category,
+		"sub_category": req.SubCategory,
+		"unit_type":    req.UnitType,
+	})
+}
+
+func (req productRequest) apply(p *models.Product, replace bool) {
+	setField(&p.SkuID, req.SkuID, replace)
+	setField(&p.SkuName, req.SkuName, replace)
+	setField(&p.ProductName, req.ProductName, replace)
+	setField(&p.MeterType, req.MeterType, replace)
+	setField(&p.Category, req.Category, replace)
+	setField(&p.SubCategory, req.SubCategory, replace)
+	setField(&p.UnitType, req.UnitType, replace)
+}
+
+// writeEntityError responde erros de escrita: 400 para validação, 409 para conflito de versão
+// ou entidade em uso
+func writeEntityError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidParameter):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fmt.Sprintf("Erro ao %s: %v", action, err), http.StatusInternalServerError)
+	}
+}
+
+// writeUsages responde a lista de usos de uma entidade
+func writeUsages(w http.ResponseWriter, usages []models.Usage, err error, entity string) {
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar uso do %s: %v", entity, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usages)
+}
+
+// ListPartnersHandler lista os parceiros
+func (h *Handler) ListPartnersHandler(w http.ResponseWriter, r *http.Request) {
+	partners, err := h.service.GetAllPartners(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar parceiros: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if partners == nil {
+		partners = []models.Partner{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(partners)
+}
+
+// GetPartnerHandler retorna um parceiro
+func (h *Handler) GetPartnerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do parceiro inválido", http.StatusBadRequest)
+		return
+	}
+
+	partner, err := h.service.GetPartner(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar parceiro: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if partner == nil {
+		http.Error(w, "Parceiro não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(partner)
+}
+
+// UpdatePartnerHandler substitui os atributos de um parceiro
+func (h *Handler) UpdatePartnerHandler(w http.ResponseWriter, r *http.Request) {
+	h.updatePartner(w, r, true)
+}
+
+// PatchPartnerHandler altera apenas os atributos informados de um parceiro
+func (h *Handler) PatchPartnerHandler(w http.ResponseWriter, r *http.Request) {
+	h.updatePartner(w, r, false)
+}
+
+func (h *Handler) updatePartner(w http.ResponseWriter, r *http.Request, replace bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do parceiro inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req partnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+	if req.UpdatedAt == nil {
+		http.Error(w, updatedAtRequired, http.StatusBadRequest)
+		return
+	}
+
+	partner, err := h.service.GetPartner(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar parceiro: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if partner == nil {
+		http.Error(w, "Parceiro não encontrado", http.StatusNotFound)
+		return
+	}
+
+	req.apply(partner, replace)
+	var fields []string
+	if !replace {
+		fields = req.fields()
+	}
+	found, err := h.service.UpdatePartner(r.Context(), partner, fields, *req.UpdatedAt)
+	if err != nil {
+		writeEntityError(w, err, "atualizar parceiro")
+		return
+	}
+	if !found {
+		http.Error(w, "Parceiro não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(partner)
+}
+
+// DeletePartnerHandler remove um parceiro sem usos vinculados
+func (h *Handler) DeletePartnerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do parceiro inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeletePartner(r.Context(), id)
+	if err != nil {
+		writeEntityError(w, err, "remover parceiro")
+		return
+	}
+	if !found {
+		http.Error(w, "Parceiro não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPartnerUsageHandler retorna o uso detalhado de um parceiro
+func (h *Handler) GetPartnerUsageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do parceiro inválido", http.StatusBadRequest)
+		return
+	}
+
+	usages, err := h.service.GetUsageByPartner(r.Context(), id)
+	writeUsages(w, usages, err, "parceiro")
+}
+
+// GetCustomerHandler retorna um cliente
+func (h *Handler) GetCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do cliente inválido", http.StatusBadRequest)
+		return
+	}
+
+	customer, err := h.service.GetCustomer(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar cliente: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if customer == nil {
+		http.Error(w, "Cliente não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// UpdateCustomerHandler substitui os atributos de um cliente
+func (h *Handler) UpdateCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	h.updateCustomer(w, r, true)
+}
+
+// PatchCustomerHandler altera apenas os atributos informados de um cliente
+func (h *Handler) PatchCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	h.updateCustomer(w, r, false)
+}
+
+func (h *Handler) updateCustomer(w http.ResponseWriter, r *http.Request, replace bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do cliente inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req customerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+	if req.UpdatedAt == nil {
+		http.Error(w, updatedAtRequired, http.StatusBadRequest)
+		return
+	}
+
+	customer, err := h.service.GetCustomer(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar cliente: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if customer == nil {
+		http.Error(w, "Cliente não encontrado", http.StatusNotFound)
+		return
+	}
+
+	req.apply(customer, replace)
+	var fields []string
+	if !replace {
+		fields = req.fields()
+	}
+	found, err := h.service.UpdateCustomer(r.Context(), customer, fields, *req.UpdatedAt)
+	if err != nil {
+		writeEntityError(w, err, "atualizar cliente")
+		return
+	}
+	if !found {
+		http.Error(w, "Cliente não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// DeleteCustomerHandler remove um cliente sem usos vinculados
+func (h *Handler) DeleteCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do cliente inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeleteCustomer(r.Context(), id)
+	if err != nil {
+		writeEntityError(w, err, "remover cliente")
+		return
+	}
+	if !found {
+		http.Error(w, "Cliente não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListProductsHandler lista os produtos
+func (h *Handler) ListProductsHandler(w http.ResponseWriter, r *http.Request) {
+	products, err := h.service.GetAllProducts(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar produtos: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if products == nil {
+		products = []models.Product{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
+
+// GetProductHandler retorna um produto
+func (h *Handler) GetProductHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do produto inválido", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.GetProduct(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar produto: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if product == nil {
+		http.Error(w, "Produto não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// UpdateProductHandler substitui os atributos de um produto
+func (h *Handler) UpdateProductHandler(w http.ResponseWriter, r *http.Request) {
+	h.updateProduct(w, r, true)
+}
+
+// PatchProductHandler altera apenas os atributos informados de um produto
+func (h *Handler) PatchProductHandler(w http.ResponseWriter, r *http.Request) {
+	h.updateProduct(w, r, false)
+}
+
+func (h *Handler) updateProduct(w http.ResponseWriter, r *http.Request, replace bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do produto inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req productRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+	if req.UpdatedAt == nil {
+		http.Error(w, updatedAtRequired, http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.GetProduct(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar produto: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if product == nil {
+		http.Error(w, "Produto não encontrado", http.StatusNotFound)
+		return
+	}
+
+	req.apply(product, replace)
+	var fields []string
+	if !replace {
+		fields = req.fields()
+	}
+	found, err := h.service.UpdateProduct(r.Context(), product, fields, *req.UpdatedAt)
+	if err != nil {
+		writeEntityError(w, err, "atualizar produto")
+		return
+	}
+	if !found {
+		http.Error(w, "Produto não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// DeleteProductHandler remove um produto sem usos vinculados
+func (h *Handler) DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do produto inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeleteProduct(r.Context(), id)
+	if err != nil {
+		writeEntityError(w, err, "remover produto")
+		return
+	}
+	if !found {
+		http.Error(w, "Produto não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetProductUsageHandler retorna o uso detalhado de um produto
+func (h *Handler) GetProductUsageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do produto inválido", http.StatusBadRequest)
+		return
+	}
+
+	usages, err := h.service.GetUsageByProduct(r.Context(), id)
+	writeUsages(w, usages, err, "produto")
+}
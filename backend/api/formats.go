@@ -0,0 +1,570 @@
+package api
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"data-importer-api-go/internal/csvdialect"
+	"data-importer-api-go/internal/locale"
+	"data-importer-api-go/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/extrame/xls"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// Formatos de entrada reconhecidos pelo importador
+const (
+	formatCSV     = "csv"
+	formatJSONL   = "jsonl"
+	formatExcel   = "excel"
+	formatXLS     = "xls"
+	formatParquet = "parquet"
+	formatZip     = "zip"
+	formatGzip    = "gzip"
+)
+
+const unsupportedFileMessage = "Tipo de arquivo não suportado. Use .csv, .jsonl, .xlsx, .xlsm, .xls, .parquet, .zip ou .gz"
+
+// supportedExtensions mapeia a extensão do arquivo para o formato esperado
+var supportedExtensions = map[string]string{
+	".csv":     formatCSV,
+	".jsonl":   formatJSONL,
+	".ndjson":  formatJSONL,
+	".xlsx":    formatExcel,
+	".xlsm":    formatExcel,
+	".xls":     formatXLS,
+	".parquet": formatParquet,
+	".zip":     formatZip,
+	".gz":      formatGzip,
+}
+
+// Assinaturas (magic bytes) dos formatos binários
+var (
+	magicZip     = []byte("PK\x03\x04")
+	magicGzip    = []byte{0x1f, 0x8b}
+	magicParquet = []byte("PAR1")
+	magicOLE2    = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+)
+
+const (
+	// maxArchiveDepth permite um .csv.gz dentro de um .zip, mas não pacotes aninhados indefinidamente
+	maxArchiveDepth = 2
+	// maxDecompressedSize limita o conteúdo extraído de um pacote (proteção contra zip bombs)
+	maxDecompressedSize = 2 << 30
+)
+
+var errDecompressedTooLarge = fmt.Errorf("conteúdo descompactado excede o limite de %d bytes", maxDecompressedSize)
+
+// IsSupportedImportFile indica se a extensão do arquivo é aceita pelo importador
+func IsSupportedImportFile(fileName string) bool {
+	_, ok := supportedExtensions[strings.ToLower(path.Ext(fileName))]
+	return ok
+}
+
+// ImportOptions ajusta a leitura do arquivo importado
+type ImportOptions struct {
+	// Sheets escolhe planilhas por nome ou índice (a partir de 1); AllSheets importa todas as
+	// que têm o cabeçalho esperado. Vazio importa a primeira planilha com o cabeçalho esperado.
+	Sheets []string
+	// CSV sobrepõe o delimitador e/ou a codificação detectados em arquivos CSV
+	CSV csvdialect.Dialect
+	// Locale define separadores numéricos e a ordem de dia e mês; o valor zero infere ambos
+	// a partir de todas as linhas do arquivo
+	Locale locale.Locale
+}
+
+// NewImportOptions valida as opções informadas pelo usuário (formulário, JSON ou CLI)
+func NewImportOptions(sheets, delimiter, encoding, localeName string) (ImportOptions, error) {
+	opts := ImportOptions{Sheets: ParseSheetList(sheets)}
+
+	var err error
+	if opts.Locale, err = locale.Lookup(localeName); err != nil {
+		return opts, err
+	}
+	if opts.CSV.Delimiter, err = csvdialect.ParseDelimiter(delimiter); err != nil {
+		return opts, err
+	}
+	if opts.CSV.Encoding, err = csvdialect.ParseEncoding(encoding); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// importOptionsFromForm lê os campos opcionais sheets, delimiter, encoding e locale do upload
+func importOptionsFromForm(r *http.Request) (ImportOptions, error) {
+	return NewImportOptions(r.FormValue("sheets"), r.FormValue("delimiter"), r.FormValue("encoding"), r.FormValue("locale"))
+}
+
+// detectFormat identifica o formato pelos magic bytes e, para formatos texto, pela extensão.
+// Um .xlsx é também um zip; a extensão decide entre planilha e pacote.
+func detectFormat(fileName string, head []byte) string {
+	byExtension := supportedExtensions[strings.ToLower(path.Ext(fileName))]
+
+	switch {
+	case bytes.HasPrefix(head, magicParquet):
+		return formatParquet
+	case bytes.HasPrefix(head, magicGzip):
+		return formatGzip
+	case bytes.HasPrefix(head, magicOLE2):
+		return formatXLS
+	case bytes.HasPrefix(head, magicZip):
+		if byExtension == formatExcel {
+			return formatExcel
+		}
+		return formatZip
+	}
+
+	// JSON Lines começa com um objeto; qualquer outro texto é tratado como CSV
+	if trimmed := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return formatJSONL
+	}
+	if byExtension == formatJSONL || byExtension == formatExcel || byExtension == formatXLS || byExtension == formatParquet {
+		return byExtension
+	}
+	return formatCSV
+}
+
+// parsedFile reúne as entidades extraídas de um arquivo ou de todos os membros de um pacote
+type parsedFile struct {
+	Partners  []models.Partner
+	Customers []models.Customer
+	Products  []models.Product
+	Usages    []models.Usage
+}
+
+func (p *parsedFile) add(other *parsedFile) {
+	p.Partners = append(p.Partners, other.Partners...)
+	p.Customers = append(p.Customers, other.Customers...)
+	p.Products = append(p.Products, other.Products...)
+	p.Usages = append(p.Usages, other.Usages...)
+}
+
+func (p *parsedFile) summary(fileName string) models.ImportSummary {
+	return models.ImportSummary{
+		FileName:     fileName,
+		Partners:     len(p.Partners),
+		Customers:    len(p.Customers),
+		Products:     len(p.Products),
+		Usages:       len(p.Usages),
+		ParsedUsages: len(p.Usages),
+	}
+}
+
+// withResult troca as contagens lidas do arquivo pelas efetivamente gravadas
+func withResult(summary models.ImportSummary, result models.ImportResult) models.ImportSummary {
+	summary.Partners = result.Partners
+	summary.Customers = result.Customers
+	summary.Products = result.Products
+	summary.Usages = result.Usages
+	summary.QualityBatchID = result.QualityBatchID
+	summary.Rejected = result.Rejected
+	summary.Warned = result.Warned
+	return summary
+}
+
+func newParsedFile(partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage, err error) (*parsedFile, error) {
+	return &parsedFile{Partners: partners, Customers: customers, Products: products, Usages: usages}, err
+}
+
+// parseFile detecta o formato do arquivo e extrai as entidades usando o mesmo mapeamento de
+// colunas para todos os formatos. Membros de .zip/.gz são lidos e combinados.
+func (h *UploadHandler) parseFile(fileName string, file io.Reader, opts ImportOptions) (*parsedFile, error) {
+	return h.parseFileAt(fileName, file, opts, 0)
+}
+
+func (h *UploadHandler) parseFileAt(fileName string, file io.Reader, opts ImportOptions, depth int) (*parsedFile, error) {
+	reader := bufio.NewReader(file)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return &parsedFile{}, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+
+	fileFormat := detectFormat(fileName, head)
+	log.Printf("📄 Formato detectado para %s: %s", fileName, fileFormat)
+
+	switch fileFormat {
+	case formatExcel:
+		return h.processExcelFile(reader, opts)
+	case formatCSV:
+		return newParsedFile(h.processCSVFile(reader, opts))
+	case formatJSONL:
+		rows, err := readJSONLRows(reader)
+		if err != nil {
+			return &parsedFile{}, err
+		}
+		return h.parseRows(rows, opts)
+	case formatXLS:
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return &parsedFile{}, fmt.Errorf("erro ao ler arquivo: %w", err)
+		}
+		sheets, err := readXLSSheets(data, opts.Sheets)
+		if err != nil {
+			return &parsedFile{}, err
+		}
+		return h.processSheets(sheets, opts, false)
+	case formatParquet:
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return &parsedFile{}, fmt.Errorf("erro ao ler arquivo: %w", err)
+		}
+		rows, err := readParquetRows(data)
+		if err != nil {
+			return &parsedFile{}, err
+		}
+		return h.parseRows(rows, opts)
+	case formatZip, formatGzip:
+		if depth >= maxArchiveDepth {
+			return &parsedFile{}, fmt.Errorf("pacotes aninhados em excesso: %s", fileName)
+		}
+		if fileFormat == formatGzip {
+			return h.parseGzip(fileName, reader, opts, depth)
+		}
+		return h.parseZip(fileName, reader, opts, depth)
+	}
+
+	return &parsedFile{}, fmt.Errorf("tipo de arquivo não suportado: %s", fileName)
+}
+
+// parseRows aplica o mapeamento de colunas às linhas lidas de JSON Lines ou Parquet, cujos
+// números já vêm no formato canônico
+func (h *UploadHandler) parseRows(rows [][]string, opts ImportOptions) (*parsedFile, error) {
+	if len(rows) < 2 {
+		return &parsedFile{}, fmt.Errorf("arquivo deve ter pelo menos 2 linhas")
+	}
+	return newParsedFile(h.processRows(rows, opts.Locale, true))
+}
+
+// parseGzip descompacta um único arquivo; o nome interno vem do cabeçalho gzip ou do nome sem .gz
+func (h *UploadHandler) parseGzip(fileName string, file io.Reader, opts ImportOptions, depth int) (*parsedFile, error) {
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return &parsedFile{}, fmt.Errorf("erro ao abrir arquivo gzip: %w", err)
+	}
+	defer gz.Close()
+
+	inner := gz.Name
+	if inner == "" {
+		inner = strings.TrimSuffix(fileName, path.Ext(fileName))
+	}
+	return h.parseFileAt(inner, &limitedReader{r: gz, remaining: maxDecompressedSize}, opts, depth+1)
+}
+
+// parseZip importa cada membro suportado do pacote; diretórios, arquivos ocultos e
+// extensões desconhecidas são ignorados
+func (h *UploadHandler) parseZip(fileName string, file io.Reader, opts ImportOptions, depth int) (*parsedFile, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return &parsedFile{}, fmt.Errorf("erro ao ler arquivo zip: %w", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return &parsedFile{}, fmt.Errorf("erro ao abrir arquivo zip: %w", err)
+	}
+
+	// Uma planilha .xlsx renomeada também é um zip
+	for _, member := range archive.File {
+		if member.Name == "[Content_Types].xml" {
+			return h.processExcelFile(bytes.NewReader(data), opts)
+		}
+	}
+
+	result := &parsedFile{}
+	limit := &limitedReader{remaining: maxDecompressedSize}
+	imported := 0
+	for _, member := range archive.File {
+		base := path.Base(member.Name)
+		if member.FileInfo().IsDir() || strings.HasPrefix(base, ".") || strings.HasPrefix(member.Name, "__MACOSX/") {
+			continue
+		}
+		if !IsSupportedImportFile(base) {
+			log.Printf("Ignorando %s em %s: tipo não suportado", member.Name, fileName)
+			continue
+		}
+
+		rc, err := member.Open()
+		if err != nil {
+			return result, fmt.Errorf("erro ao abrir %s em %s: %w", member.Name, fileName, err)
+		}
+		limit.r = rc
+		log.Printf("📦 Processando %s de %s", member.Name, fileName)
+		parsed, err := h.parseFileAt(base, limit, opts, depth+1)
+		rc.Close()
+		result.add(parsed)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", member.Name, err)
+		}
+		imported++
+	}
+
+	if imported == 0 {
+		return result, fmt.Errorf("arquivo zip não contém arquivos suportados: %s", fileName)
+	}
+	return result, nil
+}
+
+// limitedReader falha quando o total lido ultrapassa o limite, em vez de truncar o conteúdo
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		return 0, errDecompressedTooLarge
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// readJSONLRows converte JSON Lines (um objeto por linha) em linhas com cabeçalho.
+// As colunas são a união das chaves; valores aninhados são mantidos como JSON.
+func readJSONLRows(r io.Reader) ([][]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var header []string
+	index := make(map[string]int)
+	var records []map[string]interface{}
+	for {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("erro ao ler JSON Lines (registro %d): %w", len(records)+1, err)
+		}
+
+		var newKeys []string
+		for key := range record {
+			if _, ok := index[key]; !ok {
+				newKeys = append(newKeys, key)
+			}
+		}
+		sort.Strings(newKeys)
+		for _, key := range newKeys {
+			index[key] = len(header)
+			header = append(header, key)
+		}
+		records = append(records, record)
+	}
+
+	rows := make([][]string, 0, len(records)+1)
+	rows = append(rows, header)
+	for _, record := range records {
+		row := make([]string, len(header))
+		for key, value := range record {
+			row[index[key]] = jsonCellValue(value)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func jsonCellValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// readXLSSheets lê as planilhas selecionadas de um arquivo Excel 97-2003 (.xls)
+func readXLSSheets(data []byte, requested []string) (sheets []sheetRows, err error) {
+	// A biblioteca de .xls entra em pânico com arquivos corrompidos
+	defer func() {
+		if r := recover(); r != nil {
+			sheets, err = nil, fmt.Errorf("arquivo .xls inválido: %v", r)
+		}
+	}()
+
+	workbook, err := xls.OpenReader(bytes.NewReader(data), "utf-8")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo .xls: %w", err)
+	}
+
+	names := make([]string, workbook.NumSheets())
+	for i := range names {
+		names[i] = workbook.GetSheet(i).Name
+	}
+
+	return selectSheets(names, requested, func(index int) ([][]string, error) {
+		sheet := workbook.GetSheet(index)
+		var rows [][]string
+		for i := 0; i <= int(sheet.MaxRow); i++ {
+			row := xlsRow(sheet, i)
+			if row == nil {
+				rows = append(rows, nil)
+				continue
+			}
+			cells := make([]string, row.LastCol())
+			for j := range cells {
+				cells[j] = row.Col(j)
+			}
+			rows = append(rows, cells)
+		}
+		return rows, nil
+	})
+}
+
+// xlsRow retorna nil para linhas inexistentes, que fazem WorkSheet.Row entrar em pânico
+func xlsRow(sheet *xls.WorkSheet, i int) (row *xls.Row) {
+	defer func() {
+		if recover() != nil {
+			row = nil
+		}
+	}()
+	return sheet.Row(i)
+}
+
+// readParquetRows converte as colunas folha do Parquet em linhas com cabeçalho.
+// Colunas aninhadas usam o caminho separado por ponto; valores repetidos são unidos por vírgula.
+func readParquetRows(data []byte) ([][]string, error) {
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo Parquet: %w", err)
+	}
+
+	schema := file.Schema()
+	columns := schema.Columns()
+	header := make([]string, len(columns))
+	logicalTypes := make([]*format.LogicalType, len(columns))
+	for i, columnPath := range columns {
+		header[i] = strings.Join(columnPath, ".")
+		if leaf, ok := schema.Lookup(columnPath...); ok {
+			logicalTypes[i] = leaf.Node.Type().LogicalType()
+		}
+	}
+
+	rows := make([][]string, 0, file.NumRows()+1)
+	rows = append(rows, header)
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	buffer := make([]parquet.Row, 256)
+	for {
+		n, err := reader.ReadRows(buffer)
+		for _, values := range buffer[:n] {
+			row := make([]string, len(header))
+			for _, value := range values {
+				column := value.Column()
+				if column < 0 || column >= len(row) || value.IsNull() {
+					continue
+				}
+				cell := parquetCellValue(value, logicalTypes[column])
+				if row[column] != "" {
+					cell = row[column] + "," + cell
+				}
+				row[column] = cell
+			}
+			rows = append(rows, row)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler arquivo Parquet: %w", err)
+		}
+	}
+	return rows, nil
+}
+
+// parquetCellValue formata um valor Parquet como texto, respeitando datas, timestamps e decimais
+func parquetCellValue(value parquet.Value, logicalType *format.LogicalType) string {
+	if logicalType != nil {
+		switch {
+		case logicalType.Date != nil:
+			return time.Unix(int64(value.Int32())*86400, 0).UTC().Format("2006-01-02")
+		case logicalType.Timestamp != nil:
+			var t time.Time
+			switch unit := logicalType.Timestamp.Unit; {
+			case unit.Millis != nil:
+				t = time.UnixMilli(value.Int64())
+			case unit.Micros != nil:
+				t = time.UnixMicro(value.Int64())
+			default:
+				t = time.Unix(0, value.Int64())
+			}
+			return t.UTC().Format("2006-01-02 15:04:05")
+		case logicalType.Decimal != nil:
+			return formatDecimal(parquetUnscaled(value), int(logicalType.Decimal.Scale))
+		}
+	}
+
+	switch value.Kind() {
+	case parquet.Boolean:
+		return strconv.FormatBool(value.Boolean())
+	case parquet.Int32:
+		return strconv.FormatInt(int64(value.Int32()), 10)
+	case parquet.Int64:
+		return strconv.FormatInt(value.Int64(), 10)
+	case parquet.Float:
+		return strconv.FormatFloat(float64(value.Float()), 'f', -1, 32)
+	case parquet.Double:
+		return strconv.FormatFloat(value.Double(), 'f', -1, 64)
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return string(value.ByteArray())
+	default:
+		return value.String()
+	}
+}
+
+// parquetUnscaled lê o valor inteiro de um decimal (INT32, INT64 ou bytes em complemento de dois)
+func parquetUnscaled(value parquet.Value) *big.Int {
+	switch value.Kind() {
+	case parquet.Int32:
+		return big.NewInt(int64(value.Int32()))
+	case parquet.Int64:
+		return big.NewInt(value.Int64())
+	}
+
+	raw := value.ByteArray()
+	n := new(big.Int).SetBytes(raw)
+	if len(raw) > 0 && raw[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(raw)*8)))
+	}
+	return n
+}
+
+// formatDecimal posiciona o ponto decimal de acordo com a escala
+func formatDecimal(unscaled *big.Int, scale int) string {
+	if scale <= 0 {
+		return unscaled.String()
+	}
+
+	digits := new(big.Int).Abs(unscaled).String()
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	sign := ""
+	if unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
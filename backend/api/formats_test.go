@@ -0,0 +1,361 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"data-importer-api-go/internal/locale"
+	"data-importer-api-go/internal/models"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const sampleCSV = "PartnerId,CustomerId,ProductId,UsageDate,Quantity,UnitPrice\n" +
+	"P1,C1,PR1,2024-01-15,2,10.5\n" +
+	"P1,C2,PR1,2024-01-16,1,10.5\n"
+
+const sampleJSONL = `{"PartnerId":"P1","CustomerId":"C1","ProductId":"PR1","UsageDate":"2024-01-15","Quantity":2,"UnitPrice":10.5}
+{"PartnerId":"P1","CustomerId":"C2","ProductId":"PR1","UsageDate":"2024-01-16","Quantity":1,"UnitPrice":10.5}
+`
+
+func TestDetectFormat(t *testing.T) {
+	cases := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"dados.csv", []byte("PartnerId,CustomerId"), formatCSV},
+		{"dados.jsonl", []byte(`{"a":1}`), formatJSONL},
+		{"dados.csv", []byte("  {\"a\":1}"), formatJSONL},
+		{"dados.xlsx", []byte("PK\x03\x04rest"), formatExcel},
+		{"dados.xlsm", []byte("PK\x03\x04rest"), formatExcel},
+		{"pacote.zip", []byte("PK\x03\x04rest"), formatZip},
+		{"dados.csv.gz", []byte{0x1f, 0x8b, 0x08}, formatGzip},
+		{"dados.bin", []byte("PAR1...."), formatParquet},
+		{"dados.xls", []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, formatXLS},
+		{"dados.parquet", []byte("corrompido"), formatParquet},
+	}
+
+	for _, c := range cases {
+		if got := detectFormat(c.name, c.head); got != c.want {
+			t.Errorf("detectFormat(%q, %q) = %s, want %s", c.name, c.head, got, c.want)
+		}
+	}
+}
+
+func TestIsSupportedImportFile(t *testing.T) {
+	for _, name := range []string{"a.csv", "a.JSONL", "a.ndjson", "a.xlsx", "a.xlsm", "a.xls", "a.parquet", "a.zip", "a.csv.gz"} {
+		if !IsSupportedImportFile(name) {
+			t.Errorf("%s deveria ser suportado", name)
+		}
+	}
+	for _, name := range []string{"a.txt", "a.pdf", "csv"} {
+		if IsSupportedImportFile(name) {
+			t.Errorf("%s não deveria ser suportado", name)
+		}
+	}
+}
+
+func TestReadJSONLRows(t *testing.T) {
+	rows, err := readJSONLRows(bytes.NewBufferString(`{"b":"x","a":1.50}` + "\n" + `{"a":null,"c":true,"d":{"k":"v"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"a", "b", "c", "d"},
+		{"1.50", "x", "", ""},
+		{"", "", "true", `{"k":"v"}`},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %v", rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("rows[%d][%d] = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+
+	if _, err := readJSONLRows(bytes.NewBufferString("{\"a\":1}\nnão é json")); err == nil {
+		t.Error("esperava erro para JSON inválido")
+	}
+}
+
+func TestParseFile_FormatsShareRowMapping(t *testing.T) {
+	h := &UploadHandler{}
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(sampleJSONL))
+	gw.Close()
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for name, content := range map[string][]byte{
+		"janeiro.csv":             []byte(sampleCSV),
+		"fevereiro.jsonl.gz":      gz.Bytes(),
+		"LEIAME.txt":              []byte("ignorado"),
+		"__MACOSX/._janeiro":      []byte("ignorado"),
+		"subpasta/.escondido.csv": []byte("ignorado"),
+	} {
+		w, _ := zw.Create(name)
+		w.Write(content)
+	}
+	zw.Close()
+
+	cases := []struct {
+		name     string
+		data     []byte
+		usages   int
+		quantity float64
+	}{
+		{"dados.csv", []byte(sampleCSV), 2, 3},
+		{"dados.jsonl", []byte(sampleJSONL), 2, 3},
+		{"dados.jsonl.gz", gz.Bytes(), 2, 3},
+		{"pacote.zip", zipped.Bytes(), 4, 6},
+	}
+
+	for _, c := range cases {
+		parsed, err := h.parseFile(c.name, bytes.NewReader(c.data), ImportOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(parsed.Usages) != c.usages {
+			t.Errorf("%s: usages = %d, want %d", c.name, len(parsed.Usages), c.usages)
+		}
+		var quantity float64
+		for _, u := range parsed.Usages {
+			quantity += u.Quantity
+		}
+		if quantity != c.quantity {
+			t.Errorf("%s: quantity = %v, want %v", c.name, quantity, c.quantity)
+		}
+	}
+}
+
+func TestParseFile_ZipWithoutSupportedMembers(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, _ := zw.Create("LEIAME.txt")
+	w.Write([]byte("nada aqui"))
+	zw.Close()
+
+	if _, err := (&UploadHandler{}).parseFile("pacote.zip", &zipped, ImportOptions{}); err == nil {
+		t.Error("esperava erro para zip sem arquivos suportados")
+	}
+}
+
+type parquetUsage struct {
+	PartnerID  string  `parquet:"PartnerId"`
+	CustomerID string  `parquet:"CustomerId"`
+	ProductID  string  `parquet:"ProductId"`
+	UsageDate  string  `parquet:"UsageDate"`
+	Quantity   float64 `parquet:"Quantity"`
+	UnitPrice  float64 `parquet:"UnitPrice"`
+}
+
+func TestParseFile_Parquet(t *testing.T) {
+	var buf bytes.Buffer
+	err := parquet.Write(&buf, []parquetUsage{
+		{"P1", "C1", "PR1", "2024-01-15", 2, 10.5},
+		{"P1", "C2", "PR1", "2024-01-16", 1, 10.5},
+		{"P2", "C3", "PR2", "2024-01-17", 3, 1.25},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := readParquetRows(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[3][4] != "3" || rows[3][5] != "1.25" {
+		t.Fatalf("rows = %v", rows)
+	}
+
+	parsed, err := (&UploadHandler{}).parseFile("lake-export", bytes.NewReader(buf.Bytes()), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Usages) != 3 {
+		t.Errorf("usages = %d, want 3", len(parsed.Usages))
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	cases := []struct {
+		unscaled int64
+		scale    int
+		want     string
+	}{
+		{12345, 2, "123.45"},
+		{-5, 3, "-0.005"},
+		{7, 0, "7"},
+		{100, 2, "1.00"},
+	}
+	for _, c := range cases {
+		if got := formatDecimal(big.NewInt(c.unscaled), c.scale); got != c.want {
+			t.Errorf("formatDecimal(%d, %d) = %s, want %s", c.unscaled, c.scale, got, c.want)
+		}
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	l := &limitedReader{r: bytes.NewReader(make([]byte, 10)), remaining: 4}
+	buf := make([]byte, 10)
+	if n, err := l.Read(buf); n != 4 || err != nil {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if _, err := l.Read(buf); err != errDecompressedTooLarge {
+		t.Errorf("err = %v, want errDecompressedTooLarge", err)
+	}
+}
+
+func TestParseFile_CSVDialect(t *testing.T) {
+	content := "PartnerId;CustomerId;CustomerName;ProductId;UsageDate;Quantity;UnitPrice\r\n" +
+		"P1;C1;Distribuição São João;PR1;2024-01-15;2;10\r\n"
+	latin, err := charmap.Windows1252.NewEncoder().Bytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := (&UploadHandler{}).parseFile("export.csv", bytes.NewReader(latin), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Usages) != 1 || parsed.Customers[0].CustomerName != "Distribuição São João" {
+		t.Errorf("parsed = %+v", parsed.Customers)
+	}
+
+	// Codificação forçada prevalece sobre a detecção
+	opts, err := NewImportOptions("", "semicolon", "utf-8", "")
+	if err == nil {
+		t.Error("esperava erro para delimitador com mais de um caractere")
+	}
+	opts, err = NewImportOptions("", ";", "utf-8", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err = (&UploadHandler{}).parseFile("export.csv", bytes.NewReader(latin), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Customers[0].CustomerName == "Distribuição São João" {
+		t.Error("codificação forçada deveria ser aplicada")
+	}
+}
+
+func TestParseFile_LastRowWinsForRepeatedEntities(t *testing.T) {
+	// Linhas suficientes para ocupar todos os workers; o nome do cliente muda na última linha
+	var b strings.Builder
+	b.WriteString("PartnerId,CustomerId,CustomerName,ProductId,UsageDate,Quantity,UnitPrice\n")
+	for i := 1; i <= 50; i++ {
+		name := "Contoso"
+		if i == 50 {
+			name = "Contoso Ltda"
+		}
+		fmt.Fprintf(&b, "P1,C1,%s,PR1,2024-01-15,%d,1\n", name, i)
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		parsed, err := (&UploadHandler{}).parseFile("repetido.csv", strings.NewReader(b.String()), ImportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parsed.Customers) != 1 || parsed.Customers[0].CustomerName != "Contoso Ltda" {
+			t.Fatalf("expected the last row to define the customer, got %+v", parsed.Customers)
+		}
+		for i, usage := range parsed.Usages {
+			if usage.Quantity != float64(i+1) {
+				t.Fatalf("expected usages in file order, got quantity %v at position %d", usage.Quantity, i)
+			}
+		}
+	}
+}
+
+func TestParseFile_Locale(t *testing.T) {
+	// 25/03 decide a ordem dia/mês e 1.234,50 o separador decimal para o arquivo inteiro
+	brazilian := "PartnerId;CustomerId;ProductId;UsageDate;Quantity;UnitPrice\n" +
+		"P1;C1;PR1;03/04/2024;1.234;10,5\n" +
+		"P1;C2;PR1;25/03/2024;2;1.234,50\n"
+
+	parsed, err := (&UploadHandler{}).parseFile("br.csv", strings.NewReader(brazilian), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quantity float64
+	months := make(map[time.Month]bool)
+	for _, usage := range parsed.Usages {
+		quantity += usage.Quantity
+		months[usage.UsageDate.Month()] = true
+	}
+	if len(parsed.Usages) != 2 || quantity != 1236 || !months[time.April] || !months[time.March] {
+		t.Errorf("usages = %+v", parsed.Usages)
+	}
+
+	ambiguous := "PartnerId,CustomerId,ProductId,UsageDate,Quantity,UnitPrice\n" +
+		"P1,C1,PR1,03/04/2024,2,10.5\n"
+	if _, err := (&UploadHandler{}).parseFile("amb.csv", strings.NewReader(ambiguous), ImportOptions{}); !errors.Is(err, locale.ErrAmbiguousDate) {
+		t.Errorf("err = %v, want ErrAmbiguousDate", err)
+	}
+
+	opts, err := NewImportOptions("", "", "", "en-US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err = (&UploadHandler{}).parseFile("amb.csv", strings.NewReader(ambiguous), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Usages[0].UsageDate; got.Month() != time.March || got.Day() != 4 {
+		t.Errorf("UsageDate = %v", got)
+	}
+
+	if _, err := NewImportOptions("", "", "", "xx"); err == nil {
+		t.Error("esperava erro para locale desconhecido")
+	}
+}
+
+func TestProcessExcelFile_SerialDates(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetRow("Sheet1", "A1", &[]interface{}{"PartnerId", "CustomerId", "ProductId", "UsageDate", "Quantity", "UnitPrice"})
+	f.SetSheetRow("Sheet1", "A2", &[]interface{}{"P1", "C1", "PR1", time.Date(2024, time.April, 3, 0, 0, 0, 0, time.UTC), 1234.5, 10})
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, _ := NewImportOptions("", "", "", "pt-BR")
+	parsed, err := (&UploadHandler{}).processExcelFile(bytes.NewReader(buf.Bytes()), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := parsed.Usages[0]
+	if !usage.UsageDate.Equal(time.Date(2024, time.April, 3, 0, 0, 0, 0, time.UTC)) || usage.Quantity != 1234.5 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestSummaryWithResult(t *testing.T) {
+	parsed := &parsedFile{Usages: make([]models.Usage, 10), Customers: make([]models.Customer, 3)}
+	batchID := 7
+	summary := withResult(parsed.summary("janeiro.csv"), models.ImportResult{Customers: 2, Usages: 8, QualityBatchID: &batchID, Rejected: 2, Warned: 1})
+
+	if summary.Usages != 8 || summary.ParsedUsages != 10 || summary.Customers != 2 {
+		t.Errorf("expected inserted counts with the parsed usages kept apart, got %+v", summary)
+	}
+	if summary.QualityBatchID == nil || *summary.QualityBatchID != 7 || summary.Rejected != 2 || summary.Warned != 1 {
+		t.Errorf("expected the quality batch and counts, got %+v", summary)
+	}
+}
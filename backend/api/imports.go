@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/scheduler"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ListImportedFilesHandler lista o histórico de arquivos processados pelas importações agendadas
+func (h *Handler) ListImportedFilesHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro limit inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	files, err := h.service.GetImportedFiles(r.Context(), limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar arquivos importados: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(files)
+}
+
+// s3ImportRequest identifica o objeto (key) ou o conjunto de objetos (prefix) a importar
+type s3ImportRequest struct {
+	Bucket    string   `json:"bucket"`
+	Key       string   `json:"key"`
+	Prefix    string   `json:"prefix"`
+	Sheets    []string `json:"sheets"`    // planilhas a importar de arquivos Excel
+	Delimiter string   `json:"delimiter"` // sobrepõe a detecção em arquivos CSV
+	Encoding  string   `json:"encoding"`
+	Locale    string   `json:"locale"`    // pt-BR, en-US...; vazio infere do arquivo
+}
+
+// ImportFromS3Handler importa um objeto, ou todos os objetos de um prefixo, do armazenamento S3
+func (h *Handler) ImportFromS3Handler(w http.ResponseWriter, r *http.Request) {
+	if h.objectStore == nil {
+		http.Error(w, "Armazenamento S3 não configurado", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req s3ImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+	if req.Bucket == "" {
+		req.Bucket = h.objectStore.Bucket
+	}
+	if req.Bucket == "" || (req.Key == "") == (req.Prefix == "") {
+		http.Error(w, "Informe bucket e exatamente um entre key e prefix", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := NewImportOptions(strings.Join(req.Sheets, ","), req.Delimiter, req.Encoding, req.Locale)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	keys := []string{req.Key}
+	if req.Prefix != "" {
+		objects, err := h.objectStore.ListObjects(r.Context(), req.Bucket, req.Prefix)
+		if err != nil {
+			if errors.Is(err, scheduler.ErrObjectNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, fmt.Sprintf("Erro ao listar objetos: %v", err), http.StatusBadGateway)
+			return
+		}
+		keys = keys[:0]
+		for _, object := range objects {
+			if IsSupportedImportFile(object.Name) {
+				keys = append(keys, object.Name)
+			}
+		}
+	} else if !IsSupportedImportFile(req.Key) {
+		http.Error(w, unsupportedFileMessage, http.StatusBadRequest)
+		return
+	}
+
+	importer := NewUploadHandler(h.service)
+	summaries := make([]models.ImportSummary, 0, len(keys))
+	failed := 0
+	for _, key := range keys {
+		summary, err := h.importS3Object(r.Context(), importer, req.Bucket, key, opts)
+		if err != nil {
+			if req.Key != "" && errors.Is(err, scheduler.ErrObjectNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			summary.Error = err.Error()
+			failed++
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if req.Key != "" && failed > 0 {
+		http.Error(w, fmt.Sprintf("Erro ao importar arquivo: %s", summaries[0].Error), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": failed == 0,
+		"bucket":  req.Bucket,
+		"files":   summaries,
+	})
+}
+
+// importS3Object transmite o objeto diretamente para o parser do upload
+func (h *Handler) importS3Object(ctx context.Context, importer *UploadHandler, bucket, key string, opts ImportOptions) (models.ImportSummary, error) {
+	reader, err := h.objectStore.Fetch(ctx, bucket, key)
+	if err != nil {
+		return models.ImportSummary{FileName: key}, err
+	}
+	defer reader.Close()
+
+	summary, err := importer.ImportFileWithOptions(ctx, path.Base(key), reader, opts)
+	summary.FileName = key
+	return summary, err
+}
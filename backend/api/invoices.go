@@ -0,0 +1,123 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// reconcileRequest é o corpo opcional da conciliação; control_total substitui o total do arquivo
+type reconcileRequest struct {
+	ControlTotal *float64 `json:"control_total"`
+}
+
+// ListInvoicesHandler lista as faturas derivadas das importações
+func (h *Handler) ListInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := models.InvoiceFilter{Status: q.Get("status")}
+
+	intParams := []struct {
+		name   string
+		target *int
+	}{
+		{"partner_id", &filter.PartnerID},
+		{"customer_id", &filter.CustomerID},
+		{"limit", &filter.Limit},
+	}
+	for _, p := range intParams {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Parâmetro %s inválido", p.name), http.StatusBadRequest)
+				return
+			}
+			*p.target = n
+		}
+	}
+
+	var err error
+	if filter.From, err = parseQueryDate(q.Get("from")); err != nil {
+		http.Error(w, "Parâmetro from inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseQueryDate(q.Get("to")); err != nil {
+		http.Error(w, "Parâmetro to inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	invoices, err := h.service.ListInvoices(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao buscar faturas: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invoices)
+}
+
+// GetInvoiceHandler retorna uma fatura com suas linhas
+func (h *Handler) GetInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da fatura inválido", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := h.service.GetInvoice(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar fatura: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if invoice == nil {
+		http.Error(w, "Fatura não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invoice)
+}
+
+// ReconcileInvoiceHandler concilia a soma das linhas da fatura com o total de controle do
+// arquivo ou com o informado no corpo
+func (h *Handler) ReconcileInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da fatura inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req reconcileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := h.service.ReconcileInvoice(r.Context(), id, req.ControlTotal)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao conciliar fatura: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if rec == nil {
+		http.Error(w, "Fatura não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
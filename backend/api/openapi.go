@@ -0,0 +1,468 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// apiVersion é a versão publicada na página inicial e no documento OpenAPI
+const apiVersion = "1.0.0"
+
+// apiOperation documenta uma rota registrada em SetupRoutes. Os schemas de corpo e de resposta
+// são derivados por reflexão dos valores informados (structs de models ou os requests do pacote).
+type apiOperation struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Public      bool
+	// StringID indica que os parâmetros de caminho são textos, e não IDs numéricos
+	StringID bool
+	Query    []apiParam
+	Headers  []apiParam
+	// Request é o corpo JSON; Form são os campos multipart; RequestContent é o tipo de um
+	// corpo binário
+	Request        interface{}
+	Form           []apiParam
+	RequestContent string
+	// Status é o status de sucesso (zero é 200). Response é o corpo JSON de sucesso, ou
+	// apiAlternatives quando há mais de um formato; Content é o tipo de uma resposta não JSON.
+	Status   int
+	Response interface{}
+	Content  string
+}
+
+// apiParam é um parâmetro de query, cabeçalho ou campo de formulário
+type apiParam struct {
+	Name        string
+	Type        string
+	Format      string
+	Description string
+	Required    bool
+}
+
+// apiAlternatives lista os formatos possíveis de uma resposta (oneOf)
+type apiAlternatives []interface{}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+var (
+	openAPIOnce     sync.Once
+	openAPIDocument []byte
+	openAPIErr      error
+)
+
+// OpenAPIHandler retorna o documento OpenAPI 3 de todas as rotas da API
+func (h *Handler) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() {
+		openAPIDocument, openAPIErr = json.Marshal(buildOpenAPI(apiOperations))
+	})
+	if openAPIErr != nil {
+		http.Error(w, fmt.Sprintf("Erro ao gerar documento OpenAPI: %v", openAPIErr), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDocument)
+}
+
+// DocsHandler serve o Swagger UI apontando para /openapi.json. Os arquivos vêm do próprio binário
+// e a política de conteúdo impede carregar scripts de outra origem.
+func (h *Handler) DocsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, err := fs.Stat(swaggerUIAssets, "swagger-ui-bundle.js"); err != nil {
+		http.Error(w, "Swagger UI não incluído no build: execute scripts/fetch-swagger-ui.sh", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", swaggerUIPolicy)
+	w.Write([]byte(swaggerUIPage))
+}
+
+// DocsAssetHandler serve os arquivos do Swagger UI embutidos em api/swaggerui
+func (h *Handler) DocsAssetHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "asset")
+	contentType, ok := swaggerUIAssetTypes[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	content, err := fs.ReadFile(swaggerUIAssets, name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(content)
+}
+
+// apiEndpoints lista as rotas no formato "MÉTODO caminho", separadas entre públicas e protegidas
+func apiEndpoints() (public, protected []string) {
+	for _, op := range apiOperations {
+		endpoint := fmt.Sprintf("%-4s %s", op.Method, op.Path)
+		if op.Public {
+			public = append(public, endpoint)
+		} else {
+			protected = append(protected, endpoint)
+		}
+	}
+	return public, protected
+}
+
+// buildOpenAPI monta o documento OpenAPI 3.0 a partir das operações
+func buildOpenAPI(ops []apiOperation) map[string]interface{} {
+	b := newSchemaBuilder()
+	paths := make(map[string]map[string]interface{})
+	var tags []interface{}
+	seenTags := make(map[string]bool)
+
+	for _, op := range ops {
+		item, ok := paths[op.Path]
+		if !ok {
+			item = make(map[string]interface{})
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = b.operation(op)
+
+		if !seenTags[op.Tag] {
+			seenTags[op.Tag] = true
+			tags = append(tags, map[string]interface{}{"name": op.Tag})
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Data Importer API",
+			"version":     apiVersion,
+			"description": "Importação de dados de faturamento de parceiros e relatórios de custos. As rotas em /api exigem o token JWT obtido em /auth/login.",
+		},
+		"servers": []interface{}{map[string]interface{}{"url": "/"}},
+		"tags":    tags,
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": b.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+// schemaBuilder converte tipos Go em schemas, registrando as structs nomeadas em components
+type schemaBuilder struct {
+	components map[string]interface{}
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: make(map[string]interface{}), names: make(map[reflect.Type]string)}
+}
+
+func (b *schemaBuilder) operation(op apiOperation) map[string]interface{} {
+	o := map[string]interface{}{
+		"tags":    []string{op.Tag},
+		"summary": op.Summary,
+	}
+	if op.Description != "" {
+		o["description"] = op.Description
+	}
+
+	var params []interface{}
+	for _, m := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+		schema := map[string]interface{}{"type": "integer"}
+		if op.StringID {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		params = append(params, map[string]interface{}{"name": m[1], "in": "path", "required": true, "schema": schema})
+	}
+	for _, p := range op.Query {
+		params = append(params, p.parameter("query"))
+	}
+	for _, p := range op.Headers {
+		params = append(params, p.parameter("header"))
+	}
+	if len(params) > 0 {
+		o["parameters"] = params
+	}
+
+	switch {
+	case op.Request != nil:
+		o["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(b.schemaOf(op.Request)),
+		}
+	case len(op.Form) > 0:
+		properties := make(map[string]interface{})
+		var required []string
+		for _, p := range op.Form {
+			property := p.schema()
+			if p.Description != "" {
+				property["description"] = p.Description
+			}
+			properties[p.Name] = property
+			if p.Required {
+				required = append(required, p.Name)
+			}
+		}
+		form := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			form["required"] = required
+		}
+		o["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"multipart/form-data": map[string]interface{}{"schema": form}},
+		}
+	case op.RequestContent != "":
+		o["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{op.RequestContent: map[string]interface{}{"schema": binarySchema()}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	switch {
+	case op.Response != nil:
+		success["content"] = jsonContent(b.schemaOf(op.Response))
+	case strings.HasPrefix(op.Content, "text/"):
+		success["content"] = map[string]interface{}{op.Content: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+	case op.Content != "":
+		success["content"] = map[string]interface{}{op.Content: map[string]interface{}{"schema": binarySchema()}}
+	}
+
+	responses := map[string]interface{}{
+		strconv.Itoa(status): success,
+		"default": map[string]interface{}{
+			"description": "Erro; o corpo traz a mensagem em texto simples",
+			"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+		},
+	}
+	if !op.Public {
+		responses["401"] = map[string]interface{}{"description": "Token ausente ou inválido"}
+		o["security"] = []interface{}{map[string]interface{}{"bearerAuth": []string{}}}
+	}
+	o["responses"] = responses
+
+	return o
+}
+
+func (p apiParam) schema() map[string]interface{} {
+	schema := map[string]interface{}{"type": p.Type}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+	return schema
+}
+
+func (p apiParam) parameter(in string) map[string]interface{} {
+	param := map[string]interface{}{
+		"name":   p.Name,
+		"in":     in,
+		"schema": p.schema(),
+	}
+	if p.Description != "" {
+		param["description"] = p.Description
+	}
+	if p.Required {
+		param["required"] = true
+	}
+	return param
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+func binarySchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string", "format": "binary"}
+}
+
+// schemaOf retorna o schema do valor de exemplo de uma operação
+func (b *schemaBuilder) schemaOf(v interface{}) map[string]interface{} {
+	if alternatives, ok := v.(apiAlternatives); ok {
+		var oneOf []interface{}
+		for _, alt := range alternatives {
+			oneOf = append(oneOf, b.schemaOf(alt))
+		}
+		return map[string]interface{}{"oneOf": oneOf}
+	}
+	return b.schema(reflect.TypeOf(v))
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schema converte um tipo seguindo as regras de encoding/json: ponteiros viram nullable e
+// structs nomeadas viram referências a components
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := b.schema(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + b.register(t)}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	}
+	// interface{} e demais tipos aceitam qualquer valor JSON
+	return map[string]interface{}{}
+}
+
+// register adiciona a struct em components e retorna o nome usado; nomes repetidos em
+// pacotes diferentes recebem o pacote como prefixo
+func (b *schemaBuilder) register(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := exportedName(t.Name())
+	if _, taken := b.components[name]; taken {
+		name = exportedName(path.Base(t.PkgPath())) + name
+	}
+	b.names[t] = name
+	b.components[name] = map[string]interface{}{} // reservado antes de descer nos campos
+	b.components[name] = b.object(t)
+	return name
+}
+
+// object monta o schema de uma struct a partir das tags json; structs embutidas sem nome
+// têm os campos promovidos, como no encoding/json
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	b.addFields(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.addFields(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if strings.Contains(","+options+",", ",string,") {
+			properties[name] = map[string]interface{}{"type": "string"}
+			continue
+		}
+		properties[name] = b.schema(field.Type)
+	}
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+//go:embed swaggerui
+var swaggerUIFiles embed.FS
+
+// swaggerUIAssets é o conteúdo de api/swaggerui: a cópia fixada do swagger-ui-dist (veja
+// scripts/fetch-swagger-ui.sh) e a inicialização da página
+var swaggerUIAssets fs.FS = func() fs.FS {
+	sub, err := fs.Sub(swaggerUIFiles, "swaggerui")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
+
+// swaggerUIAssetTypes lista os arquivos servidos em /docs/{asset} e seus tipos
+var swaggerUIAssetTypes = map[string]string{
+	"swagger-ui.css":         "text/css; charset=utf-8",
+	"swagger-ui-bundle.js":   "application/javascript; charset=utf-8",
+	"swagger-initializer.js": "application/javascript; charset=utf-8",
+}
+
+// swaggerUIPolicy restringe a página à própria origem; o Swagger UI aplica estilos inline e
+// usa imagens em data URI
+const swaggerUIPolicy = "default-src 'self'; style-src 'self' 'unsafe-inline'; img-src 'self' data:"
+
+// swaggerUIPage carrega o Swagger UI da própria API e aponta para o documento servido em /openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Data Importer API - Documentação</title>
+    <link rel="stylesheet" href="/docs/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="/docs/swagger-ui-bundle.js"></script>
+    <script src="/docs/swagger-initializer.js"></script>
+</body>
+</html>`
@@ -0,0 +1,376 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/uploads"
+	"net/http"
+)
+
+// Parâmetros repetidos entre as operações
+var (
+	asOfParam  = apiParam{Name: "as_of", Type: "string", Description: "Data (YYYY-MM-DD, até o fim do dia) ou instante RFC 3339 da visão dos dados"}
+	fromParam  = apiParam{Name: "from", Type: "string", Format: "date", Description: "Data inicial (YYYY-MM-DD)"}
+	toParam    = apiParam{Name: "to", Type: "string", Format: "date", Description: "Data final (YYYY-MM-DD)"}
+	limitParam = apiParam{Name: "limit", Type: "integer", Description: "Quantidade máxima de itens"}
+)
+
+// apiOperations documenta todas as rotas de SetupRoutes; o teste de cobertura falha quando uma
+// rota é registrada sem a operação correspondente
+var apiOperations = []apiOperation{
+	// Rotas públicas
+	{Method: http.MethodGet, Path: "/", Tag: "Geral", Summary: "Página inicial da API", Public: true,
+		Description: "Retorna a página HTML; com Accept: application/json, retorna as informações do serviço e a lista de rotas.",
+		Content:     "text/html"},
+	{Method: http.MethodPost, Path: "/auth/login", Tag: "Autenticação", Summary: "Autentica o usuário e retorna um token JWT", Public: true,
+		Request: models.LoginRequest{}, Response: models.LoginResponse{}},
+	{Method: http.MethodPost, Path: "/auth/refresh", Tag: "Autenticação", Summary: "Troca um token ainda válido por um novo, com nova validade",
+		Response: models.LoginResponse{}},
+	{Method: http.MethodGet, Path: "/health", Tag: "Geral", Summary: "Status de saúde da aplicação", Public: true,
+		Response: struct {
+			Status  string `json:"status"`
+			Service string `json:"service"`
+		}{}},
+	{Method: http.MethodGet, Path: "/test", Tag: "Geral", Summary: "Informações de teste da API", Public: true,
+		Response: struct {
+			Status    string            `json:"status"`
+			Message   string            `json:"message"`
+			Timestamp string            `json:"timestamp"`
+			Endpoints map[string]string `json:"endpoints"`
+		}{}},
+	{Method: http.MethodGet, Path: "/debug/data", Tag: "Geral", Summary: "Contagem e amostras dos dados no banco", Public: true,
+		Response: struct {
+			Status     string                 `json:"status"`
+			Timestamp  string                 `json:"timestamp"`
+			DataCounts map[string]int         `json:"data_counts"`
+			SampleData map[string]interface{} `json:"sample_data"`
+		}{}},
+	{Method: http.MethodGet, Path: "/debug/ids", Tag: "Geral", Summary: "Teste da resolução de IDs", Public: true,
+		Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "Geral", Summary: "Este documento OpenAPI", Public: true,
+		Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/docs", Tag: "Geral", Summary: "Documentação interativa (Swagger UI)", Public: true,
+		Content: "text/html"},
+	{Method: http.MethodGet, Path: "/docs/{asset}", Tag: "Geral", Summary: "Arquivos do Swagger UI servidos pela própria API", Public: true,
+		StringID: true, Content: "application/javascript"},
+
+	// Clientes
+	{Method: http.MethodGet, Path: "/api/customers", Tag: "Clientes", Summary: "Lista os clientes",
+		Response: []models.Customer{}},
+	{Method: http.MethodGet, Path: "/api/customers/{id}", Tag: "Clientes", Summary: "Retorna um cliente",
+		Response: models.Customer{}},
+	{Method: http.MethodPut, Path: "/api/customers/{id}", Tag: "Clientes", Summary: "Substitui os campos editáveis de um cliente",
+		Description: "updated_at é obrigatório e deve ser o valor lido; se o registro mudou desde então, retorna 409.",
+		Request:     customerRequest{}, Response: models.Customer{}},
+	{Method: http.MethodPatch, Path: "/api/customers/{id}", Tag: "Clientes", Summary: "Altera apenas os campos informados de um cliente",
+		Description: "updated_at é obrigatório e deve ser o valor lido; se o registro mudou desde então, retorna 409.",
+		Request:     customerRequest{}, Response: models.Customer{}},
+	{Method: http.MethodDelete, Path: "/api/customers/{id}", Tag: "Clientes", Summary: "Remove um cliente sem usos",
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/customers/{id}/usage", Tag: "Clientes", Summary: "Usos de um cliente",
+		Response: []models.Usage{}},
+	{Method: http.MethodGet, Path: "/api/customers/{id}/versions", Tag: "Clientes", Summary: "Histórico de versões de um cliente",
+		Response: []models.DimensionVersion{}},
+	{Method: http.MethodPut, Path: "/api/customers/{id}/group", Tag: "Grupos de Clientes", Summary: "Associa manualmente um cliente a um grupo",
+		Description: "group_id nulo desfaz a associação manual.",
+		Request: struct {
+			GroupID *int `json:"group_id"`
+		}{}, Response: models.Customer{}},
+
+	// Grupos de clientes
+	{Method: http.MethodGet, Path: "/api/customer-groups", Tag: "Grupos de Clientes", Summary: "Lista os grupos",
+		Response: []models.CustomerGroup{}},
+	{Method: http.MethodPost, Path: "/api/customer-groups", Tag: "Grupos de Clientes", Summary: "Cria um grupo",
+		Request: customerGroupRequest{}, Status: http.StatusCreated, Response: models.CustomerGroup{}},
+	{Method: http.MethodPost, Path: "/api/customer-groups/apply", Tag: "Grupos de Clientes", Summary: "Reaplica os padrões de domínio aos clientes",
+		Response: struct {
+			Updated int `json:"updated"`
+		}{}},
+	{Method: http.MethodGet, Path: "/api/customer-groups/{id}", Tag: "Grupos de Clientes", Summary: "Retorna um grupo com seus clientes",
+		Response: models.CustomerGroupDetail{}},
+	{Method: http.MethodPut, Path: "/api/customer-groups/{id}", Tag: "Grupos de Clientes", Summary: "Substitui um grupo",
+		Request: customerGroupRequest{}, Response: models.CustomerGroup{}},
+	{Method: http.MethodDelete, Path: "/api/customer-groups/{id}", Tag: "Grupos de Clientes", Summary: "Remove um grupo",
+		Status: http.StatusNoContent},
+
+	// Parceiros
+	{Method: http.MethodGet, Path: "/api/partners", Tag: "Parceiros", Summary: "Lista os parceiros",
+		Response: []models.Partner{}},
+	{Method: http.MethodGet, Path: "/api/partners/{id}", Tag: "Parceiros", Summary: "Retorna um parceiro",
+		Response: models.Partner{}},
+	{Method: http.MethodPut, Path: "/api/partners/{id}", Tag: "Parceiros", Summary: "Substitui os campos editáveis de um parceiro",
+		Description: "updated_at é obrigatório e deve ser o valor lido; se o registro mudou desde então, retorna 409.",
+		Request:     partnerRequest{}, Response: models.Partner{}},
+	{Method: http.MethodPatch, Path: "/api/partners/{id}", Tag: "Parceiros", Summary: "Altera apenas os campos informados de um parceiro",
+		Description: "updated_at é obrigatório e deve ser o valor lido; se o registro mudou desde então, retorna 409.",
+		Request:     partnerRequest{}, Response: models.Partner{}},
+	{Method: http.MethodDelete, Path: "/api/partners/{id}", Tag: "Parceiros", Summary: "Remove um parceiro sem usos",
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/partners/{id}/usage", Tag: "Parceiros", Summary: "Usos de um parceiro",
+		Response: []models.Usage{}},
+	{Method: http.MethodGet, Path: "/api/partners/{id}/versions", Tag: "Parceiros", Summary: "Histórico de versões de um parceiro",
+		Response: []models.DimensionVersion{}},
+
+	// Produtos
+	{Method: http.MethodGet, Path: "/api/products", Tag: "Produtos", Summary: "Lista os produtos",
+		Response: []models.Product{}},
+	{Method: http.MethodGet, Path: "/api/products/{id}", Tag: "Produtos", Summary: "Retorna um produto",
+		Response: models.Product{}},
+	{Method: http.MethodPut, Path: "/api/products/{id}", Tag: "Produtos", Summary: "Substitui os campos editáveis de um produto",
+		Description: "updated_at é obrigatório e deve ser o valor lido; se o registro mudou desde então, retorna 409.",
+		Request:     productRequest{}, Response: models.Product{}},
+	{Method: http.MethodPatch, Path: "/api/products/{id}", Tag: "Produtos", Summary: "Altera apenas os campos informados de um produto",
+		Description: "updated_at é obrigatório e deve ser o valor lido; se o registro mudou desde então, retorna 409.",
+		Request:     productRequest{}, Response: models.Product{}},
+	{Method: http.MethodDelete, Path: "/api/products/{id}", Tag: "Produtos", Summary: "Remove um produto sem usos",
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/products/{id}/usage", Tag: "Produtos", Summary: "Usos de um produto",
+		Response: []models.Usage{}},
+	{Method: http.MethodGet, Path: "/api/products/{id}/versions", Tag: "Produtos", Summary: "Histórico de versões de um produto",
+		Response: []models.DimensionVersion{}},
+
+	// Busca
+	{Method: http.MethodGet, Path: "/api/search", Tag: "Busca", Summary: "Busca clientes, produtos e parceiros",
+		Query: []apiParam{
+			{Name: "q", Type: "string", Description: "Texto buscado, de 2 a 100 caracteres", Required: true},
+			{Name: "types", Type: "string", Description: "customer, product e/ou partner, separados por vírgula; padrão todos"},
+			{Name: "limit", Type: "integer", Description: "Padrão 20, máximo 100"},
+		},
+		Response: models.SearchResponse{}},
+
+	// Relatórios
+	{Method: http.MethodGet, Path: "/api/reports/billing/monthly", Tag: "Relatórios", Summary: "Faturamento por mês",
+		Query: []apiParam{asOfParam}, Response: []models.BillingReport{}},
+	{Method: http.MethodGet, Path: "/api/reports/billing/by-product", Tag: "Relatórios", Summary: "Faturamento por produto",
+		Query: []apiParam{asOfParam}, Response: []models.BillingByProduct{}},
+	{Method: http.MethodGet, Path: "/api/reports/billing/by-partner", Tag: "Relatórios", Summary: "Faturamento por parceiro",
+		Query: []apiParam{asOfParam}, Response: []models.BillingByPartner{}},
+	{Method: http.MethodGet, Path: "/api/reports/billing/by-category", Tag: "Relatórios", Summary: "Faturamento por categoria",
+		Query: []apiParam{asOfParam}, Response: []models.CategoryBillingReport{}},
+	{Method: http.MethodGet, Path: "/api/reports/billing/by-resource", Tag: "Relatórios", Summary: "Faturamento por recurso",
+		Query: []apiParam{asOfParam}, Response: []models.ResourceBillingReport{}},
+	{Method: http.MethodGet, Path: "/api/reports/billing/by-customer", Tag: "Relatórios", Summary: "Faturamento por cliente",
+		Description: "Com group_by=customer_group, consolida os clientes por grupo.",
+		Query: []apiParam{
+			asOfParam,
+			{Name: "group_by", Type: "string", Description: "customer_group"},
+		},
+		Response: apiAlternatives{[]models.CustomerBillingReport{}, []models.CustomerGroupBillingReport{}}},
+	{Method: http.MethodGet, Path: "/api/reports/billing/timeseries", Tag: "Relatórios", Summary: "Série temporal de faturamento",
+		Query: []apiParam{
+			{Name: "granularity", Type: "string", Description: "day, week, month, quarter ou year"},
+			{Name: "group_by", Type: "string", Description: "Dimensão de agrupamento, inclusive tag:<chave>"},
+			{Name: "compare", Type: "string", Description: "previous_period ou previous_year"},
+			{Name: "fill_gaps", Type: "boolean", Description: "Preenche com zero os períodos sem uso"},
+			fromParam, toParam, asOfParam,
+		},
+		Response: models.BillingTimeseriesReport{}},
+	{Method: http.MethodGet, Path: "/api/reports/aggregate", Tag: "Relatórios", Summary: "Agregação ad-hoc dos usos",
+		Description: "Filtros no formato filter.<dimensão>=valor, repetíveis para múltiplos valores.",
+		Query: []apiParam{
+			{Name: "dimensions", Type: "string", Description: "Dimensões separadas por vírgula, inclusive tag:<chave>"},
+			{Name: "measures", Type: "string", Description: "Medidas separadas por vírgula"},
+			{Name: "sort", Type: "string", Description: "Dimensão ou medida selecionada; prefixo - para decrescente"},
+			limitParam, fromParam, toParam, asOfParam,
+		},
+		Response: models.AggregationResult{}},
+	{Method: http.MethodGet, Path: "/api/reports/top", Tag: "Relatórios", Summary: "Ranking com análise de Pareto",
+		Query: []apiParam{
+			{Name: "dimension", Type: "string", Description: "Padrão customer"},
+			{Name: "metric", Type: "string", Description: "sum_billing (padrão), sum_quantity ou count"},
+			{Name: "n", Type: "integer", Description: "Quantidade de itens"},
+			{Name: "threshold", Type: "number", Description: "Percentual acumulado que define os itens do Pareto"},
+			fromParam, toParam, asOfParam,
+		},
+		Response: models.TopNReport{}},
+	{Method: http.MethodGet, Path: "/api/reports/forecast", Tag: "Relatórios", Summary: "Previsão de gasto do mês corrente e do próximo trimestre",
+		Query: []apiParam{
+			{Name: "scope", Type: "string", Description: "overall (padrão), customer ou partner"},
+			{Name: "id", Type: "integer", Description: "ID do cliente ou parceiro"},
+		},
+		Response: models.ForecastReport{}},
+	{Method: http.MethodGet, Path: "/api/reports/kpi", Tag: "Relatórios", Summary: "Métricas de KPI",
+		Query: []apiParam{asOfParam}, Response: models.KPIData{}},
+	{Method: http.MethodGet, Path: "/api/reports/chargeback", Tag: "Relatórios", Summary: "Custos por valor de tag após as regras de rateio",
+		Query: []apiParam{
+			{Name: "tag_key", Type: "string", Description: "Chave da tag", Required: true},
+			fromParam, toParam, asOfParam,
+		},
+		Response: models.ChargebackReport{}},
+	{Method: http.MethodGet, Path: "/api/reports/margin", Tag: "Relatórios", Summary: "Receita de revenda, custo e margem",
+		Query: []apiParam{
+			{Name: "group_by", Type: "string", Description: "customer (padrão), product ou customer_product"},
+			fromParam, toParam,
+		},
+		Response: models.MarginReport{}},
+
+	// Regras de preço
+	{Method: http.MethodGet, Path: "/api/price-rules", Tag: "Regras de Preço", Summary: "Lista as regras de preço",
+		Response: []models.PriceRule{}},
+	{Method: http.MethodPost, Path: "/api/price-rules", Tag: "Regras de Preço", Summary: "Cria uma regra de preço",
+		Request: priceRuleRequest{}, Status: http.StatusCreated, Response: models.PriceRule{}},
+	{Method: http.MethodGet, Path: "/api/price-rules/{id}", Tag: "Regras de Preço", Summary: "Retorna uma regra de preço",
+		Response: models.PriceRule{}},
+	{Method: http.MethodPut, Path: "/api/price-rules/{id}", Tag: "Regras de Preço", Summary: "Substitui uma regra de preço",
+		Request: priceRuleRequest{}, Response: models.PriceRule{}},
+	{Method: http.MethodDelete, Path: "/api/price-rules/{id}", Tag: "Regras de Preço", Summary: "Remove uma regra de preço",
+		Status: http.StatusNoContent},
+
+	// Demonstrativos
+	{Method: http.MethodGet, Path: "/api/statements", Tag: "Demonstrativos", Summary: "Lista as versões dos demonstrativos, sem o conteúdo",
+		Query: []apiParam{
+			{Name: "customer_id", Type: "integer"},
+			{Name: "tag_key", Type: "string"},
+			{Name: "tag_value", Type: "string"},
+			{Name: "period", Type: "string", Description: "YYYY-MM"},
+		},
+		Response: []models.Statement{}},
+	{Method: http.MethodPost, Path: "/api/statements", Tag: "Demonstrativos", Summary: "Gera o demonstrativo de um cliente ou centro de custo",
+		Description: "Retorna 201 com a nova versão, ou 200 com a última versão quando o conteúdo não mudou.",
+		Request:     statementRequest{}, Status: http.StatusCreated, Response: models.Statement{}},
+	{Method: http.MethodGet, Path: "/api/statements/{id}", Tag: "Demonstrativos", Summary: "Retorna uma versão com o conteúdo",
+		Response: models.Statement{}},
+	{Method: http.MethodGet, Path: "/api/statements/{id}/xlsx", Tag: "Demonstrativos", Summary: "Baixa a versão em XLSX",
+		Content: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	{Method: http.MethodGet, Path: "/api/statements/{id}/pdf", Tag: "Demonstrativos", Summary: "Baixa a versão em PDF",
+		Content: "application/pdf"},
+
+	// Regras de rateio
+	{Method: http.MethodGet, Path: "/api/allocation-rules", Tag: "Regras de Rateio", Summary: "Lista as regras de rateio",
+		Response: []models.AllocationRule{}},
+	{Method: http.MethodPost, Path: "/api/allocation-rules", Tag: "Regras de Rateio", Summary: "Cria uma regra de rateio",
+		Request: allocationRuleRequest{}, Status: http.StatusCreated, Response: models.AllocationRule{}},
+	{Method: http.MethodGet, Path: "/api/allocation-rules/{id}", Tag: "Regras de Rateio", Summary: "Retorna uma regra de rateio",
+		Response: models.AllocationRule{}},
+	{Method: http.MethodPut, Path: "/api/allocation-rules/{id}", Tag: "Regras de Rateio", Summary: "Substitui uma regra de rateio",
+		Request: allocationRuleRequest{}, Response: models.AllocationRule{}},
+	{Method: http.MethodDelete, Path: "/api/allocation-rules/{id}", Tag: "Regras de Rateio", Summary: "Remove uma regra de rateio",
+		Status: http.StatusNoContent},
+
+	// Anomalias
+	{Method: http.MethodGet, Path: "/api/anomalies", Tag: "Anomalias", Summary: "Lista as anomalias detectadas",
+		Query: []apiParam{
+			{Name: "severity", Type: "string"},
+			{Name: "acknowledged", Type: "boolean"},
+			{Name: "customer_id", Type: "integer"},
+			limitParam,
+		},
+		Response: []models.Anomaly{}},
+	{Method: http.MethodPost, Path: "/api/anomalies/detect", Tag: "Anomalias", Summary: "Executa a detecção de anomalias",
+		Response: struct {
+			Success   bool `json:"success"`
+			Anomalies int  `json:"anomalies"`
+		}{}},
+	{Method: http.MethodPost, Path: "/api/anomalies/{id}/acknowledge", Tag: "Anomalias", Summary: "Reconhece uma anomalia",
+		Response: models.Anomaly{}},
+
+	// Orçamentos
+	{Method: http.MethodGet, Path: "/api/budgets", Tag: "Orçamentos", Summary: "Lista os orçamentos",
+		Response: []models.Budget{}},
+	{Method: http.MethodPost, Path: "/api/budgets", Tag: "Orçamentos", Summary: "Cria um orçamento",
+		Request: budgetRequest{}, Status: http.StatusCreated, Response: models.Budget{}},
+	{Method: http.MethodPost, Path: "/api/budgets/evaluate", Tag: "Orçamentos", Summary: "Avalia os orçamentos e entrega alertas pendentes",
+		Response: struct {
+			Success bool `json:"success"`
+			Alerts  int  `json:"alerts"`
+		}{}},
+	{Method: http.MethodGet, Path: "/api/budgets/{id}", Tag: "Orçamentos", Summary: "Retorna um orçamento",
+		Response: models.Budget{}},
+	{Method: http.MethodPut, Path: "/api/budgets/{id}", Tag: "Orçamentos", Summary: "Substitui um orçamento",
+		Request: budgetRequest{}, Response: models.Budget{}},
+	{Method: http.MethodDelete, Path: "/api/budgets/{id}", Tag: "Orçamentos", Summary: "Remove um orçamento",
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/budgets/{id}/alerts", Tag: "Orçamentos", Summary: "Alertas de um orçamento",
+		Response: []models.BudgetAlert{}},
+
+	// Faturas
+	{Method: http.MethodGet, Path: "/api/invoices", Tag: "Faturas", Summary: "Lista as faturas",
+		Query: []apiParam{
+			{Name: "status", Type: "string"},
+			{Name: "partner_id", Type: "integer"},
+			{Name: "customer_id", Type: "integer"},
+			limitParam, fromParam, toParam,
+		},
+		Response: []models.Invoice{}},
+	{Method: http.MethodGet, Path: "/api/invoices/{id}", Tag: "Faturas", Summary: "Retorna uma fatura com suas linhas",
+		Response: models.InvoiceDetail{}},
+	{Method: http.MethodPost, Path: "/api/invoices/{id}/reconcile", Tag: "Faturas", Summary: "Concilia a fatura com o total de controle",
+		Description: "O corpo é opcional; control_total substitui o total de controle do arquivo.",
+		Request:     reconcileRequest{}, Response: models.InvoiceReconciliation{}},
+
+	// Regras de qualidade
+	{Method: http.MethodGet, Path: "/api/quality/rules", Tag: "Qualidade de Dados", Summary: "Lista as regras de qualidade",
+		Response: []models.QualityRule{}},
+	{Method: http.MethodPost, Path: "/api/quality/rules", Tag: "Qualidade de Dados", Summary: "Cria uma regra de qualidade",
+		Request: qualityRuleRequest{}, Status: http.StatusCreated, Response: models.QualityRule{}},
+	{Method: http.MethodGet, Path: "/api/quality/rules/{id}", Tag: "Qualidade de Dados", Summary: "Retorna uma regra de qualidade",
+		Response: models.QualityRule{}},
+	{Method: http.MethodPut, Path: "/api/quality/rules/{id}", Tag: "Qualidade de Dados", Summary: "Substitui uma regra de qualidade",
+		Request: qualityRuleRequest{}, Response: models.QualityRule{}},
+	{Method: http.MethodDelete, Path: "/api/quality/rules/{id}", Tag: "Qualidade de Dados", Summary: "Remove uma regra de qualidade",
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/quality/batches", Tag: "Qualidade de Dados", Summary: "Lista os lotes avaliados",
+		Query: []apiParam{limitParam}, Response: []models.QualityBatch{}},
+	{Method: http.MethodGet, Path: "/api/quality/batches/{id}", Tag: "Qualidade de Dados", Summary: "Retorna um lote com as violações",
+		Query:    []apiParam{{Name: "severity", Type: "string", Description: "Filtra as violações pela severidade"}},
+		Response: models.QualityBatch{}},
+
+	// Webhooks
+	{Method: http.MethodGet, Path: "/api/webhooks", Tag: "Webhooks", Summary: "Lista as assinaturas",
+		Response: []models.WebhookSubscription{}},
+	{Method: http.MethodPost, Path: "/api/webhooks", Tag: "Webhooks", Summary: "Cria uma assinatura",
+		Request: webhookRequest{}, Status: http.StatusCreated, Response: models.WebhookSubscription{}},
+	{Method: http.MethodGet, Path: "/api/webhooks/{id}", Tag: "Webhooks", Summary: "Retorna uma assinatura",
+		Response: models.WebhookSubscription{}},
+	{Method: http.MethodPut, Path: "/api/webhooks/{id}", Tag: "Webhooks", Summary: "Substitui uma assinatura",
+		Request: webhookRequest{}, Response: models.WebhookSubscription{}},
+	{Method: http.MethodDelete, Path: "/api/webhooks/{id}", Tag: "Webhooks", Summary: "Remove uma assinatura",
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/webhooks/{id}/deliveries", Tag: "Webhooks", Summary: "Entregas de uma assinatura",
+		Query: []apiParam{limitParam}, Response: []models.WebhookDelivery{}},
+
+	// Importações agendadas
+	{Method: http.MethodGet, Path: "/api/imports/files", Tag: "Importações", Summary: "Arquivos processados pelas importações agendadas",
+		Query: []apiParam{limitParam}, Response: []models.ImportedFile{}},
+	{Method: http.MethodPost, Path: "/api/imports/s3", Tag: "Importações", Summary: "Importa um objeto ou um prefixo do S3",
+		Request: s3ImportRequest{},
+		Response: struct {
+			Success bool                   `json:"success"`
+			Bucket  string                 `json:"bucket"`
+			Files   []models.ImportSummary `json:"files"`
+		}{}},
+
+	// Upload
+	{Method: http.MethodPost, Path: "/api/upload", Tag: "Importações", Summary: "Importa um arquivo enviado no formulário",
+		Form: []apiParam{
+			{Name: "file", Type: "string", Format: "binary", Description: "CSV, JSON Lines, Excel, Parquet, gzip ou zip", Required: true},
+			{Name: "sheets", Type: "string", Description: "Planilhas por nome ou índice, separadas por vírgula, ou *"},
+			{Name: "delimiter", Type: "string", Description: "Delimitador do CSV"},
+			{Name: "encoding", Type: "string", Description: "Codificação do CSV"},
+			{Name: "locale", Type: "string", Description: "pt-BR, en-US...; vazio infere do arquivo"},
+		},
+		Response: struct {
+			Success bool   `json:"success"`
+			Message string `json:"message"`
+			Data    struct {
+				Partners  int `json:"partners"`
+				Customers int `json:"customers"`
+				Products  int `json:"products"`
+				Usages    int `json:"usages"`
+			} `json:"data"`
+		}{}},
+
+	// Upload retomável em chunks
+	{Method: http.MethodPost, Path: "/api/uploads", Tag: "Importações", Summary: "Inicia um upload retomável",
+		Request: createUploadRequest{}, Status: http.StatusCreated, Response: uploads.Upload{}},
+	{Method: http.MethodGet, Path: "/api/uploads/{id}", Tag: "Importações", Summary: "Estado de um upload retomável", StringID: true,
+		Response: uploads.Upload{}},
+	{Method: http.MethodPatch, Path: "/api/uploads/{id}", Tag: "Importações", Summary: "Envia um chunk do upload", StringID: true,
+		Description: "Ao receber o último chunk, o arquivo é montado e importado em segundo plano.",
+		Headers: []apiParam{
+			{Name: "Upload-Offset", Type: "integer", Description: "Posição do chunk no arquivo", Required: true},
+			{Name: "Upload-Checksum", Type: "string", Description: "sha256 <hex> do chunk"},
+		},
+		RequestContent: "application/offset+octet-stream", Response: uploads.Upload{}},
+	{Method: http.MethodDelete, Path: "/api/uploads/{id}", Tag: "Importações", Summary: "Cancela um upload retomável", StringID: true,
+		Status: http.StatusNoContent},
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestOpenAPICoversAllRoutes(t *testing.T) {
+	router := NewHandler(nil).SetupRoutes()
+
+	registered := make(map[string]bool)
+	err := chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		registered[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("chi.Walk: %v", err)
+	}
+
+	documented := make(map[string]bool)
+	for _, op := range apiOperations {
+		key := op.Method + " " + op.Path
+		if documented[key] {
+			t.Errorf("operation %s documented twice", key)
+		}
+		documented[key] = true
+	}
+
+	var missing, stale []string
+	for route := range registered {
+		if !documented[route] {
+			missing = append(missing, route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			stale = append(stale, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+
+	if len(missing) > 0 {
+		t.Errorf("routes registered in SetupRoutes without an OpenAPI operation (add them to apiOperations): %v", missing)
+	}
+	if len(stale) > 0 {
+		t.Errorf("OpenAPI operations without a registered route: %v", stale)
+	}
+}
+
+func TestOpenAPIDocumentIsConsistent(t *testing.T) {
+	doc := buildOpenAPI(apiOperations)
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	var decoded struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if decoded.OpenAPI != "3.0.3" {
+		t.Errorf("openapi = %q", decoded.OpenAPI)
+	}
+
+	// Toda referência aponta para um schema registrado
+	for _, ref := range strings.Split(string(raw), `"$ref":"#/components/schemas/`)[1:] {
+		name := ref[:strings.Index(ref, `"`)]
+		if _, ok := decoded.Components.Schemas[name]; !ok {
+			t.Errorf("dangling reference to schema %q", name)
+		}
+	}
+
+	for _, name := range []string{"Customer", "Usage", "KPIData", "BillingTimeseriesReport", "CustomerRequest", "Upload"} {
+		if _, ok := decoded.Components.Schemas[name]; !ok {
+			t.Errorf("expected schema %q in components", name)
+		}
+	}
+
+	kpi := decoded.Paths["/api/reports/kpi"]["get"]
+	if kpi == nil || kpi["security"] == nil {
+		t.Errorf("expected protected kpi operation, got %v", kpi)
+	}
+	if login := decoded.Paths["/auth/login"]["post"]; login == nil || login["security"] != nil {
+		t.Errorf("expected public login operation, got %v", login)
+	}
+	if del := decoded.Paths["/api/customers/{id}"]["delete"]; del == nil {
+		t.Error("expected delete operation on /api/customers/{id}")
+	} else if _, ok := del["responses"].(map[string]interface{})["204"]; !ok {
+		t.Errorf("expected 204 response on delete, got %v", del["responses"])
+	}
+}
+
+func TestSchemaBuilder(t *testing.T) {
+	type embedded struct {
+		Shared string `json:"shared"`
+	}
+	type sample struct {
+		embedded
+		ID       int               `json:"id"`
+		Name     *string           `json:"name,omitempty"`
+		Count    int64             `json:"count,string"`
+		Tags     map[string]string `json:"tags"`
+		When     time.Time         `json:"when"`
+		Items    []float64         `json:"items"`
+		Hidden   string            `json:"-"`
+		internal string
+	}
+
+	b := newSchemaBuilder()
+	ref := b.schema(reflect.TypeOf(sample{}))
+	if ref["$ref"] != "#/components/schemas/Sample" {
+		t.Fatalf("unexpected reference: %v", ref)
+	}
+
+	props := b.components["Sample"].(map[string]interface{})["properties"].(map[string]interface{})
+	want := map[string]map[string]interface{}{
+		"shared": {"type": "string"},
+		"id":     {"type": "integer"},
+		"name":   {"type": "string", "nullable": true},
+		"count":  {"type": "string"},
+		"tags":   {"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+		"when":   {"type": "string", "format": "date-time"},
+		"items":  {"type": "array", "items": map[string]interface{}{"type": "number"}},
+	}
+	if len(props) != len(want) {
+		t.Errorf("expected %d properties, got %v", len(want), props)
+	}
+	for name, schema := range want {
+		if !reflect.DeepEqual(props[name], schema) {
+			t.Errorf("property %s = %v, want %v", name, props[name], schema)
+		}
+	}
+
+	nullable := b.schema(reflect.TypeOf(&sample{}))
+	if nullable["nullable"] != true || nullable["allOf"] == nil {
+		t.Errorf("expected nullable reference, got %v", nullable)
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	server := httptest.NewServer(NewHandler(nil).SetupRoutes())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("GET /openapi.json: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var doc map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(doc["paths"].(map[string]interface{})) == 0 {
+		t.Error("expected documented paths")
+	}
+}
+
+func TestDocsHandlerServesEmbeddedSwaggerUI(t *testing.T) {
+	original := swaggerUIAssets
+	defer func() { swaggerUIAssets = original }()
+	swaggerUIAssets = fstest.MapFS{
+		"swagger-ui.css":         {Data: []byte("body{}")},
+		"swagger-ui-bundle.js":   {Data: []byte("var SwaggerUIBundle;")},
+		"swagger-initializer.js": {Data: []byte("window.onload = null;")},
+		"README.md":              {Data: []byte("# Swagger UI")},
+	}
+
+	server := httptest.NewServer(NewHandler(nil).SetupRoutes())
+	defer server.Close()
+
+	docs, err := http.Get(server.URL + "/docs")
+	if err != nil {
+		t.Fatalf("GET /docs: %v", err)
+	}
+	page, _ := io.ReadAll(docs.Body)
+	docs.Body.Close()
+	if docs.StatusCode != http.StatusOK || !strings.HasPrefix(docs.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected /docs response: %d %s", docs.StatusCode, docs.Header.Get("Content-Type"))
+	}
+	// nada é carregado de outra origem
+	if strings.Contains(string(page), "://") || !strings.Contains(docs.Header.Get("Content-Security-Policy"), "default-src 'self'") {
+		t.Errorf("expected same-origin page, got CSP %q:\n%s", docs.Header.Get("Content-Security-Policy"), page)
+	}
+
+	cases := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{"/docs/swagger-ui-bundle.js", http.StatusOK, "application/javascript"},
+		{"/docs/swagger-ui.css", http.StatusOK, "text/css"},
+		{"/docs/README.md", http.StatusNotFound, ""},
+	}
+	for _, tc := range cases {
+		resp, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", tc.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status || !strings.HasPrefix(resp.Header.Get("Content-Type"), tc.contentType) {
+			t.Errorf("GET %s: %d %s", tc.path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+	}
+
+	// sem a cópia do swagger-ui-dist a página não é servida
+	swaggerUIAssets = fstest.MapFS{"swagger-initializer.js": {Data: []byte("")}}
+	missing, err := http.Get(server.URL + "/docs")
+	if err != nil {
+		t.Fatalf("GET /docs: %v", err)
+	}
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without vendored assets, got %d", missing.StatusCode)
+	}
+}
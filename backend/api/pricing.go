@@ -0,0 +1,208 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// priceRuleRequest é o corpo aceito na criação e atualização de regras de preço; as datas
+// de vigência usam YYYY-MM-DD
+type priceRuleRequest struct {
+	Name          string             `json:"name"`
+	RuleType      string             `json:"rule_type"`
+	CustomerID    *string            `json:"customer_id"`
+	ProductID     *string            `json:"product_id"`
+	Category      *string            `json:"category"`
+	Percent       *float64           `json:"percent"`
+	Tiers         []models.PriceTier `json:"tiers"`
+	EffectiveFrom string             `json:"effective_from"`
+	EffectiveTo   *string            `json:"effective_to"`
+	Active        *bool              `json:"active"`
+}
+
+func (req priceRuleRequest) toPriceRule() (models.PriceRule, error) {
+	rule := models.PriceRule{
+		Name:       req.Name,
+		RuleType:   req.RuleType,
+		CustomerID: req.CustomerID,
+		ProductID:  req.ProductID,
+		Category:   req.Category,
+		Percent:    req.Percent,
+		Tiers:      req.Tiers,
+		Active:     true,
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+
+	from, err := parseQueryDate(req.EffectiveFrom)
+	if err != nil {
+		return rule, fmt.Errorf("effective_from inválido, use YYYY-MM-DD")
+	}
+	if from != nil {
+		rule.EffectiveFrom = *from
+	}
+	if req.EffectiveTo != nil {
+		to, err := parseQueryDate(*req.EffectiveTo)
+		if err != nil {
+			return rule, fmt.Errorf("effective_to inválido, use YYYY-MM-DD")
+		}
+		rule.EffectiveTo = to
+	}
+	return rule, nil
+}
+
+// decodePriceRule lê o corpo da requisição, respondendo 400 se for inválido
+func decodePriceRule(w http.ResponseWriter, r *http.Request) (models.PriceRule, bool) {
+	var req priceRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return models.PriceRule{}, false
+	}
+	rule, err := req.toPriceRule()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return models.PriceRule{}, false
+	}
+	return rule, true
+}
+
+// ListPriceRulesHandler lista as regras de preço
+func (h *Handler) ListPriceRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetPriceRules(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar regras de preço: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// GetPriceRuleHandler retorna uma regra de preço
+func (h *Handler) GetPriceRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.GetPriceRule(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar regra de preço: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if rule == nil {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// CreatePriceRuleHandler cria uma regra de preço
+func (h *Handler) CreatePriceRuleHandler(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodePriceRule(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CreatePriceRule(r.Context(), &rule); err != nil {
+		writeEntityError(w, err, "criar regra de preço")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdatePriceRuleHandler substitui uma regra de preço
+func (h *Handler) UpdatePriceRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	rule, ok := decodePriceRule(w, r)
+	if !ok {
+		return
+	}
+	rule.ID = id
+
+	found, err := h.service.UpdatePriceRule(r.Context(), &rule)
+	if err != nil {
+		writeEntityError(w, err, "atualizar regra de preço")
+		return
+	}
+	if !found {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeletePriceRuleHandler remove uma regra de preço
+func (h *Handler) DeletePriceRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeletePriceRule(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao remover regra de preço: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarginReportHandler retorna receita de revenda, custo e margem por cliente e/ou produto
+func (h *Handler) MarginReportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	from, err := parseQueryDate(q.Get("from"))
+	if err != nil {
+		http.Error(w, "Parâmetro from inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	to, err := parseQueryDate(q.Get("to"))
+	if err != nil {
+		http.Error(w, "Parâmetro to inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetMarginReport(r.Context(), models.MarginQuery{
+		GroupBy: q.Get("group_by"),
+		From:    from,
+		To:      to,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao gerar relatório de margem: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// qualityRuleRequest é o corpo aceito na criação e atualização de regras de qualidade
+type qualityRuleRequest struct {
+	Name     string          `json:"name"`
+	RuleType string          `json:"rule_type"`
+	Params   json.RawMessage `json:"params"`
+	Severity string          `json:"severity"`
+	Active   *bool           `json:"active"`
+}
+
+func (req qualityRuleRequest) toQualityRule() models.QualityRule {
+	rule := models.QualityRule{
+		Name:     req.Name,
+		RuleType: req.RuleType,
+		Params:   req.Params,
+		Severity: req.Severity,
+		Active:   true,
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	return rule
+}
+
+// ListQualityRulesHandler lista as regras de qualidade
+func (h *Handler) ListQualityRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetQualityRules(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar regras de qualidade: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// GetQualityRuleHandler retorna uma regra de qualidade
+func (h *Handler) GetQualityRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.GetQualityRule(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar regra de qualidade: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if rule == nil {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// CreateQualityRuleHandler cria uma regra de qualidade
+func (h *Handler) CreateQualityRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var req qualityRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	rule := req.toQualityRule()
+	if err := h.service.CreateQualityRule(r.Context(), &rule); err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao criar regra de qualidade: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateQualityRuleHandler substitui os dados de uma regra de qualidade
+func (h *Handler) UpdateQualityRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req qualityRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	rule := req.toQualityRule()
+	rule.ID = id
+	found, err := h.service.UpdateQualityRule(r.Context(), &rule)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao atualizar regra de qualidade: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteQualityRuleHandler remove uma regra de qualidade
+func (h *Handler) DeleteQualityRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID da regra inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeleteQualityRule(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao remover regra de qualidade: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Regra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListQualityBatchesHandler lista os lotes avaliados pelas regras de qualidade
+func (h *Handler) ListQualityBatchesHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro limit inválido", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	batches, err := h.service.ListQualityBatches(r.Context(), limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar lotes de qualidade: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+// GetQualityBatchHandler retorna um lote com as violações encontradas
+func (h *Handler) GetQualityBatchHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do lote inválido", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.service.GetQualityBatch(r.Context(), id, r.URL.Query().Get("severity"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao buscar lote de qualidade: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batch == nil {
+		http.Error(w, "Lote não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batch)
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/uploads"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Limites do upload retomável
+const (
+	maxResumableUploadSize = 2 << 30 // 2 GiB por arquivo
+	maxChunkSize           = 8 << 20 // 8 MiB por requisição, para caber no ReadTimeout de 15s do servidor
+)
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// createUploadRequest declara o arquivo antes do envio dos chunks
+type createUploadRequest struct {
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"` // SHA-256 (hex) opcional do arquivo completo
+}
+
+// CreateUploadHandler inicia um upload retomável e devolve o ID usado nos chunks
+func (h *Handler) CreateUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if h.uploads == nil {
+		http.Error(w, "Upload retomável não configurado", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req createUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+	if !IsSupportedImportFile(req.FileName) {
+		http.Error(w, unsupportedFileMessage, http.StatusBadRequest)
+		return
+	}
+	if req.Size <= 0 || req.Size > maxResumableUploadSize {
+		http.Error(w, fmt.Sprintf("size deve estar entre 1 e %d bytes", maxResumableUploadSize), http.StatusBadRequest)
+		return
+	}
+	if req.Checksum != "" && !sha256Pattern.MatchString(req.Checksum) {
+		http.Error(w, "checksum deve ser um SHA-256 em hexadecimal", http.StatusBadRequest)
+		return
+	}
+
+	upload, err := h.uploads.Create(req.FileName, req.Size, req.Checksum)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao iniciar upload: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/api/uploads/"+upload.ID)
+	writeUpload(w, http.StatusCreated, upload)
+}
+
+// GetUploadHandler retorna o estado do upload; o offset indica de onde retomar
+func (h *Handler) GetUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if h.uploads == nil {
+		http.Error(w, "Upload retomável não configurado", http.StatusServiceUnavailable)
+		return
+	}
+
+	upload, err := h.uploads.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		writeUploadError(w, nil, err)
+		return
+	}
+	writeUpload(w, http.StatusOK, upload)
+}
+
+// PatchUploadHandler recebe um chunk. Cabeçalhos: Upload-Offset (obrigatório) e
+// Upload-Checksum ("sha256 <hex>", opcional). Ao receber o último chunk, o arquivo é
+// montado e importado em segundo plano.
+func (h *Handler) PatchUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if h.uploads == nil {
+		http.Error(w, "Upload retomável não configurado", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Cabeçalho Upload-Offset inválido", http.StatusBadRequest)
+		return
+	}
+
+	checksum := ""
+	if v := r.Header.Get("Upload-Checksum"); v != "" {
+		algorithm, value, _ := strings.Cut(v, " ")
+		if !strings.EqualFold(algorithm, "sha256") || !sha256Pattern.MatchString(value) {
+			http.Error(w, "Cabeçalho Upload-Checksum deve ser 'sha256 <hex>'", http.StatusBadRequest)
+			return
+		}
+		checksum = value
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxChunkSize)
+	upload, err := h.uploads.WriteChunk(id, offset, body, checksum)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("Chunk maior que o limite de %d bytes", maxChunkSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		writeUploadError(w, upload, err)
+		return
+	}
+
+	if upload.Offset == upload.Size {
+		path, err := h.uploads.Assemble(id)
+		if err != nil {
+			writeUploadError(w, nil, err)
+			return
+		}
+		go h.importAssembledUpload(id, upload.FileName, path)
+		upload.State = uploads.StateImporting
+	}
+
+	writeUpload(w, http.StatusOK, upload)
+}
+
+// DeleteUploadHandler cancela um upload e descarta os chunks recebidos
+func (h *Handler) DeleteUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if h.uploads == nil {
+		http.Error(w, "Upload retomável não configurado", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.uploads.Delete(chi.URLParam(r, "id")); err != nil {
+		writeUploadError(w, nil, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importAssembledUpload entrega o arquivo montado ao fluxo normal de importação
+func (h *Handler) importAssembledUpload(id, fileName, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Erro ao abrir upload %s montado: %v", id, err)
+		h.uploads.Finish(id, models.ImportSummary{FileName: fileName}, err)
+		return
+	}
+	defer file.Close()
+
+	log.Printf("Importando upload retomável %s (%s)", id, fileName)
+	summary, err := NewUploadHandler(h.service).ImportFile(context.Background(), fileName, file)
+	if err := h.uploads.Finish(id, summary, err); err != nil {
+		log.Printf("Erro ao registrar resultado do upload %s: %v", id, err)
+	}
+}
+
+func writeUpload(w http.ResponseWriter, status int, upload *uploads.Upload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(upload)
+}
+
+// writeUploadError traduz os erros do store; com o upload conhecido, informa o offset atual
+func writeUploadError(w http.ResponseWriter, upload *uploads.Upload, err error) {
+	if upload != nil {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	}
+
+	switch {
+	case errors.Is(err, uploads.ErrNotFound):
+		http.Error(w, "Upload não encontrado", http.StatusNotFound)
+	case errors.Is(err, uploads.ErrOffsetMismatch), errors.Is(err, uploads.ErrNotWritable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, uploads.ErrChecksumMismatch):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, uploads.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		http.Error(w, fmt.Sprintf("Erro no upload: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"data-importer-api-go/internal/auth"
 	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/scheduler"
 	"data-importer-api-go/internal/service"
+	"data-importer-api-go/internal/uploads"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -19,13 +22,25 @@ import (
 )
 
 type Handler struct {
-	service *service.Service
+	service     *service.Service
+	objectStore *scheduler.S3Source
+	uploads     *uploads.Store
 }
 
 func NewHandler(service *service.Service) *Handler {
 	return &Handler{service: service}
 }
 
+// SetUploadStore habilita os uploads retomáveis em /api/uploads
+func (h *Handler) SetUploadStore(store *uploads.Store) {
+	h.uploads = store
+}
+
+// SetObjectStore habilita a importação a partir do armazenamento S3 configurado
+func (h *Handler) SetObjectStore(source *scheduler.S3Source) {
+	h.objectStore = source
+}
+
 func (h *Handler) SetupRoutes() *chi.Mux {
 	r := chi.NewRouter()
 
@@ -34,8 +49,9 @@ func (h *Handler) SetupRoutes() *chi.Mux {
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Upload-Offset", "Upload-Checksum"},
+		ExposedHeaders: []string{"Location", "Upload-Offset", "Upload-Length"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -43,10 +59,14 @@ func (h *Handler) SetupRoutes() *chi.Mux {
 	// Rotas públicas
 	r.Get("/", h.RootHandler)
 	r.Post("/auth/login", h.LoginHandler)
+	r.With(h.AuthMiddleware).Post("/auth/refresh", h.RefreshTokenHandler)
 	r.Get("/health", h.HealthCheckHandler)
 	r.Get("/test", h.TestHandler)
 	r.Get("/debug/data", h.DebugDataHandler)
 	r.Get("/debug/ids", h.DebugIDsHandler)
+	r.Get("/openapi.json", h.OpenAPIHandler)
+	r.Get("/docs", h.DocsHandler)
+	r.Get("/docs/{asset}", h.DocsAssetHandler)
 
 	// Rotas protegidas
 	r.Route("/api", func(r chi.Router) {
@@ -54,8 +74,43 @@ func (h *Handler) SetupRoutes() *chi.Mux {
 		
 		// Clientes
 		r.Get("/customers", h.GetCustomersHandler)
+		r.Get("/customers/{id}", h.GetCustomerHandler)
+		r.Put("/customers/{id}", h.UpdateCustomerHandler)
+		r.Patch("/customers/{id}", h.PatchCustomerHandler)
+		r.Delete("/customers/{id}", h.DeleteCustomerHandler)
 		r.Get("/customers/{id}/usage", h.GetCustomerUsageHandler)
+		r.Get("/customers/{id}/versions", h.CustomerVersionsHandler)
+		r.Put("/customers/{id}/group", h.SetCustomerGroupHandler)
+
+		// Grupos de clientes
+		r.Get("/customer-groups", h.ListCustomerGroupsHandler)
+		r.Post("/customer-groups", h.CreateCustomerGroupHandler)
+		r.Post("/customer-groups/apply", h.ApplyCustomerGroupsHandler)
+		r.Get("/customer-groups/{id}", h.GetCustomerGroupHandler)
+		r.Put("/customer-groups/{id}", h.UpdateCustomerGroupHandler)
+		r.Delete("/customer-groups/{id}", h.DeleteCustomerGroupHandler)
+
+		// Parceiros
+		r.Get("/partners", h.ListPartnersHandler)
+		r.Get("/partners/{id}", h.GetPartnerHandler)
+		r.Put("/partners/{id}", h.UpdatePartnerHandler)
+		r.Patch("/partners/{id}", h.PatchPartnerHandler)
+		r.Delete("/partners/{id}", h.DeletePartnerHandler)
+		r.Get("/partners/{id}/usage", h.GetPartnerUsageHandler)
+		r.Get("/partners/{id}/versions", h.PartnerVersionsHandler)
+
+		// Produtos
+		r.Get("/products", h.ListProductsHandler)
+		r.Get("/products/{id}", h.GetProductHandler)
+		r.Put("/products/{id}", h.UpdateProductHandler)
+		r.Patch("/products/{id}", h.PatchProductHandler)
+		r.Delete("/products/{id}", h.DeleteProductHandler)
+		r.Get("/products/{id}/usage", h.GetProductUsageHandler)
+		r.Get("/products/{id}/versions", h.ProductVersionsHandler)
 		
+		// Busca
+		r.Get("/search", h.SearchHandler)
+
 		// Relatórios
 		r.Get("/reports/billing/monthly", h.MonthlyBillingHandler)
 		r.Get("/reports/billing/by-product", h.BillingByProductHandler)
@@ -63,11 +118,84 @@ func (h *Handler) SetupRoutes() *chi.Mux {
 		r.Get("/reports/billing/by-category", h.BillingByCategoryHandler)
 		r.Get("/reports/billing/by-resource", h.BillingByResourceHandler)
 		r.Get("/reports/billing/by-customer", h.BillingByCustomerHandler)
+		r.Get("/reports/billing/timeseries", h.BillingTimeseriesHandler)
+		r.Get("/reports/aggregate", h.AggregateHandler)
+		r.Get("/reports/top", h.TopNHandler)
+		r.Get("/reports/forecast", h.ForecastHandler)
 		r.Get("/reports/kpi", h.KPIHandler)
+		r.Get("/reports/chargeback", h.ChargebackHandler)
+		r.Get("/reports/margin", h.MarginReportHandler)
+
+		// Regras de preço
+		r.Get("/price-rules", h.ListPriceRulesHandler)
+		r.Post("/price-rules", h.CreatePriceRuleHandler)
+		r.Get("/price-rules/{id}", h.GetPriceRuleHandler)
+		r.Put("/price-rules/{id}", h.UpdatePriceRuleHandler)
+		r.Delete("/price-rules/{id}", h.DeletePriceRuleHandler)
+
+		// Demonstrativos
+		r.Get("/statements", h.ListStatementsHandler)
+		r.Post("/statements", h.GenerateStatementHandler)
+		r.Get("/statements/{id}", h.GetStatementHandler)
+		r.Get("/statements/{id}/xlsx", h.StatementXLSXHandler)
+		r.Get("/statements/{id}/pdf", h.StatementPDFHandler)
+
+		// Regras de rateio
+		r.Get("/allocation-rules", h.ListAllocationRulesHandler)
+		r.Post("/allocation-rules", h.CreateAllocationRuleHandler)
+		r.Get("/allocation-rules/{id}", h.GetAllocationRuleHandler)
+		r.Put("/allocation-rules/{id}", h.UpdateAllocationRuleHandler)
+		r.Delete("/allocation-rules/{id}", h.DeleteAllocationRuleHandler)
+
+		// Anomalias
+		r.Get("/anomalies", h.ListAnomaliesHandler)
+		r.Post("/anomalies/detect", h.DetectAnomaliesHandler)
+		r.Post("/anomalies/{id}/acknowledge", h.AcknowledgeAnomalyHandler)
+
+		// Orçamentos
+		r.Get("/budgets", h.ListBudgetsHandler)
+		r.Post("/budgets", h.CreateBudgetHandler)
+		r.Post("/budgets/evaluate", h.EvaluateBudgetsHandler)
+		r.Get("/budgets/{id}", h.GetBudgetHandler)
+		r.Put("/budgets/{id}", h.UpdateBudgetHandler)
+		r.Delete("/budgets/{id}", h.DeleteBudgetHandler)
+		r.Get("/budgets/{id}/alerts", h.ListBudgetAlertsHandler)
+
+		// Faturas
+		r.Get("/invoices", h.ListInvoicesHandler)
+		r.Get("/invoices/{id}", h.GetInvoiceHandler)
+		r.Post("/invoices/{id}/reconcile", h.ReconcileInvoiceHandler)
+
+		// Regras de qualidade
+		r.Get("/quality/rules", h.ListQualityRulesHandler)
+		r.Post("/quality/rules", h.CreateQualityRuleHandler)
+		r.Get("/quality/rules/{id}", h.GetQualityRuleHandler)
+		r.Put("/quality/rules/{id}", h.UpdateQualityRuleHandler)
+		r.Delete("/quality/rules/{id}", h.DeleteQualityRuleHandler)
+		r.Get("/quality/batches", h.ListQualityBatchesHandler)
+		r.Get("/quality/batches/{id}", h.GetQualityBatchHandler)
+
+		// Webhooks
+		r.Get("/webhooks", h.ListWebhooksHandler)
+		r.Post("/webhooks", h.CreateWebhookHandler)
+		r.Get("/webhooks/{id}", h.GetWebhookHandler)
+		r.Put("/webhooks/{id}", h.UpdateWebhookHandler)
+		r.Delete("/webhooks/{id}", h.DeleteWebhookHandler)
+		r.Get("/webhooks/{id}/deliveries", h.ListWebhookDeliveriesHandler)
 		
+		// Importações agendadas
+		r.Get("/imports/files", h.ListImportedFilesHandler)
+		r.Post("/imports/s3", h.ImportFromS3Handler)
+
 		// Upload 
 		uploadHandler := NewUploadHandler(h.service)
 		r.Post("/upload", uploadHandler.UploadFileHandler)
+
+		// Upload retomável em chunks
+		r.Post("/uploads", h.CreateUploadHandler)
+		r.Get("/uploads/{id}", h.GetUploadHandler)
+		r.Patch("/uploads/{id}", h.PatchUploadHandler)
+		r.Delete("/uploads/{id}", h.DeleteUploadHandler)
 	})
 
 	return r
@@ -76,9 +204,15 @@ func (h *Handler) SetupRoutes() *chi.Mux {
 // KPIHandler retorna as métricas de KPI do sistema
 func (h *Handler) KPIHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	
+
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
 	// Obter KPIs do banco de dados
-	kpiData, err := h.service.GetKPIData(ctx)
+	kpiData, err := h.service.GetKPIData(ctx, asOf)
 	if err != nil {
 		http.Error(w, "Erro ao obter KPIs: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -92,9 +226,15 @@ func (h *Handler) KPIHandler(w http.ResponseWriter, r *http.Request) {
 // BillingByCategoryHandler retorna o faturamento agrupado por categoria
 func (h *Handler) BillingByCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	
+
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
 	// Obter dados de faturamento por categoria
-	billingData, err := h.service.GetBillingByCategory(ctx)
+	billingData, err := h.service.GetBillingByCategory(ctx, asOf)
 	if err != nil {
 		http.Error(w, "Erro ao obter dados de faturamento por categoria: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -108,9 +248,15 @@ func (h *Handler) BillingByCategoryHandler(w http.ResponseWriter, r *http.Reques
 // BillingByResourceHandler retorna o faturamento agrupado por recurso
 func (h *Handler) BillingByResourceHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	
+
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
 	// Obter dados de faturamento por recurso
-	billingData, err := h.service.GetBillingByResource(ctx)
+	billingData, err := h.service.GetBillingByResource(ctx, asOf)
 	if err != nil {
 		http.Error(w, "Erro ao obter dados de faturamento por recurso: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -124,9 +270,32 @@ func (h *Handler) BillingByResourceHandler(w http.ResponseWriter, r *http.Reques
 // BillingByCustomerHandler retorna o faturamento agrupado por cliente
 func (h *Handler) BillingByCustomerHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	
+
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	// group_by=customer_group consolida os tenants de uma mesma organização
+	switch r.URL.Query().Get("group_by") {
+	case "":
+	case "customer_group":
+		groupData, err := h.service.GetBillingByCustomerGroup(ctx, asOf)
+		if err != nil {
+			http.Error(w, "Erro ao obter dados de faturamento por grupo de clientes: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(groupData)
+		return
+	default:
+		http.Error(w, "Parâmetro group_by inválido: use customer_group", http.StatusBadRequest)
+		return
+	}
+
 	// Obter dados de faturamento por cliente
-	billingData, err := h.service.GetBillingByCustomer(ctx)
+	billingData, err := h.service.GetBillingByCustomer(ctx, asOf)
 	if err != nil {
 		http.Error(w, "Erro ao obter dados de faturamento por cliente: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -137,6 +306,246 @@ func (h *Handler) BillingByCustomerHandler(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(billingData)
 }
 
+// BillingTimeseriesHandler retorna a série temporal de faturamento
+func (h *Handler) BillingTimeseriesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	from, err := parseQueryDate(q.Get("from"))
+	if err != nil {
+		http.Error(w, "Parâmetro from inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	to, err := parseQueryDate(q.Get("to"))
+	if err != nil {
+		http.Error(w, "Parâmetro to inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	asOf, err := parseAsOf(q.Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	query := models.BillingTimeseriesQuery{
+		Granularity: q.Get("granularity"),
+		GroupBy:     q.Get("group_by"),
+		Compare:     q.Get("compare"),
+		From:        from,
+		To:          to,
+		FillGaps:    q.Get("fill_gaps") == "true",
+		AsOf:        asOf,
+	}
+
+	report, err := h.service.GetBillingTimeseries(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao buscar série temporal de faturamento: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// AggregateHandler executa uma agregação ad-hoc sobre os usos
+func (h *Handler) AggregateHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	from, err := parseQueryDate(q.Get("from"))
+	if err != nil {
+		http.Error(w, "Parâmetro from inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	to, err := parseQueryDate(q.Get("to"))
+	if err != nil {
+		http.Error(w, "Parâmetro to inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	asOf, err := parseAsOf(q.Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro limit inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Filtros no formato filter.<dimensão>=valor, repetíveis para múltiplos valores
+	filters := make(map[string][]string)
+	for key, values := range q {
+		if strings.HasPrefix(key, "filter.") {
+			filters[strings.TrimPrefix(key, "filter.")] = values
+		}
+	}
+
+	query := models.AggregationQuery{
+		Dimensions: splitQueryList(q.Get("dimensions")),
+		Measures:   splitQueryList(q.Get("measures")),
+		Filters:    filters,
+		Sort:       q.Get("sort"),
+		Limit:      limit,
+		From:       from,
+		To:         to,
+		AsOf:       asOf,
+	}
+
+	result, err := h.service.Aggregate(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao executar agregação: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// TopNHandler retorna as maiores entidades de uma dimensão com análise de Pareto
+func (h *Handler) TopNHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	from, err := parseQueryDate(q.Get("from"))
+	if err != nil {
+		http.Error(w, "Parâmetro from inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	to, err := parseQueryDate(q.Get("to"))
+	if err != nil {
+		http.Error(w, "Parâmetro to inválido, use YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	asOf, err := parseAsOf(q.Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	n := 0
+	if v := q.Get("n"); v != "" {
+		n, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro n inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	threshold := 0.0
+	if v := q.Get("threshold"); v != "" {
+		threshold, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "Parâmetro threshold inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dimension := q.Get("dimension")
+	if dimension == "" {
+		dimension = "customer"
+	}
+
+	report, err := h.service.GetTopN(r.Context(), models.TopNQuery{
+		Dimension:    dimension,
+		Metric:       q.Get("metric"),
+		N:            n,
+		ThresholdPct: threshold,
+		From:         from,
+		To:           to,
+		AsOf:         asOf,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao buscar ranking: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ForecastHandler retorna a previsão de gasto do mês corrente e do próximo trimestre
+func (h *Handler) ForecastHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	entityID := 0
+	if v := q.Get("id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			http.Error(w, "Parâmetro id inválido", http.StatusBadRequest)
+			return
+		}
+		entityID = id
+	}
+
+	report, err := h.service.GetForecast(r.Context(), q.Get("scope"), entityID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao calcular previsão: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// splitQueryList converte um parâmetro separado por vírgulas em lista
+func splitQueryList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// asOfError é a mensagem de as_of inválido
+const asOfError = "Parâmetro as_of inválido, use YYYY-MM-DD ou RFC 3339"
+
+// parseAsOf converte o parâmetro opcional as_of: uma data vale até o fim do dia; um instante
+// RFC 3339 é usado como informado
+func parseAsOf(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	endOfDay := day.AddDate(0, 0, 1).Add(-time.Microsecond)
+	return &endOfDay, nil
+}
+
+// parseQueryDate converte um parâmetro opcional no formato YYYY-MM-DD
+func parseQueryDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // LoginHandler autentica o usuário e retorna um token JWT
 func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	var loginReq models.LoginRequest
@@ -169,6 +578,20 @@ func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// RefreshTokenHandler emite um novo token para o usuário de um token ainda válido
+func (h *Handler) RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	username := usernameFromRequest(r)
+
+	token, err := auth.GenerateToken(username)
+	if err != nil {
+		http.Error(w, "Erro ao gerar token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.LoginResponse{Token: token, User: username})
+}
+
 // AuthMiddleware valida o token JWT
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -230,7 +653,13 @@ func (h *Handler) GetCustomerUsageHandler(w http.ResponseWriter, r *http.Request
 
 // MonthlyBillingHandler retorna faturamento por mês
 func (h *Handler) MonthlyBillingHandler(w http.ResponseWriter, r *http.Request) {
-	reports, err := h.service.GetBillingMonthly(r.Context())
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	reports, err := h.service.GetBillingMonthly(r.Context(), asOf)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Erro ao buscar faturamento mensal: %v", err), http.StatusInternalServerError)
 		return
@@ -242,7 +671,13 @@ func (h *Handler) MonthlyBillingHandler(w http.ResponseWriter, r *http.Request)
 
 // BillingByProductHandler retorna faturamento por produto
 func (h *Handler) BillingByProductHandler(w http.ResponseWriter, r *http.Request) {
-	reports, err := h.service.GetBillingByProduct(r.Context())
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	reports, err := h.service.GetBillingByProduct(r.Context(), asOf)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Erro ao buscar faturamento por produto: %v", err), http.StatusInternalServerError)
 		return
@@ -254,7 +689,13 @@ func (h *Handler) BillingByProductHandler(w http.ResponseWriter, r *http.Request
 
 // BillingByPartnerHandler retorna faturamento por parceiro
 func (h *Handler) BillingByPartnerHandler(w http.ResponseWriter, r *http.Request) {
-	reports, err := h.service.GetBillingByPartner(r.Context())
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		http.Error(w, asOfError, http.StatusBadRequest)
+		return
+	}
+
+	reports, err := h.service.GetBillingByPartner(r.Context(), asOf)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Erro ao buscar faturamento por parceiro: %v", err), http.StatusInternalServerError)
 		return
@@ -291,50 +732,59 @@ func (h *Handler) UploadFileHandler(w http.ResponseWriter, r *http.Request) {
 	fileName := header.Filename
 	log.Printf("Arquivo recebido: %s", fileName)
 
-	var partners []models.Partner
-	var customers []models.Customer
-	var products []models.Product
-	var usages []models.Usage
+	if !IsSupportedImportFile(fileName) {
+		http.Error(w, unsupportedFileMessage, http.StatusBadRequest)
+		return
+	}
 
-	// Processar arquivo baseado na extensão
-	uploadHandler := NewUploadHandler(h.service)
-	if strings.HasSuffix(strings.ToLower(fileName), ".xlsx") {
-		partners, customers, products, usages, err = uploadHandler.processExcelFile(file)
-	} else if strings.HasSuffix(strings.ToLower(fileName), ".csv") {
-		partners, customers, products, usages, err = uploadHandler.processCSVFile(file)
-	} else {
-		http.Error(w, "Tipo de arquivo não suportado. Use .csv ou .xlsx", http.StatusBadRequest)
+	opts, err := importOptionsFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	// Processar arquivo conforme o formato detectado
+	parsed, err := NewUploadHandler(h.service).parseFile(fileName, file, opts)
+	summary := parsed.summary(fileName)
+	summary.Replaced = true
+
 	if err != nil {
 		log.Printf("Erro ao processar arquivo: %v", err)
+		summary.Error = err.Error()
+		h.service.PublishEvent(r.Context(), service.EventImportFailed, summary)
 		http.Error(w, fmt.Sprintf("Erro ao processar arquivo: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Inserir dados no banco (substituindo dados existentes)
 	log.Printf("Iniciando substituição de dados: %d partners, %d customers, %d products, %d usages", 
-		len(partners), len(customers), len(products), len(usages))
+		summary.Partners, summary.Customers, summary.Products, summary.Usages)
 	
-	err = h.service.ProcessImportDataWithReplace(r.Context(), partners, customers, products, usages)
+	result, err := h.service.ProcessImportDataWithReplace(r.Context(), parsed.Partners, parsed.Customers, parsed.Products, parsed.Usages)
 	if err != nil {
 		log.Printf("Erro ao inserir dados: %v", err)
+		summary.Error = err.Error()
+		h.service.PublishEvent(r.Context(), service.EventImportFailed, summary)
 		http.Error(w, fmt.Sprintf("Erro ao inserir dados no banco: %v", err), http.StatusInternalServerError)
 		return
 	}
 	
 	log.Printf("Dados substituídos com sucesso")
+	summary = withResult(summary, result)
+	h.service.PublishEvent(r.Context(), service.EventImportCompleted, summary)
 
 	// Resposta de sucesso
 	response := map[string]interface{}{
 		"success": true,
 		"message": "Arquivo processado e dados substituídos com sucesso",
 		"data": map[string]interface{}{
-			"partners":  len(partners),
-			"customers": len(customers),
-			"products":  len(products),
-			"usages":    len(usages),
+			"partners":         summary.Partners,
+			"customers":        summary.Customers,
+			"products":         summary.Products,
+			"usages":           summary.Usages,
+			"quality_batch_id": summary.QualityBatchID,
+			"rejected":         summary.Rejected,
+			"warned":           summary.Warned,
 		},
 	}
 
@@ -346,24 +796,17 @@ func (h *Handler) UploadFileHandler(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) RootHandler(w http.ResponseWriter, r *http.Request) {
 	// Verificar se é uma requisição JSON
 	if r.Header.Get("Accept") == "application/json" {
-    apiInfo := map[string]interface{}{
+		public, protected := apiEndpoints()
+		apiInfo := map[string]interface{}{
 			"service": "Data Importer API",
-			"version": "1.0.0",
+			"version": apiVersion,
 			"status":  "running",
 			"endpoints": map[string]interface{}{
-				"public": []string{
-					"GET  /",
-					"GET  /health",
-					"POST /auth/login",
-				},
-				"protected": []string{
-					"GET  /api/customers",
-					"GET  /api/customers/{id}/usage",
-					"GET  /api/reports/billing/monthly",
-					"GET  /api/reports/billing/by-product",
-					"GET  /api/reports/billing/by-partner",
-				},
+				"public":    public,
+				"protected": protected,
 			},
+			"openapi":       "/openapi.json",
+			"docs":          "/docs",
 			"documentation": "https://github.com/GabrielDK-vish/data-importer-api-go",
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -649,35 +1092,12 @@ func (h *Handler) RootHandler(w http.ResponseWriter, r *http.Request) {
         <div class="card">
             <h2>Endpoints da API</h2>
             
-            <h3>Endpoints Públicos</h3>
-            <div class="endpoint">
-                <span class="method get">GET</span> <strong>/</strong> - Página inicial da API
-            </div>
-            <div class="endpoint">
-                <span class="method get">GET</span> <strong>/health</strong> - Status de saúde da aplicação
-            </div>
-            <div class="endpoint">
-                <span class="method post">POST</span> <strong>/auth/login</strong> - Autenticação de usuário
-            </div>
-            
-            <h3>Endpoints Protegidos (Requer Autenticação)</h3>
-            <div class="endpoint">
-                <span class="method get">GET</span> <strong>/api/customers</strong> - Listar todos os clientes
-            </div>
-            <div class="endpoint">
-                <span class="method get">GET</span> <strong>/api/customers/{id}/usage</strong> - Uso detalhado por cliente
-            </div>
-            <div class="endpoint">
-                <span class="method get">GET</span> <strong>/api/reports/billing/monthly</strong> - Faturamento por mês
-            </div>
-            <div class="endpoint">
-                <span class="method get">GET</span> <strong>/api/reports/billing/by-product</strong> - Faturamento por produto
-            </div>
+            <p>A lista completa de rotas, com parâmetros e schemas de requisição e resposta, está no documento OpenAPI.</p>
             <div class="endpoint">
-                <span class="method get">GET</span> <strong>/api/reports/billing/by-partner</strong> - Faturamento por parceiro
+                <span class="method get">GET</span> <strong><a href="/docs">/docs</a></strong> - Documentação interativa (Swagger UI)
             </div>
             <div class="endpoint">
-                <span class="method post">POST</span> <strong>/api/upload</strong> - Upload de arquivos Excel/CSV
+                <span class="method get">GET</span> <strong><a href="/openapi.json">/openapi.json</a></strong> - Especificação OpenAPI 3
             </div>
         </div>
         
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// maxHeaderScanRows limita a busca do cabeçalho às primeiras linhas (títulos e banners)
+const maxHeaderScanRows = 20
+
+// minHeaderScore é o mínimo de colunas obrigatórias para considerar uma linha como cabeçalho
+const minHeaderScore = 2
+
+// headerScore conta quantas colunas obrigatórias a linha contém, com a mesma regra de
+// correspondência usada por processRows
+func headerScore(row []string) int {
+	score := 0
+	for _, required := range requiredColumns {
+		for _, cell := range row {
+			if cell != "" && strings.Contains(canonicalColumn(cell), required) {
+				score++
+				break
+			}
+		}
+	}
+	return score
+}
+
+// detectHeaderRow retorna o índice da linha de cabeçalho: a primeira com mais colunas
+// obrigatórias entre as iniciais. Sem candidata, mantém a primeira linha.
+func detectHeaderRow(rows [][]string) int {
+	best, bestScore := 0, minHeaderScore-1
+	for i := 0; i < len(rows) && i < maxHeaderScanRows; i++ {
+		if score := headerScore(rows[i]); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// matchesHeaderProfile indica se a planilha tem um cabeçalho com todas as colunas obrigatórias
+func matchesHeaderProfile(rows [][]string) bool {
+	if len(rows) == 0 {
+		return false
+	}
+	return headerScore(rows[detectHeaderRow(rows)]) == len(requiredColumns)
+}
+
+// AllSheets seleciona todas as planilhas cujo cabeçalho corresponde ao perfil de importação
+const AllSheets = "*"
+
+// ParseSheetList interpreta a lista de planilhas separada por vírgulas
+func ParseSheetList(value string) []string {
+	var sheets []string
+	for _, sheet := range strings.Split(value, ",") {
+		if sheet = strings.TrimSpace(sheet); sheet != "" {
+			sheets = append(sheets, sheet)
+		}
+	}
+	return sheets
+}
+
+// sheetRows são as linhas lidas de uma planilha
+type sheetRows struct {
+	Name string
+	Rows [][]string
+}
+
+// selectSheets escolhe as planilhas a importar; readSheet lê as linhas pelo índice (base 0)
+func selectSheets(names []string, requested []string, readSheet func(index int) ([][]string, error)) ([]sheetRows, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("arquivo Excel não possui planilhas")
+	}
+
+	read := func(index int) (sheetRows, error) {
+		rows, err := readSheet(index)
+		if err != nil {
+			return sheetRows{}, fmt.Errorf("erro ao ler planilha %s: %w", names[index], err)
+		}
+		return sheetRows{Name: names[index], Rows: rows}, nil
+	}
+
+	// Sem seleção: primeira planilha com o cabeçalho esperado (ignora capas e resumos)
+	if len(requested) == 0 || (len(requested) == 1 && (requested[0] == AllSheets || strings.EqualFold(requested[0], "all"))) {
+		all := len(requested) > 0
+		var selected []sheetRows
+		for i := range names {
+			sheet, err := read(i)
+			if err != nil {
+				return nil, err
+			}
+			if !matchesHeaderProfile(sheet.Rows) {
+				log.Printf("📊 Planilha %s ignorada: cabeçalho não reconhecido", sheet.Name)
+				continue
+			}
+			selected = append(selected, sheet)
+			if !all {
+				break
+			}
+		}
+		if len(selected) > 0 {
+			return selected, nil
+		}
+		if all {
+			return nil, fmt.Errorf("nenhuma planilha com as colunas obrigatórias %v", requiredColumns)
+		}
+		// Mantém o comportamento anterior: a primeira planilha, com o erro de colunas de processRows
+		sheet, err := read(0)
+		if err != nil {
+			return nil, err
+		}
+		return []sheetRows{sheet}, nil
+	}
+
+	var selected []sheetRows
+	seen := make(map[int]bool)
+	for _, want := range requested {
+		index, err := resolveSheet(names, want)
+		if err != nil {
+			return nil, err
+		}
+		if seen[index] {
+			continue
+		}
+		seen[index] = true
+
+		sheet, err := read(index)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, sheet)
+	}
+	return selected, nil
+}
+
+// resolveSheet localiza a planilha pelo nome (sem diferenciar maiúsculas) ou pelo índice a partir de 1
+func resolveSheet(names []string, want string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(name, want) {
+			return i, nil
+		}
+	}
+	if index, err := strconv.Atoi(want); err == nil && index >= 1 && index <= len(names) {
+		return index - 1, nil
+	}
+	return 0, fmt.Errorf("planilha não encontrada: %s (disponíveis: %s)", want, strings.Join(names, ", "))
+}
+
+// processSheets aplica o mapeamento de colunas a cada planilha e combina o resultado; canonical
+// indica que as células numéricas vêm sem formatação
+func (h *UploadHandler) processSheets(sheets []sheetRows, opts ImportOptions, canonical bool) (*parsedFile, error) {
+	result := &parsedFile{}
+	for _, sheet := range sheets {
+		log.Printf("📊 Processando planilha: %s (%d linhas)", sheet.Name, len(sheet.Rows))
+		if len(sheet.Rows) < 2 {
+			return result, fmt.Errorf("planilha %s deve ter pelo menos 2 linhas", sheet.Name)
+		}
+
+		parsed, err := newParsedFile(h.processRows(sheet.Rows, opts.Locale, canonical))
+		result.add(parsed)
+		if err != nil {
+			return result, fmt.Errorf("planilha %s: %w", sheet.Name, err)
+		}
+	}
+	return result, nil
+}
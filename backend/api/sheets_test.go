@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"data-importer-api-go/internal/locale"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var usageHeader = []string{"PartnerId", "CustomerId", "ProductId", "UsageDate", "Quantity", "UnitPrice"}
+
+// buildWorkbook cria uma pasta de trabalho com uma planilha de resumo seguida das planilhas informadas
+func buildWorkbook(t *testing.T, sheets map[string][][]string, order []string) []byte {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetName("Sheet1", "Resumo")
+	f.SetSheetRow("Resumo", "A1", &[]interface{}{"Total do trimestre", 1234.5})
+
+	for _, name := range order {
+		if _, err := f.NewSheet(name); err != nil {
+			t.Fatal(err)
+		}
+		for i, row := range sheets[name] {
+			cells := make([]interface{}, len(row))
+			for j, v := range row {
+				cells[j] = v
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			f.SetSheetRow(name, cell, &cells)
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func monthlyWorkbook(t *testing.T) []byte {
+	return buildWorkbook(t, map[string][][]string{
+		"Jan": {
+			{"Relatório de consumo - Janeiro"},
+			{},
+			usageHeader,
+			{"P1", "C1", "PR1", "2024-01-15", "2", "10"},
+			{"P1", "C2", "PR1", "2024-01-16", "1", "10"},
+		},
+		"Fev": {
+			usageHeader,
+			{"P1", "C1", "PR1", "2024-02-15", "5", "10"},
+		},
+	}, []string{"Jan", "Fev"})
+}
+
+func TestDetectHeaderRow(t *testing.T) {
+	rows := [][]string{
+		{"Fornecedor XYZ"},
+		{"Gerado em 2024-02-01", ""},
+		{"Partner ID", "Customer ID", "Product ID", "Usage Date", "Quantity", "Unit Price"},
+		{"P1", "C1", "PR1", "2024-01-15", "2", "10"},
+	}
+	if got := detectHeaderRow(rows); got != 2 {
+		t.Errorf("detectHeaderRow = %d, want 2", got)
+	}
+
+	// Sem cabeçalho reconhecível, mantém a primeira linha
+	if got := detectHeaderRow([][]string{{"a", "b"}, {"1", "2"}}); got != 0 {
+		t.Errorf("detectHeaderRow = %d, want 0", got)
+	}
+}
+
+func TestProcessRows_HeaderBelowBanner(t *testing.T) {
+	rows := [][]string{
+		{"Exportação Partner Center"},
+		usageHeader,
+		{"P1", "C1", "PR1", "2024-01-15", "2", "10"},
+	}
+	_, _, _, usages, err := (&UploadHandler{}).processRows(rows, locale.Locale{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usages) != 1 || usages[0].Quantity != 2 {
+		t.Errorf("usages = %+v", usages)
+	}
+}
+
+func TestProcessExcelFile_SheetSelection(t *testing.T) {
+	data := monthlyWorkbook(t)
+	h := &UploadHandler{}
+
+	cases := []struct {
+		name     string
+		sheets   []string
+		quantity float64
+	}{
+		{"padrão ignora o resumo", nil, 3},
+		{"todas com o perfil", []string{AllSheets}, 8},
+		{"por nome", []string{"fev"}, 5},
+		{"por índice", []string{"2"}, 3},
+		{"nome e índice sem duplicar", []string{"Jan", "2", "Fev"}, 8},
+	}
+
+	for _, c := range cases {
+		parsed, err := h.processExcelFile(bytes.NewReader(data), ImportOptions{Sheets: c.sheets})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var quantity float64
+		for _, u := range parsed.Usages {
+			quantity += u.Quantity
+		}
+		if quantity != c.quantity {
+			t.Errorf("%s: quantity = %v, want %v", c.name, quantity, c.quantity)
+		}
+	}
+
+	if _, err := h.processExcelFile(bytes.NewReader(data), ImportOptions{Sheets: []string{"Mar"}}); err == nil {
+		t.Error("esperava erro para planilha inexistente")
+	}
+	if _, err := h.processExcelFile(bytes.NewReader(data), ImportOptions{Sheets: []string{"Resumo"}}); err == nil {
+		t.Error("esperava erro de colunas para a planilha de resumo")
+	}
+}
+
+func TestParseSheetList(t *testing.T) {
+	got := ParseSheetList(" Jan, 3 ,,Fev ")
+	want := []string{"Jan", "3", "Fev"}
+	if len(got) != len(want) {
+		t.Fatalf("ParseSheetList = %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParseSheetList[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if ParseSheetList("") != nil {
+		t.Error("lista vazia deveria resultar em nil")
+	}
+}
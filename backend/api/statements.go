@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"data-importer-api-go/internal/statement"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// statementRequest é o corpo aceito na geração de demonstrativos: customer_id, ou tag_key e
+// tag_value para um centro de custo
+type statementRequest struct {
+	CustomerID int    `json:"customer_id"`
+	TagKey     string `json:"tag_key"`
+	TagValue   string `json:"tag_value"`
+	Period     string `json:"period"`
+}
+
+// ListStatementsHandler lista as versões de demonstrativos, sem o conteúdo
+func (h *Handler) ListStatementsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	query := models.StatementQuery{
+		TagKey:   q.Get("tag_key"),
+		TagValue: q.Get("tag_value"),
+		Period:   q.Get("period"),
+	}
+	if v := q.Get("customer_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro customer_id inválido", http.StatusBadRequest)
+			return
+		}
+		query.CustomerID = id
+	}
+
+	statements, err := h.service.ListStatements(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao listar demonstrativos: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statements)
+}
+
+// GenerateStatementHandler gera o demonstrativo do mês. Responde 201 com a nova versão quando
+// o conteúdo mudou e 200 com a última versão quando ela já está atualizada.
+func (h *Handler) GenerateStatementHandler(w http.ResponseWriter, r *http.Request) {
+	var req statementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	st, created, err := h.service.GenerateStatement(r.Context(), service.StatementRequest{
+		CustomerID: req.CustomerID,
+		TagKey:     req.TagKey,
+		TagValue:   req.TagValue,
+		Period:     req.Period,
+	})
+	if err != nil {
+		writeEntityError(w, err, "gerar demonstrativo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(st)
+}
+
+// GetStatementHandler retorna uma versão de demonstrativo com o conteúdo
+func (h *Handler) GetStatementHandler(w http.ResponseWriter, r *http.Request) {
+	st, ok := h.loadStatement(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+// StatementXLSXHandler baixa uma versão de demonstrativo em XLSX
+func (h *Handler) StatementXLSXHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeStatementFile(w, r, "xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", statement.WriteXLSX)
+}
+
+// StatementPDFHandler baixa uma versão de demonstrativo em PDF
+func (h *Handler) StatementPDFHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeStatementFile(w, r, "pdf", "application/pdf", statement.WritePDF)
+}
+
+// loadStatement busca o demonstrativo do parâmetro id, respondendo 400/404/500 quando não há
+func (h *Handler) loadStatement(w http.ResponseWriter, r *http.Request) (*models.Statement, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do demonstrativo inválido", http.StatusBadRequest)
+		return nil, false
+	}
+
+	st, err := h.service.GetStatement(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar demonstrativo: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if st == nil {
+		http.Error(w, "Demonstrativo não encontrado", http.StatusNotFound)
+		return nil, false
+	}
+	return st, true
+}
+
+// writeStatementFile gera o documento em memória antes de responder, para que uma falha na
+// geração ainda possa virar um 500
+func (h *Handler) writeStatementFile(w http.ResponseWriter, r *http.Request, ext, contentType string,
+	write func(io.Writer, *models.Statement) error) {
+	st, ok := h.loadStatement(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, st); err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao gerar demonstrativo: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("demonstrativo-%d-%s-v%d.%s", st.ID, st.Period, st.Version, ext)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	buf.WriteTo(w)
+}
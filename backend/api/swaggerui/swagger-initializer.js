@@ -0,0 +1,8 @@
+// Configuração do Swagger UI servido em /docs, apontando para o documento em /openapi.json
+window.onload = function () {
+    window.ui = SwaggerUIBundle({
+        url: "/openapi.json",
+        dom_id: "#swagger-ui",
+        persistAuthorization: true
+    });
+};
@@ -1,17 +1,17 @@
 package api
 
 import (
+	"context"
+	"data-importer-api-go/internal/csvdialect"
+	"data-importer-api-go/internal/locale"
 	"data-importer-api-go/internal/models"
 	"data-importer-api-go/internal/service"
 	"database/sql"
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -58,40 +58,20 @@ func (h *UploadHandler) UploadFileHandler(w http.ResponseWriter, r *http.Request
 	log.Printf("Arquivo recebido: %s (%s)", fileName, contentType)
 	log.Printf("Tamanho do arquivo: %d bytes", header.Size)
 
-	var partners []models.Partner
-	var customers []models.Customer
-	var products []models.Product
-	var usages []models.Usage
-
-	// Processar arquivo baseado na extensão
-	if strings.HasSuffix(strings.ToLower(fileName), ".xlsx") {
-		partners, customers, products, usages, err = h.processExcelFile(file)
-	} else if strings.HasSuffix(strings.ToLower(fileName), ".csv") {
-		partners, customers, products, usages, err = h.processCSVFile(file)
-	} else {
-		http.Error(w, "Tipo de arquivo não suportado. Use .csv ou .xlsx", http.StatusBadRequest)
+	if !IsSupportedImportFile(fileName) {
+		http.Error(w, unsupportedFileMessage, http.StatusBadRequest)
 		return
 	}
 
+	opts, err := importOptionsFromForm(r)
 	if err != nil {
-		log.Printf("❌ Erro ao processar arquivo: %v", err)
-		http.Error(w, fmt.Sprintf("Erro ao processar arquivo: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	
-	log.Printf("Dados extraídos: %d partners, %d customers, %d products, %d usages", 
-		len(partners), len(customers), len(products), len(usages))
-		
-	// Verificar se temos dados de usages
-	if len(usages) == 0 {
-		log.Printf("ALERTA: Nenhum registro de usage foi extraído do arquivo!")
-	}
 
-	// Inserir dados no banco
-	err = h.service.ProcessImportData(r.Context(), partners, customers, products, usages)
+	summary, err := h.ImportFileWithOptions(r.Context(), fileName, file, opts)
 	if err != nil {
-		log.Printf("Erro ao inserir dados: %v", err)
-		http.Error(w, fmt.Sprintf("Erro ao inserir dados: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Erro ao importar arquivo: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -100,10 +80,13 @@ func (h *UploadHandler) UploadFileHandler(w http.ResponseWriter, r *http.Request
 		"success": true,
 		"message": "Arquivo processado com sucesso",
 		"data": map[string]interface{}{
-			"partners":  len(partners),
-			"customers": len(customers),
-			"products":  len(products),
-			"usages":    len(usages),
+			"partners":         summary.Partners,
+			"customers":        summary.Customers,
+			"products":         summary.Products,
+			"usages":           summary.Usages,
+			"quality_batch_id": summary.QualityBatchID,
+			"rejected":         summary.Rejected,
+			"warned":           summary.Warned,
 		},
 	}
 
@@ -111,188 +94,228 @@ func (h *UploadHandler) UploadFileHandler(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(response)
 }
 
-func (h *UploadHandler) processExcelFile(file io.Reader) ([]models.Partner, []models.Customer, []models.Product, []models.Usage, error) {
+// ImportFile lê um arquivo em qualquer formato suportado e grava os dados, publicando os eventos de importação.
+// É o fluxo usado tanto pelo upload quanto pelas importações agendadas.
+func (h *UploadHandler) ImportFile(ctx context.Context, fileName string, file io.Reader) (models.ImportSummary, error) {
+	return h.ImportFileWithOptions(ctx, fileName, file, ImportOptions{})
+}
+
+// ImportRecordedFile é ImportFile para as fontes agendadas: em caso de sucesso, record é gravado no
+// histórico de arquivos importados na mesma transação dos usos
+func (h *UploadHandler) ImportRecordedFile(ctx context.Context, record *models.ImportedFile, file io.Reader) (models.ImportSummary, error) {
+	return h.importFile(ctx, record.FileName, file, ImportOptions{}, record)
+}
+
+// ImportFileWithOptions é ImportFile com a seleção de planilhas informada pelo chamador
+func (h *UploadHandler) ImportFileWithOptions(ctx context.Context, fileName string, file io.Reader, opts ImportOptions) (models.ImportSummary, error) {
+	return h.importFile(ctx, fileName, file, opts, nil)
+}
+
+func (h *UploadHandler) importFile(ctx context.Context, fileName string, file io.Reader, opts ImportOptions, record *models.ImportedFile) (models.ImportSummary, error) {
+	// Processar arquivo conforme o formato detectado
+	parsed, err := h.parseFile(fileName, file, opts)
+	summary := parsed.summary(fileName)
+
+	if err != nil {
+		log.Printf("❌ Erro ao processar arquivo: %v", err)
+		summary.Error = err.Error()
+		h.service.PublishEvent(ctx, service.EventImportFailed, summary)
+		return summary, fmt.Errorf("erro ao processar arquivo: %w", err)
+	}
+	
+	log.Printf("Dados extraídos: %d partners, %d customers, %d products, %d usages", 
+		summary.Partners, summary.Customers, summary.Products, summary.Usages)
+		
+	// Verificar se temos dados de usages
+	if summary.Usages == 0 {
+		log.Printf("ALERTA: Nenhum registro de usage foi extraído do arquivo!")
+	}
+
+	// Inserir dados no banco
+	result, err := h.service.ProcessImportDataForFile(ctx, fileName, record, parsed.Partners, parsed.Customers, parsed.Products, parsed.Usages)
+	if err != nil {
+		log.Printf("Erro ao inserir dados: %v", err)
+		summary.Error = err.Error()
+		h.service.PublishEvent(ctx, service.EventImportFailed, summary)
+		return summary, fmt.Errorf("erro ao inserir dados: %w", err)
+	}
+
+	summary = withResult(summary, result)
+	h.service.PublishEvent(ctx, service.EventImportCompleted, summary)
+	return summary, nil
+}
+
+// processExcelFile lê as planilhas selecionadas de um arquivo .xlsx/.xlsm. As células são lidas
+// sem formatação: números no formato canônico e datas como número serial.
+func (h *UploadHandler) processExcelFile(file io.Reader, opts ImportOptions) (*parsedFile, error) {
 	// Ler arquivo Excel
 	f, err := excelize.OpenReader(file)
 	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("erro ao abrir arquivo Excel: %w", err)
+		return &parsedFile{}, fmt.Errorf("erro ao abrir arquivo Excel: %w", err)
 	}
 	defer f.Close()
 
-	// Obter primeira planilha
 	sheetList := f.GetSheetList()
-	if len(sheetList) == 0 {
-		return nil, nil, nil, nil, fmt.Errorf("arquivo Excel não possui planilhas")
+	selected, err := selectSheets(sheetList, opts.Sheets, func(index int) ([][]string, error) {
+		return f.GetRows(sheetList[index], excelize.Options{RawCellValue: true})
+	})
+	if err != nil {
+		return &parsedFile{}, err
 	}
 
-	sheetName := sheetList[0]
-	log.Printf("📊 Processando planilha: %s", sheetName)
-	
-	rows, err := f.GetRows(sheetName)
+	return h.processSheets(selected, opts, true)
+}
+
+// processCSVFile lê um CSV detectando delimitador, codificação e BOM; opts.CSV sobrepõe a detecção
+func (h *UploadHandler) processCSVFile(file io.Reader, opts ImportOptions) ([]models.Partner, []models.Customer, []models.Product, []models.Usage, error) {
+	reader, detected, err := csvdialect.NewReader(file, opts.CSV)
 	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("erro ao ler planilha: %w", err)
+		return nil, nil, nil, nil, err
 	}
+	log.Printf("📄 CSV: %s", detected)
 
-	log.Printf("📏 Total de linhas encontradas: %d", len(rows))
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao ler CSV: %w", err)
+		}
+		rows = append(rows, record)
+	}
 
 	if len(rows) < 2 {
-		return nil, nil, nil, nil, fmt.Errorf("planilha deve ter pelo menos 2 linhas")
+		return nil, nil, nil, nil, fmt.Errorf("CSV deve ter pelo menos 2 linhas")
 	}
 
-	// Processar dados
-	log.Printf("📊 Iniciando processamento de %d linhas de dados", len(rows))
-	
-	// Verificar se há dados suficientes
-	if len(rows) <= 1 {
-		log.Printf("❌ ERRO: Arquivo não contém dados suficientes. Apenas %d linhas encontradas.", len(rows))
-		return nil, nil, nil, nil, fmt.Errorf("arquivo não contém dados suficientes")
+	return h.processRows(rows, opts.Locale, false)
+}
+
+// requiredColumns são as colunas sem as quais uma linha não pode ser importada
+var requiredColumns = []string{"partner_id", "customer_id", "product_id", "usage_date", "quantity", "unit_price"}
+
+// normalizeHeader remove espaços, separadores e caixa do nome da coluna
+func normalizeHeader(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.ReplaceAll(s, "_", "")
+	s = strings.ReplaceAll(s, "-", "")
+	return s
+}
+
+// canonicalColumn retorna o nome canônico da coluna, aplicando os aliases
+func canonicalColumn(name string) string {
+	n := normalizeHeader(name)
+	if mapped, ok := headerAliases[n]; ok {
+		return mapped
 	}
+	return n
+}
+
+// headerAliases mapeia o cabeçalho normalizado para o nome canônico da coluna
+var headerAliases = map[string]string{
+	// Partner fields
+	"partnerid":              "partner_id",
+	"partnername":            "partner_name",
+	"mpnid":                  "mpn_id",
+	"tier2mpnid":             "tier2_mpn_id",
+	"tier2mpn":               "tier2_mpn_id",
 	
-	// Verificar se há cabeçalhos
-	if len(rows[0]) == 0 {
-		log.Printf("❌ ERRO: Cabeçalhos não encontrados no arquivo.")
-		return nil, nil, nil, nil, fmt.Errorf("cabeçalhos não encontrados no arquivo")
-	}
+	// Customer fields
+	"customerid":             "customer_id",
+	"customername":           "customer_name",
+	"customerdomainname":     "customer_domain_name",
+	"customercountry":        "country",
+	"customerdomain":         "customer_domain_name",
 	
-	// Imprimir primeiras linhas para debug
-	for i := 0; i < 3 && i < len(rows); i++ {
-		log.Printf("📋 Linha %d: %v", i, rows[i])
-	}
+	// Product fields
+	"productid":              "product_id",
+	"skuid":                  "sku_id",
+	"skuname":                "sku_name",
+	"productname":            "product_name",
+	"metertype":              "meter_type",
+	"metercategory":          "category",
+	"metersubcategory":       "sub_category",
+	"unit":                   "unit_type",
+	"unittype":               "unit_type",
+	"resourcelocation":       "resource_location",
+	"category":               "category",
+	"subcategory":            "sub_category",
 	
-	return h.processRows(rows)
+	// Usage fields
+	"invoicenumber":          "invoice_number",
+	"usagedate":              "usage_date",
+	"chargestartdate":        "charge_start_date",
+	"chargeenddate":          "charge_end_date",
+	"unitprice":              "unit_price",
+	"effectiveunitprice":     "unit_price",
+	"quantity":               "quantity",
+	"billingpretaxtotal":     "billing_pre_tax_total",
+	"billingcurrency":        "billing_currency",
+	"pricingpretaxtotal":     "pricing_pre_tax_total",
+	"pricingcurrency":        "pricing_currency",
+	"benefittype":            "benefit_type",
+	"tags":                   "tags",
+	"additionalinfo":         "additional_info",
+	"serviceinfo1":           "service_info1",
+	"serviceinfo2":           "service_info2",
+	"pcbcexchangerate":       "pc_to_bc_exchange_rate",
+	"pcbcexchangeratedate":   "pc_to_bc_exchange_rate_date",
+	"entitlementid":          "entitlement_id",
+	"entitlementdescription": "entitlement_description",
+	"partnerearnedcreditpercentage": "partner_earned_credit_percentage",
+	"creditpercentage":       "credit_percentage",
+	"credittype":             "credit_type",
+	"benefitorderid":         "benefit_order_id",
+	"benefitid":              "benefit_id",
+	"invoicetotal":           "invoice_control_total",
+	"invoicecontroltotal":    "invoice_control_total",
+	"controltotal":           "invoice_control_total",
 }
 
-func (h *UploadHandler) processCSVFile(file io.Reader) ([]models.Partner, []models.Customer, []models.Product, []models.Usage, error) {
-	// Ler todo o conteúdo para detectar delimitador
-	data, err := ioutil.ReadAll(file)
-	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("erro ao ler arquivo: %w", err)
-	}
-
-	content := string(data)
-	delimiter := ','
-	// Heurística simples: se houver mais tabs do que vírgulas na primeira linha, usar tab
-	firstLine := content
-	if idx := strings.IndexAny(content, "\r\n"); idx != -1 {
-		firstLine = content[:idx]
-	}
-	if strings.Count(firstLine, "\t") > strings.Count(firstLine, ",") {
-		delimiter = '\t'
-	}
+// numericColumns e dateColumns são interpretadas de acordo com o locale do arquivo
+var (
+	numericColumns = []string{"quantity", "unit_price", "billing_pre_tax_total", "invoice_control_total"}
+	dateColumns    = []string{"usage_date", "charge_start_date", "charge_end_date"}
+)
 
-	parseWith := func(delim rune) ([][]string, error) {
-		reader := csv.NewReader(strings.NewReader(content))
-		reader.Comma = delim
-		reader.LazyQuotes = true
-		var rows [][]string
-		for {
-			record, err := reader.Read()
-			if err == io.EOF {
-				break
+// inferParser define o parser de números e datas a partir de todas as linhas de dados, para
+// que 03/04/2024 tenha a mesma leitura em todo o arquivo
+func inferParser(rows [][]string, columnMap map[string]int, loc locale.Locale, canonical bool) (locale.Parser, error) {
+	indexes := func(columns []string) []int {
+		var idx []int
+		for _, col := range columns {
+			if i, ok := columnMap[col]; ok {
+				idx = append(idx, i)
 			}
-			if err != nil {
-				return nil, fmt.Errorf("erro ao ler CSV: %w", err)
-			}
-			rows = append(rows, record)
 		}
-		return rows, nil
+		return idx
 	}
-
-	rows, err := parseWith(delimiter)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-
-	if len(rows) < 2 {
-		return nil, nil, nil, nil, fmt.Errorf("CSV deve ter pelo menos 2 linhas")
-	}
-
-	return h.processRows(rows)
+	return locale.InferRows(loc, rows, indexes(numericColumns), indexes(dateColumns), canonical)
 }
 
-func (h *UploadHandler) processRows(rows [][]string) ([]models.Partner, []models.Customer, []models.Product, []models.Usage, error) {
-	// Processar cabeçalho
-	header := rows[0]
+// processRows mapeia as colunas e converte as linhas; loc define separadores e ordem das datas
+// (o valor zero infere do arquivo) e canonical indica números sem formatação (Excel, Parquet, JSON)
+func (h *UploadHandler) processRows(rows [][]string, loc locale.Locale, canonical bool) ([]models.Partner, []models.Customer, []models.Product, []models.Usage, error) {
+	// Processar cabeçalho, que pode estar abaixo de linhas de título
+	headerRow := detectHeaderRow(rows)
+	if headerRow > 0 {
+		log.Printf("📋 Cabeçalho detectado na linha %d", headerRow+1)
+	}
+	header := rows[headerRow]
 	log.Printf("📋 Cabeçalhos encontrados: %v", header)
 	log.Printf("📊 Total de linhas para processar: %d", len(rows))
 	columnMap := make(map[string]int)
 
-	// Normalizar cabeçalhos e aplicar aliases
-	normalize := func(s string) string {
-		s = strings.ToLower(strings.TrimSpace(s))
-		s = strings.ReplaceAll(s, " ", "")
-		s = strings.ReplaceAll(s, "_", "")
-		s = strings.ReplaceAll(s, "-", "")
-		return s
-	}
-	alias := map[string]string{
-		// Partner fields
-		"partnerid":              "partner_id",
-		"partnername":            "partner_name",
-		"mpnid":                  "mpn_id",
-		"tier2mpnid":             "tier2_mpn_id",
-		"tier2mpn":               "tier2_mpn_id",
-		
-		// Customer fields
-		"customerid":             "customer_id",
-		"customername":           "customer_name",
-		"customerdomainname":     "customer_domain_name",
-		"customercountry":        "country",
-		"customerdomain":         "customer_domain_name",
-		
-		// Product fields
-		"productid":              "product_id",
-		"skuid":                  "sku_id",
-		"skuname":                "sku_name",
-		"productname":            "product_name",
-		"metertype":              "meter_type",
-		"metercategory":          "category",
-		"metersubcategory":       "sub_category",
-		"unit":                   "unit_type",
-		"unittype":               "unit_type",
-		"resourcelocation":       "resource_location",
-		"category":               "category",
-		"subcategory":            "sub_category",
-		
-		// Usage fields
-		"invoicenumber":          "invoice_number",
-		"usagedate":              "usage_date",
-		"chargestartdate":        "charge_start_date",
-		"unitprice":              "unit_price",
-		"effectiveunitprice":     "unit_price",
-		"quantity":               "quantity",
-		"billingpretaxtotal":     "billing_pre_tax_total",
-		"billingcurrency":        "billing_currency",
-		"pricingpretaxtotal":     "pricing_pre_tax_total",
-		"pricingcurrency":        "pricing_currency",
-		"benefittype":            "benefit_type",
-		"tags":                   "tags",
-		"additionalinfo":         "additional_info",
-		"serviceinfo1":           "service_info1",
-		"serviceinfo2":           "service_info2",
-		"pcbcexchangerate":       "pc_to_bc_exchange_rate",
-		"pcbcexchangeratedate":   "pc_to_bc_exchange_rate_date",
-		"entitlementid":          "entitlement_id",
-		"entitlementdescription": "entitlement_description",
-		"partnerearnedcreditpercentage": "partner_earned_credit_percentage",
-		"creditpercentage":       "credit_percentage",
-		"credittype":             "credit_type",
-		"benefitorderid":         "benefit_order_id",
-		"benefitid":              "benefit_id",
-	}
-
 	for i, col := range header {
-		n := normalize(col)
-		key := n
-		if mapped, ok := alias[n]; ok {
-			key = mapped
-		}
+		key := canonicalColumn(col)
 		columnMap[key] = i
 		log.Printf("🔗 Coluna %d: '%s' -> '%s'", i, col, key)
 	}
 
 	// Verificar colunas obrigatórias com mapeamento flexível
-	requiredColumns := []string{"partner_id", "customer_id", "product_id", "usage_date", "quantity", "unit_price"}
 	missingColumns := []string{}
 	
 	// Mapear colunas disponíveis para colunas obrigatórias
@@ -356,6 +379,12 @@ func (h *UploadHandler) processRows(rows [][]string) ([]models.Partner, []models
 		columnMap[required] = columnMap[available]
 	}
 
+	parser, err := inferParser(rows[headerRow+1:], columnMap, loc, canonical)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	log.Printf("🌐 Locale: %s, decimal %q, datas %s", localeName(loc), parser.Decimal, parser.DateOrder)
+
 	// Estruturas para processamento paralelo
 	type rowResult struct {
 		partner  *models.Partner
@@ -393,7 +422,7 @@ func (h *UploadHandler) processRows(rows [][]string) ([]models.Partner, []models
 				}
 
 				// Processar linha
-				partner, customer, product, usage, err := h.parseRow(record, columnMap, rowNum)
+				partner, customer, product, usage, err := h.parseRow(record, columnMap, rowNum, parser)
 				resultChan <- rowResult{
 					partner:  partner,
 					customer: customer,
@@ -408,7 +437,7 @@ func (h *UploadHandler) processRows(rows [][]string) ([]models.Partner, []models
 	
 	// Distribuir trabalho
 	go func() {
-		for i := 1; i < len(rows); i++ {
+		for i := headerRow + 1; i < len(rows); i++ {
 			workChan <- i
 		}
 		close(workChan)
@@ -420,20 +449,30 @@ func (h *UploadHandler) processRows(rows [][]string) ([]models.Partner, []models
 		close(resultChan)
 	}()
 
-	// Coletar resultados
+	// Coletar resultados na ordem das linhas: os workers terminam em qualquer ordem, mas os
+	// atributos de um parceiro, cliente ou produto repetido devem vir sempre da última linha do arquivo
+	ordered := make([]*rowResult, len(rows))
+	for result := range resultChan {
+		result := result
+		ordered[result.rowNum] = &result
+	}
+
 	var partners []models.Partner
 	var customers []models.Customer
 	var products []models.Product
 	var usages []models.Usage
 
-	partnerMap := make(map[string]*models.Partner)
-	customerMap := make(map[string]*models.Customer)
-	productMap := make(map[string]*models.Product)
+	partnerIndex := make(map[string]int)
+	customerIndex := make(map[string]int)
+	productIndex := make(map[string]int)
 
 	processedCount := 0
 	errorCount := 0
 
-	for result := range resultChan {
+	for _, result := range ordered {
+		if result == nil {
+			continue
+		}
 		if result.err != nil {
 			log.Printf("⚠️  Erro ao processar linha %d: %v", result.rowNum+1, result.err)
 			errorCount++
@@ -441,22 +480,28 @@ func (h *UploadHandler) processRows(rows [][]string) ([]models.Partner, []models
 			continue
 		}
 
-		// Adicionar partner se não existir
-		if _, exists := partnerMap[result.partner.PartnerID]; !exists {
+		// Adicionar partner ou atualizar com a linha mais recente
+		if i, exists := partnerIndex[result.partner.PartnerID]; exists {
+			partners[i] = *result.partner
+		} else {
+			partnerIndex[result.partner.PartnerID] = len(partners)
 			partners = append(partners, *result.partner)
-			partnerMap[result.partner.PartnerID] = result.partner
 		}
 
-		// Adicionar customer se não existir
-		if _, exists := customerMap[result.customer.CustomerID]; !exists {
+		// Adicionar customer ou atualizar com a linha mais recente
+		if i, exists := customerIndex[result.customer.CustomerID]; exists {
+			customers[i] = *result.customer
+		} else {
+			customerIndex[result.customer.CustomerID] = len(customers)
 			customers = append(customers, *result.customer)
-			customerMap[result.customer.CustomerID] = result.customer
 		}
 
-		// Adicionar product se não existir
-		if _, exists := productMap[result.product.ProductID]; !exists {
+		// Adicionar product ou atualizar com a linha mais recente
+		if i, exists := productIndex[result.product.ProductID]; exists {
+			products[i] = *result.product
+		} else {
+			productIndex[result.product.ProductID] = len(products)
 			products = append(products, *result.product)
-			productMap[result.product.ProductID] = result.product
 		}
 
 		// Adicionar usage
@@ -486,7 +531,7 @@ func getAvailableColumns(header []string) []string {
 	return columns
 }
 
-func (h *UploadHandler) parseRow(record []string, columnMap map[string]int, rowNum int) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
+func (h *UploadHandler) parseRow(record []string, columnMap map[string]int, rowNum int, parser locale.Parser) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
 	// Função auxiliar para obter valor da coluna
 	getValue := func(colName string) string {
 		if idx, exists := columnMap[colName]; exists && idx < len(record) {
@@ -498,92 +543,6 @@ func (h *UploadHandler) parseRow(record []string, columnMap map[string]int, rowN
 	// Log para debug
 	log.Printf("🔍 Processando linha %d: %v", rowNum+1, record)
 
-	// Função auxiliar para converter string para float
-	parseFloat := func(value string) (float64, error) {
-		if value == "" {
-			return 0, nil
-		}
-		
-		// Limpar valor
-		value = strings.TrimSpace(value)
-		
-		// Remover caracteres não numéricos exceto ponto, vírgula e sinal
-		cleaned := ""
-		for _, char := range value {
-			if char >= '0' && char <= '9' || char == '.' || char == ',' || char == '-' || char == '+' {
-				cleaned += string(char)
-			}
-		}
-		
-		if cleaned == "" {
-			return 0, nil
-		}
-		
-		// Substituir vírgula por ponto
-		cleaned = strings.ReplaceAll(cleaned, ",", ".")
-		
-		// Verificar se há múltiplos pontos (formato brasileiro)
-		if strings.Count(cleaned, ".") > 1 {
-			// Se há múltiplos pontos, o último é o decimal
-			parts := strings.Split(cleaned, ".")
-			if len(parts) > 2 {
-				cleaned = strings.Join(parts[:len(parts)-1], "") + "." + parts[len(parts)-1]
-			}
-		}
-		
-		return strconv.ParseFloat(cleaned, 64)
-	}
-
-	// Função auxiliar para converter string para data
-    parseDate := func(value string) (time.Time, error) {
-		if value == "" {
-			return time.Time{}, nil
-		}
-		
-		// Limpar valor
-		value = strings.TrimSpace(value)
-		
-		// Tentar diferentes formatos de data
-        formats := []string{
-			"2006-01-02",
-			"2006/01/02",
-			"02/01/2006",
-			"02-01-2006",
-			"02/01/06",   // ano curto com /
-			"02-01-06",   // ano curto com -
-			"1/2/2006",
-			"1-2-2006",
-			"1/2/06",     // ano curto, sem zero à esquerda
-			"1-2-06",     // ano curto, sem zero à esquerda
-			"2006-01-02 15:04:05",
-			"2006/01/02 15:04:05",
-			"2006-01-02T15:04:05Z",
-			"2006-01-02T15:04:05.000Z",
-		}
-		
-		
-		
-		for _, format := range formats {
-			if t, err := time.Parse(format, value); err == nil {
-				return t, nil
-			}
-		}
-		
-		// Tentar parsear como número serial do Excel
-		if serial, err := strconv.ParseFloat(value, 64); err == nil {
-			// Verificar se é um número serial do Excel (geralmente entre 1 e 100000)
-			if serial > 1 && serial < 100000 {
-				baseDate := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
-				days := int(serial)
-				return baseDate.AddDate(0, 0, days), nil
-			}
-		}
-		
-		// Se não conseguir parsear, retornar data atual como fallback
-		log.Printf("⚠️  Não foi possível parsear data '%s', usando data atual", value)
-		return time.Now(), nil
-	}
-
 	// Criar Partner
 	partner := &models.Partner{
 		PartnerID:   getValue("partner_id"),
@@ -623,42 +582,56 @@ func (h *UploadHandler) parseRow(record []string, columnMap map[string]int, rowN
 		return nil, nil, nil, nil, fmt.Errorf("product_id é obrigatório")
 	}
 
-	// Parsear datas com tratamento de erro mais flexível
-	usageDate, err := parseDate(getValue("usage_date"))
+	// Datas e números seguem o locale do arquivo; valores inválidos rejeitam a linha
+	usageDate, err := parser.ParseDate(getValue("usage_date"))
 	if err != nil {
-		log.Printf("⚠️  Erro ao parsear usage_date na linha %d: %v, usando data atual", rowNum+1, err)
-		usageDate = time.Now()
+		return nil, nil, nil, nil, fmt.Errorf("usage_date: %w", err)
 	}
 
-	chargeStartDate, err := parseDate(getValue("charge_start_date"))
-	if err != nil {
-		log.Printf("⚠️  Erro ao parsear charge_start_date na linha %d: %v, usando data atual", rowNum+1, err)
-		chargeStartDate = time.Now()
+	var chargeStartDate time.Time
+	if value := getValue("charge_start_date"); value != "" {
+		if chargeStartDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("charge_start_date: %w", err)
+		}
+	}
+
+	var chargeEndDate time.Time
+	if value := getValue("charge_end_date"); value != "" {
+		if chargeEndDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("charge_end_date: %w", err)
+		}
 	}
 
-	// Parsear valores numéricos com tratamento de erro mais flexível
-	quantity, err := parseFloat(getValue("quantity"))
+	quantity, err := parser.ParseNumber(getValue("quantity"))
 	if err != nil {
-		log.Printf("⚠️  Erro ao parsear quantity na linha %d: %v, usando 0", rowNum+1, err)
-		quantity = 0
+		return nil, nil, nil, nil, fmt.Errorf("quantity: %w", err)
 	}
 
-	unitPrice, err := parseFloat(getValue("unit_price"))
+	unitPrice, err := parser.ParseNumber(getValue("unit_price"))
 	if err != nil {
-		log.Printf("⚠️  Erro ao parsear unit_price na linha %d: %v, usando 0", rowNum+1, err)
-		unitPrice = 0
+		return nil, nil, nil, nil, fmt.Errorf("unit_price: %w", err)
 	}
 
-	billingPreTaxTotal, err := parseFloat(getValue("billing_pre_tax_total"))
+	billingPreTaxTotal, err := parser.ParseNumber(getValue("billing_pre_tax_total"))
 	if err != nil {
-		log.Printf("⚠️  Erro ao parsear billing_pre_tax_total na linha %d: %v, usando 0", rowNum+1, err)
-		billingPreTaxTotal = 0
+		return nil, nil, nil, nil, fmt.Errorf("billing_pre_tax_total: %w", err)
+	}
+
+	// Total de controle da fatura, opcional, usado na conciliação
+	var invoiceControlTotal sql.NullFloat64
+	if value := getValue("invoice_control_total"); value != "" {
+		total, err := parser.ParseNumber(value)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("invoice_control_total: %w", err)
+		}
+		invoiceControlTotal = sql.NullFloat64{Float64: total, Valid: true}
 	}
 
 	// Criar Usage
 	usage := &models.Usage{
 		InvoiceNumber:      getValue("invoice_number"),
 		ChargeStartDate:    timeToNullTime(chargeStartDate),
+		ChargeEndDate:      timeToNullTime(chargeEndDate),
 		UsageDate:          usageDate,
 		Quantity:           quantity,
 		UnitPrice:          unitPrice,
@@ -666,6 +639,9 @@ func (h *UploadHandler) parseRow(record []string, columnMap map[string]int, rowN
 		ResourceLocation:   getValue("resource_location"),
 		Tags:               getValue("tags"),
 		BenefitType:        getValue("benefit_type"),
+		BillingCurrency:    getValue("billing_currency"),
+		InvoiceControlTotal: invoiceControlTotal,
+		SourceRow:          rowNum + 1,
 		PartnerIDStr:       partner.PartnerID,    // Adicionado para mapeamento
 		CustomerIDStr:      customer.CustomerID,  // Adicionado para mapeamento
 		ProductIDStr:       product.ProductID,    // Adicionado para mapeamento
@@ -686,6 +662,14 @@ func (h *UploadHandler) parseRow(record []string, columnMap map[string]int, rowN
 	return partner, customer, product, usage, nil
 }
 
+// localeName descreve o locale para os logs
+func localeName(loc locale.Locale) string {
+	if loc.Name == "" {
+		return "auto"
+	}
+	return loc.Name
+}
+
 // timeToNullTime converte time.Time para sql.NullTime
 func timeToNullTime(t time.Time) sql.NullTime {
 	if t.IsZero() {
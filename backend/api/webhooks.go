@@ -0,0 +1,183 @@
+package api
+
+import (
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// webhookRequest é o corpo aceito na criação e atualização de assinaturas de webhook
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+func (req webhookRequest) toSubscription() models.WebhookSubscription {
+	subscription := models.WebhookSubscription{
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+		Active: true,
+	}
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+	return subscription
+}
+
+// ListWebhooksHandler lista as assinaturas de webhook
+func (h *Handler) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.service.GetAllWebhookSubscriptions(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar webhooks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscriptions)
+}
+
+// GetWebhookHandler retorna uma assinatura de webhook
+func (h *Handler) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do webhook inválido", http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := h.service.GetWebhookSubscription(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar webhook: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if subscription == nil {
+		http.Error(w, "Webhook não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// CreateWebhookHandler cria uma assinatura de webhook; a resposta é a única que traz o segredo
+func (h *Handler) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	subscription := req.toSubscription()
+	if err := h.service.CreateWebhookSubscription(r.Context(), &subscription); err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao criar webhook: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// UpdateWebhookHandler substitui os dados de uma assinatura; secret vazio mantém o atual
+func (h *Handler) UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do webhook inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		return
+	}
+
+	subscription := req.toSubscription()
+	subscription.ID = id
+	found, err := h.service.UpdateWebhookSubscription(r.Context(), &subscription)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParameter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao atualizar webhook: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Webhook não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// DeleteWebhookHandler remove uma assinatura e seu log de entregas
+func (h *Handler) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do webhook inválido", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.service.DeleteWebhookSubscription(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao remover webhook: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Webhook não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveriesHandler lista o log de entregas de uma assinatura
+func (h *Handler) ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "ID do webhook inválido", http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Parâmetro limit inválido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	subscription, err := h.service.GetWebhookSubscription(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar webhook: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if subscription == nil {
+		http.Error(w, "Webhook não encontrado", http.StatusNotFound)
+		return
+	}
+
+	deliveries, err := h.service.GetWebhookDeliveries(r.Context(), id, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao buscar entregas: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
@@ -0,0 +1,257 @@
+// Package client é o SDK Go da Data Importer API. Os métodos retornam os tipos de models,
+// recebem um context, repetem as chamadas idempotentes em falhas transitórias e traduzem as
+// respostas de erro em *APIError, comparável com errors.Is aos erros de status do pacote.
+//
+//	c := client.New("https://data-importer-api-go.onrender.com")
+//	if _, err := c.Login(ctx, "admin", "admin123"); err != nil {
+//		return err
+//	}
+//	customers, err := c.ListCustomers(ctx)
+package client
+
+import (
+	"bytes"
+	"context"
+	"data-importer-api-go/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Valores padrão do Client
+const (
+	DefaultTimeout    = 60 * time.Second
+	DefaultMaxRetries = 3
+	DefaultRetryDelay = 500 * time.Millisecond
+)
+
+// Client chama a API com o token obtido em Login ou informado em WithToken
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+
+	mu       sync.Mutex
+	token    string
+	username string
+	password string
+}
+
+// Option ajusta o Client criado por New
+type Option func(*Client)
+
+// WithHTTPClient substitui o http.Client usado nas chamadas
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken usa um token já emitido; sem credenciais, um 401 não é renovado automaticamente
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries define quantas vezes uma chamada idempotente é repetida e o intervalo inicial,
+// dobrado a cada tentativa. maxRetries 0 desativa as repetições.
+func WithRetries(maxRetries int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = delay
+	}
+}
+
+// New cria um Client para a URL base da API, sem a barra final
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		maxRetries: DefaultMaxRetries,
+		retryDelay: DefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token retorna o token atual
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// Login autentica o usuário e passa a usar o token emitido. As credenciais ficam guardadas
+// para renovar o token quando a API responder 401.
+func (c *Client) Login(ctx context.Context, username, password string) (*models.LoginResponse, error) {
+	body := models.LoginRequest{Username: username, Password: password}
+
+	var resp models.LoginResponse
+	if err := c.send(ctx, http.MethodPost, "/auth/login", nil, jsonBody(body), false, &resp); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.token, c.username, c.password = resp.Token, username, password
+	c.mu.Unlock()
+	return &resp, nil
+}
+
+// RefreshToken troca o token atual, ainda válido, por um novo com nova validade
+func (c *Client) RefreshToken(ctx context.Context) (*models.LoginResponse, error) {
+	var resp models.LoginResponse
+	if err := c.send(ctx, http.MethodPost, "/auth/refresh", nil, nil, true, &resp); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.token = resp.Token
+	c.mu.Unlock()
+	return &resp, nil
+}
+
+// requestBody monta o corpo a cada tentativa, já que um io.Reader só pode ser lido uma vez
+type requestBody func() (io.Reader, string, error)
+
+func jsonBody(v interface{}) requestBody {
+	return func() (io.Reader, string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), "application/json", nil
+	}
+}
+
+// get chama uma rota protegida com GET e decodifica a resposta em out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.send(ctx, http.MethodGet, path, query, nil, true, out)
+}
+
+// send executa a chamada: repete GET, PUT e DELETE em falhas transitórias e, com credenciais
+// guardadas, refaz o login uma vez quando uma rota protegida responde 401
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body requestBody, authenticated bool, out interface{}) error {
+	err := c.sendWithRetries(ctx, method, path, query, body, authenticated, out)
+	if !authenticated || !isStatus(err, http.StatusUnauthorized) {
+		return err
+	}
+
+	c.mu.Lock()
+	username, password := c.username, c.password
+	c.mu.Unlock()
+	if username == "" {
+		return err
+	}
+	if _, loginErr := c.Login(ctx, username, password); loginErr != nil {
+		return err
+	}
+	return c.sendWithRetries(ctx, method, path, query, body, authenticated, out)
+}
+
+func (c *Client) sendWithRetries(ctx context.Context, method, path string, query url.Values, body requestBody, authenticated bool, out interface{}) error {
+	attempts := 1
+	if idempotent(method) {
+		attempts += c.maxRetries
+	}
+
+	delay := c.retryDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = c.do(ctx, method, path, query, body, authenticated, out)
+		if err == nil || !retry || attempt >= attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// do executa uma tentativa; retry indica se a falha é transitória
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body requestBody, authenticated bool, out interface{}) (retry bool, err error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		if reader, contentType, err = body(); err != nil {
+			return false, fmt.Errorf("erro ao montar requisição %s %s: %w", method, path, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return false, fmt.Errorf("erro ao montar requisição %s %s: %w", method, path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if authenticated {
+		if token := c.Token(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Falhas de rede são transitórias, exceto o cancelamento pelo chamador
+		return ctx.Err() == nil, fmt.Errorf("erro na requisição %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Path:       path,
+			Message:    strings.TrimSpace(string(message)),
+		}
+		return retryableStatus(resp.StatusCode), apiErr
+	}
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("erro ao decodificar resposta de %s %s: %w", method, path, err)
+	}
+	return false, nil
+}
+
+// idempotent indica os métodos que podem ser repetidos sem efeito duplicado
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
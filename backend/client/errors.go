@@ -0,0 +1,55 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Erros de status; uma resposta de erro da API é um *APIError e corresponde, com errors.Is,
+// ao erro do seu status
+var (
+	ErrBadRequest   = errors.New("requisição inválida")
+	ErrUnauthorized = errors.New("não autorizado")
+	ErrNotFound     = errors.New("não encontrado")
+	ErrConflict     = errors.New("conflito")
+	ErrUnavailable  = errors.New("serviço indisponível")
+	ErrServer       = errors.New("erro no servidor")
+)
+
+// APIError é uma resposta de erro da API; Message é o texto retornado pelo servidor
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is permite errors.Is(err, client.ErrNotFound) e afins
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// isStatus indica se err é um *APIError com o status informado
+func isStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Os relatórios de faturamento aceitam asOf para consultar os dados como estavam naquele
+// instante; nil consulta os dados atuais.
+
+// MonthlyBilling retorna o faturamento por mês
+func (c *Client) MonthlyBilling(ctx context.Context, asOf *time.Time) ([]BillingReport, error) {
+	var reports []BillingReport
+	if err := c.get(ctx, "/api/reports/billing/monthly", asOfQuery(asOf), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// BillingByProduct retorna o faturamento por produto
+func (c *Client) BillingByProduct(ctx context.Context, asOf *time.Time) ([]BillingByProduct, error) {
+	var reports []BillingByProduct
+	if err := c.get(ctx, "/api/reports/billing/by-product", asOfQuery(asOf), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// BillingByPartner retorna o faturamento por parceiro
+func (c *Client) BillingByPartner(ctx context.Context, asOf *time.Time) ([]BillingByPartner, error) {
+	var reports []BillingByPartner
+	if err := c.get(ctx, "/api/reports/billing/by-partner", asOfQuery(asOf), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// BillingByCategory retorna o faturamento por categoria
+func (c *Client) BillingByCategory(ctx context.Context, asOf *time.Time) ([]CategoryBillingReport, error) {
+	var reports []CategoryBillingReport
+	if err := c.get(ctx, "/api/reports/billing/by-category", asOfQuery(asOf), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// BillingByResource retorna o faturamento por recurso
+func (c *Client) BillingByResource(ctx context.Context, asOf *time.Time) ([]ResourceBillingReport, error) {
+	var reports []ResourceBillingReport
+	if err := c.get(ctx, "/api/reports/billing/by-resource", asOfQuery(asOf), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// BillingByCustomer retorna o faturamento por cliente
+func (c *Client) BillingByCustomer(ctx context.Context, asOf *time.Time) ([]CustomerBillingReport, error) {
+	var reports []CustomerBillingReport
+	if err := c.get(ctx, "/api/reports/billing/by-customer", asOfQuery(asOf), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// BillingByCustomerGroup retorna o faturamento por cliente consolidado por grupo
+func (c *Client) BillingByCustomerGroup(ctx context.Context, asOf *time.Time) ([]CustomerGroupBillingReport, error) {
+	query := asOfQuery(asOf)
+	query.Set("group_by", "customer_group")
+
+	var reports []CustomerGroupBillingReport
+	if err := c.get(ctx, "/api/reports/billing/by-customer", query, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// BillingTimeseries retorna a série temporal de faturamento
+func (c *Client) BillingTimeseries(ctx context.Context, q BillingTimeseriesQuery) (*BillingTimeseriesReport, error) {
+	query := asOfQuery(q.AsOf)
+	setString(query, "granularity", q.Granularity)
+	setString(query, "group_by", q.GroupBy)
+	setString(query, "compare", q.Compare)
+	setDate(query, "from", q.From)
+	setDate(query, "to", q.To)
+	if q.FillGaps {
+		query.Set("fill_gaps", "true")
+	}
+
+	var report BillingTimeseriesReport
+	if err := c.get(ctx, "/api/reports/billing/timeseries", query, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Aggregate executa uma agregação ad-hoc sobre os usos
+func (c *Client) Aggregate(ctx context.Context, q AggregationQuery) (*AggregationResult, error) {
+	query := asOfQuery(q.AsOf)
+	setString(query, "dimensions", strings.Join(q.Dimensions, ","))
+	setString(query, "measures", strings.Join(q.Measures, ","))
+	setString(query, "sort", q.Sort)
+	setInt(query, "limit", q.Limit)
+	setDate(query, "from", q.From)
+	setDate(query, "to", q.To)
+	for dimension, values := range q.Filters {
+		for _, value := range values {
+			query.Add("filter."+dimension, value)
+		}
+	}
+
+	var result AggregationResult
+	if err := c.get(ctx, "/api/reports/aggregate", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TopN retorna as maiores entidades de uma dimensão com análise de Pareto
+func (c *Client) TopN(ctx context.Context, q TopNQuery) (*TopNReport, error) {
+	query := asOfQuery(q.AsOf)
+	setString(query, "dimension", q.Dimension)
+	setString(query, "metric", q.Metric)
+	setInt(query, "n", q.N)
+	if q.ThresholdPct != 0 {
+		query.Set("threshold", strconv.FormatFloat(q.ThresholdPct, 'f', -1, 64))
+	}
+	setDate(query, "from", q.From)
+	setDate(query, "to", q.To)
+
+	var report TopNReport
+	if err := c.get(ctx, "/api/reports/top", query, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Forecast retorna a previsão de gasto; scope é overall, customer ou partner e id identifica
+// o cliente ou parceiro (zero para overall)
+func (c *Client) Forecast(ctx context.Context, scope string, id int) (*ForecastReport, error) {
+	query := url.Values{}
+	setString(query, "scope", scope)
+	setInt(query, "id", id)
+
+	var report ForecastReport
+	if err := c.get(ctx, "/api/reports/forecast", query, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// KPI retorna as métricas de KPI
+func (c *Client) KPI(ctx context.Context, asOf *time.Time) (*KPIData, error) {
+	var kpi KPIData
+	if err := c.get(ctx, "/api/reports/kpi", asOfQuery(asOf), &kpi); err != nil {
+		return nil, err
+	}
+	return &kpi, nil
+}
+
+// Chargeback retorna os custos por valor de tag após as regras de rateio
+func (c *Client) Chargeback(ctx context.Context, q ChargebackQuery) (*ChargebackReport, error) {
+	query := asOfQuery(q.AsOf)
+	setString(query, "tag_key", q.TagKey)
+	setDate(query, "from", q.From)
+	setDate(query, "to", q.To)
+
+	var report ChargebackReport
+	if err := c.get(ctx, "/api/reports/chargeback", query, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Margin retorna a receita de revenda, o custo e a margem
+func (c *Client) Margin(ctx context.Context, q MarginQuery) (*MarginReport, error) {
+	query := url.Values{}
+	setString(query, "group_by", q.GroupBy)
+	setDate(query, "from", q.From)
+	setDate(query, "to", q.To)
+
+	var report MarginReport
+	if err := c.get(ctx, "/api/reports/margin", query, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// asOfQuery inicia a query com o instante as_of em RFC 3339, quando informado
+func asOfQuery(asOf *time.Time) url.Values {
+	query := url.Values{}
+	if asOf != nil {
+		query.Set("as_of", asOf.UTC().Format(time.RFC3339Nano))
+	}
+	return query
+}
+
+func setString(query url.Values, name, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
+
+func setInt(query url.Values, name string, value int) {
+	if value != 0 {
+		query.Set(name, strconv.Itoa(value))
+	}
+}
+
+// setDate envia apenas a data (YYYY-MM-DD), o formato aceito pelos filtros from e to
+func setDate(query url.Values, name string, value *time.Time) {
+	if value != nil {
+		query.Set(name, value.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"strconv"
+)
+
+// ListCustomers retorna todos os clientes
+func (c *Client) ListCustomers(ctx context.Context) ([]Customer, error) {
+	var customers []Customer
+	if err := c.get(ctx, "/api/customers", nil, &customers); err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+// GetCustomer retorna um cliente; um ID inexistente retorna um erro que corresponde a ErrNotFound
+func (c *Client) GetCustomer(ctx context.Context, id int) (*Customer, error) {
+	var customer Customer
+	if err := c.get(ctx, "/api/customers/"+strconv.Itoa(id), nil, &customer); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// CustomerUsage retorna os usos de um cliente
+func (c *Client) CustomerUsage(ctx context.Context, id int) ([]Usage, error) {
+	return c.usages(ctx, "/api/customers/"+strconv.Itoa(id)+"/usage")
+}
+
+// ListPartners retorna todos os parceiros
+func (c *Client) ListPartners(ctx context.Context) ([]Partner, error) {
+	var partners []Partner
+	if err := c.get(ctx, "/api/partners", nil, &partners); err != nil {
+		return nil, err
+	}
+	return partners, nil
+}
+
+// PartnerUsage retorna os usos de um parceiro
+func (c *Client) PartnerUsage(ctx context.Context, id int) ([]Usage, error) {
+	return c.usages(ctx, "/api/partners/"+strconv.Itoa(id)+"/usage")
+}
+
+// ListProducts retorna todos os produtos
+func (c *Client) ListProducts(ctx context.Context) ([]Product, error) {
+	var products []Product
+	if err := c.get(ctx, "/api/products", nil, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// ProductUsage retorna os usos de um produto
+func (c *Client) ProductUsage(ctx context.Context, id int) ([]Usage, error) {
+	return c.usages(ctx, "/api/products/"+strconv.Itoa(id)+"/usage")
+}
+
+func (c *Client) usages(ctx context.Context, path string) ([]Usage, error) {
+	var usages []Usage
+	if err := c.get(ctx, path, nil, &usages); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
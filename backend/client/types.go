@@ -0,0 +1,35 @@
+package client
+
+import "data-importer-api-go/internal/models"
+
+// Aliases dos tipos de models usados pelo Client, para que programas fora deste módulo, que
+// não podem importar pacotes internal, consigam nomeá-los
+type (
+	LoginResponse = models.LoginResponse
+	ImportSummary = models.ImportSummary
+
+	Customer = models.Customer
+	Partner  = models.Partner
+	Product  = models.Product
+	Usage    = models.Usage
+
+	BillingReport              = models.BillingReport
+	BillingByProduct           = models.BillingByProduct
+	BillingByPartner           = models.BillingByPartner
+	CategoryBillingReport      = models.CategoryBillingReport
+	ResourceBillingReport      = models.ResourceBillingReport
+	CustomerBillingReport      = models.CustomerBillingReport
+	CustomerGroupBillingReport = models.CustomerGroupBillingReport
+	BillingTimeseriesQuery     = models.BillingTimeseriesQuery
+	BillingTimeseriesReport    = models.BillingTimeseriesReport
+	AggregationQuery           = models.AggregationQuery
+	AggregationResult          = models.AggregationResult
+	TopNQuery                  = models.TopNQuery
+	TopNReport                 = models.TopNReport
+	ForecastReport             = models.ForecastReport
+	KPIData                    = models.KPIData
+	ChargebackQuery            = models.ChargebackQuery
+	ChargebackReport           = models.ChargebackReport
+	MarginQuery                = models.MarginQuery
+	MarginReport               = models.MarginReport
+)
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// UploadOptions são os campos opcionais do upload; vazios, a API detecta ou infere cada um
+type UploadOptions struct {
+	Sheets    []string // planilhas por nome ou índice (a partir de 1); "*" importa todas
+	Delimiter string   // delimitador de arquivos CSV
+	Encoding  string   // codificação de arquivos CSV
+	Locale    string   // pt-BR, en-US...
+}
+
+// UploadResponse é a resposta do upload; Data traz as quantidades importadas
+type UploadResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Data    ImportSummary `json:"data"`
+}
+
+// Upload envia um arquivo em qualquer formato aceito pela importação. O upload não é repetido
+// em falhas transitórias, já que a importação pode ter sido gravada.
+func (c *Client) Upload(ctx context.Context, fileName string, file io.Reader, opts UploadOptions) (*UploadResponse, error) {
+	// O conteúdo fica em memória para que a requisição possa ser refeita após renovar o token
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo %s: %w", fileName, err)
+	}
+
+	fields := map[string]string{
+		"sheets":    strings.Join(opts.Sheets, ","),
+		"delimiter": opts.Delimiter,
+		"encoding":  opts.Encoding,
+		"locale":    opts.Locale,
+	}
+	body := func() (io.Reader, string, error) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+
+		part, err := writer.CreateFormFile("file", filepath.Base(fileName))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", err
+		}
+		for name, value := range fields {
+			if value == "" {
+				continue
+			}
+			if err := writer.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, writer.FormDataContentType(), nil
+	}
+
+	var resp UploadResponse
+	if err := c.send(ctx, http.MethodPost, "/api/upload", nil, body, true, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
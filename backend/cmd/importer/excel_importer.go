@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"data-importer-api-go/internal/config"
+	"data-importer-api-go/internal/locale"
 	"data-importer-api-go/internal/models"
 	"data-importer-api-go/internal/repository"
 	"data-importer-api-go/internal/service"
@@ -10,7 +11,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -66,7 +66,7 @@ func processExcel(filename string, service *service.Service) error {
 	log.Printf("Processando planilha: %s", sheetName)
 
 	// Obter todas as linhas
-	rows, err := f.GetRows(sheetName)
+	rows, err := f.GetRows(sheetName, excelize.Options{RawCellValue: true})
 	if err != nil {
 		return fmt.Errorf("erro ao ler linhas da planilha: %w", err)
 	}
@@ -93,6 +93,20 @@ func processExcel(filename string, service *service.Service) error {
 		}
 	}
 
+	// Células lidas sem formatação; a ordem de dia e mês de datas em texto é inferida do arquivo
+	columnIndex := func(col string) int {
+		if idx, exists := columnMap[col]; exists {
+			return idx
+		}
+		return -1
+	}
+	parser, err := locale.InferRows(locale.Locale{}, rows[1:],
+		[]int{columnIndex("quantity"), columnIndex("unit_price"), columnIndex("billing_pre_tax_total"), columnIndex("invoice_control_total")},
+		[]int{columnIndex("usage_date"), columnIndex("charge_start_date"), columnIndex("charge_end_date")}, true)
+	if err != nil {
+		return err
+	}
+
 	// Processar dados em lotes
 	batchSize := 1000
 	var partners []models.Partner
@@ -106,6 +120,7 @@ func processExcel(filename string, service *service.Service) error {
 
 	rowCount := 0
 	processedCount := 0
+	session := service.NewImportSession(filename)
 
 	// Processar linhas de dados (pular cabeçalho)
 	for i := 1; i < len(rows); i++ {
@@ -118,7 +133,7 @@ func processExcel(filename string, service *service.Service) error {
 		}
 
 		// Processar linha
-		partner, customer, product, usage, err := parseExcelRow(record, columnMap, rowCount)
+		partner, customer, product, usage, err := parseExcelRow(record, columnMap, rowCount, parser)
 		if err != nil {
 			log.Printf("Erro ao processar linha %d: %v", rowCount, err)
 			continue
@@ -147,7 +162,7 @@ func processExcel(filename string, service *service.Service) error {
 
 		// Processar lote quando atingir o tamanho
 		if len(usages) >= batchSize {
-			if err := service.ProcessImportData(context.Background(), partners, customers, products, usages); err != nil {
+			if err := service.ProcessImportBatch(context.Background(), session, partners, customers, products, usages); err != nil {
 				return fmt.Errorf("erro ao processar lote: %w", err)
 			}
 
@@ -164,13 +179,16 @@ func processExcel(filename string, service *service.Service) error {
 
 	// Processar último lote
 	if len(usages) > 0 {
-		if err := service.ProcessImportData(context.Background(), partners, customers, products, usages); err != nil {
+		if err := service.ProcessImportBatch(context.Background(), session, partners, customers, products, usages); err != nil {
 			return fmt.Errorf("erro ao processar último lote: %w", err)
 		}
 		processedCount += len(usages)
 		log.Printf("Processado último lote: %d registros", len(usages))
 	}
 
+	// Rotinas que dependem do arquivo inteiro rodam uma vez, após o último lote
+	service.FinishImport(context.Background(), session)
+
 	log.Printf("Total processado: %d registros de %d linhas", processedCount, rowCount)
 	return nil
 }
@@ -184,7 +202,7 @@ func allEmpty(record []string) bool {
 	return true
 }
 
-func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
+func parseExcelRow(record []string, columnMap map[string]int, rowNum int, parser locale.Parser) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
 	// Função auxiliar para obter valor da coluna
 	getValue := func(colName string) string {
 		if idx, exists := columnMap[colName]; exists && idx < len(record) {
@@ -193,57 +211,6 @@ func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*mode
 		return ""
 	}
 
-	// Função auxiliar para converter string para float
-	parseFloat := func(value string) (float64, error) {
-		if value == "" {
-			return 0, nil
-		}
-		// Remover vírgulas e converter
-		value = strings.ReplaceAll(value, ",", ".")
-		return strconv.ParseFloat(value, 64)
-	}
-
-	// Função auxiliar para converter string para data
-	parseDate := func(value string) (time.Time, error) {
-		if value == "" {
-			return time.Time{}, nil
-		}
-		
-		// Tentar diferentes formatos de data
-		formats := []string{
-			"2006-01-02",
-			"2006/01/02",
-			"02/01/2006",
-			"02-01-2006",
-			"02/01/06",   // ano curto
-			"02-01-06",
-			"1/2/2006",
-			"1-2-2006",
-			"2006-01-02 15:04:05",
-			"2006/01/02 15:04:05",
-			"2006-01-02T15:04:05Z",
-			"2006-01-02T15:04:05.000Z",
-		}
-		
-		
-		for _, format := range formats {
-			if t, err := time.Parse(format, value); err == nil {
-				return t, nil
-			}
-		}
-		
-		// Tentar parsear como número serial do Excel
-		if serial, err := strconv.ParseFloat(value, 64); err == nil {
-			// Converter número serial do Excel para data
-			// Excel usa 1900-01-01 como base, mas tem bug do ano bissexto
-			baseDate := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
-			days := int(serial)
-			return baseDate.AddDate(0, 0, days), nil
-		}
-		
-		return time.Time{}, fmt.Errorf("formato de data inválido: %s", value)
-	}
-
 	// Criar Partner
 	partner := &models.Partner{
 		PartnerID:   getValue("partner_id"),
@@ -284,36 +251,56 @@ func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*mode
 	}
 
 	// Parsear datas
-	usageDate, err := parseDate(getValue("usage_date"))
+	usageDate, err := parser.ParseDate(getValue("usage_date"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear usage_date: %w", err)
 	}
 
-	chargeStartDate, err := parseDate(getValue("charge_start_date"))
-	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_start_date: %w", err)
+	var chargeStartDate time.Time
+	if value := getValue("charge_start_date"); value != "" {
+		if chargeStartDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_start_date: %w", err)
+		}
+	}
+
+	var chargeEndDate time.Time
+	if value := getValue("charge_end_date"); value != "" {
+		if chargeEndDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_end_date: %w", err)
+		}
 	}
 
 	// Parsear valores numéricos
-	quantity, err := parseFloat(getValue("quantity"))
+	quantity, err := parser.ParseNumber(getValue("quantity"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear quantity: %w", err)
 	}
 
-	unitPrice, err := parseFloat(getValue("unit_price"))
+	unitPrice, err := parser.ParseNumber(getValue("unit_price"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear unit_price: %w", err)
 	}
 
-	billingPreTaxTotal, err := parseFloat(getValue("billing_pre_tax_total"))
+	billingPreTaxTotal, err := parser.ParseNumber(getValue("billing_pre_tax_total"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear billing_pre_tax_total: %w", err)
 	}
 
+	// Total de controle da fatura, opcional, usado na conciliação
+	var invoiceControlTotal sql.NullFloat64
+	if value := getValue("invoice_control_total"); value != "" {
+		total, err := parser.ParseNumber(value)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear invoice_control_total: %w", err)
+		}
+		invoiceControlTotal = sql.NullFloat64{Float64: total, Valid: true}
+	}
+
 	// Criar Usage
 	usage := &models.Usage{
 		InvoiceNumber:      getValue("invoice_number"),
 		ChargeStartDate:    timeToNullTime(chargeStartDate),
+		ChargeEndDate:      timeToNullTime(chargeEndDate),
 		UsageDate:          usageDate,
 		Quantity:           quantity,
 		UnitPrice:          unitPrice,
@@ -321,6 +308,8 @@ func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*mode
 		ResourceLocation:   getValue("resource_location"),
 		Tags:               getValue("tags"),
 		BenefitType:        getValue("benefit_type"),
+		BillingCurrency:    getValue("billing_currency"),
+		InvoiceControlTotal: invoiceControlTotal,
 		PartnerID:          0, // Será preenchido após inserção
 		CustomerID:         0, // Será preenchido após inserção
 		ProductID:          0, // Será preenchido após inserção
@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"data-importer-api-go/api"
 	"data-importer-api-go/internal/config"
+	"data-importer-api-go/internal/csvdialect"
+	"data-importer-api-go/internal/locale"
 	"data-importer-api-go/internal/models"
 	"data-importer-api-go/internal/repository"
+	"data-importer-api-go/internal/scheduler"
 	"data-importer-api-go/internal/service"
 	"database/sql"
-	"encoding/csv"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strconv"
+	"path"
 	"strings"
 	"time"
 
@@ -20,7 +25,7 @@ import (
 
 func main() {
 	if len(os.Args) < 2 {
-		log.Fatal("Uso: go run ./cmd/importer/main.go <arquivo.csv>")
+		log.Fatal("Uso: go run ./cmd/importer/main.go <arquivo.csv> [-delimiter <d>] [-encoding <e>] [-locale <l>] | s3 -bucket <bucket> (-key <chave> | -prefix <prefixo>)")
 	}
 
 	csvFile := os.Args[1]
@@ -39,15 +44,97 @@ func main() {
 	repo := repository.NewRepository(db)
 	svc := service.NewService(repo)
 
-	// Processar arquivo CSV
-	if err := processCSV(csvFile, svc); err != nil {
+	// Subcomando s3: importar objetos do armazenamento compatível com S3
+	if csvFile == "s3" {
+		if err := runS3Import(cfg, svc, os.Args[2:]); err != nil {
+			log.Fatalf("Erro na importação S3: %v", err)
+		}
+		log.Println("✅ Importação concluída com sucesso!")
+		return
+	}
+
+	// Processar arquivo CSV; delimitador e codificação são detectados se não informados
+	flags := flag.NewFlagSet("csv", flag.ExitOnError)
+	delimiter := flags.String("delimiter", "", "delimitador (, ; | ou tab); vazio detecta")
+	encoding := flags.String("encoding", "", "codificação (utf-8, utf-16le, utf-16be, latin1, windows-1252); vazio detecta")
+	localeName := flags.String("locale", "", "locale de números e datas (pt-BR, en-US...); vazio infere do arquivo")
+	flags.Parse(os.Args[2:])
+
+	dialect, err := parseDialect(*delimiter, *encoding)
+	if err != nil {
+		log.Fatal(err)
+	}
+	loc, err := locale.Lookup(*localeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := processCSV(csvFile, dialect, loc, svc); err != nil {
 		log.Fatalf("Erro ao processar CSV: %v", err)
 	}
 
 	log.Println("✅ Importação concluída com sucesso!")
 }
 
-func processCSV(filename string, service *service.Service) error {
+// parseDialect converte as flags -delimiter e -encoding
+func parseDialect(delimiter, encoding string) (csvdialect.Dialect, error) {
+	var dialect csvdialect.Dialect
+	var err error
+	if dialect.Delimiter, err = csvdialect.ParseDelimiter(delimiter); err != nil {
+		return dialect, err
+	}
+	if dialect.Encoding, err = csvdialect.ParseEncoding(encoding); err != nil {
+		return dialect, err
+	}
+	return dialect, nil
+}
+
+// inferCSVParser percorre o arquivo inteiro para definir separador decimal e ordem das datas
+// antes da importação em lotes
+func inferCSVParser(filename string, dialect csvdialect.Dialect, columnMap map[string]int, loc locale.Locale) (locale.Parser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return locale.Parser{}, fmt.Errorf("erro ao abrir arquivo: %w", err)
+	}
+	defer file.Close()
+
+	reader, _, err := csvdialect.NewReader(file, dialect)
+	if err != nil {
+		return locale.Parser{}, err
+	}
+	// Descartar cabeçalho
+	if _, err := reader.Read(); err != nil {
+		return locale.Parser{}, fmt.Errorf("erro ao ler cabeçalho: %w", err)
+	}
+
+	var numbers, dates []string
+	collect := func(record []string, col string, values *[]string) {
+		if idx, exists := columnMap[col]; exists && idx < len(record) {
+			if value := strings.TrimSpace(record[idx]); value != "" {
+				*values = append(*values, value)
+			}
+		}
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		collect(record, "quantity", &numbers)
+		collect(record, "unit_price", &numbers)
+		collect(record, "billing_pre_tax_total", &numbers)
+		collect(record, "invoice_control_total", &numbers)
+		collect(record, "usage_date", &dates)
+		collect(record, "charge_start_date", &dates)
+		collect(record, "charge_end_date", &dates)
+	}
+	return locale.Infer(loc, numbers, dates, false)
+}
+
+func processCSV(filename string, dialect csvdialect.Dialect, loc locale.Locale, service *service.Service) error {
 	// Abrir arquivo CSV
 	file, err := os.Open(filename)
 	if err != nil {
@@ -55,10 +142,12 @@ func processCSV(filename string, service *service.Service) error {
 	}
 	defer file.Close()
 
-	// Criar leitor CSV
-	reader := csv.NewReader(file)
-	reader.Comma = ','
-	reader.LazyQuotes = true
+	// Criar leitor CSV com o dialeto detectado
+	reader, detected, err := csvdialect.NewReader(file, dialect)
+	if err != nil {
+		return err
+	}
+	log.Printf("📄 CSV: %s", detected)
 
 	// Ler cabeçalho
 	header, err := reader.Read()
@@ -82,6 +171,13 @@ func processCSV(filename string, service *service.Service) error {
 		}
 	}
 
+	// Definir como números e datas serão lidos, com o mesmo dialeto já detectado
+	parser, err := inferCSVParser(filename, detected, columnMap, loc)
+	if err != nil {
+		return err
+	}
+	log.Printf("🌐 Decimal %q, datas %s", parser.Decimal, parser.DateOrder)
+
 	// Processar dados em lotes
 	batchSize := 1000
 	var partners []models.Partner
@@ -95,6 +191,7 @@ func processCSV(filename string, service *service.Service) error {
 
 	rowCount := 0
 	processedCount := 0
+	session := service.NewImportSession(filename)
 
 	for {
 		record, err := reader.Read()
@@ -109,7 +206,7 @@ func processCSV(filename string, service *service.Service) error {
 		rowCount++
 
 		// Processar linha
-		partner, customer, product, usage, err := parseRow(record, columnMap, rowCount)
+		partner, customer, product, usage, err := parseRow(record, columnMap, rowCount, parser)
 		if err != nil {
 			log.Printf("⚠️  Erro ao processar linha %d: %v", rowCount, err)
 			continue
@@ -138,7 +235,7 @@ func processCSV(filename string, service *service.Service) error {
 
 		// Processar lote quando atingir o tamanho
 		if len(usages) >= batchSize {
-			if err := service.ProcessImportData(context.Background(), partners, customers, products, usages); err != nil {
+			if err := service.ProcessImportBatch(context.Background(), session, partners, customers, products, usages); err != nil {
 				return fmt.Errorf("erro ao processar lote: %w", err)
 			}
 
@@ -155,18 +252,21 @@ func processCSV(filename string, service *service.Service) error {
 
 	// Processar último lote
 	if len(usages) > 0 {
-		if err := service.ProcessImportData(context.Background(), partners, customers, products, usages); err != nil {
+		if err := service.ProcessImportBatch(context.Background(), session, partners, customers, products, usages); err != nil {
 			return fmt.Errorf("erro ao processar último lote: %w", err)
 		}
 		processedCount += len(usages)
 		log.Printf("📦 Processado último lote: %d registros", len(usages))
 	}
 
+	// Rotinas que dependem do arquivo inteiro rodam uma vez, após o último lote
+	service.FinishImport(context.Background(), session)
+
 	log.Printf("✅ Total processado: %d registros de %d linhas", processedCount, rowCount)
 	return nil
 }
 
-func parseRow(record []string, columnMap map[string]int, rowNum int) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
+func parseRow(record []string, columnMap map[string]int, rowNum int, parser locale.Parser) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
 	// Função auxiliar para obter valor da coluna
 	getValue := func(colName string) string {
 		if idx, exists := columnMap[colName]; exists && idx < len(record) {
@@ -175,45 +275,6 @@ func parseRow(record []string, columnMap map[string]int, rowNum int) (*models.Pa
 		return ""
 	}
 
-	// Função auxiliar para converter string para float
-	parseFloat := func(value string) (float64, error) {
-		if value == "" {
-			return 0, nil
-		}
-		return strconv.ParseFloat(value, 64)
-	}
-
-	// Função auxiliar para converter string para data
-	parseDate := func(value string) (time.Time, error) {
-		if value == "" {
-			return time.Time{}, nil
-		}
-		// Tentar diferentes formatos de data
-		formats := []string{
-			"2006-01-02",
-			"2006/01/02",
-			"02/01/2006",
-			"02-01-2006",
-			"02/01/06",   // ano curto
-			"02-01-06",
-			"1/2/2006",
-			"1-2-2006",
-			"2006-01-02 15:04:05",
-			"2006/01/02 15:04:05",
-			"2006-01-02T15:04:05Z",
-			"2006-01-02T15:04:05.000Z",
-		}
-		
-		
-		for _, format := range formats {
-			if t, err := time.Parse(format, value); err == nil {
-				return t, nil
-			}
-		}
-		
-		return time.Time{}, fmt.Errorf("formato de data inválido: %s", value)
-	}
-
 	// Criar Partner
 	partner := &models.Partner{
 		PartnerID:   getValue("partner_id"),
@@ -253,37 +314,57 @@ func parseRow(record []string, columnMap map[string]int, rowNum int) (*models.Pa
 		return nil, nil, nil, nil, fmt.Errorf("product_id é obrigatório")
 	}
 
-	// Parsear datas
-	usageDate, err := parseDate(getValue("usage_date"))
+	// Parsear datas de acordo com o locale do arquivo
+	usageDate, err := parser.ParseDate(getValue("usage_date"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear usage_date: %w", err)
 	}
 
-	chargeStartDate, err := parseDate(getValue("charge_start_date"))
-	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_start_date: %w", err)
+	var chargeStartDate time.Time
+	if value := getValue("charge_start_date"); value != "" {
+		if chargeStartDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_start_date: %w", err)
+		}
+	}
+
+	var chargeEndDate time.Time
+	if value := getValue("charge_end_date"); value != "" {
+		if chargeEndDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_end_date: %w", err)
+		}
 	}
 
 	// Parsear valores numéricos
-	quantity, err := parseFloat(getValue("quantity"))
+	quantity, err := parser.ParseNumber(getValue("quantity"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear quantity: %w", err)
 	}
 
-	unitPrice, err := parseFloat(getValue("unit_price"))
+	unitPrice, err := parser.ParseNumber(getValue("unit_price"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear unit_price: %w", err)
 	}
 
-	billingPreTaxTotal, err := parseFloat(getValue("billing_pre_tax_total"))
+	billingPreTaxTotal, err := parser.ParseNumber(getValue("billing_pre_tax_total"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear billing_pre_tax_total: %w", err)
 	}
 
+	// Total de controle da fatura, opcional, usado na conciliação
+	var invoiceControlTotal sql.NullFloat64
+	if value := getValue("invoice_control_total"); value != "" {
+		total, err := parser.ParseNumber(value)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear invoice_control_total: %w", err)
+		}
+		invoiceControlTotal = sql.NullFloat64{Float64: total, Valid: true}
+	}
+
 	// Criar Usage
 	usage := &models.Usage{
 		InvoiceNumber:      getValue("invoice_number"),
 		ChargeStartDate:    timeToNullTime(chargeStartDate),
+		ChargeEndDate:      timeToNullTime(chargeEndDate),
 		UsageDate:          usageDate,
 		Quantity:           quantity,
 		UnitPrice:          unitPrice,
@@ -291,6 +372,8 @@ func parseRow(record []string, columnMap map[string]int, rowNum int) (*models.Pa
 		ResourceLocation:   getValue("resource_location"),
 		Tags:               getValue("tags"),
 		BenefitType:        getValue("benefit_type"),
+		BillingCurrency:    getValue("billing_currency"),
+		InvoiceControlTotal: invoiceControlTotal,
 		PartnerID:          0, // Será preenchido após inserção
 		CustomerID:         0, // Será preenchido após inserção
 		ProductID:          0, // Será preenchido após inserção
@@ -306,3 +389,72 @@ func timeToNullTime(t time.Time) sql.NullTime {
 	}
 	return sql.NullTime{Time: t, Valid: true}
 }
+
+// runS3Import lista (por prefixo) ou busca (por chave) objetos no S3 configurado em IMPORT_S3_*
+// e os transmite para o mesmo parser do upload
+func runS3Import(cfg *config.Config, svc *service.Service, args []string) error {
+	flags := flag.NewFlagSet("s3", flag.ExitOnError)
+	bucket := flags.String("bucket", cfg.S3Bucket, "bucket de origem (padrão IMPORT_S3_BUCKET)")
+	key := flags.String("key", "", "chave de um objeto a importar")
+	prefix := flags.String("prefix", "", "prefixo cujos objetos em formato suportado serão importados")
+	sheets := flags.String("sheets", "", "planilhas Excel (nomes ou índices separados por vírgula, * para todas)")
+	delimiter := flags.String("delimiter", "", "delimitador CSV (, ; | ou tab); vazio detecta")
+	encoding := flags.String("encoding", "", "codificação CSV; vazio detecta")
+	localeName := flags.String("locale", "", "locale de números e datas (pt-BR, en-US...); vazio infere do arquivo")
+	flags.Parse(args)
+
+	opts, err := api.NewImportOptions(*sheets, *delimiter, *encoding, *localeName)
+	if err != nil {
+		return err
+	}
+
+	if cfg.S3Endpoint == "" {
+		return fmt.Errorf("IMPORT_S3_ENDPOINT não configurado")
+	}
+	if *bucket == "" || (*key == "") == (*prefix == "") {
+		return fmt.Errorf("informe -bucket e exatamente um entre -key e -prefix")
+	}
+
+	source, err := scheduler.NewS3Source("s3", scheduler.S3Config{
+		Endpoint:  cfg.S3Endpoint,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+		Region:    cfg.S3Region,
+		UseSSL:    cfg.S3UseSSL,
+	}, *bucket, "")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	keys := []string{*key}
+	if *prefix != "" {
+		objects, err := source.ListObjects(ctx, *bucket, *prefix)
+		if err != nil {
+			return err
+		}
+		keys = keys[:0]
+		for _, object := range objects {
+			if api.IsSupportedImportFile(object.Name) {
+				keys = append(keys, object.Name)
+			}
+		}
+		log.Printf("📦 %d objetos encontrados em %s/%s", len(keys), *bucket, *prefix)
+	}
+
+	importer := api.NewUploadHandler(svc)
+	for _, k := range keys {
+		reader, err := source.Fetch(ctx, *bucket, k)
+		if err != nil {
+			return err
+		}
+		summary, err := importer.ImportFileWithOptions(ctx, path.Base(k), reader, opts)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("erro ao importar %s: %w", k, err)
+		}
+		log.Printf("✅ %s: %d usages importados", k, summary.Usages)
+	}
+
+	return nil
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"data-importer-api-go/api"
 	"data-importer-api-go/internal/config"
+	"data-importer-api-go/internal/locale"
 	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/notifier"
 	"data-importer-api-go/internal/repository"
+	"data-importer-api-go/internal/scheduler"
 	"data-importer-api-go/internal/service"
+	"data-importer-api-go/internal/uploads"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -44,6 +47,10 @@ func main() {
 	// Inicializar camadas
 	repo := repository.NewRepository(db)
 	svc := service.NewService(repo)
+	if cfg.AlertWebhookURL != "" {
+		svc.SetNotifier(notifier.NewWebhookNotifier(cfg.AlertWebhookURL))
+		log.Printf("Alertas de orçamento serão enviados para o webhook configurado")
+	}
 	
 	// Criar tabela de métricas de processamento
 	if err := repo.CreateProcessingMetricsTable(context.Background()); err != nil {
@@ -58,6 +65,37 @@ func main() {
 	}
 	handler := api.NewHandler(svc)
 
+	// Armazenamento S3 (opcional) para importação via API e agendada
+	var objectStore *scheduler.S3Source
+	if cfg.S3Endpoint != "" {
+		objectStore, err = newS3Source(cfg)
+		if err != nil {
+			log.Printf("⚠️  Aviso: armazenamento S3 indisponível: %v", err)
+		} else {
+			handler.SetObjectStore(objectStore)
+		}
+	}
+
+	// Uploads retomáveis; uploads abandonados são descartados após 24h
+	uploadStore, err := uploads.NewStore(cfg.UploadDir)
+	if err != nil {
+		log.Printf("⚠️  Aviso: upload retomável indisponível: %v", err)
+	} else {
+		handler.SetUploadStore(uploadStore)
+		go runUploadCleanup(uploadStore, time.Hour, 24*time.Hour)
+	}
+
+	// Reenviar entregas de webhook e alertas de orçamento pendentes em segundo plano
+	dispatchCtx, stopDispatcher := context.WithCancel(context.Background())
+	defer stopDispatcher()
+	go svc.RunWebhookDispatcher(dispatchCtx, 30*time.Second)
+	go svc.RunBudgetAlertDispatcher(dispatchCtx, 30*time.Second)
+
+	// Importações agendadas a partir das fontes configuradas
+	importScheduler := setupScheduler(cfg, svc, objectStore)
+	importScheduler.Start()
+	defer importScheduler.Stop()
+
 	// Configurar rotas
 	router := handler.SetupRoutes()
 
@@ -160,6 +198,71 @@ func loadInitialData(svc *service.Service) (err error) {
 	return nil
 }
 
+// runUploadCleanup remove periodicamente os uploads retomáveis parados há mais de maxAge
+func runUploadCleanup(store *uploads.Store, interval, maxAge time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		removed, err := store.Cleanup(maxAge)
+		if err != nil {
+			log.Printf("Erro ao limpar uploads expirados: %v", err)
+			continue
+		}
+		if removed > 0 {
+			log.Printf("🧹 %d uploads expirados removidos", removed)
+		}
+	}
+}
+
+// setupScheduler registra as fontes de importação configuradas; sem fontes o scheduler fica ocioso
+func setupScheduler(cfg *config.Config, svc *service.Service, objectStore *scheduler.S3Source) *scheduler.Scheduler {
+	importer := api.NewUploadHandler(svc)
+	sched := scheduler.New(svc, importer.ImportRecordedFile)
+
+	if cfg.InboxDir != "" {
+		if err := sched.AddSource(scheduler.NewLocalSource("inbox", cfg.InboxDir), cfg.InboxSchedule); err != nil {
+			log.Printf("⚠️  Aviso: caixa de entrada não agendada: %v", err)
+		}
+	}
+
+	if cfg.SFTPAddr != "" {
+		source, err := scheduler.NewSFTPSource("sftp", scheduler.SFTPConfig{
+			Addr:       cfg.SFTPAddr,
+			User:       cfg.SFTPUser,
+			Password:   cfg.SFTPPassword,
+			KeyFile:    cfg.SFTPKeyFile,
+			KnownHosts: cfg.SFTPKnownHosts,
+			HostKey:    cfg.SFTPHostKey,
+			Dir:        cfg.SFTPDir,
+		})
+		if err != nil {
+			log.Printf("⚠️  Aviso: fonte SFTP não agendada: %v", err)
+		} else if err := sched.AddSource(source, cfg.SFTPSchedule); err != nil {
+			log.Printf("⚠️  Aviso: fonte SFTP não agendada: %v", err)
+		}
+	}
+
+	if objectStore != nil && objectStore.Bucket != "" && cfg.S3Schedule != "" {
+		if err := sched.AddSource(objectStore, cfg.S3Schedule); err != nil {
+			log.Printf("⚠️  Aviso: fonte S3 não agendada: %v", err)
+		}
+	}
+
+	return sched
+}
+
+// newS3Source cria a fonte S3 a partir das variáveis IMPORT_S3_*
+func newS3Source(cfg *config.Config) (*scheduler.S3Source, error) {
+	return scheduler.NewS3Source("s3", scheduler.S3Config{
+		Endpoint:  cfg.S3Endpoint,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+		Region:    cfg.S3Region,
+		UseSSL:    cfg.S3UseSSL,
+	}, cfg.S3Bucket, cfg.S3Prefix)
+}
+
 func runMigrations(databaseURL string) error {
 	m, err := migrate.New(
 		"file://db/migrations",
@@ -197,7 +300,7 @@ func processExcelFile(svc *service.Service, filename string) error {
 	log.Printf("Processando planilha: %s", sheetName)
 
 	// Obter todas as linhas
-	rows, err := f.GetRows(sheetName)
+	rows, err := f.GetRows(sheetName, excelize.Options{RawCellValue: true})
 	if err != nil {
 		return fmt.Errorf("erro ao ler linhas da planilha: %w", err)
 	}
@@ -281,6 +384,9 @@ func processExcelFile(svc *service.Service, filename string) error {
 		"credittype":             "credit_type",
 		"benefitorderid":         "benefit_order_id",
 		"benefitid":              "benefit_id",
+		"invoicetotal":           "invoice_control_total",
+		"invoicecontroltotal":    "invoice_control_total",
+		"controltotal":           "invoice_control_total",
 	}
 	
 	// Normalizar cabeçalhos e aplicar aliases
@@ -310,6 +416,20 @@ func processExcelFile(svc *service.Service, filename string) error {
 		}
 	}
 
+	// Células lidas sem formatação; a ordem de dia e mês de datas em texto é inferida do arquivo
+	columnIndex := func(col string) int {
+		if idx, exists := columnMap[col]; exists {
+			return idx
+		}
+		return -1
+	}
+	parser, err := locale.InferRows(locale.Locale{}, rows[1:],
+		[]int{columnIndex("quantity"), columnIndex("unit_price"), columnIndex("billing_pre_tax_total"), columnIndex("invoice_control_total")},
+		[]int{columnIndex("usage_date"), columnIndex("charge_start_date"), columnIndex("charge_end_date")}, true)
+	if err != nil {
+		return err
+	}
+
 	// Processar dados em lotes
 	batchSize := 1000
 	var partners []models.Partner
@@ -323,6 +443,7 @@ func processExcelFile(svc *service.Service, filename string) error {
 
 	rowCount := 0
 	processedCount := 0
+	session := svc.NewImportSession(filename)
 
 	// Processar linhas de dados (pular cabeçalho)
 	for i := 1; i < len(rows); i++ {
@@ -335,7 +456,7 @@ func processExcelFile(svc *service.Service, filename string) error {
 		}
 
 		// Processar linha
-		partner, customer, product, usage, err := parseExcelRow(record, columnMap, rowCount)
+		partner, customer, product, usage, err := parseExcelRow(record, columnMap, rowCount, parser)
 		if err != nil {
 			log.Printf("Erro ao processar linha %d: %v", rowCount, err)
 			continue
@@ -364,7 +485,7 @@ func processExcelFile(svc *service.Service, filename string) error {
 
 		// Processar lote quando atingir o tamanho
 		if len(usages) >= batchSize {
-			if err := svc.ProcessImportData(context.Background(), partners, customers, products, usages); err != nil {
+			if err := svc.ProcessImportBatch(context.Background(), session, partners, customers, products, usages); err != nil {
 				return fmt.Errorf("erro ao processar lote: %w", err)
 			}
 
@@ -381,13 +502,16 @@ func processExcelFile(svc *service.Service, filename string) error {
 
 	// Processar último lote
 	if len(usages) > 0 {
-		if err := svc.ProcessImportData(context.Background(), partners, customers, products, usages); err != nil {
+		if err := svc.ProcessImportBatch(context.Background(), session, partners, customers, products, usages); err != nil {
 			return fmt.Errorf("erro ao processar último lote: %w", err)
 		}
 		processedCount += len(usages)
 		log.Printf("Processado último lote: %d registros", len(usages))
 	}
 
+	// Rotinas que dependem do arquivo inteiro rodam uma vez, após o último lote
+	svc.FinishImport(context.Background(), session)
+
 	log.Printf("Total processado: %d registros de %d linhas", processedCount, rowCount)
 	return nil
 }
@@ -401,7 +525,7 @@ func allEmpty(record []string) bool {
 	return true
 }
 
-func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
+func parseExcelRow(record []string, columnMap map[string]int, rowNum int, parser locale.Parser) (*models.Partner, *models.Customer, *models.Product, *models.Usage, error) {
 	// Função auxiliar para obter valor da coluna
 	getValue := func(colName string) string {
 		if idx, exists := columnMap[colName]; exists && idx < len(record) {
@@ -410,57 +534,6 @@ func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*mode
 		return ""
 	}
 
-	// Função auxiliar para converter string para float
-	parseFloat := func(value string) (float64, error) {
-		if value == "" {
-			return 0, nil
-		}
-		// Remover vírgulas e converter
-		value = strings.ReplaceAll(value, ",", ".")
-		return strconv.ParseFloat(value, 64)
-	}
-
-	// Função auxiliar para converter string para data
-	parseDate := func(value string) (time.Time, error) {
-		if value == "" {
-			return time.Time{}, nil
-		}
-		
-		// Tentar diferentes formatos de data
-		formats := []string{
-			"2006-01-02",
-			"2006/01/02",
-			"02/01/2006",
-			"02-01-2006",
-			"02/01/06",   // ano curto
-			"02-01-06",
-			"1/2/2006",
-			"1-2-2006",
-			"2006-01-02 15:04:05",
-			"2006/01/02 15:04:05",
-			"2006-01-02T15:04:05Z",
-			"2006-01-02T15:04:05.000Z",
-		}
-		
-		
-		for _, format := range formats {
-			if t, err := time.Parse(format, value); err == nil {
-				return t, nil
-			}
-		}
-		
-		// Tentar parsear como número serial do Excel
-		if serial, err := strconv.ParseFloat(value, 64); err == nil {
-			// Converter número serial do Excel para data
-			// Excel usa 1900-01-01 como base, mas tem bug do ano bissexto
-			baseDate := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
-			days := int(serial)
-			return baseDate.AddDate(0, 0, days), nil
-		}
-		
-		return time.Time{}, fmt.Errorf("formato de data inválido: %s", value)
-	}
-
 	// Criar Partner
 	partner := &models.Partner{
 		PartnerID:   getValue("partner_id"),
@@ -501,36 +574,56 @@ func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*mode
 	}
 
 	// Parsear datas
-	usageDate, err := parseDate(getValue("usage_date"))
+	usageDate, err := parser.ParseDate(getValue("usage_date"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear usage_date: %w", err)
 	}
 
-	chargeStartDate, err := parseDate(getValue("charge_start_date"))
-	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_start_date: %w", err)
+	var chargeStartDate time.Time
+	if value := getValue("charge_start_date"); value != "" {
+		if chargeStartDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_start_date: %w", err)
+		}
+	}
+
+	var chargeEndDate time.Time
+	if value := getValue("charge_end_date"); value != "" {
+		if chargeEndDate, err = parser.ParseDate(value); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear charge_end_date: %w", err)
+		}
 	}
 
 	// Parsear valores numéricos
-	quantity, err := parseFloat(getValue("quantity"))
+	quantity, err := parser.ParseNumber(getValue("quantity"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear quantity: %w", err)
 	}
 
-	unitPrice, err := parseFloat(getValue("unit_price"))
+	unitPrice, err := parser.ParseNumber(getValue("unit_price"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear unit_price: %w", err)
 	}
 
-	billingPreTaxTotal, err := parseFloat(getValue("billing_pre_tax_total"))
+	billingPreTaxTotal, err := parser.ParseNumber(getValue("billing_pre_tax_total"))
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("erro ao parsear billing_pre_tax_total: %w", err)
 	}
 
+	// Total de controle da fatura, opcional, usado na conciliação
+	var invoiceControlTotal sql.NullFloat64
+	if value := getValue("invoice_control_total"); value != "" {
+		total, err := parser.ParseNumber(value)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("erro ao parsear invoice_control_total: %w", err)
+		}
+		invoiceControlTotal = sql.NullFloat64{Float64: total, Valid: true}
+	}
+
 	// Criar Usage
 	usage := &models.Usage{
 		InvoiceNumber:      getValue("invoice_number"),
 		ChargeStartDate:    timeToNullTime(chargeStartDate),
+		ChargeEndDate:      timeToNullTime(chargeEndDate),
 		UsageDate:          usageDate,
 		Quantity:           quantity,
 		UnitPrice:          unitPrice,
@@ -538,6 +631,8 @@ func parseExcelRow(record []string, columnMap map[string]int, rowNum int) (*mode
 		ResourceLocation:   getValue("resource_location"),
 		Tags:               getValue("tags"),
 		BenefitType:        getValue("benefit_type"),
+		BillingCurrency:    getValue("billing_currency"),
+		InvoiceControlTotal: invoiceControlTotal,
 		PartnerID:          0, // Será preenchido após inserção
 		CustomerID:         0, // Será preenchido após inserção
 		ProductID:          0, // Será preenchido após inserção
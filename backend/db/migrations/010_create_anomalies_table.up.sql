@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS anomalies (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    granularity VARCHAR(10) NOT NULL,
+    period_start DATE NOT NULL,
+    value DECIMAL(15,2) NOT NULL,
+    baseline DECIMAL(15,2) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    severity VARCHAR(10) NOT NULL,
+    acknowledged BOOLEAN DEFAULT false,
+    acknowledged_by VARCHAR(255),
+    acknowledged_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (customer_id, product_id, granularity, period_start)
+);
+
+CREATE INDEX idx_anomalies_severity ON anomalies(severity);
+CREATE INDEX idx_anomalies_acknowledged ON anomalies(acknowledged);
+CREATE INDEX idx_anomalies_period_start ON anomalies(period_start);
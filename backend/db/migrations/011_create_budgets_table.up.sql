@@ -0,0 +1,32 @@
+-- Budgets referenciam as chaves de negócio (customer_id, partner_id, category) e não os IDs
+-- numéricos, pois a importação com substituição recria clientes e parceiros.
+CREATE TABLE IF NOT EXISTS budgets (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    scope VARCHAR(20) NOT NULL,
+    scope_ref VARCHAR(255) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    thresholds INTEGER[] NOT NULL DEFAULT '{50,80,100}',
+    active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_budgets_scope ON budgets(scope, scope_ref);
+
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    id SERIAL PRIMARY KEY,
+    budget_id INTEGER REFERENCES budgets(id) ON DELETE CASCADE,
+    month DATE NOT NULL,
+    threshold INTEGER NOT NULL,
+    spend DECIMAL(15,2) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    state VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    notified_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (budget_id, month, threshold)
+);
+
+CREATE INDEX idx_budget_alerts_state ON budget_alerts(state);
@@ -0,0 +1,14 @@
+-- Registro dos arquivos importados pelas fontes agendadas; o checksum evita reimportar o mesmo conteúdo
+CREATE TABLE IF NOT EXISTS imported_files (
+    id SERIAL PRIMARY KEY,
+    source VARCHAR(100) NOT NULL,
+    file_name VARCHAR(500) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    usages INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_imported_files_checksum ON imported_files(checksum, status);
+CREATE INDEX idx_imported_files_created_at ON imported_files(created_at);
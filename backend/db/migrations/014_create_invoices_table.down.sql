@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS invoices;
+ALTER TABLE usages DROP COLUMN IF EXISTS billing_currency;
@@ -0,0 +1,28 @@
+-- Moeda de faturamento de cada linha, lida da coluna BillingCurrency
+ALTER TABLE usages ADD COLUMN IF NOT EXISTS billing_currency VARCHAR(10);
+
+-- Faturas derivadas das usages na importação (número × parceiro × cliente). O total de controle
+-- vem do arquivo ou do usuário e é comparado com a soma das linhas na conciliação.
+CREATE TABLE IF NOT EXISTS invoices (
+    id SERIAL PRIMARY KEY,
+    invoice_number VARCHAR(255) NOT NULL,
+    partner_id INTEGER NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    currency VARCHAR(10),
+    pre_tax_total DECIMAL(15,2) NOT NULL,
+    line_count INTEGER NOT NULL,
+    control_total DECIMAL(15,2),
+    control_source VARCHAR(20),
+    reconciliation_status VARCHAR(20) NOT NULL DEFAULT 'unreconciled',
+    difference DECIMAL(15,2),
+    reconciled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (invoice_number, partner_id, customer_id)
+);
+
+CREATE INDEX idx_invoices_customer_id ON invoices(customer_id);
+CREATE INDEX idx_invoices_period_start ON invoices(period_start);
+CREATE INDEX idx_invoices_reconciliation_status ON invoices(reconciliation_status);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS quality_results;
+DROP TABLE IF EXISTS quality_batches;
+DROP TABLE IF EXISTS quality_rules;
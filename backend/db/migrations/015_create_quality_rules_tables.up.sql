@@ -0,0 +1,47 @@
+-- Regras de qualidade configuráveis, avaliadas em cada lote importado
+CREATE TABLE IF NOT EXISTS quality_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    rule_type VARCHAR(50) NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    severity VARCHAR(10) NOT NULL DEFAULT 'warn',
+    active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Um registro por lote avaliado, com os totais de linhas rejeitadas e com aviso
+CREATE TABLE IF NOT EXISTS quality_batches (
+    id SERIAL PRIMARY KEY,
+    rows_checked INTEGER NOT NULL,
+    rejected_rows INTEGER NOT NULL DEFAULT 0,
+    warned_rows INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Violações encontradas; nome e severidade são copiados para sobreviver à alteração da regra
+CREATE TABLE IF NOT EXISTS quality_results (
+    id SERIAL PRIMARY KEY,
+    batch_id INTEGER NOT NULL REFERENCES quality_batches(id) ON DELETE CASCADE,
+    rule_id INTEGER REFERENCES quality_rules(id) ON DELETE SET NULL,
+    rule_name VARCHAR(100) NOT NULL,
+    severity VARCHAR(10) NOT NULL,
+    row_number INTEGER NOT NULL,
+    invoice_number VARCHAR(255),
+    customer_id VARCHAR(255),
+    product_id VARCHAR(255),
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_quality_results_batch ON quality_results(batch_id, row_number);
+CREATE INDEX idx_quality_batches_created_at ON quality_batches(created_at);
+
+INSERT INTO quality_rules (name, rule_type, params, severity) VALUES
+    ('Total coerente com quantidade x preço', 'amount_consistency', '{"tolerance": 0.01, "relative_tolerance": 0.001}', 'warn'),
+    ('Data de uso no futuro', 'usage_date_not_future', '{"max_days_ahead": 1}', 'reject'),
+    ('Data de uso dentro do período da fatura', 'usage_date_in_period', '{}', 'warn'),
+    ('País ISO-3166', 'country_iso3166', '{"allow_empty": true}', 'warn'),
+    ('Domínio do cliente válido', 'domain_name', '{"allow_empty": true}', 'warn'),
+    ('Total negativo apenas para créditos', 'negative_total', '{"allowed_benefit_types": ["Credit"]}', 'warn')
+ON CONFLICT (name) DO NOTHING;
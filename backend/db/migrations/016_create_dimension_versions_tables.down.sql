@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_usages_created_at;
+ALTER TABLE usages DROP COLUMN IF EXISTS product_version_id;
+ALTER TABLE usages DROP COLUMN IF EXISTS customer_version_id;
+ALTER TABLE usages DROP COLUMN IF EXISTS partner_version_id;
+DROP TABLE IF EXISTS product_versions;
+DROP TABLE IF EXISTS customer_versions;
+DROP TABLE IF EXISTS partner_versions;
@@ -0,0 +1,79 @@
+-- Histórico SCD tipo 2 de parceiros, clientes e produtos. As tabelas base continuam com os
+-- atributos atuais; cada alteração fecha a versão corrente e abre uma nova. valid_from e
+-- valid_to seguem o eixo de usage_date, com valid_to exclusivo.
+CREATE TABLE IF NOT EXISTS partner_versions (
+    id SERIAL PRIMARY KEY,
+    partner_ref INTEGER NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    partner_id VARCHAR(255) NOT NULL,
+    partner_name VARCHAR(255),
+    mpn_id VARCHAR(255),
+    tier2_mpn_id VARCHAR(255),
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    is_current BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS customer_versions (
+    id SERIAL PRIMARY KEY,
+    customer_ref INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    customer_id VARCHAR(255) NOT NULL,
+    customer_name VARCHAR(255),
+    customer_domain_name VARCHAR(255),
+    country VARCHAR(100),
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    is_current BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS product_versions (
+    id SERIAL PRIMARY KEY,
+    product_ref INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_id VARCHAR(255) NOT NULL,
+    sku_id VARCHAR(255),
+    sku_name VARCHAR(255),
+    product_name VARCHAR(255),
+    meter_type VARCHAR(100),
+    category VARCHAR(100),
+    sub_category VARCHAR(100),
+    unit_type VARCHAR(50),
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    is_current BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_partner_versions_current ON partner_versions(partner_ref) WHERE is_current;
+CREATE UNIQUE INDEX idx_customer_versions_current ON customer_versions(customer_ref) WHERE is_current;
+CREATE UNIQUE INDEX idx_product_versions_current ON product_versions(product_ref) WHERE is_current;
+CREATE INDEX idx_partner_versions_ref ON partner_versions(partner_ref, valid_from);
+CREATE INDEX idx_customer_versions_ref ON customer_versions(customer_ref, valid_from);
+CREATE INDEX idx_product_versions_ref ON product_versions(product_ref, valid_from);
+
+-- Versão da dimensão vigente na usage_date, gravada na importação
+ALTER TABLE usages ADD COLUMN IF NOT EXISTS partner_version_id INTEGER REFERENCES partner_versions(id) ON DELETE SET NULL;
+ALTER TABLE usages ADD COLUMN IF NOT EXISTS customer_version_id INTEGER REFERENCES customer_versions(id) ON DELETE SET NULL;
+ALTER TABLE usages ADD COLUMN IF NOT EXISTS product_version_id INTEGER REFERENCES product_versions(id) ON DELETE SET NULL;
+CREATE INDEX idx_usages_created_at ON usages(created_at);
+
+-- Uma versão inicial por entidade existente, a partir da primeira usage_date
+INSERT INTO partner_versions (partner_ref, partner_id, partner_name, mpn_id, tier2_mpn_id, valid_from)
+SELECT p.id, p.partner_id, p.partner_name, p.mpn_id, p.tier2_mpn_id,
+       COALESCE((SELECT MIN(u.usage_date) FROM usages u WHERE u.partner_id = p.id), p.created_at::date, CURRENT_DATE)
+FROM partners p;
+
+INSERT INTO customer_versions (customer_ref, customer_id, customer_name, customer_domain_name, country, valid_from)
+SELECT c.id, c.customer_id, c.customer_name, c.customer_domain_name, c.country,
+       COALESCE((SELECT MIN(u.usage_date) FROM usages u WHERE u.customer_id = c.id), c.created_at::date, CURRENT_DATE)
+FROM customers c;
+
+INSERT INTO product_versions (product_ref, product_id, sku_id, sku_name, product_name, meter_type, category, sub_category, unit_type, valid_from)
+SELECT pr.id, pr.product_id, pr.sku_id, pr.sku_name, pr.product_name, pr.meter_type, pr.category, pr.sub_category, pr.unit_type,
+       COALESCE((SELECT MIN(u.usage_date) FROM usages u WHERE u.product_id = pr.id), pr.created_at::date, CURRENT_DATE)
+FROM products pr;
+
+UPDATE usages u SET
+    partner_version_id = (SELECT v.id FROM partner_versions v WHERE v.partner_ref = u.partner_id),
+    customer_version_id = (SELECT v.id FROM customer_versions v WHERE v.customer_ref = u.customer_id),
+    product_version_id = (SELECT v.id FROM product_versions v WHERE v.product_ref = u.product_id);
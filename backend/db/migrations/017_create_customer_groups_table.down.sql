@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_customers_group_id;
+ALTER TABLE customers DROP COLUMN IF EXISTS group_source;
+ALTER TABLE customers DROP COLUMN IF EXISTS group_id;
+DROP TABLE IF EXISTS customer_groups;
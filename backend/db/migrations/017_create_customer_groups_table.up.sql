@@ -0,0 +1,16 @@
+-- Grupos de clientes: a organização real por trás de vários customer_id (um por tenant)
+CREATE TABLE IF NOT EXISTS customer_groups (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    domain_patterns TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Cada cliente pertence a no máximo um grupo. group_source indica se a associação foi feita
+-- manualmente pela API (manual) ou por padrão de domínio (pattern); as manuais não são
+-- sobrescritas pelos padrões.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS group_id INTEGER REFERENCES customer_groups(id) ON DELETE SET NULL;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS group_source VARCHAR(10);
+
+CREATE INDEX IF NOT EXISTS idx_customers_group_id ON customers(group_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_allocation_rules_tag_key;
+DROP TABLE IF EXISTS allocation_rules;
+DROP INDEX IF EXISTS idx_usages_tag_map;
+ALTER TABLE usages DROP COLUMN IF EXISTS tag_map;
@@ -0,0 +1,51 @@
+-- Tags interpretadas das usages, com chaves em minúsculas ({"costcenter": "CC1"})
+ALTER TABLE usages ADD COLUMN IF NOT EXISTS tag_map JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_usages_tag_map ON usages USING GIN (tag_map);
+
+-- Preencher as usages existentes cujas tags estejam em JSON, com ou sem as chaves externas
+-- (formato das exportações CSV do Azure). Os demais formatos são interpretados nas próximas
+-- importações.
+DO $$
+DECLARE
+    r RECORD;
+    parsed JSONB;
+BEGIN
+    FOR r IN SELECT id, btrim(tags) AS tags FROM usages WHERE btrim(COALESCE(tags, '')) <> '' LOOP
+        parsed := NULL;
+        BEGIN
+            IF left(r.tags, 1) = '{' THEN
+                parsed := r.tags::jsonb;
+            ELSIF left(r.tags, 1) = '"' THEN
+                parsed := ('{' || r.tags || '}')::jsonb;
+            END IF;
+        EXCEPTION WHEN others THEN
+            parsed := NULL;
+        END;
+
+        IF parsed IS NOT NULL AND jsonb_typeof(parsed) = 'object' THEN
+            UPDATE usages
+            SET tag_map = (
+                SELECT COALESCE(jsonb_object_agg(lower(btrim(key)), btrim(value #>> '{}')), '{}')
+                FROM jsonb_each(parsed)
+                WHERE btrim(key) <> ''
+            )
+            WHERE id = r.id;
+        END IF;
+    END LOOP;
+END $$;
+
+-- Regras de rateio: o custo das usages cujo valor da tag tag_key é source_value (vazio para
+-- usages sem a tag) é dividido entre os valores de splits ([{"value": "CC1", "percent": 60}])
+CREATE TABLE IF NOT EXISTS allocation_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    tag_key VARCHAR(255) NOT NULL,
+    source_value VARCHAR(255) NOT NULL DEFAULT '',
+    splits JSONB NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_allocation_rules_tag_key ON allocation_rules(tag_key);
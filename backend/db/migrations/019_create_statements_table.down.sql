@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_statements_period;
+DROP TABLE IF EXISTS statements;
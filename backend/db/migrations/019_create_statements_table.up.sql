@@ -0,0 +1,16 @@
+-- Demonstrativos mensais (chargeback/showback) por cliente ou centro de custo. subject_key
+-- identifica o sujeito (customer:<id> ou tag:<chave>=<valor>); cada geração com conteúdo
+-- diferente da última grava uma nova versão, de modo que reimportações geram revisões.
+CREATE TABLE IF NOT EXISTS statements (
+    id SERIAL PRIMARY KEY,
+    subject_key VARCHAR(600) NOT NULL,
+    period VARCHAR(7) NOT NULL,
+    version INTEGER NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subject_key, period, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_statements_period ON statements(period);
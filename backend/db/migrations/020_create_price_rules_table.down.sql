@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_price_rules_effective;
+DROP TABLE IF EXISTS price_rules;
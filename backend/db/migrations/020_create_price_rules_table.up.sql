@@ -0,0 +1,23 @@
+-- Regras de preço de revenda. Cada regra é um markup sobre o custo do parceiro ou um desconto
+-- sobre o preço com markup, com percentual fixo ou por faixas de quantidade mensal
+-- ([{"min_quantity": 0, "percent": 20}, {"min_quantity": 1000, "percent": 15}]). O escopo é
+-- qualquer combinação de cliente, produto e categoria (nulos valem para todos); na usage vale
+-- a regra mais específica vigente na usage_date (cliente pesa mais que produto, que pesa mais
+-- que categoria; effective_to é exclusivo).
+CREATE TABLE IF NOT EXISTS price_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    rule_type VARCHAR(20) NOT NULL,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    category VARCHAR(100),
+    percent DECIMAL(9,4),
+    tiers JSONB,
+    effective_from DATE NOT NULL,
+    effective_to DATE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_rules_effective ON price_rules(effective_from, effective_to);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_partners_tier2_mpn_id_trgm;
+DROP INDEX IF EXISTS idx_partners_mpn_id_trgm;
+DROP INDEX IF EXISTS idx_partners_name_trgm;
+DROP INDEX IF EXISTS idx_products_sku_name_trgm;
+DROP INDEX IF EXISTS idx_products_name_trgm;
+DROP INDEX IF EXISTS idx_customers_domain_trgm;
+DROP INDEX IF EXISTS idx_customers_name_trgm;
@@ -0,0 +1,11 @@
+-- Busca por trechos de nomes, SKUs, domínios e MPN IDs: índices trigram atendem tanto ILIKE
+-- '%termo%' quanto a similaridade (operador %) usada para tolerar erros de digitação
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_customers_name_trgm ON customers USING GIN (customer_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_domain_trgm ON customers USING GIN (customer_domain_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (product_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_sku_name_trgm ON products USING GIN (sku_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_partners_name_trgm ON partners USING GIN (partner_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_partners_mpn_id_trgm ON partners USING GIN (mpn_id gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_partners_tier2_mpn_id_trgm ON partners USING GIN (tier2_mpn_id gin_trgm_ops);
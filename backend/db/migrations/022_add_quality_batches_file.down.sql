@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_quality_batches_checksum;
+ALTER TABLE quality_batches DROP COLUMN IF EXISTS checksum;
+ALTER TABLE quality_batches DROP COLUMN IF EXISTS file_name;
@@ -0,0 +1,6 @@
+-- Liga cada lote de qualidade ao arquivo importado: nome e, nas importações agendadas, o checksum
+-- registrado em imported_files
+ALTER TABLE quality_batches ADD COLUMN IF NOT EXISTS file_name VARCHAR(500);
+ALTER TABLE quality_batches ADD COLUMN IF NOT EXISTS checksum CHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_quality_batches_checksum ON quality_batches(checksum);
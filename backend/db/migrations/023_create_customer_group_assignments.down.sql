@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_customer_group_assignments_group_id;
+DROP TABLE IF EXISTS customer_group_assignments;
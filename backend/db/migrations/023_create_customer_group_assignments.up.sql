@@ -0,0 +1,14 @@
+-- Associações manuais de clientes a grupos, pela chave de negócio customer_id. Ficam fora de
+-- customers para sobreviver às importações com substituição, que recriam os clientes.
+CREATE TABLE IF NOT EXISTS customer_group_assignments (
+    customer_id VARCHAR(255) PRIMARY KEY,
+    group_id INTEGER NOT NULL REFERENCES customer_groups(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_group_assignments_group_id ON customer_group_assignments(group_id);
+
+INSERT INTO customer_group_assignments (customer_id, group_id)
+SELECT customer_id, group_id FROM customers
+WHERE group_source = 'manual' AND group_id IS NOT NULL
+ON CONFLICT (customer_id) DO NOTHING;
@@ -0,0 +1,6 @@
+UPDATE statements s
+SET subject_key = 'customer:' || c.id,
+    data = s.data #- '{subject,customer_key}'
+FROM customers c
+WHERE s.subject_key = 'customer:' || c.customer_id
+  AND s.data->'subject'->>'customer_key' = c.customer_id;
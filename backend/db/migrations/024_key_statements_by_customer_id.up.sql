@@ -0,0 +1,7 @@
+-- Os demonstrativos de cliente passam a ser identificados pelo customer_id de negócio
+-- (customer:<customer_id>), que sobrevive às reimportações, em vez do id interno
+UPDATE statements s
+SET subject_key = 'customer:' || c.customer_id,
+    data = jsonb_set(s.data, '{subject,customer_key}', to_jsonb(c.customer_id))
+FROM customers c
+WHERE s.subject_key = 'customer:' || c.id;
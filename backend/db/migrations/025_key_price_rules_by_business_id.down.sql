@@ -0,0 +1,15 @@
+ALTER TABLE price_rules ADD COLUMN IF NOT EXISTS customer_ref INTEGER REFERENCES customers(id) ON DELETE CASCADE;
+ALTER TABLE price_rules ADD COLUMN IF NOT EXISTS product_ref INTEGER REFERENCES products(id) ON DELETE CASCADE;
+
+UPDATE price_rules r SET customer_ref = c.id FROM customers c WHERE c.customer_id = r.customer_id;
+UPDATE price_rules r SET product_ref = p.id FROM products p WHERE p.product_id = r.product_id;
+
+-- Regras de clientes ou produtos que não existem mais seriam removidas pelo CASCADE; sem isso
+-- passariam a valer para todos
+DELETE FROM price_rules
+WHERE (customer_id IS NOT NULL AND customer_ref IS NULL) OR (product_id IS NOT NULL AND product_ref IS NULL);
+
+ALTER TABLE price_rules DROP COLUMN customer_id;
+ALTER TABLE price_rules DROP COLUMN product_id;
+ALTER TABLE price_rules RENAME COLUMN customer_ref TO customer_id;
+ALTER TABLE price_rules RENAME COLUMN product_ref TO product_id;
@@ -0,0 +1,13 @@
+-- O escopo das regras de preço passa a usar as chaves de negócio (customer_id e product_id), sem
+-- chave estrangeira: as regras sobrevivem às importações com substituição, que recriam clientes e
+-- produtos, e são resolvidas no cálculo de preço
+ALTER TABLE price_rules ADD COLUMN IF NOT EXISTS customer_key VARCHAR(255);
+ALTER TABLE price_rules ADD COLUMN IF NOT EXISTS product_key VARCHAR(255);
+
+UPDATE price_rules r SET customer_key = c.customer_id FROM customers c WHERE c.id = r.customer_id;
+UPDATE price_rules r SET product_key = p.product_id FROM products p WHERE p.id = r.product_id;
+
+ALTER TABLE price_rules DROP COLUMN customer_id;
+ALTER TABLE price_rules DROP COLUMN product_id;
+ALTER TABLE price_rules RENAME COLUMN customer_key TO customer_id;
+ALTER TABLE price_rules RENAME COLUMN product_key TO product_id;
@@ -0,0 +1,245 @@
+// Package csvdialect detecta delimitador, codificação e BOM de arquivos CSV e entrega um
+// csv.Reader já configurado, com o conteúdo convertido para UTF-8.
+package csvdialect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Codificações suportadas
+const (
+	UTF8        = "utf-8"
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Latin1      = "iso-8859-1"
+	Windows1252 = "windows-1252"
+)
+
+// Delimiters são os delimitadores considerados na detecção, em ordem de preferência
+var Delimiters = []rune{',', ';', '\t', '|'}
+
+// sampleSize é o trecho inicial usado na detecção
+const sampleSize = 64 << 10
+
+// maxSampleRecords limita os registros analisados na detecção do delimitador
+const maxSampleRecords = 50
+
+// Dialect descreve como o arquivo deve ser lido. Campos zerados são detectados.
+type Dialect struct {
+	Delimiter rune   `json:"delimiter"`
+	Encoding  string `json:"encoding"`
+	BOM       bool   `json:"bom"`
+}
+
+func (d Dialect) String() string {
+	delimiter := string(d.Delimiter)
+	if d.Delimiter == '\t' {
+		delimiter = "tab"
+	}
+	return fmt.Sprintf("delimitador %q, codificação %s, BOM %t", delimiter, d.Encoding, d.BOM)
+}
+
+// NewReader detecta o dialeto nos primeiros bytes de r, aplicando os campos definidos em
+// override, e retorna um leitor tolerante a aspas mal formadas e linhas com número variável
+// de colunas.
+func NewReader(r io.Reader, override Dialect) (*csv.Reader, Dialect, error) {
+	buffered := bufio.NewReaderSize(r, sampleSize)
+	sample, err := buffered.Peek(sampleSize)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, Dialect{}, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+
+	dialect := override
+	bomEncoding := detectBOM(sample)
+	dialect.BOM = bomEncoding != ""
+	if dialect.Encoding == "" {
+		dialect.Encoding = DetectEncoding(sample)
+	}
+
+	enc, err := encodingFor(dialect.Encoding)
+	if err != nil {
+		return nil, Dialect{}, err
+	}
+
+	if dialect.Delimiter == 0 {
+		// Amostra cortada: descarta a última linha, possivelmente incompleta
+		if len(sample) == sampleSize {
+			if idx := bytes.LastIndexByte(sample, '\n'); idx > 0 {
+				sample = sample[:idx]
+			}
+		}
+		dialect.Delimiter = DetectDelimiter(decodeSample(sample, dialect.Encoding, enc))
+	}
+
+	reader := csv.NewReader(transform.NewReader(buffered, unicode.BOMOverride(enc.NewDecoder())))
+	reader.Comma = dialect.Delimiter
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	return reader, dialect, nil
+}
+
+// detectBOM retorna a codificação indicada pelo BOM, ou vazio
+func detectBOM(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xef, 0xbb, 0xbf}):
+		return UTF8
+	case bytes.HasPrefix(sample, []byte{0xff, 0xfe}):
+		return UTF16LE
+	case bytes.HasPrefix(sample, []byte{0xfe, 0xff}):
+		return UTF16BE
+	}
+	return ""
+}
+
+// DetectEncoding identifica a codificação pelo BOM, pela posição dos bytes nulos (UTF-16 sem
+// BOM) ou pela validade UTF-8; o restante é tratado como Windows-1252, superconjunto prático
+// do Latin-1 nas exportações do Excel.
+func DetectEncoding(sample []byte) string {
+	if enc := detectBOM(sample); enc != "" {
+		return enc
+	}
+
+	var evenZeros, oddZeros int
+	for i, b := range sample {
+		if b == 0 {
+			if i%2 == 0 {
+				evenZeros++
+			} else {
+				oddZeros++
+			}
+		}
+	}
+	if half := len(sample) / 2; half > 0 {
+		if oddZeros > half/2 && oddZeros > evenZeros {
+			return UTF16LE
+		}
+		if evenZeros > half/2 && evenZeros > oddZeros {
+			return UTF16BE
+		}
+	}
+
+	if utf8.Valid(trimIncompleteRune(sample)) {
+		return UTF8
+	}
+	return Windows1252
+}
+
+// trimIncompleteRune descarta um caractere UTF-8 cortado no fim da amostra
+func trimIncompleteRune(sample []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.RuneStart(sample[len(sample)-i]) {
+			if !utf8.FullRune(sample[len(sample)-i:]) {
+				return sample[:len(sample)-i]
+			}
+			break
+		}
+	}
+	return sample
+}
+
+// DetectDelimiter escolhe o delimitador cujo número de colunas (maior que 1) se repete em mais
+// registros; linhas de título acima do cabeçalho não atrapalham a escolha. Empates favorecem
+// mais colunas e, depois, a ordem de Delimiters.
+func DetectDelimiter(sample string) rune {
+	best, bestFrequency, bestFields := ',', 0, 0
+	for _, delimiter := range Delimiters {
+		reader := csv.NewReader(strings.NewReader(sample))
+		reader.Comma = delimiter
+		reader.LazyQuotes = true
+		reader.FieldsPerRecord = -1
+
+		counts := make(map[int]int)
+		for i := 0; i < maxSampleRecords; i++ {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			if len(record) > 1 {
+				counts[len(record)]++
+			}
+		}
+
+		for fields, frequency := range counts {
+			if frequency > bestFrequency || (frequency == bestFrequency && fields > bestFields) {
+				best, bestFrequency, bestFields = delimiter, frequency, fields
+			}
+		}
+	}
+	return best
+}
+
+// decodeSample converte a amostra para UTF-8 para a detecção do delimitador
+func decodeSample(sample []byte, name string, enc encoding.Encoding) string {
+	if name == UTF16LE || name == UTF16BE {
+		sample = sample[:len(sample)-len(sample)%2]
+	}
+	decoded, _, err := transform.Bytes(unicode.BOMOverride(enc.NewDecoder()), sample)
+	if err != nil {
+		return string(sample)
+	}
+	return string(decoded)
+}
+
+func encodingFor(name string) (encoding.Encoding, error) {
+	switch name {
+	case UTF8:
+		return unicode.UTF8, nil
+	case UTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), nil
+	case UTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	case Latin1:
+		return charmap.ISO8859_1, nil
+	case Windows1252:
+		return charmap.Windows1252, nil
+	}
+	return nil, fmt.Errorf("codificação não suportada: %s", name)
+}
+
+// ParseEncoding normaliza o nome da codificação informado pelo usuário; vazio significa detectar
+func ParseEncoding(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "auto":
+		return "", nil
+	case "utf-8", "utf8":
+		return UTF8, nil
+	case "utf-16", "utf16", "utf-16le", "utf16le":
+		return UTF16LE, nil
+	case "utf-16be", "utf16be":
+		return UTF16BE, nil
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		return Latin1, nil
+	case "windows-1252", "cp1252", "win1252":
+		return Windows1252, nil
+	}
+	return "", fmt.Errorf("codificação não suportada: %s (use utf-8, utf-16le, utf-16be, latin1 ou windows-1252)", value)
+}
+
+// ParseDelimiter interpreta o delimitador informado pelo usuário: um caractere, "tab" ou "\t";
+// vazio significa detectar
+func ParseDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "", "auto":
+		return 0, nil
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(value)
+	if size != len(value) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("delimitador inválido: %q (use um caractere, como , ; | ou tab)", value)
+	}
+	return r, nil
+}
@@ -0,0 +1,148 @@
+package csvdialect
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func readAll(t *testing.T, data []byte, override Dialect) ([][]string, Dialect) {
+	t.Helper()
+	reader, dialect, err := NewReader(bytes.NewReader(data), override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, record)
+	}
+	return rows, dialect
+}
+
+func TestNewReader_BrazilianExport(t *testing.T) {
+	content := "Descrição;Preço;Município\r\n\"Licença; anual\";1.234,56;São Paulo\r\n"
+	data, err := charmap.Windows1252.NewEncoder().Bytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, dialect := readAll(t, data, Dialect{})
+	if dialect.Delimiter != ';' || dialect.Encoding != Windows1252 || dialect.BOM {
+		t.Errorf("dialect = %+v", dialect)
+	}
+	if len(rows) != 2 || rows[0][0] != "Descrição" || rows[1][0] != "Licença; anual" || rows[1][2] != "São Paulo" {
+		t.Errorf("rows = %q", rows)
+	}
+}
+
+func TestNewReader_UTF8BOM(t *testing.T) {
+	data := append([]byte{0xef, 0xbb, 0xbf}, []byte("PartnerId,Quantity\nP1,2\n")...)
+
+	rows, dialect := readAll(t, data, Dialect{})
+	if dialect.Encoding != UTF8 || !dialect.BOM || dialect.Delimiter != ',' {
+		t.Errorf("dialect = %+v", dialect)
+	}
+	if rows[0][0] != "PartnerId" {
+		t.Errorf("BOM não removido: %q", rows[0][0])
+	}
+}
+
+func TestNewReader_UTF16(t *testing.T) {
+	content := "PartnerId\tCustomerId\tQuantidade\nP1\tC1\t2\nP2\tC2\t3\n"
+
+	withBOM, _ := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(content))
+	rows, dialect := readAll(t, withBOM, Dialect{})
+	if dialect.Encoding != UTF16LE || !dialect.BOM || dialect.Delimiter != '\t' {
+		t.Errorf("dialect = %+v", dialect)
+	}
+	if len(rows) != 3 || rows[0][0] != "PartnerId" || rows[2][2] != "3" {
+		t.Errorf("rows = %q", rows)
+	}
+
+	bigEndian, _ := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte(content))
+	rows, dialect = readAll(t, bigEndian, Dialect{})
+	if dialect.Encoding != UTF16BE || dialect.BOM {
+		t.Errorf("dialect = %+v", dialect)
+	}
+	if rows[1][1] != "C1" {
+		t.Errorf("rows = %q", rows)
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	cases := []struct {
+		sample string
+		want   rune
+	}{
+		{"a,b,c\n1,2,3\n", ','},
+		{"a;b;c\n1,5;2,5;3\n", ';'},
+		{"a|b|c\n1|2|3\n", '|'},
+		{"a\tb\n1\t2\n", '\t'},
+		// Título com vírgula acima de um cabeçalho com ponto e vírgula
+		{"Relatório, janeiro\na;b;c;d\n1;2;3;4\n5;6;7;8\n", ';'},
+		// Aspas protegem o delimitador
+		{"\"x;y\",b\n\"1;2\",3\n", ','},
+		{"sem delimitador\n", ','},
+	}
+	for _, c := range cases {
+		if got := DetectDelimiter(c.sample); got != c.want {
+			t.Errorf("DetectDelimiter(%q) = %q, want %q", c.sample, got, c.want)
+		}
+	}
+}
+
+func TestNewReader_Overrides(t *testing.T) {
+	data := []byte("a;b|c\n1;2|3\n")
+
+	rows, dialect := readAll(t, data, Dialect{Delimiter: '|', Encoding: Latin1})
+	if dialect.Delimiter != '|' || dialect.Encoding != Latin1 {
+		t.Errorf("dialect = %+v", dialect)
+	}
+	if len(rows[0]) != 2 || rows[0][0] != "a;b" {
+		t.Errorf("rows = %q", rows)
+	}
+
+	if _, _, err := NewReader(bytes.NewReader(data), Dialect{Encoding: "ebcdic"}); err == nil {
+		t.Error("esperava erro para codificação desconhecida")
+	}
+}
+
+func TestNewReader_LazyQuotesAndRaggedRows(t *testing.T) {
+	rows, _ := readAll(t, []byte("a,b,c\n1,polegada 5\",3\n4,5\n"), Dialect{})
+	if len(rows) != 3 || rows[1][1] != `polegada 5"` || len(rows[2]) != 2 {
+		t.Errorf("rows = %q", rows)
+	}
+}
+
+func TestParseDelimiterAndEncoding(t *testing.T) {
+	for value, want := range map[string]rune{"": 0, "auto": 0, "tab": '\t', `\t`: '\t', ";": ';', "|": '|'} {
+		if got, err := ParseDelimiter(value); err != nil || got != want {
+			t.Errorf("ParseDelimiter(%q) = %q, %v", value, got, err)
+		}
+	}
+	for _, value := range []string{";;", `"`, "\n"} {
+		if _, err := ParseDelimiter(value); err == nil {
+			t.Errorf("ParseDelimiter(%q) deveria falhar", value)
+		}
+	}
+
+	for value, want := range map[string]string{"": "", "UTF8": UTF8, "latin1": Latin1, "cp1252": Windows1252, "utf-16": UTF16LE, "UTF-16BE": UTF16BE} {
+		if got, err := ParseEncoding(value); err != nil || got != want {
+			t.Errorf("ParseEncoding(%q) = %q, %v", value, got, err)
+		}
+	}
+	if _, err := ParseEncoding("shift-jis"); err == nil || !strings.Contains(err.Error(), "não suportada") {
+		t.Errorf("ParseEncoding(shift-jis) err = %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package locale
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isoLayouts não dependem do locale
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"2006.01.02",
+}
+
+// numericDate captura datas a/b/aaaa com separador / - ou . e hora opcional
+var numericDate = regexp.MustCompile(`^(\d{1,2})([/.-])(\d{1,2})([/.-])(\d{2}|\d{4})(?:[ T](\d{1,2}):(\d{2})(?::(\d{2}))?)?$`)
+
+// compactDate captura aaaammdd
+var compactDate = regexp.MustCompile(`^(\d{4})(\d{2})(\d{2})$`)
+
+// excelEpoch é a base das datas seriais do Excel (sistema 1900, já compensando o 29/02/1900 inexistente)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Limites de datas seriais aceitas (1900-01-01 a 2199-12-31); inteiros fora disso não são datas
+const (
+	minExcelSerial = 1
+	maxExcelSerial = 109574
+)
+
+// ParseDate converte a data. Datas ISO e seriais do Excel são aceitas em qualquer locale; em
+// datas numéricas como 03/04/2024, a ordem de dia e mês vem do parser e, sem ela, só é aceita
+// quando um dos campos for maior que 12.
+func (p Parser) ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("data vazia")
+	}
+
+	for _, layout := range isoLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	if m := compactDate.FindStringSubmatch(value); m != nil {
+		if t, err := time.Parse("20060102", value); err == nil {
+			return t, nil
+		}
+	}
+
+	if m := numericDate.FindStringSubmatch(value); m != nil {
+		if m[2] != m[4] {
+			return time.Time{}, fmt.Errorf("data inválida %q: separadores diferentes", value)
+		}
+		return p.parseNumericDate(value, m)
+	}
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		return excelSerialDate(value, serial)
+	}
+
+	return time.Time{}, fmt.Errorf("data inválida %q", value)
+}
+
+// parseNumericDate resolve dia e mês de acordo com a ordem do parser
+func (p Parser) parseNumericDate(value string, m []string) (time.Time, error) {
+	a, _ := strconv.Atoi(m[1])
+	b, _ := strconv.Atoi(m[3])
+	year, _ := strconv.Atoi(m[5])
+	if len(m[5]) == 2 {
+		// Pivô em 70: 69 → 2069, 70 → 1970
+		if year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	order := p.DateOrder
+	if order == OrderUnknown {
+		order = orderFromValues(a, b)
+		if order == OrderUnknown {
+			return time.Time{}, fmt.Errorf("%w: %q pode ser dia/mês ou mês/dia (informe o locale, ex.: pt-BR ou en-US)", ErrAmbiguousDate, value)
+		}
+	}
+
+	day, month := a, b
+	if order == MonthDay {
+		day, month = b, a
+	}
+
+	var hour, minute, second int
+	if m[6] != "" {
+		hour, _ = strconv.Atoi(m[6])
+		minute, _ = strconv.Atoi(m[7])
+		if m[8] != "" {
+			second, _ = strconv.Atoi(m[8])
+		}
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
+	// time.Date normaliza valores fora do intervalo (31/02 vira março); rejeita em vez de ajustar
+	if t.Day() != day || int(t.Month()) != month || t.Hour() != hour || t.Minute() != minute || t.Second() != second {
+		return time.Time{}, fmt.Errorf("data inválida %q para a ordem %s", value, order)
+	}
+	return t, nil
+}
+
+// orderFromValues deduz a ordem de um único valor; retorna OrderUnknown quando ambos os campos
+// podem ser mês. Valores iguais (05/05) não dependem da ordem.
+func orderFromValues(a, b int) DateOrder {
+	switch {
+	case a > 12:
+		return DayMonth
+	case b > 12:
+		return MonthDay
+	case a == b:
+		return DayMonth
+	}
+	return OrderUnknown
+}
+
+// excelSerialDate converte o número serial do Excel (dias desde 1899-12-30, fração = hora)
+func excelSerialDate(value string, serial float64) (time.Time, error) {
+	if serial < minExcelSerial || serial >= maxExcelSerial+1 {
+		return time.Time{}, fmt.Errorf("data inválida %q", value)
+	}
+	days := int(serial)
+	seconds := int((serial-float64(days))*86400 + 0.5)
+	return excelEpoch.AddDate(0, 0, days).Add(time.Duration(seconds) * time.Second), nil
+}
@@ -0,0 +1,130 @@
+package locale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Infer define o parser de um arquivo a partir do locale informado e, no que ele não definir,
+// das evidências de todos os valores: qualquer valor que só admite uma leitura (1.234,56 ou
+// 25/03/2024) decide o arquivo inteiro. Evidências contraditórias e valores que continuam
+// ambíguos após a inferência são erros, com um exemplo do valor problemático.
+func Infer(base Locale, numbers, dates []string, canonical bool) (Parser, error) {
+	p := NewParser(base)
+	p.Canonical = canonical
+
+	if p.Decimal == 0 {
+		decimal, err := inferDecimal(numbers, canonical)
+		if err != nil {
+			return p, err
+		}
+		if decimal != 0 {
+			p.Decimal, p.Group = decimal, otherSeparator(decimal)
+		}
+	}
+	for _, value := range numbers {
+		if _, err := p.ParseNumber(value); err != nil && IsAmbiguous(err) {
+			return p, fmt.Errorf("não foi possível determinar o separador decimal: %w", err)
+		}
+	}
+
+	if p.DateOrder == OrderUnknown {
+		order, err := inferDateOrder(dates)
+		if err != nil {
+			return p, err
+		}
+		p.DateOrder = order
+	}
+	for _, value := range dates {
+		if _, err := p.ParseDate(value); err != nil && IsAmbiguous(err) {
+			return p, fmt.Errorf("não foi possível determinar a ordem de dia e mês: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// InferRows aplica Infer aos valores das colunas informadas (índices) em todas as linhas;
+// índices negativos ou fora da linha são ignorados
+func InferRows(base Locale, rows [][]string, numberColumns, dateColumns []int, canonical bool) (Parser, error) {
+	collect := func(columns []int) []string {
+		var values []string
+		for _, row := range rows {
+			for _, idx := range columns {
+				if idx >= 0 && idx < len(row) {
+					if value := strings.TrimSpace(row[idx]); value != "" {
+						values = append(values, value)
+					}
+				}
+			}
+		}
+		return values
+	}
+	return Infer(base, collect(numberColumns), collect(dateColumns), canonical)
+}
+
+// inferDecimal retorna o separador decimal indicado pelos valores inequívocos, ou 0 sem evidência
+func inferDecimal(numbers []string, canonical bool) (rune, error) {
+	var decimal rune
+	var example string
+	for _, value := range numbers {
+		if value == "" || (canonical && canonicalNumber.MatchString(value)) {
+			continue
+		}
+		_, digits, err := splitSign(value)
+		if err != nil {
+			continue
+		}
+		found, _, ambiguous := classifySeparators(digits)
+		if ambiguous || !containsSeparator(digits) {
+			continue
+		}
+		if decimal == 0 {
+			decimal, example = found, value
+			continue
+		}
+		if found != decimal {
+			return 0, fmt.Errorf("separador decimal inconsistente no arquivo: %q usa '%c' e %q usa '%c'", example, decimal, value, found)
+		}
+	}
+	return decimal, nil
+}
+
+// inferDateOrder retorna a ordem indicada pelas datas numéricas inequívocas, ou OrderUnknown
+func inferDateOrder(dates []string) (DateOrder, error) {
+	order := OrderUnknown
+	var example string
+	for _, value := range dates {
+		m := numericDate.FindStringSubmatch(value)
+		if m == nil {
+			continue
+		}
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[3])
+		if a == b || (a > 12 && b > 12) {
+			continue
+		}
+		found := orderFromValues(a, b)
+		if found == OrderUnknown {
+			continue
+		}
+		if order == OrderUnknown {
+			order, example = found, value
+			continue
+		}
+		if found != order {
+			return OrderUnknown, fmt.Errorf("ordem de dia e mês inconsistente no arquivo: %q é %s e %q é %s", example, order, value, found)
+		}
+	}
+	return order, nil
+}
+
+func containsSeparator(digits string) bool {
+	for _, r := range digits {
+		if r == '.' || r == ',' {
+			return true
+		}
+	}
+	return false
+}
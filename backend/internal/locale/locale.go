@@ -0,0 +1,102 @@
+// Package locale interpreta números e datas de arquivos importados de acordo com o locale
+// informado ou inferido do próprio arquivo, rejeitando valores ambíguos em vez de adivinhar.
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DateOrder é a ordem de dia e mês em datas numéricas (ex.: 03/04/2024)
+type DateOrder int
+
+const (
+	// OrderUnknown indica que a ordem ainda não foi definida nem inferida
+	OrderUnknown DateOrder = iota
+	// DayMonth lê 03/04/2024 como 3 de abril
+	DayMonth
+	// MonthDay lê 03/04/2024 como 4 de março
+	MonthDay
+)
+
+func (o DateOrder) String() string {
+	switch o {
+	case DayMonth:
+		return "dia/mês"
+	case MonthDay:
+		return "mês/dia"
+	}
+	return "indefinida"
+}
+
+// Erros de ambiguidade: o valor admite mais de uma leitura e o locale não decide
+var (
+	ErrAmbiguousNumber = errors.New("número ambíguo")
+	ErrAmbiguousDate   = errors.New("data ambígua")
+)
+
+// IsAmbiguous indica se o erro é de número ou data ambíguos
+func IsAmbiguous(err error) bool {
+	return errors.Is(err, ErrAmbiguousNumber) || errors.Is(err, ErrAmbiguousDate)
+}
+
+// Locale descreve separadores numéricos e ordem das datas. O valor zero representa detecção
+// automática.
+type Locale struct {
+	Name      string
+	Decimal   rune
+	Group     rune
+	DateOrder DateOrder
+}
+
+// locales suportados; a chave é o nome normalizado (minúsculas)
+var locales = map[string]Locale{
+	"pt-br": {Name: "pt-BR", Decimal: ',', Group: '.', DateOrder: DayMonth},
+	"pt-pt": {Name: "pt-PT", Decimal: ',', Group: ' ', DateOrder: DayMonth},
+	"en-us": {Name: "en-US", Decimal: '.', Group: ',', DateOrder: MonthDay},
+	"en-gb": {Name: "en-GB", Decimal: '.', Group: ',', DateOrder: DayMonth},
+	"es-es": {Name: "es-ES", Decimal: ',', Group: '.', DateOrder: DayMonth},
+	"es-mx": {Name: "es-MX", Decimal: '.', Group: ',', DateOrder: DayMonth},
+	"de-de": {Name: "de-DE", Decimal: ',', Group: '.', DateOrder: DayMonth},
+	"fr-fr": {Name: "fr-FR", Decimal: ',', Group: ' ', DateOrder: DayMonth},
+}
+
+// Lookup retorna o locale pelo nome (ex.: pt-BR, en_US); vazio ou "auto" significa detectar
+func Lookup(name string) (Locale, error) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"))
+	if key == "" || key == "auto" {
+		return Locale{}, nil
+	}
+	if loc, ok := locales[key]; ok {
+		return loc, nil
+	}
+	return Locale{}, fmt.Errorf("locale não suportado: %s (use %s ou auto)", name, strings.Join(Names(), ", "))
+}
+
+// Names lista os locales suportados
+func Names() []string {
+	names := make([]string, 0, len(locales))
+	for _, loc := range locales {
+		names = append(names, loc.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parser converte os valores de um arquivo. Campos zerados são tratados por valor: formatos
+// que não dependem do locale são aceitos e os ambíguos, rejeitados.
+type Parser struct {
+	Decimal   rune
+	Group     rune
+	DateOrder DateOrder
+	// Canonical indica que números sem vírgula vêm no formato canônico (ponto decimal), como as
+	// células numéricas do Excel, do Parquet e do JSON
+	Canonical bool
+}
+
+// NewParser cria o parser para o locale, sem inferência
+func NewParser(loc Locale) Parser {
+	return Parser{Decimal: loc.Decimal, Group: loc.Group, DateOrder: loc.DateOrder}
+}
@@ -0,0 +1,205 @@
+package locale
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustLookup(t *testing.T, name string) Locale {
+	t.Helper()
+	loc, err := Lookup(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestLookup(t *testing.T) {
+	if loc := mustLookup(t, "pt_br"); loc.Name != "pt-BR" || loc.Decimal != ',' || loc.DateOrder != DayMonth {
+		t.Errorf("pt_br = %+v", loc)
+	}
+	if loc := mustLookup(t, "auto"); loc != (Locale{}) {
+		t.Errorf("auto = %+v", loc)
+	}
+	if _, err := Lookup("xx-YY"); err == nil || !strings.Contains(err.Error(), "en-US") {
+		t.Errorf("Lookup(xx-YY) err = %v", err)
+	}
+}
+
+func TestParseNumber_Locales(t *testing.T) {
+	ptBR := NewParser(mustLookup(t, "pt-BR"))
+	enUS := NewParser(mustLookup(t, "en-US"))
+	frFR := NewParser(mustLookup(t, "fr-FR"))
+
+	cases := []struct {
+		parser Parser
+		value  string
+		want   float64
+	}{
+		{ptBR, "1.234,56", 1234.56},
+		{ptBR, "1.234", 1234},
+		{ptBR, "R$ 12,5", 12.5},
+		{ptBR, "-3,75", -3.75},
+		{ptBR, "(10,00)", -10},
+		{enUS, "1,234.56", 1234.56},
+		{enUS, "1,234", 1234},
+		{enUS, "$1.5", 1.5},
+		{enUS, "1e3", 1000},
+		{frFR, "1 234,56 €", 1234.56},
+		{frFR, "1 234,5", 1234.5},
+	}
+	for _, c := range cases {
+		got, err := c.parser.ParseNumber(c.value)
+		if err != nil || got != c.want {
+			t.Errorf("ParseNumber(%q) = %v, %v; want %v", c.value, got, err, c.want)
+		}
+	}
+
+	for _, value := range []string{"1,234.56", "1.2.3,4", "12.34", "abc"} {
+		if _, err := ptBR.ParseNumber(value); err == nil {
+			t.Errorf("pt-BR ParseNumber(%q) deveria falhar", value)
+		}
+	}
+	if _, err := enUS.ParseNumber("12,34"); err == nil {
+		t.Error("en-US ParseNumber(12,34) deveria falhar")
+	}
+}
+
+func TestParseNumber_Auto(t *testing.T) {
+	var auto Parser
+	for value, want := range map[string]float64{
+		"1.234,56":  1234.56,
+		"1,234.56":  1234.56,
+		"12,5":      12.5,
+		"12.5":      12.5,
+		"1.234.567": 1234567,
+		"0,125":     0.125,
+		"1234.567":  1234.567,
+		"42":        42,
+	} {
+		if got, err := auto.ParseNumber(value); err != nil || got != want {
+			t.Errorf("ParseNumber(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"1.234", "1,234", "999.999"} {
+		if _, err := auto.ParseNumber(value); !errors.Is(err, ErrAmbiguousNumber) {
+			t.Errorf("ParseNumber(%q) err = %v, want ErrAmbiguousNumber", value, err)
+		}
+	}
+
+	canonical := Parser{Canonical: true}
+	if got, err := canonical.ParseNumber("1.234"); err != nil || got != 1.234 {
+		t.Errorf("canonical ParseNumber(1.234) = %v, %v", got, err)
+	}
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseDate(t *testing.T) {
+	ptBR := NewParser(mustLookup(t, "pt-BR"))
+	enUS := NewParser(mustLookup(t, "en-US"))
+	var auto Parser
+
+	cases := []struct {
+		parser Parser
+		value  string
+		want   time.Time
+	}{
+		{ptBR, "03/04/2024", date(2024, time.April, 3)},
+		{enUS, "03/04/2024", date(2024, time.March, 4)},
+		{ptBR, "03-04-24", date(2024, time.April, 3)},
+		{ptBR, "3.4.2024", date(2024, time.April, 3)},
+		{enUS, "2024-04-03", date(2024, time.April, 3)},
+		{auto, "25/03/2024", date(2024, time.March, 25)},
+		{auto, "03/25/2024", date(2024, time.March, 25)},
+		{auto, "05/05/2024", date(2024, time.May, 5)},
+		{auto, "20240403", date(2024, time.April, 3)},
+		{auto, "45385", date(2024, time.April, 3)},
+		{auto, "45385.5", date(2024, time.April, 3).Add(12 * time.Hour)},
+		{ptBR, "03/04/2024 14:30", date(2024, time.April, 3).Add(14*time.Hour + 30*time.Minute)},
+	}
+	for _, c := range cases {
+		got, err := c.parser.ParseDate(c.value)
+		if err != nil || !got.Equal(c.want) {
+			t.Errorf("ParseDate(%q) = %v, %v; want %v", c.value, got, err, c.want)
+		}
+	}
+
+	if _, err := auto.ParseDate("03/04/2024"); !errors.Is(err, ErrAmbiguousDate) {
+		t.Errorf("ParseDate(03/04/2024) err = %v, want ErrAmbiguousDate", err)
+	}
+	for _, value := range []string{"31/02/2024", "03/25/2024", "13/13/2024", "03/04-2024", "ontem", "0", ""} {
+		if _, err := ptBR.ParseDate(value); err == nil {
+			t.Errorf("pt-BR ParseDate(%q) deveria falhar", value)
+		}
+	}
+}
+
+func TestInfer(t *testing.T) {
+	p, err := Infer(Locale{}, []string{"1.234", "1.234,56"}, []string{"03/04/2024", "25/04/2024"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Decimal != ',' || p.DateOrder != DayMonth {
+		t.Errorf("parser = %+v", p)
+	}
+	if got, _ := p.ParseNumber("1.234"); got != 1234 {
+		t.Errorf("ParseNumber(1.234) = %v", got)
+	}
+	if got, _ := p.ParseDate("03/04/2024"); got.Month() != time.April {
+		t.Errorf("ParseDate(03/04/2024) = %v", got)
+	}
+
+	p, err = Infer(Locale{}, []string{"12.50"}, []string{"04/03/2024", "12/31/2024"}, false)
+	if err != nil || p.DateOrder != MonthDay || p.Decimal != '.' {
+		t.Errorf("parser = %+v, err = %v", p, err)
+	}
+
+	// O locale informado prevalece sobre a inferência
+	p, err = Infer(mustLookup(t, "en-US"), []string{"1,234"}, []string{"03/04/2024"}, false)
+	if err != nil || p.DateOrder != MonthDay {
+		t.Errorf("parser = %+v, err = %v", p, err)
+	}
+}
+
+func TestInfer_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		numbers []string
+		dates   []string
+		want    string
+	}{
+		{"datas contraditórias", nil, []string{"25/03/2024", "03/25/2024"}, "inconsistente"},
+		{"separadores contraditórios", []string{"1,5", "2.5"}, nil, "inconsistente"},
+		{"data ambígua", nil, []string{"03/04/2024", "05/06/2024"}, "03/04/2024"},
+		{"número ambíguo", []string{"1.234", "2.500"}, nil, "1.234"},
+	}
+	for _, c := range cases {
+		_, err := Infer(Locale{}, c.numbers, c.dates, false)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: err = %v, want %q", c.name, err, c.want)
+		}
+	}
+
+	// Valores canônicos (Excel, Parquet) não são ambíguos nem servem de evidência
+	if _, err := Infer(Locale{}, []string{"1.234", "2,5"}, nil, true); err != nil {
+		t.Errorf("canonical: err = %v", err)
+	}
+}
+
+func TestInferRows(t *testing.T) {
+	rows := [][]string{
+		{"P1", "03/04/2024", "1.234,5"},
+		{"P2", "25/04/2024", ""},
+		{"P3"},
+	}
+	p, err := InferRows(Locale{}, rows, []int{2}, []int{1, -1, 7}, false)
+	if err != nil || p.Decimal != ',' || p.DateOrder != DayMonth {
+		t.Errorf("parser = %+v, err = %v", p, err)
+	}
+}
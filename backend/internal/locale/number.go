@@ -0,0 +1,196 @@
+package locale
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// canonicalNumber é o formato de máquina: ponto decimal, sem agrupamento, notação científica opcional
+var canonicalNumber = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// groupSeparators são sempre separadores de milhar quando aparecem entre dígitos
+const groupSeparators = "\u00a0\u202f '"
+
+// ParseNumber converte o valor respeitando os separadores do parser. Símbolos de moeda e
+// percentuais são ignorados; negativos podem vir com sinal ou entre parênteses.
+func (p Parser) ParseNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if canonicalNumber.MatchString(value) {
+		switch {
+		case p.Canonical, p.Decimal == '.', !strings.Contains(value, "."):
+			return strconv.ParseFloat(value, 64)
+		case p.Decimal == 0 && !ambiguousSingleSeparator(value):
+			return strconv.ParseFloat(value, 64)
+		}
+	}
+
+	negative, digits, err := splitSign(value)
+	if err != nil {
+		return 0, err
+	}
+
+	decimal := p.Decimal
+	group := p.Group
+	if decimal == 0 {
+		var ambiguous bool
+		decimal, group, ambiguous = classifySeparators(digits)
+		if ambiguous {
+			return 0, fmt.Errorf("%w: %q (informe o locale, ex.: pt-BR ou en-US)", ErrAmbiguousNumber, value)
+		}
+	}
+
+	normalized, err := normalizeNumber(digits, decimal, group)
+	if err != nil {
+		return 0, fmt.Errorf("número inválido %q: %w", value, err)
+	}
+
+	n, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, fmt.Errorf("número inválido %q", value)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// splitSign remove moeda, percentual e sinal, retornando apenas dígitos e separadores
+func splitSign(value string) (bool, string, error) {
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+
+	var b strings.Builder
+	seenDigit := false
+	for _, r := range value {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+			seenDigit = true
+		case r == '.' || r == ',' || strings.ContainsRune(groupSeparators, r):
+			if seenDigit {
+				b.WriteRune(r)
+			} else if r == '.' || r == ',' {
+				// ".5" ou ",5"
+				b.WriteRune('0')
+				b.WriteRune(r)
+			}
+		case r == '-':
+			negative = !negative
+		case r == '+' || r == '%' || unicode.IsLetter(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			// moeda (R$, US$, €), percentual e espaços nas bordas
+		default:
+			return false, "", fmt.Errorf("número inválido %q", value)
+		}
+	}
+
+	digits := strings.TrimRight(b.String(), groupSeparators)
+	if digits == "" {
+		return false, "", fmt.Errorf("número inválido %q", value)
+	}
+	return negative, digits, nil
+}
+
+// classifySeparators decide o separador decimal a partir do próprio valor. É ambíguo quando há
+// um único '.' ou ',' seguido de exatamente três dígitos e precedido de 1 a 3 dígitos
+// (ex.: 1.234 pode ser mil duzentos e trinta e quatro ou um vírgula dois três quatro).
+func classifySeparators(digits string) (decimal, group rune, ambiguous bool) {
+	dots := strings.Count(digits, ".")
+	commas := strings.Count(digits, ",")
+
+	switch {
+	case dots > 0 && commas > 0:
+		// O último separador é o decimal
+		if strings.LastIndex(digits, ".") > strings.LastIndex(digits, ",") {
+			return '.', ',', false
+		}
+		return ',', '.', false
+	case dots > 1:
+		return ',', '.', false
+	case commas > 1:
+		return '.', ',', false
+	case dots == 1:
+		if ambiguousSingleSeparator(digits) {
+			return 0, 0, true
+		}
+		return '.', ',', false
+	case commas == 1:
+		if ambiguousSingleSeparator(digits) {
+			return 0, 0, true
+		}
+		return ',', '.', false
+	}
+	return '.', 0, false
+}
+
+// ambiguousSingleSeparator indica se um único separador pode ser tanto decimal quanto de milhar
+func ambiguousSingleSeparator(value string) bool {
+	value = strings.TrimLeft(value, "+-")
+	idx := strings.IndexAny(value, ".,")
+	if idx < 0 || strings.IndexAny(value[idx+1:], ".,") >= 0 {
+		return false
+	}
+	intPart, fracPart := value[:idx], value[idx+1:]
+	return len(fracPart) == 3 && len(intPart) >= 1 && len(intPart) <= 3 && intPart[0] != '0' && isDigits(intPart) && isDigits(fracPart)
+}
+
+// normalizeNumber valida o agrupamento de milhar e converte para o formato canônico
+func normalizeNumber(digits string, decimal, group rune) (string, error) {
+	intPart, fracPart := digits, ""
+	if idx := strings.LastIndex(digits, string(decimal)); idx >= 0 {
+		intPart, fracPart = digits[:idx], digits[idx+1:]
+		if !isDigits(fracPart) {
+			return "", fmt.Errorf("separador decimal %q duplicado ou fora de posição", decimal)
+		}
+	}
+
+	groups := strings.FieldsFunc(intPart, func(r rune) bool {
+		return r == group || strings.ContainsRune(groupSeparators, r)
+	})
+	if len(groups) == 0 {
+		groups = []string{"0"}
+	}
+	if len(groups) > 1 {
+		for i, g := range groups {
+			if !isDigits(g) || (i == 0 && len(g) > 3) || (i > 0 && len(g) != 3) {
+				return "", fmt.Errorf("agrupamento de milhar inválido")
+			}
+		}
+	}
+	joined := strings.Join(groups, "")
+	if !isDigits(joined) {
+		return "", fmt.Errorf("separador %q não corresponde ao locale", otherSeparator(decimal))
+	}
+
+	if fracPart == "" {
+		return joined, nil
+	}
+	return joined + "." + fracPart, nil
+}
+
+func otherSeparator(decimal rune) rune {
+	if decimal == ',' {
+		return '.'
+	}
+	return ','
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
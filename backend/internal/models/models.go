@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -49,6 +50,16 @@ type CustomerBillingReport struct {
 	Count        int     `json:"count"`
 }
 
+// CustomerGroupBillingReport representa o faturamento por grupo de clientes. Clientes sem grupo
+// aparecem sozinhos, com GroupID nulo e o próprio nome.
+type CustomerGroupBillingReport struct {
+	GroupID   *int    `json:"group_id"`
+	GroupName string  `json:"group_name"`
+	Customers int     `json:"customers"`
+	Total     float64 `json:"total"`
+	Count     int     `json:"count"`
+}
+
 // Partner representa um parceiro
 type Partner struct {
 	ID          int       `json:"id" db:"id"`
@@ -67,6 +78,8 @@ type Customer struct {
 	CustomerName       string    `json:"customer_name" db:"customer_name"`
 	CustomerDomainName string    `json:"customer_domain_name" db:"customer_domain_name"`
 	Country            string    `json:"country" db:"country"`
+	GroupID            *int      `json:"group_id" db:"group_id"`
+	GroupSource        string    `json:"group_source,omitempty" db:"group_source"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -97,10 +110,17 @@ type Usage struct {
 	BillingPreTaxTotal   float64        `json:"billing_pre_tax_total" db:"billing_pre_tax_total"`
 	ResourceLocation     string         `json:"resource_location" db:"resource_location"`
 	Tags                 string         `json:"tags" db:"tags"`
+	// Tags interpretadas na importação, com chaves normalizadas (coluna tag_map)
+	TagMap               map[string]string `json:"tag_map,omitempty" db:"tag_map"`
 	BenefitType          string         `json:"benefit_type" db:"benefit_type"`
+	BillingCurrency      string         `json:"billing_currency,omitempty" db:"billing_currency"`
 	PartnerID            int            `json:"partner_id" db:"partner_id"`
 	CustomerID           int            `json:"customer_id" db:"customer_id"`
 	ProductID            int            `json:"product_id" db:"product_id"`
+	// Versões das dimensões vigentes na usage_date (histórico SCD tipo 2)
+	PartnerVersionID     int            `json:"partner_version_id,omitempty" db:"partner_version_id"`
+	CustomerVersionID    int            `json:"customer_version_id,omitempty" db:"customer_version_id"`
+	ProductVersionID     int            `json:"product_version_id,omitempty" db:"product_version_id"`
 	CreatedAt            time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at" db:"updated_at"`
 	
@@ -108,6 +128,12 @@ type Usage struct {
 	PartnerIDStr         string         `json:"-" db:"-"`
 	CustomerIDStr        string         `json:"-" db:"-"`
 	ProductIDStr         string         `json:"-" db:"-"`
+	// Total de controle da fatura informado no arquivo, repetido nas linhas da fatura
+	InvoiceControlTotal  sql.NullFloat64 `json:"-" db:"-"`
+	// Fim do período de cobrança, usado apenas pelas regras de qualidade
+	ChargeEndDate        sql.NullTime   `json:"-" db:"-"`
+	// Linha de origem no arquivo (a partir de 1), usada nos relatórios de qualidade e nos logs
+	SourceRow            int            `json:"-" db:"-"`
 	
 	// Relacionamentos
 	Partner  *Partner  `json:"partner,omitempty"`
@@ -166,4 +192,687 @@ type LoginResponse struct {
     Customers        int   `json:"customers,omitempty"`
     Products         int   `json:"products,omitempty"`
     Usages           int   `json:"usages,omitempty"`
-}
\ No newline at end of file
+}
+// BillingTimeseriesPoint representa um bucket da série temporal de faturamento
+type BillingTimeseriesPoint struct {
+	Period        string    `json:"period"`
+	PeriodStart   time.Time `json:"period_start"`
+	Group         string    `json:"group,omitempty"`
+	GroupKey      string    `json:"group_key,omitempty"` // chave de negócio quando o grupo é partner, customer ou product
+	Total         float64   `json:"total"`
+	Count         int       `json:"count"`
+	PreviousTotal *float64  `json:"previous_total,omitempty"`
+	Delta         *float64  `json:"delta,omitempty"`
+	DeltaPct      *float64  `json:"delta_pct,omitempty"`
+}
+
+// BillingTimeseriesReport representa a série temporal de faturamento com seus parâmetros
+type BillingTimeseriesReport struct {
+	Granularity string                   `json:"granularity"`
+	GroupBy     string                   `json:"group_by,omitempty"`
+	Compare     string                   `json:"compare,omitempty"`
+	From        string                   `json:"from"`
+	To          string                   `json:"to"`
+	Points      []BillingTimeseriesPoint `json:"points"`
+}
+
+// BillingTimeseriesQuery representa os parâmetros da série temporal de faturamento
+type BillingTimeseriesQuery struct {
+	Granularity string
+	GroupBy     string
+	Compare     string
+	From        *time.Time
+	To          *time.Time
+	FillGaps    bool
+	AsOf        *time.Time
+}
+
+// AggregationQuery representa uma consulta de agregação ad-hoc sobre os usos
+type AggregationQuery struct {
+	Dimensions []string            `json:"dimensions"`
+	Measures   []string            `json:"measures"`
+	Filters    map[string][]string `json:"filters,omitempty"`
+	Sort       string              `json:"sort,omitempty"`
+	Limit      int                 `json:"limit,omitempty"`
+	From       *time.Time          `json:"from,omitempty"`
+	To         *time.Time          `json:"to,omitempty"`
+	AsOf       *time.Time          `json:"as_of,omitempty"`
+}
+
+// AggregationRow representa uma linha do resultado de agregação
+type AggregationRow struct {
+	Dimensions map[string]string  `json:"dimensions"`
+	Measures   map[string]float64 `json:"measures"`
+	// Keys traz a chave de negócio das dimensões partner, customer e product, cujo valor em
+	// Dimensions é apenas o nome
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+// AggregationResult representa o resultado de uma consulta de agregação
+type AggregationResult struct {
+	Dimensions []string         `json:"dimensions"`
+	Measures   []string         `json:"measures"`
+	Rows       []AggregationRow `json:"rows"`
+}
+
+// TopNEntry representa uma entidade do ranking Top-N
+type TopNEntry struct {
+	Rank            int     `json:"rank"`
+	Key             string  `json:"key"`
+	ID              string  `json:"id,omitempty"` // chave de negócio quando a dimensão é partner, customer ou product
+	Value           float64 `json:"value"`
+	Share           float64 `json:"share"`
+	CumulativeShare float64 `json:"cumulative_share"`
+	EntityPct       float64 `json:"entity_pct"`
+}
+
+// TopNOther agrupa as entidades fora do Top-N (cauda longa)
+type TopNOther struct {
+	EntityCount int     `json:"entity_count"`
+	Value       float64 `json:"value"`
+	Share       float64 `json:"share"`
+}
+
+// ParetoSummary resume a concentração da métrica entre as entidades
+type ParetoSummary struct {
+	ThresholdPct        float64 `json:"threshold_pct"`
+	EntitiesToThreshold int     `json:"entities_to_threshold"`
+	EntitySharePct      float64 `json:"entity_share_pct"`
+}
+
+// TopNReport representa o ranking Top-N com análise de cauda longa
+type TopNReport struct {
+	Dimension   string        `json:"dimension"`
+	Metric      string        `json:"metric"`
+	N           int           `json:"n"`
+	Total       float64       `json:"total"`
+	EntityCount int           `json:"entity_count"`
+	Items       []TopNEntry   `json:"items"`
+	Other       *TopNOther    `json:"other,omitempty"`
+	Pareto      ParetoSummary `json:"pareto"`
+}
+
+// TopNQuery representa os parâmetros do ranking Top-N
+type TopNQuery struct {
+	Dimension    string
+	Metric       string
+	N            int
+	ThresholdPct float64
+	From         *time.Time
+	To           *time.Time
+	AsOf         *time.Time
+}
+
+// UsageSeriesPoint representa o gasto de um cliente em um produto em um período
+type UsageSeriesPoint struct {
+	CustomerID  int       `json:"customer_id"`
+	ProductID   int       `json:"product_id"`
+	PeriodStart time.Time `json:"period_start"`
+	Total       float64   `json:"total"`
+}
+
+// Anomaly representa um desvio detectado no gasto de um cliente em um produto
+type Anomaly struct {
+	ID             int        `json:"id" db:"id"`
+	CustomerID     int        `json:"customer_id" db:"customer_id"`
+	ProductID      int        `json:"product_id" db:"product_id"`
+	CustomerName   string     `json:"customer_name,omitempty"`
+	ProductName    string     `json:"product_name,omitempty"`
+	Granularity    string     `json:"granularity" db:"granularity"`
+	PeriodStart    time.Time  `json:"period_start" db:"period_start"`
+	Value          float64    `json:"value" db:"value"`
+	Baseline       float64    `json:"baseline" db:"baseline"`
+	Score          float64    `json:"score" db:"score"`
+	Method         string     `json:"method" db:"method"`
+	Severity       string     `json:"severity" db:"severity"`
+	Acknowledged   bool       `json:"acknowledged" db:"acknowledged"`
+	AcknowledgedBy *string    `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AnomalyFilter representa os filtros da listagem de anomalias
+type AnomalyFilter struct {
+	Severity     string
+	Acknowledged *bool
+	CustomerID   int
+	Limit        int
+}
+
+// DailySpend representa o gasto diário de uma entidade (cliente, parceiro ou total)
+type DailySpend struct {
+	EntityID   int       `json:"entity_id"`
+	EntityName string    `json:"entity_name"`
+	Day        time.Time `json:"day"`
+	Total      float64   `json:"total"`
+}
+
+// ForecastPoint representa a previsão de um período com banda de confiança
+type ForecastPoint struct {
+	Period   string  `json:"period"`
+	Forecast float64 `json:"forecast"`
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+}
+
+// MonthEndProjection representa a projeção de fechamento do mês de referência
+type MonthEndProjection struct {
+	Month        string  `json:"month"`
+	ActualToDate float64 `json:"actual_to_date"`
+	DaysElapsed  int     `json:"days_elapsed"`
+	DaysInMonth  int     `json:"days_in_month"`
+	Projected    float64 `json:"projected"`
+	Lower        float64 `json:"lower"`
+	Upper        float64 `json:"upper"`
+}
+
+// QuarterForecast representa a previsão do próximo trimestre
+type QuarterForecast struct {
+	Quarter  string          `json:"quarter"`
+	Forecast float64         `json:"forecast"`
+	Lower    float64         `json:"lower"`
+	Upper    float64         `json:"upper"`
+	Months   []ForecastPoint `json:"months"`
+}
+
+// SpendForecast representa a previsão de gasto de uma entidade
+type SpendForecast struct {
+	EntityID      int                `json:"entity_id,omitempty"`
+	EntityName    string             `json:"entity_name"`
+	Model         string             `json:"model"`
+	Alpha         float64            `json:"alpha"`
+	Beta          float64            `json:"beta"`
+	HistoryMonths int                `json:"history_months"`
+	MonthEnd      MonthEndProjection `json:"month_end"`
+	NextQuarter   QuarterForecast    `json:"next_quarter"`
+}
+
+// ForecastReport representa as previsões de gasto de um escopo
+type ForecastReport struct {
+	Scope     string          `json:"scope"`
+	AsOf      string          `json:"as_of"`
+	Forecasts []SpendForecast `json:"forecasts"`
+}
+
+// Budget representa um orçamento mensal para um cliente, parceiro ou categoria
+type Budget struct {
+	ID         int       `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Scope      string    `json:"scope" db:"scope"`
+	ScopeRef   string    `json:"scope_ref" db:"scope_ref"`
+	Amount     float64   `json:"amount" db:"amount"`
+	Thresholds []int     `json:"thresholds" db:"thresholds"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BudgetAlert representa o cruzamento de um limiar de orçamento em um mês
+type BudgetAlert struct {
+	ID         int        `json:"id" db:"id"`
+	BudgetID   int        `json:"budget_id" db:"budget_id"`
+	BudgetName string     `json:"budget_name,omitempty"`
+	Scope      string     `json:"scope,omitempty"`
+	ScopeRef   string     `json:"scope_ref,omitempty"`
+	Month      time.Time  `json:"month" db:"month"`
+	Threshold  int        `json:"threshold" db:"threshold"`
+	Spend      float64    `json:"spend" db:"spend"`
+	Amount     float64    `json:"amount" db:"amount"`
+	State      string     `json:"state" db:"state"`
+	Attempts   int        `json:"attempts" db:"attempts"`
+	LastError  *string    `json:"last_error,omitempty" db:"last_error"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty" db:"notified_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// WebhookSubscription representa um destino externo inscrito em eventos da API
+type WebhookSubscription struct {
+	ID        int       `json:"id" db:"id"`
+	URL       string    `json:"url" db:"url"`
+	Events    []string  `json:"events" db:"events"`
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookDelivery representa uma entrega de evento para uma assinatura
+type WebhookDelivery struct {
+	ID             int             `json:"id" db:"id"`
+	SubscriptionID int             `json:"subscription_id" db:"subscription_id"`
+	Event          string          `json:"event" db:"event"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	State          string          `json:"state" db:"state"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty" db:"response_status"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	URL            string          `json:"-"`
+	Secret         string          `json:"-"`
+}
+
+// ImportSummary resume o resultado de uma importação para os eventos de webhook
+// Em import.completed as contagens são as gravadas; em import.failed, as lidas do arquivo.
+type ImportSummary struct {
+	FileName     string `json:"file_name"`
+	Partners     int    `json:"partners"`
+	Customers    int    `json:"customers"`
+	Products     int    `json:"products"`
+	Usages       int    `json:"usages"`
+	ParsedUsages int    `json:"parsed_usages"`
+	// QualityBatchID é o lote de qualidade da importação, com as violações por linha
+	QualityBatchID *int   `json:"quality_batch_id,omitempty"`
+	Rejected       int    `json:"rejected"`
+	Warned         int    `json:"warned"`
+	Replaced       bool   `json:"replaced"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ImportResult contabiliza o que uma importação efetivamente gravou
+type ImportResult struct {
+	Partners       int  `json:"partners"`
+	Customers      int  `json:"customers"`
+	Products       int  `json:"products"`
+	Usages         int  `json:"usages"`
+	QualityBatchID *int `json:"quality_batch_id,omitempty"`
+	// Rejected e Warned contam as linhas descartadas ou apenas sinalizadas pelas regras de qualidade
+	Rejected int `json:"rejected"`
+	Warned   int `json:"warned"`
+}
+
+// ImportedFile representa um arquivo processado por uma fonte de importação agendada
+type ImportedFile struct {
+	ID        int       `json:"id" db:"id"`
+	Source    string    `json:"source" db:"source"`
+	FileName  string    `json:"file_name" db:"file_name"`
+	Checksum  string    `json:"checksum" db:"checksum"`
+	Status    string    `json:"status" db:"status"`
+	Usages    int       `json:"usages" db:"usages"`
+	Error     *string   `json:"error,omitempty" db:"error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Situações da conciliação de uma fatura
+const (
+	InvoiceUnreconciled = "unreconciled"
+	InvoiceMatched      = "matched"
+	InvoiceDiscrepancy  = "discrepancy"
+)
+
+// Invoice representa uma fatura derivada das usages importadas (número × parceiro × cliente)
+type Invoice struct {
+	ID                   int        `json:"id" db:"id"`
+	InvoiceNumber        string     `json:"invoice_number" db:"invoice_number"`
+	PartnerID            int        `json:"partner_id" db:"partner_id"`
+	CustomerID           int        `json:"customer_id" db:"customer_id"`
+	PartnerName          string     `json:"partner_name,omitempty"`
+	CustomerName         string     `json:"customer_name,omitempty"`
+	PeriodStart          time.Time  `json:"period_start" db:"period_start"`
+	PeriodEnd            time.Time  `json:"period_end" db:"period_end"`
+	Currency             *string    `json:"currency,omitempty" db:"currency"`
+	PreTaxTotal          float64    `json:"pre_tax_total" db:"pre_tax_total"`
+	LineCount            int        `json:"line_count" db:"line_count"`
+	ControlTotal         *float64   `json:"control_total,omitempty" db:"control_total"`
+	ControlSource        *string    `json:"control_source,omitempty" db:"control_source"`
+	ReconciliationStatus string     `json:"reconciliation_status" db:"reconciliation_status"`
+	Difference           *float64   `json:"difference,omitempty" db:"difference"`
+	ReconciledAt         *time.Time `json:"reconciled_at,omitempty" db:"reconciled_at"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// InvoiceControl é o total de controle de uma fatura informado no arquivo importado
+type InvoiceControl struct {
+	InvoiceNumber string
+	PartnerID     int
+	CustomerID    int
+	Total         float64
+}
+
+// InvoiceFilter representa os filtros da listagem de faturas
+type InvoiceFilter struct {
+	PartnerID  int
+	CustomerID int
+	Status     string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+// InvoiceDetail é a fatura com suas linhas de uso
+type InvoiceDetail struct {
+	Invoice
+	Lines []Usage `json:"lines"`
+}
+
+// InvoiceReconciliation é o resultado da comparação da soma das linhas com o total de controle
+type InvoiceReconciliation struct {
+	InvoiceID     int      `json:"invoice_id"`
+	InvoiceNumber string   `json:"invoice_number"`
+	LineSum       float64  `json:"line_sum"`
+	LineCount     int      `json:"line_count"`
+	ControlTotal  float64  `json:"control_total"`
+	ControlSource string   `json:"control_source"`
+	Difference    float64  `json:"difference"`
+	Tolerance     float64  `json:"tolerance"`
+	Currencies    []string `json:"currencies"`
+	Status        string   `json:"status"`
+	Issues        []string `json:"issues,omitempty"`
+}
+
+// Severidades das regras de qualidade: reject descarta a linha, warn apenas registra
+const (
+	QualityReject = "reject"
+	QualityWarn   = "warn"
+)
+
+// QualityRule é uma regra de qualidade configurável avaliada em cada lote importado.
+// Params depende de RuleType (ex.: {"tolerance": 0.01} para amount_consistency).
+type QualityRule struct {
+	ID        int             `json:"id" db:"id"`
+	Name      string          `json:"name" db:"name"`
+	RuleType  string          `json:"rule_type" db:"rule_type"`
+	Params    json.RawMessage `json:"params" db:"params"`
+	Severity  string          `json:"severity" db:"severity"`
+	Active    bool            `json:"active" db:"active"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// QualityBatch resume a avaliação das regras em um lote importado
+type QualityBatch struct {
+	ID           int             `json:"id" db:"id"`
+	FileName     string          `json:"file_name,omitempty" db:"file_name"`
+	Checksum     string          `json:"checksum,omitempty" db:"checksum"`
+	RowsChecked  int             `json:"rows_checked" db:"rows_checked"`
+	RejectedRows int             `json:"rejected_rows" db:"rejected_rows"`
+	WarnedRows   int             `json:"warned_rows" db:"warned_rows"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	Results      []QualityResult `json:"results,omitempty" db:"-"`
+}
+
+// QualityResult é uma violação de regra em uma linha do lote
+type QualityResult struct {
+	ID            int       `json:"id" db:"id"`
+	BatchID       int       `json:"batch_id" db:"batch_id"`
+	RuleID        *int      `json:"rule_id" db:"rule_id"`
+	RuleName      string    `json:"rule_name" db:"rule_name"`
+	Severity      string    `json:"severity" db:"severity"`
+	RowNumber     int       `json:"row_number" db:"row_number"`
+	InvoiceNumber string    `json:"invoice_number,omitempty" db:"invoice_number"`
+	CustomerID    string    `json:"customer_id,omitempty" db:"customer_id"`
+	ProductID     string    `json:"product_id,omitempty" db:"product_id"`
+	Message       string    `json:"message" db:"message"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// DimensionVersion é uma versão (SCD tipo 2) de um parceiro, cliente ou produto. ValidTo é
+// exclusivo e nulo na versão corrente.
+type DimensionVersion struct {
+	ID         int               `json:"id"`
+	Dimension  string            `json:"dimension"`
+	EntityID   int               `json:"entity_id"`
+	Key        string            `json:"key"`
+	Attributes map[string]string `json:"attributes"`
+	ValidFrom  time.Time         `json:"valid_from"`
+	ValidTo    *time.Time        `json:"valid_to"`
+	IsCurrent  bool              `json:"is_current"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// Origem da associação de um cliente ao grupo
+const (
+	GroupSourceManual  = "manual"
+	GroupSourcePattern = "pattern"
+)
+
+// CustomerGroup agrupa os clientes (tenants) de uma mesma organização. DomainPatterns são
+// padrões glob (ex.: *.contoso.com) comparados com customer_domain_name.
+type CustomerGroup struct {
+	ID             int       `json:"id" db:"id"`
+	Name           string    `json:"name" db:"name"`
+	DomainPatterns []string  `json:"domain_patterns" db:"domain_patterns"`
+	CustomerCount  int       `json:"customer_count" db:"customer_count"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CustomerGroupDetail é um grupo com os clientes associados
+type CustomerGroupDetail struct {
+	CustomerGroup
+	Customers []Customer `json:"customers"`
+}
+
+// AllocationSplit é a fatia de uma regra de rateio destinada a um valor da tag (centro de custo)
+type AllocationSplit struct {
+	Value   string  `json:"value"`
+	Percent float64 `json:"percent"`
+}
+
+// AllocationRule divide o custo das usages cujo valor da tag TagKey é SourceValue entre os
+// valores de Splits. SourceValue vazio seleciona as usages sem a tag.
+type AllocationRule struct {
+	ID          int               `json:"id" db:"id"`
+	Name        string            `json:"name" db:"name"`
+	TagKey      string            `json:"tag_key" db:"tag_key"`
+	SourceValue string            `json:"source_value" db:"source_value"`
+	Splits      []AllocationSplit `json:"splits" db:"splits"`
+	Active      bool              `json:"active" db:"active"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ChargebackItem é o custo de um valor da tag: direto (usages com o valor) e recebido por rateio
+type ChargebackItem struct {
+	Value     string  `json:"value"`
+	Direct    float64 `json:"direct"`
+	Allocated float64 `json:"allocated"`
+	Total     float64 `json:"total"`
+}
+
+// ChargebackReport é o custo por valor de uma tag após as regras de rateio. Unallocated é o
+// custo sem a tag que nenhuma regra distribuiu.
+type ChargebackReport struct {
+	TagKey      string           `json:"tag_key"`
+	From        *time.Time       `json:"from,omitempty"`
+	To          *time.Time       `json:"to,omitempty"`
+	Total       float64          `json:"total"`
+	Unallocated float64          `json:"unallocated"`
+	Items       []ChargebackItem `json:"items"`
+}
+
+// ChargebackQuery representa os parâmetros do relatório de chargeback
+type ChargebackQuery struct {
+	TagKey string
+	From   *time.Time
+	To     *time.Time
+	AsOf   *time.Time
+}
+
+// Tipos de sujeito de um demonstrativo
+const (
+	StatementCustomer   = "customer"
+	StatementCostCenter = "cost_center"
+)
+
+// StatementSubject identifica de quem é o demonstrativo: um cliente ou um centro de custo
+// (valor de uma tag). O cliente é identificado pelo customer_id de negócio (CustomerKey), que
+// sobrevive às reimportações; CustomerID é o id interno no momento da geração.
+type StatementSubject struct {
+	Type        string `json:"type"`
+	CustomerID  int    `json:"customer_id,omitempty"`
+	CustomerKey string `json:"customer_key,omitempty"`
+	TagKey      string `json:"tag_key,omitempty"`
+	TagValue    string `json:"tag_value,omitempty"`
+	Name        string `json:"name"`
+}
+
+// StatementGroup é o total de um produto ou categoria no período e no período anterior
+type StatementGroup struct {
+	Name          string  `json:"name"`
+	Total         float64 `json:"total"`
+	PreviousTotal float64 `json:"previous_total"`
+	Delta         float64 `json:"delta"`
+}
+
+// StatementLine é um uso detalhado no demonstrativo
+type StatementLine struct {
+	UsageDate        time.Time `json:"usage_date"`
+	InvoiceNumber    string    `json:"invoice_number"`
+	Product          string    `json:"product"`
+	Category         string    `json:"category"`
+	ResourceLocation string    `json:"resource_location"`
+	Quantity         float64   `json:"quantity"`
+	UnitPrice        float64   `json:"unit_price"`
+	Total            float64   `json:"total"`
+}
+
+// StatementData é o conteúdo de um demonstrativo. Allocated é o custo recebido por rateio,
+// apenas para centros de custo; não aparece nas linhas.
+type StatementData struct {
+	Subject       StatementSubject `json:"subject"`
+	Period        string           `json:"period"`
+	PeriodStart   time.Time        `json:"period_start"`
+	PeriodEnd     time.Time        `json:"period_end"`
+	Direct        float64          `json:"direct"`
+	Allocated     float64          `json:"allocated"`
+	Total         float64          `json:"total"`
+	PreviousTotal float64          `json:"previous_total"`
+	Delta         float64          `json:"delta"`
+	DeltaPct      *float64         `json:"delta_pct,omitempty"`
+	Products      []StatementGroup `json:"products"`
+	Categories    []StatementGroup `json:"categories"`
+	Lines         []StatementLine  `json:"lines"`
+}
+
+// Statement é uma versão gravada de um demonstrativo mensal. Cada geração com conteúdo
+// diferente da última versão cria uma nova versão; as anteriores são mantidas.
+type Statement struct {
+	ID         int              `json:"id" db:"id"`
+	SubjectKey string           `json:"subject_key" db:"subject_key"`
+	Subject    StatementSubject `json:"subject" db:"-"`
+	Period     string           `json:"period" db:"period"`
+	Version    int              `json:"version" db:"version"`
+	Checksum   string           `json:"checksum" db:"checksum"`
+	Total      float64          `json:"total" db:"total"`
+	Data       *StatementData   `json:"data,omitempty" db:"data"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`
+}
+
+// StatementQuery filtra a listagem de demonstrativos
+type StatementQuery struct {
+	CustomerID  int
+	CustomerKey string
+	TagKey      string
+	TagValue    string
+	Period      string
+}
+
+// Tipos de regra de preço
+const (
+	PriceRuleMarkup   = "markup"
+	PriceRuleDiscount = "discount"
+)
+
+// PriceTier é uma faixa de quantidade mensal (cliente e produto) com o percentual aplicado a
+// partir de MinQuantity
+type PriceTier struct {
+	MinQuantity float64 `json:"min_quantity"`
+	Percent     float64 `json:"percent"`
+}
+
+// PriceRule é uma regra de preço de revenda: markup sobre o custo ou desconto sobre o preço
+// com markup. CustomerID, ProductID e Category delimitam o escopo (nulos valem para todos);
+// cliente e produto são as chaves de negócio, que sobrevivem às reimportações.
+// Percent e Tiers são exclusivos. EffectiveTo é exclusivo; nulo vale sem data de término.
+type PriceRule struct {
+	ID            int         `json:"id" db:"id"`
+	Name          string      `json:"name" db:"name"`
+	RuleType      string      `json:"rule_type" db:"rule_type"`
+	CustomerID    *string     `json:"customer_id" db:"customer_id"`
+	ProductID     *string     `json:"product_id" db:"product_id"`
+	Category      *string     `json:"category" db:"category"`
+	Percent       *float64    `json:"percent,omitempty" db:"percent"`
+	Tiers         []PriceTier `json:"tiers,omitempty" db:"tiers"`
+	EffectiveFrom time.Time   `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time  `json:"effective_to" db:"effective_to"`
+	Active        bool        `json:"active" db:"active"`
+	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// PricingUsage é o custo e a quantidade de um cliente e produto em um dia, base do cálculo de
+// preço de revenda. CustomerKey e ProductKey são as chaves de negócio usadas pelas regras.
+type PricingUsage struct {
+	CustomerID  int       `json:"customer_id"`
+	CustomerKey string    `json:"customer_key"`
+	Customer    string    `json:"customer"`
+	ProductID   int       `json:"product_id"`
+	ProductKey  string    `json:"product_key"`
+	Product     string    `json:"product"`
+	Category    string    `json:"category"`
+	UsageDate   time.Time `json:"usage_date"`
+	Quantity    float64   `json:"quantity"`
+	Cost        float64   `json:"cost"`
+}
+
+// PricingQuantity é a quantidade de um cliente e produto em um mês de calendário inteiro, base das
+// faixas de preço
+type PricingQuantity struct {
+	CustomerID int
+	ProductID  int
+	Month      time.Time
+	Quantity   float64
+}
+
+// MarginRow é o custo, a receita de revenda e a margem de um cliente e/ou produto
+type MarginRow struct {
+	CustomerID int      `json:"customer_id,omitempty"`
+	Customer   string   `json:"customer,omitempty"`
+	ProductID  int      `json:"product_id,omitempty"`
+	Product    string   `json:"product,omitempty"`
+	Quantity   float64  `json:"quantity"`
+	Cost       float64  `json:"cost"`
+	Revenue    float64  `json:"revenue"`
+	Margin     float64  `json:"margin"`
+	MarginPct  *float64 `json:"margin_pct,omitempty"`
+}
+
+// MarginReport é o relatório de receita, custo e margem. UnpricedCost é o custo dos usos sem
+// regra de markup vigente, revendidos a preço de custo.
+type MarginReport struct {
+	GroupBy      string      `json:"group_by"`
+	From         *time.Time  `json:"from,omitempty"`
+	To           *time.Time  `json:"to,omitempty"`
+	Totals       MarginRow   `json:"totals"`
+	UnpricedCost float64     `json:"unpriced_cost"`
+	Rows         []MarginRow `json:"rows"`
+}
+
+// MarginQuery representa os parâmetros do relatório de margem
+type MarginQuery struct {
+	GroupBy string
+	From    *time.Time
+	To      *time.Time
+}
+
+// SearchResult é um resultado da busca unificada. Highlights traz, por campo que contém os
+// termos buscados, o valor com HTML escapado e os trechos encontrados entre <mark> e </mark>.
+type SearchResult struct {
+	Type       string            `json:"type"`
+	ID         int               `json:"id"`
+	Title      string            `json:"title"`
+	Subtitle   string            `json:"subtitle"`
+	Score      float64           `json:"score"`
+	Fields     map[string]string `json:"-"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchResponse é a resposta da busca unificada
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Event representa uma notificação enviada para fora da API
+type Event struct {
+	Type       string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Notifier entrega eventos para um destino externo
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// LogNotifier apenas registra os eventos no log; é o padrão quando nenhum destino é configurado
+type LogNotifier struct{}
+
+// Notify registra o evento no log
+func (LogNotifier) Notify(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+	log.Printf("🔔 Notificação %s: %s", event.Type, payload)
+	return nil
+}
+
+// Cabeçalhos da assinatura, enviados quando há segredo configurado: SignatureHeader traz o
+// HMAC-SHA256 de "<timestamp>.<corpo>" e TimestampHeader o instante do envio em segundos Unix
+const (
+	SignatureHeader = "X-Signature-256"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// SignatureTolerance é a diferença máxima recomendada entre o timestamp assinado e o relógio de
+// quem recebe; entregas fora dessa janela devem ser recusadas para impedir a repetição
+const SignatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature indica assinatura ausente, divergente ou com timestamp fora da tolerância
+var ErrInvalidSignature = errors.New("assinatura de webhook inválida")
+
+// Sign calcula a assinatura no formato "sha256=<hex>" do timestamp e do corpo com o segredo informado
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify confere a assinatura e o timestamp recebidos nos cabeçalhos, com a tolerância informada
+func Verify(secret, timestamp, signature string, payload []byte, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp fora da tolerância de %s", ErrInvalidSignature, tolerance)
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, ts, payload))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// WebhookNotifier entrega eventos como POST JSON para uma URL
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// NewWebhookNotifier cria um notificador de webhook com timeout padrão
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify envia o evento e considera sucesso qualquer resposta 2xx
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+
+	_, err = n.Send(ctx, event.Type, payload, nil)
+	return err
+}
+
+// Send envia um corpo já serializado, assinando-o com o timestamp quando há segredo, e retorna o status HTTP.
+// O status é zero quando a requisição não chegou a obter resposta.
+func (n *WebhookNotifier) Send(ctx context.Context, eventType string, payload []byte, header http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar requisição de webhook: %w", err)
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", eventType)
+	if n.Secret != "" {
+		// Cada tentativa é assinada com o próprio timestamp, para que reenvios fiquem dentro da tolerância
+		timestamp := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(n.Secret, timestamp, payload))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao enviar webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook respondeu com status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifierDeliversEvent(t *testing.T) {
+	var received Event
+	var contentType, eventType string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		eventType = r.Header.Get("X-Event-Type")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sink.Close()
+
+	n := NewWebhookNotifier(sink.URL)
+	err := n.Notify(context.Background(), Event{
+		Type:       "budget.threshold_crossed",
+		OccurredAt: time.Now(),
+		Data:       map[string]int{"threshold": 80},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if received.Type != "budget.threshold_crossed" || eventType != "budget.threshold_crossed" {
+		t.Errorf("unexpected event: %+v (header %q)", received, eventType)
+	}
+	if contentType != "application/json" {
+		t.Errorf("unexpected content type: %q", contentType)
+	}
+}
+
+func TestWebhookNotifierFailsOnErrorStatus(t *testing.T) {
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer sink.Close()
+
+	if err := NewWebhookNotifier(sink.URL).Notify(context.Background(), Event{Type: "test"}); err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+}
+
+func TestWebhookNotifierSignsPayload(t *testing.T) {
+	var body []byte
+	var signature, timestamp string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+		timestamp = r.Header.Get(TimestampHeader)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer sink.Close()
+
+	n := NewWebhookNotifier(sink.URL)
+	n.Secret = "segredo"
+	status, err := n.Send(context.Background(), "import.completed", []byte(`{"event":"import.completed"}`), nil)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("unexpected result: status %d, err %v", status, err)
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+		t.Fatalf("unexpected timestamp header %q", timestamp)
+	}
+	if signature == "" || signature != Sign("segredo", ts, body) {
+		t.Errorf("signature %q does not match timestamp %s and body %s", signature, timestamp, body)
+	}
+	if Sign("outro", ts, body) == signature || Sign("segredo", ts+1, body) == signature {
+		t.Error("signature should depend on the secret and the timestamp")
+	}
+	if err := Verify("segredo", timestamp, signature, body, SignatureTolerance, time.Now()); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+}
+
+func TestVerifyRejectsReplayAndTampering(t *testing.T) {
+	body := []byte(`{"event":"import.completed"}`)
+	sentAt := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	signature := Sign("segredo", sentAt.Unix(), body)
+
+	cases := map[string]struct {
+		timestamp, signature string
+		body                 []byte
+		now                  time.Time
+	}{
+		"replayed after tolerance": {timestamp, signature, body, sentAt.Add(SignatureTolerance + time.Second)},
+		"timestamp in the future":  {timestamp, signature, body, sentAt.Add(-SignatureTolerance - time.Second)},
+		"timestamp swapped":        {strconv.FormatInt(sentAt.Unix()+60, 10), signature, body, sentAt},
+		"body changed":             {timestamp, signature, []byte(`{"event":"import.failed"}`), sentAt},
+		"missing timestamp":        {"", signature, body, sentAt},
+	}
+	for name, c := range cases {
+		if err := Verify("segredo", c.timestamp, c.signature, c.body, SignatureTolerance, c.now); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+
+	if err := Verify("segredo", timestamp, signature, body, SignatureTolerance, sentAt.Add(time.Minute)); err != nil {
+		t.Errorf("expected signature within tolerance to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/tags"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// usageBaseFrom é a junção padrão usada pelas consultas de agregação sobre usos
+const usageBaseFrom = `
+		FROM usages u
+		LEFT JOIN ` + partnerJoin + `
+		LEFT JOIN ` + customerJoin + `
+		LEFT JOIN ` + customerGroupJoin + `
+		LEFT JOIN ` + productJoin
+
+// Junções de cada dimensão com os atributos atuais (tabelas base)
+const (
+	partnerJoin  = `partners p ON u.partner_id = p.id`
+	customerJoin = `customers c ON u.customer_id = c.id`
+	productJoin  = `products pr ON u.product_id = pr.id`
+)
+
+// customerGroupJoin liga o cliente (c) ao seu grupo. O grupo não é versionado: mesmo com
+// asOf vale a associação atual.
+const customerGroupJoin = `customer_groups cg ON c.group_id = cg.id`
+
+// Junções com as versões gravadas na importação. Expõem as mesmas colunas das tabelas base,
+// com id sendo o id da entidade, para que as expressões das consultas sirvam aos dois casos.
+const (
+	partnerVersionJoin = `(SELECT id AS version_id, partner_ref AS id, partner_id, partner_name, mpn_id, tier2_mpn_id
+			FROM partner_versions) p ON u.partner_version_id = p.version_id`
+	customerVersionJoin = `(SELECT v.id AS version_id, v.customer_ref AS id, v.customer_id, v.customer_name,
+			v.customer_domain_name, v.country, cu.group_id
+			FROM customer_versions v JOIN customers cu ON cu.id = v.customer_ref) c ON u.customer_version_id = c.version_id`
+	productVersionJoin = `(SELECT id AS version_id, product_ref AS id, product_id, sku_id, sku_name, product_name, meter_type,
+			category, sub_category, unit_type FROM product_versions) pr ON u.product_version_id = pr.version_id`
+)
+
+// asOfJoins escolhe as junções de parceiro, cliente e produto: atributos atuais sem asOf, ou
+// os da versão vigente na usage_date, como foram reportados
+func asOfJoins(asOf *time.Time) (partner, customer, product string) {
+	if asOf == nil {
+		return partnerJoin, customerJoin, productJoin
+	}
+	return partnerVersionJoin, customerVersionJoin, productVersionJoin
+}
+
+// usageFromAsOf é usageBaseFrom com as junções de asOfJoins
+func usageFromAsOf(asOf *time.Time) string {
+	partner, customer, product := asOfJoins(asOf)
+	return `
+		FROM usages u
+		LEFT JOIN ` + partner + `
+		LEFT JOIN ` + customer + `
+		LEFT JOIN ` + customerGroupJoin + `
+		LEFT JOIN ` + product
+}
+
+// whereClause monta a cláusula WHERE a partir das condições, vazia se não houver nenhuma
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(where, " AND ")
+}
+
+// addAsOfFilter restringe a consulta aos usos já importados no instante asOf
+func addAsOfFilter(asOf *time.Time, args *[]interface{}, where *[]string) {
+	if asOf == nil {
+		return
+	}
+	*args = append(*args, *asOf)
+	*where = append(*where, fmt.Sprintf("u.created_at <= $%d", len(*args)))
+}
+
+// dimensionRegistry mapeia as dimensões permitidas para expressões SQL seguras.
+// Apenas nomes presentes aqui podem chegar a uma consulta gerada dinamicamente.
+var dimensionRegistry = map[string]string{
+	"partner":           "COALESCE(p.partner_name, '')",
+	"customer":          "COALESCE(c.customer_name, '')",
+	"product":           "COALESCE(pr.product_name, '')",
+	"category":          "COALESCE(pr.category, '')",
+	"sub_category":      "COALESCE(pr.sub_category, '')",
+	"meter_type":        "COALESCE(pr.meter_type, '')",
+	"resource_location": "COALESCE(u.resource_location, '')",
+	"benefit_type":      "COALESCE(u.benefit_type, '')",
+	"month":             "TO_CHAR(u.usage_date, 'YYYY-MM')",
+	"country":           "COALESCE(c.country, '')",
+	// clientes sem grupo ficam em linhas próprias, identificadas pelo customer_id, para não se
+	// somarem a um grupo ou a outro cliente de mesmo nome
+	"customer_group": "COALESCE(cg.name, 'sem grupo: ' || COALESCE(c.customer_name, '') || ' (' || c.customer_id || ')')",
+}
+
+// dimensionKeys mapeia as dimensões de entidade para a chave de negócio que as identifica. O nome
+// da dimensão é só o rótulo: entidades diferentes com o mesmo nome, ou sem nome, não se somam.
+var dimensionKeys = map[string]string{
+	"partner":  "COALESCE(p.partner_id, '')",
+	"customer": "COALESCE(c.customer_id, '')",
+	"product":  "COALESCE(pr.product_id, '')",
+}
+
+// DimensionHasKey informa se a dimensão é agrupada por uma chave além do rótulo
+func DimensionHasKey(name string) bool {
+	_, ok := dimensionKeys[name]
+	return ok
+}
+
+// measureRegistry mapeia as medidas permitidas para expressões SQL agregadas
+var measureRegistry = map[string]string{
+	"sum_billing":    "COALESCE(SUM(u.billing_pre_tax_total), 0)::float8",
+	"sum_quantity":   "COALESCE(SUM(u.quantity), 0)::float8",
+	"count":          "COUNT(*)::float8",
+	"avg_unit_price": "COALESCE(AVG(u.unit_price), 0)::float8",
+}
+
+// tagDimensionPrefix identifica as dimensões de tag (tag:<chave>), que não estão no registro:
+// a chave vai como parâmetro da consulta, nunca no texto do SQL
+const tagDimensionPrefix = "tag:"
+
+// TagKey retorna a chave normalizada de uma dimensão de tag, ou vazio se a dimensão não for de tag
+func TagKey(name string) string {
+	if !strings.HasPrefix(name, tagDimensionPrefix) {
+		return ""
+	}
+	return tags.NormalizeKey(strings.TrimPrefix(name, tagDimensionPrefix))
+}
+
+// dimensionExpr retorna a expressão SQL da dimensão; a chave das dimensões de tag é
+// acrescentada a args
+func dimensionExpr(name string, args *[]interface{}) (string, bool) {
+	if key := TagKey(name); key != "" {
+		*args = append(*args, key)
+		return fmt.Sprintf("COALESCE(u.tag_map->>$%d, '')", len(*args)), true
+	}
+	expr, ok := dimensionRegistry[name]
+	return expr, ok
+}
+
+// tagFilterExpr retorna o filtro de uma dimensão de tag pelos valores informados. Cada valor é
+// comparado com @>, que usa o índice GIN de tag_map (->> não usa); o valor vazio seleciona os
+// usos sem a tag, como na dimensão.
+func tagFilterExpr(key string, values []string, args *[]interface{}) string {
+	*args = append(*args, key)
+	keyArg := len(*args)
+
+	terms := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			terms = append(terms, fmt.Sprintf("COALESCE(u.tag_map->>$%d, '') = ''", keyArg))
+			continue
+		}
+		*args = append(*args, value)
+		terms = append(terms, fmt.Sprintf("u.tag_map @> jsonb_build_object($%d::text, $%d::text)", keyArg, len(*args)))
+	}
+	if len(terms) == 0 {
+		return "false"
+	}
+	return "(" + strings.Join(terms, " OR ") + ")"
+}
+
+// IsDimension informa se a dimensão está registrada ou é uma dimensão de tag
+func IsDimension(name string) bool {
+	if TagKey(name) != "" {
+		return true
+	}
+	_, ok := dimensionRegistry[name]
+	return ok
+}
+
+// IsMeasure informa se a medida está registrada
+func IsMeasure(name string) bool {
+	_, ok := measureRegistry[name]
+	return ok
+}
+
+// buildAggregationQuery gera o SQL parametrizado de uma consulta de agregação já validada
+func buildAggregationQuery(q models.AggregationQuery) (string, []interface{}, error) {
+	var selects, groupBy, where []string
+	var args []interface{}
+
+	for i, dim := range q.Dimensions {
+		expr, ok := dimensionExpr(dim, &args)
+		if !ok {
+			return "", nil, fmt.Errorf("dimensão inválida: %s", dim)
+		}
+		selects = append(selects, expr)
+		groupBy = append(groupBy, fmt.Sprintf("%d", i+1))
+	}
+	for _, m := range q.Measures {
+		expr, ok := measureRegistry[m]
+		if !ok {
+			return "", nil, fmt.Errorf("medida inválida: %s", m)
+		}
+		selects = append(selects, expr)
+	}
+	if len(selects) == 0 {
+		return "", nil, fmt.Errorf("nenhuma dimensão ou medida informada")
+	}
+	// As chaves vêm depois das medidas, para não deslocar as posições usadas na ordenação
+	for _, dim := range q.Dimensions {
+		if key, ok := dimensionKeys[dim]; ok {
+			selects = append(selects, key)
+			groupBy = append(groupBy, fmt.Sprintf("%d", len(selects)))
+		}
+	}
+
+	addAsOfFilter(q.AsOf, &args, &where)
+	if q.From != nil {
+		args = append(args, *q.From)
+		where = append(where, fmt.Sprintf("u.usage_date >= $%d", len(args)))
+	}
+	if q.To != nil {
+		args = append(args, *q.To)
+		where = append(where, fmt.Sprintf("u.usage_date <= $%d", len(args)))
+	}
+
+	// Ordenar as chaves para gerar sempre o mesmo SQL para a mesma consulta
+	filterKeys := make([]string, 0, len(q.Filters))
+	for dim := range q.Filters {
+		filterKeys = append(filterKeys, dim)
+	}
+	sort.Strings(filterKeys)
+	for _, dim := range filterKeys {
+		if key := TagKey(dim); key != "" {
+			where = append(where, tagFilterExpr(key, q.Filters[dim], &args))
+			continue
+		}
+		expr, ok := dimensionExpr(dim, &args)
+		if !ok {
+			return "", nil, fmt.Errorf("filtro inválido: %s", dim)
+		}
+		args = append(args, q.Filters[dim])
+		where = append(where, fmt.Sprintf("%s = ANY($%d)", expr, len(args)))
+	}
+
+	query := "SELECT " + strings.Join(selects, ", ") + usageFromAsOf(q.AsOf)
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	if len(groupBy) > 0 {
+		query += "\n\t\tGROUP BY " + strings.Join(groupBy, ", ")
+	}
+
+	orderBy, err := aggregationOrderBy(q)
+	if err != nil {
+		return "", nil, err
+	}
+	if orderBy != "" {
+		query += "\n\t\tORDER BY " + orderBy
+	}
+
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf("\n\t\tLIMIT $%d", len(args))
+	}
+
+	return query, args, nil
+}
+
+// aggregationOrderBy converte o campo de ordenação em uma referência posicional
+func aggregationOrderBy(q models.AggregationQuery) (string, error) {
+	if q.Sort == "" {
+		if len(q.Measures) > 0 {
+			return fmt.Sprintf("%d DESC", len(q.Dimensions)+1), nil
+		}
+		return "", nil
+	}
+
+	field, direction := q.Sort, "ASC"
+	if strings.HasPrefix(field, "-") {
+		field, direction = field[1:], "DESC"
+	}
+
+	for i, dim := range q.Dimensions {
+		if dim == field {
+			return fmt.Sprintf("%d %s", i+1, direction), nil
+		}
+	}
+	for i, m := range q.Measures {
+		if m == field {
+			return fmt.Sprintf("%d %s", len(q.Dimensions)+i+1, direction), nil
+		}
+	}
+
+	return "", fmt.Errorf("ordenação inválida: %s", q.Sort)
+}
+
+// Aggregate executa uma consulta de agregação ad-hoc sobre os usos
+func (r *Repository) Aggregate(ctx context.Context, q models.AggregationQuery) ([]models.AggregationRow, error) {
+	query, args, err := buildAggregationQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar agregação: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.AggregationRow
+	for rows.Next() {
+		dimValues := make([]string, len(q.Dimensions))
+		measureValues := make([]float64, len(q.Measures))
+		keyValues := make(map[string]*string)
+		dest := make([]interface{}, 0, len(dimValues)+len(measureValues))
+		for i := range dimValues {
+			dest = append(dest, &dimValues[i])
+		}
+		for i := range measureValues {
+			dest = append(dest, &measureValues[i])
+		}
+		for _, dim := range q.Dimensions {
+			if DimensionHasKey(dim) {
+				keyValues[dim] = new(string)
+				dest = append(dest, keyValues[dim])
+			}
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("erro ao escanear agregação: %w", err)
+		}
+
+		row := models.AggregationRow{
+			Dimensions: make(map[string]string, len(q.Dimensions)),
+			Measures:   make(map[string]float64, len(q.Measures)),
+		}
+		for i, dim := range q.Dimensions {
+			row.Dimensions[dim] = dimValues[i]
+		}
+		for i, m := range q.Measures {
+			row.Measures[m] = measureValues[i]
+		}
+		if len(keyValues) > 0 {
+			row.Keys = make(map[string]string, len(keyValues))
+			for dim, key := range keyValues {
+				row.Keys[dim] = *key
+			}
+		}
+		results = append(results, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de agregação: %w", err)
+	}
+
+	return results, nil
+}
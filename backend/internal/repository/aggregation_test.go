@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"data-importer-api-go/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildAggregationQuery(t *testing.T) {
+	q := models.AggregationQuery{
+		Dimensions: []string{"customer", "month"},
+		Measures:   []string{"sum_billing", "count"},
+		Filters: map[string][]string{
+			"country":  {"BR", "US"},
+			"category": {"Compute"},
+		},
+		Sort:  "-count",
+		Limit: 10,
+	}
+
+	query, args, err := buildAggregationQuery(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"COALESCE(c.customer_name, '')",
+		"TO_CHAR(u.usage_date, 'YYYY-MM')",
+		"COALESCE(pr.category, '') = ANY($1)",
+		"COALESCE(c.country, '') = ANY($2)",
+		"GROUP BY 1, 2",
+		"ORDER BY 4 DESC",
+		"LIMIT $3",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if len(args) != 3 {
+		t.Errorf("expected 3 args, got %d", len(args))
+	}
+}
+
+func TestBuildAggregationQueryRejectsUnknownNames(t *testing.T) {
+	cases := []models.AggregationQuery{
+		{Dimensions: []string{"customer; DROP TABLE usages"}, Measures: []string{"count"}},
+		{Measures: []string{"sum(billing)"}},
+		{Measures: []string{"count"}, Filters: map[string][]string{"1=1 OR c.country": {"x"}}},
+		{Measures: []string{"count"}, Sort: "customer"},
+	}
+	for _, q := range cases {
+		if _, _, err := buildAggregationQuery(q); err == nil {
+			t.Errorf("expected error for %+v", q)
+		}
+	}
+}
+
+func TestBuildAggregationQueryAsOf(t *testing.T) {
+	asOf := time.Date(2024, time.March, 31, 23, 59, 59, 0, time.UTC)
+	q := models.AggregationQuery{
+		Dimensions: []string{"customer"},
+		Measures:   []string{"sum_billing"},
+		Filters:    map[string][]string{"country": {"BR"}},
+		AsOf:       &asOf,
+	}
+
+	query, args, err := buildAggregationQuery(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"FROM customer_versions",
+		"c ON u.customer_version_id = c.version_id",
+		"u.created_at <= $",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if len(args) == 0 || args[0] != asOf {
+		t.Errorf("expected as_of as first arg, got %v", args)
+	}
+}
+
+func TestBuildAggregationQueryCustomerGroupAsOf(t *testing.T) {
+	asOf := time.Date(2024, time.March, 31, 23, 59, 59, 0, time.UTC)
+	q := models.AggregationQuery{
+		Dimensions: []string{"customer_group"},
+		Measures:   []string{"sum_billing"},
+		AsOf:       &asOf,
+	}
+
+	query, _, err := buildAggregationQuery(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"FROM customer_versions",
+		"LEFT JOIN customer_groups cg ON c.group_id = cg.id",
+		// clientes sem grupo não podem ter a mesma chave de um grupo ou de outro cliente de mesmo nome
+		"'sem grupo: ' || COALESCE(c.customer_name, '') || ' (' || c.customer_id || ')'",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, "COALESCE(cg.name, c.customer_name") {
+		t.Errorf("ungrouped customers must not be keyed by name alone, got:\n%s", query)
+	}
+}
+
+func TestBuildAggregationQueryGroupsEntitiesByKey(t *testing.T) {
+	q := models.AggregationQuery{
+		Dimensions: []string{"customer", "category"},
+		Measures:   []string{"sum_billing"},
+		Sort:       "-sum_billing",
+	}
+
+	query, _, err := buildAggregationQuery(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a chave do cliente vem depois das medidas, sem deslocar a ordenação posicional
+	for _, want := range []string{
+		"SELECT COALESCE(c.customer_name, ''), COALESCE(pr.category, ''), COALESCE(SUM(u.billing_pre_tax_total), 0)::float8, COALESCE(c.customer_id, '')",
+		"GROUP BY 1, 2, 4",
+		"ORDER BY 3 DESC",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+}
+
+func TestBuildAggregationQueryTagDimension(t *testing.T) {
+	q := models.AggregationQuery{
+		Dimensions: []string{"tag:CostCenter"},
+		Measures:   []string{"sum_billing"},
+		Filters:    map[string][]string{"tag:project": {"apollo", ""}},
+	}
+
+	query, args, err := buildAggregationQuery(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"COALESCE(u.tag_map->>$1, '')",
+		"(u.tag_map @> jsonb_build_object($2::text, $3::text) OR COALESCE(u.tag_map->>$2, '') = '')",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, "costcenter") || strings.Contains(query, "project") {
+		t.Errorf("tag keys must be query parameters, got:\n%s", query)
+	}
+	if len(args) != 3 || args[0] != "costcenter" || args[1] != "project" || args[2] != "apollo" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	if IsDimension("tag:") || IsDimension("tag:  ") {
+		t.Error("expected tag dimension without key to be rejected")
+	}
+}
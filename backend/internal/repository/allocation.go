@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const allocationRuleColumns = `id, name, tag_key, source_value, splits, active, created_at, updated_at`
+
+func scanAllocationRule(row pgx.Row, a *models.AllocationRule) error {
+	return row.Scan(&a.ID, &a.Name, &a.TagKey, &a.SourceValue, &a.Splits, &a.Active, &a.CreatedAt, &a.UpdatedAt)
+}
+
+// GetAllocationRules retorna as regras de rateio; tagKey não vazio filtra as regras ativas da tag
+func (r *Repository) GetAllocationRules(ctx context.Context, tagKey string) ([]models.AllocationRule, error) {
+	query := `SELECT ` + allocationRuleColumns + ` FROM allocation_rules`
+	var args []interface{}
+	if tagKey != "" {
+		args = append(args, tagKey)
+		query += ` WHERE active AND tag_key = $1`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar regras de rateio: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.AllocationRule
+	for rows.Next() {
+		var a models.AllocationRule
+		if err := scanAllocationRule(rows, &a); err != nil {
+			return nil, fmt.Errorf("erro ao escanear regra de rateio: %w", err)
+		}
+		rules = append(rules, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de regras de rateio: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetAllocationRuleByID busca uma regra de rateio; retorna nil se não existir
+func (r *Repository) GetAllocationRuleByID(ctx context.Context, id int) (*models.AllocationRule, error) {
+	var a models.AllocationRule
+	err := scanAllocationRule(r.db.QueryRow(ctx, `SELECT `+allocationRuleColumns+` FROM allocation_rules WHERE id = $1`, id), &a)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar regra de rateio: %w", err)
+	}
+	return &a, nil
+}
+
+// InsertAllocationRule cria uma regra de rateio; retorna false se já existir uma regra com o nome
+func (r *Repository) InsertAllocationRule(ctx context.Context, a *models.AllocationRule) (bool, error) {
+	query := `
+		INSERT INTO allocation_rules (name, tag_key, source_value, splits, active)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + allocationRuleColumns
+
+	err := scanAllocationRule(r.db.QueryRow(ctx, query, a.Name, a.TagKey, a.SourceValue, a.Splits, a.Active), a)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao inserir regra de rateio: %w", err)
+	}
+	return true, nil
+}
+
+// UpdateAllocationRule atualiza uma regra de rateio; retorna false se não existir ou se o novo
+// nome já pertencer a outra regra
+func (r *Repository) UpdateAllocationRule(ctx context.Context, a *models.AllocationRule) (bool, error) {
+	query := `
+		UPDATE allocation_rules
+		SET name = $2, tag_key = $3, source_value = $4, splits = $5, active = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM allocation_rules o WHERE o.name = $2 AND o.id <> $1)
+		RETURNING ` + allocationRuleColumns
+
+	err := scanAllocationRule(r.db.QueryRow(ctx, query, a.ID, a.Name, a.TagKey, a.SourceValue, a.Splits, a.Active), a)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar regra de rateio: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteAllocationRule remove uma regra de rateio
+func (r *Repository) DeleteAllocationRule(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM allocation_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover regra de rateio: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetBillingByTagValue retorna o faturamento por valor da tag no intervalo [from, to];
+// usages sem a tag aparecem com valor vazio
+func (r *Repository) GetBillingByTagValue(ctx context.Context, tagKey string, from, to, asOf *time.Time) (map[string]float64, error) {
+	args := []interface{}{tagKey}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+	if from != nil {
+		args = append(args, *from)
+		where = append(where, fmt.Sprintf("u.usage_date >= $%d", len(args)))
+	}
+	if to != nil {
+		args = append(args, *to)
+		where = append(where, fmt.Sprintf("u.usage_date <= $%d", len(args)))
+	}
+
+	// Os usos com a tag são selecionados com ?, que usa o índice GIN de tag_map; os demais
+	// formam o valor vazio
+	tagged := append([]string{"u.tag_map ? $1"}, where...)
+	untagged := append([]string{"NOT (u.tag_map ? $1)"}, where...)
+	query := `
+		SELECT COALESCE(u.tag_map->>$1, '') as tag_value, COALESCE(SUM(u.billing_pre_tax_total), 0)::float8 as total
+		FROM usages u` + whereClause(tagged) + `
+		GROUP BY 1
+		UNION ALL
+		SELECT '', COALESCE(SUM(u.billing_pre_tax_total), 0)::float8
+		FROM usages u` + whereClause(untagged) + `
+		HAVING COUNT(*) > 0
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar faturamento por tag: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]float64)
+	for rows.Next() {
+		var value string
+		var total float64
+		if err := rows.Scan(&value, &total); err != nil {
+			return nil, fmt.Errorf("erro ao escanear faturamento por tag: %w", err)
+		}
+		totals[value] += total
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de faturamento por tag: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetUsageSeries retorna o gasto por cliente × produto × período, ordenado por série e data. Com
+// customerIDs/productIDs (pares nas mesmas posições) só essas séries são lidas; com from, só os
+// períodos a partir dele.
+func (r *Repository) GetUsageSeries(ctx context.Context, granularity string, customerIDs, productIDs []int, from *time.Time) ([]models.UsageSeriesPoint, error) {
+	if !timeseriesGranularities[granularity] {
+		return nil, fmt.Errorf("granularidade inválida: %s", granularity)
+	}
+
+	var args []interface{}
+	where := []string{"customer_id IS NOT NULL", "product_id IS NOT NULL"}
+	if customerIDs != nil {
+		args = append(args, customerIDs, productIDs)
+		where = append(where, fmt.Sprintf("(customer_id, product_id) IN (SELECT * FROM unnest($%d::int[], $%d::int[]))", len(args)-1, len(args)))
+	}
+	if from != nil {
+		args = append(args, *from)
+		where = append(where, fmt.Sprintf("usage_date >= $%d", len(args)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			customer_id,
+			product_id,
+			DATE_TRUNC('%s', usage_date)::date as period_start,
+			SUM(billing_pre_tax_total) as total
+		FROM usages
+		WHERE %s
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`, granularity, strings.Join(where, " AND "))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar séries de uso: %w", err)
+	}
+	defer rows.Close()
+
+	var points []models.UsageSeriesPoint
+	for rows.Next() {
+		var p models.UsageSeriesPoint
+		if err := rows.Scan(&p.CustomerID, &p.ProductID, &p.PeriodStart, &p.Total); err != nil {
+			return nil, fmt.Errorf("erro ao escanear série de uso: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de séries de uso: %w", err)
+	}
+
+	return points, nil
+}
+
+// ReplaceAnomalies grava o resultado de uma detecção na mesma transação: remove as anomalias dos
+// períodos reavaliados que deixaram de ser anômalos (cleared) e grava as detectadas preservando o
+// reconhecimento já feito
+func (r *Repository) ReplaceAnomalies(ctx context.Context, granularity string, cleared []models.UsageSeriesPoint, anomalies []models.Anomaly) error {
+	if len(cleared) == 0 && len(anomalies) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação de anomalias: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if len(cleared) > 0 {
+		customerIDs := make([]int, len(cleared))
+		productIDs := make([]int, len(cleared))
+		periods := make([]time.Time, len(cleared))
+		for i, p := range cleared {
+			customerIDs[i], productIDs[i], periods[i] = p.CustomerID, p.ProductID, p.PeriodStart
+		}
+		_, err := tx.Exec(ctx, `
+			DELETE FROM anomalies
+			WHERE granularity = $1
+			  AND (customer_id, product_id, period_start) IN (SELECT * FROM unnest($2::int[], $3::int[], $4::date[]))
+		`, granularity, customerIDs, productIDs, periods)
+		if err != nil {
+			return fmt.Errorf("erro ao remover anomalias reavaliadas: %w", err)
+		}
+	}
+
+	if len(anomalies) > 0 {
+		query := `
+			INSERT INTO anomalies (customer_id, product_id, granularity, period_start, value, baseline, score, method, severity)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (customer_id, product_id, granularity, period_start) DO UPDATE SET
+				value = EXCLUDED.value,
+				baseline = EXCLUDED.baseline,
+				score = EXCLUDED.score,
+				method = EXCLUDED.method,
+				severity = EXCLUDED.severity,
+				updated_at = CURRENT_TIMESTAMP
+		`
+
+		batch := &pgx.Batch{}
+		for _, a := range anomalies {
+			batch.Queue(query, a.CustomerID, a.ProductID, a.Granularity, a.PeriodStart, a.Value, a.Baseline, a.Score, a.Method, a.Severity)
+		}
+
+		results := tx.SendBatch(ctx, batch)
+		for range anomalies {
+			if _, err := results.Exec(); err != nil {
+				results.Close()
+				return fmt.Errorf("erro ao gravar anomalia: %w", err)
+			}
+		}
+		if err := results.Close(); err != nil {
+			return fmt.Errorf("erro ao gravar anomalias: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("erro ao confirmar anomalias: %w", err)
+	}
+	return nil
+}
+
+const anomalySelect = `
+		SELECT a.id, a.customer_id, a.product_id, COALESCE(c.customer_name, ''), COALESCE(pr.product_name, ''),
+		       a.granularity, a.period_start, a.value, a.baseline, a.score, a.method, a.severity,
+		       a.acknowledged, a.acknowledged_by, a.acknowledged_at, a.created_at, a.updated_at
+		FROM anomalies a
+		LEFT JOIN customers c ON a.customer_id = c.id
+		LEFT JOIN products pr ON a.product_id = pr.id`
+
+func scanAnomaly(row pgx.Row, a *models.Anomaly) error {
+	return row.Scan(
+		&a.ID, &a.CustomerID, &a.ProductID, &a.CustomerName, &a.ProductName,
+		&a.Granularity, &a.PeriodStart, &a.Value, &a.Baseline, &a.Score, &a.Method, &a.Severity,
+		&a.Acknowledged, &a.AcknowledgedBy, &a.AcknowledgedAt, &a.CreatedAt, &a.UpdatedAt,
+	)
+}
+
+// ListAnomalies retorna as anomalias mais recentes de acordo com os filtros
+func (r *Repository) ListAnomalies(ctx context.Context, filter models.AnomalyFilter) ([]models.Anomaly, error) {
+	var where []string
+	var args []interface{}
+
+	if filter.Severity != "" {
+		args = append(args, filter.Severity)
+		where = append(where, fmt.Sprintf("a.severity = $%d", len(args)))
+	}
+	if filter.Acknowledged != nil {
+		args = append(args, *filter.Acknowledged)
+		where = append(where, fmt.Sprintf("a.acknowledged = $%d", len(args)))
+	}
+	if filter.CustomerID > 0 {
+		args = append(args, filter.CustomerID)
+		where = append(where, fmt.Sprintf("a.customer_id = $%d", len(args)))
+	}
+
+	query := anomalySelect
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n\t\tORDER BY a.period_start DESC, a.score DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf("\n\t\tLIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar anomalias: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []models.Anomaly
+	for rows.Next() {
+		var a models.Anomaly
+		if err := scanAnomaly(rows, &a); err != nil {
+			return nil, fmt.Errorf("erro ao escanear anomalia: %w", err)
+		}
+		anomalies = append(anomalies, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de anomalias: %w", err)
+	}
+
+	return anomalies, nil
+}
+
+// AcknowledgeAnomaly marca uma anomalia como reconhecida; retorna nil se não existir
+func (r *Repository) AcknowledgeAnomaly(ctx context.Context, id int, username string) (*models.Anomaly, error) {
+	query := `
+		UPDATE anomalies
+		SET acknowledged = true, acknowledged_by = $2, acknowledged_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, username)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reconhecer anomalia: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	var a models.Anomaly
+	if err := scanAnomaly(r.db.QueryRow(ctx, anomalySelect+"\n\t\tWHERE a.id = $1", id), &a); err != nil {
+		return nil, fmt.Errorf("erro ao buscar anomalia: %w", err)
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// budgetScopeColumns mapeia o escopo do orçamento para a chave de negócio correspondente
+var budgetScopeColumns = map[string]string{
+	"customer": "c.customer_id",
+	"partner":  "p.partner_id",
+	"category": "pr.category",
+}
+
+const budgetColumns = `id, name, scope, scope_ref, amount, thresholds, active, created_at, updated_at`
+
+func scanBudget(row pgx.Row, b *models.Budget) error {
+	return row.Scan(&b.ID, &b.Name, &b.Scope, &b.ScopeRef, &b.Amount, &b.Thresholds, &b.Active, &b.CreatedAt, &b.UpdatedAt)
+}
+
+// GetAllBudgets retorna todos os orçamentos
+func (r *Repository) GetAllBudgets(ctx context.Context) ([]models.Budget, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+budgetColumns+` FROM budgets ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar orçamentos: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []models.Budget
+	for rows.Next() {
+		var b models.Budget
+		if err := scanBudget(rows, &b); err != nil {
+			return nil, fmt.Errorf("erro ao escanear orçamento: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de orçamentos: %w", err)
+	}
+
+	return budgets, nil
+}
+
+// GetBudgetByID busca um orçamento; retorna nil se não existir
+func (r *Repository) GetBudgetByID(ctx context.Context, id int) (*models.Budget, error) {
+	var b models.Budget
+	err := scanBudget(r.db.QueryRow(ctx, `SELECT `+budgetColumns+` FROM budgets WHERE id = $1`, id), &b)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar orçamento: %w", err)
+	}
+	return &b, nil
+}
+
+// InsertBudget cria um orçamento
+func (r *Repository) InsertBudget(ctx context.Context, b *models.Budget) error {
+	query := `
+		INSERT INTO budgets (name, scope, scope_ref, amount, thresholds, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + budgetColumns
+
+	err := scanBudget(r.db.QueryRow(ctx, query, b.Name, b.Scope, b.ScopeRef, b.Amount, b.Thresholds, b.Active), b)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir orçamento: %w", err)
+	}
+	return nil
+}
+
+// UpdateBudget atualiza um orçamento; retorna false se não existir
+func (r *Repository) UpdateBudget(ctx context.Context, b *models.Budget) (bool, error) {
+	query := `
+		UPDATE budgets
+		SET name = $2, scope = $3, scope_ref = $4, amount = $5, thresholds = $6, active = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + budgetColumns
+
+	err := scanBudget(r.db.QueryRow(ctx, query, b.ID, b.Name, b.Scope, b.ScopeRef, b.Amount, b.Thresholds, b.Active), b)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar orçamento: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteBudget remove um orçamento e seus alertas; retorna false se não existir
+func (r *Repository) DeleteBudget(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM budgets WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover orçamento: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetBudgetSpend retorna o gasto do escopo do orçamento no intervalo [from, to)
+func (r *Repository) GetBudgetSpend(ctx context.Context, scope, scopeRef string, from, to time.Time) (float64, error) {
+	column, ok := budgetScopeColumns[scope]
+	if !ok {
+		return 0, fmt.Errorf("escopo de orçamento inválido: %s", scope)
+	}
+
+	query := `SELECT COALESCE(SUM(u.billing_pre_tax_total), 0)::float8` + usageBaseFrom + `
+		WHERE ` + column + ` = $1 AND u.usage_date >= $2 AND u.usage_date < $3`
+
+	var spend float64
+	if err := r.db.QueryRow(ctx, query, scopeRef, from, to).Scan(&spend); err != nil {
+		return 0, fmt.Errorf("erro ao calcular gasto do orçamento: %w", err)
+	}
+	return spend, nil
+}
+
+// InsertBudgetAlert registra o cruzamento de um limiar; retorna false se o alerta já existia
+func (r *Repository) InsertBudgetAlert(ctx context.Context, a *models.BudgetAlert) (bool, error) {
+	query := `
+		INSERT INTO budget_alerts (budget_id, month, threshold, spend, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (budget_id, month, threshold) DO NOTHING
+		RETURNING id, state, attempts, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, a.BudgetID, a.Month, a.Threshold, a.Spend, a.Amount).Scan(&a.ID, &a.State, &a.Attempts, &a.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao inserir alerta de orçamento: %w", err)
+	}
+	return true, nil
+}
+
+const budgetAlertSelect = `
+		SELECT a.id, a.budget_id, b.name, b.scope, b.scope_ref, a.month, a.threshold, a.spend, a.amount,
+		       a.state, a.attempts, a.last_error, a.notified_at, a.created_at
+		FROM budget_alerts a
+		JOIN budgets b ON a.budget_id = b.id`
+
+func (r *Repository) queryBudgetAlerts(ctx context.Context, query string, args ...interface{}) ([]models.BudgetAlert, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alertas de orçamento: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []models.BudgetAlert
+	for rows.Next() {
+		var a models.BudgetAlert
+		err := rows.Scan(&a.ID, &a.BudgetID, &a.BudgetName, &a.Scope, &a.ScopeRef, &a.Month, &a.Threshold, &a.Spend, &a.Amount,
+			&a.State, &a.Attempts, &a.LastError, &a.NotifiedAt, &a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear alerta de orçamento: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de alertas de orçamento: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// GetBudgetAlerts retorna os alertas de um orçamento, mais recentes primeiro
+func (r *Repository) GetBudgetAlerts(ctx context.Context, budgetID int) ([]models.BudgetAlert, error) {
+	return r.queryBudgetAlerts(ctx, budgetAlertSelect+`
+		WHERE a.budget_id = $1
+		ORDER BY a.month DESC, a.threshold DESC`, budgetID)
+}
+
+// GetUndeliveredBudgetAlerts retorna alertas pendentes ou com falha abaixo do limite de tentativas
+func (r *Repository) GetUndeliveredBudgetAlerts(ctx context.Context, maxAttempts int) ([]models.BudgetAlert, error) {
+	return r.queryBudgetAlerts(ctx, budgetAlertSelect+`
+		WHERE a.state IN ('pending', 'failed') AND a.attempts < $1
+		ORDER BY a.id`, maxAttempts)
+}
+
+// UpdateBudgetAlertDelivery registra o resultado de uma tentativa de notificação
+func (r *Repository) UpdateBudgetAlertDelivery(ctx context.Context, id int, deliveryErr error) error {
+	var err error
+	if deliveryErr == nil {
+		_, err = r.db.Exec(ctx, `
+			UPDATE budget_alerts
+			SET state = 'sent', attempts = attempts + 1, last_error = NULL, notified_at = CURRENT_TIMESTAMP
+			WHERE id = $1`, id)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			UPDATE budget_alerts
+			SET state = 'failed', attempts = attempts + 1, last_error = $2
+			WHERE id = $1`, id, deliveryErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar entrega do alerta: %w", err)
+	}
+	return nil
+}
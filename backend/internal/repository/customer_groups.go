@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const customerGroupSelect = `
+	SELECT g.id, g.name, g.domain_patterns,
+	       (SELECT COUNT(*) FROM customers c WHERE c.group_id = g.id),
+	       g.created_at, g.updated_at
+	FROM customer_groups g`
+
+func scanCustomerGroup(row pgx.Row, g *models.CustomerGroup) error {
+	return row.Scan(&g.ID, &g.Name, &g.DomainPatterns, &g.CustomerCount, &g.CreatedAt, &g.UpdatedAt)
+}
+
+// GetCustomerGroups retorna os grupos de clientes com a quantidade de clientes de cada um
+func (r *Repository) GetCustomerGroups(ctx context.Context) ([]models.CustomerGroup, error) {
+	rows, err := r.db.Query(ctx, customerGroupSelect+` ORDER BY g.name`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar grupos de clientes: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []models.CustomerGroup
+	for rows.Next() {
+		var g models.CustomerGroup
+		if err := scanCustomerGroup(rows, &g); err != nil {
+			return nil, fmt.Errorf("erro ao escanear grupo de clientes: %w", err)
+		}
+		groups = append(groups, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de grupos de clientes: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetCustomerGroupByID busca um grupo de clientes; retorna nil se não existir
+func (r *Repository) GetCustomerGroupByID(ctx context.Context, id int) (*models.CustomerGroup, error) {
+	var g models.CustomerGroup
+	if err := scanCustomerGroup(r.db.QueryRow(ctx, customerGroupSelect+` WHERE g.id = $1`, id), &g); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar grupo de clientes: %w", err)
+	}
+	return &g, nil
+}
+
+// InsertCustomerGroup cria um grupo de clientes; retorna false se já existir um grupo com o nome
+func (r *Repository) InsertCustomerGroup(ctx context.Context, g *models.CustomerGroup) (bool, error) {
+	query := `
+		INSERT INTO customer_groups (name, domain_patterns)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, g.Name, g.DomainPatterns).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao inserir grupo de clientes: %w", err)
+	}
+	return true, nil
+}
+
+// UpdateCustomerGroup atualiza um grupo de clientes; retorna false se não existir ou se o novo
+// nome já pertencer a outro grupo
+func (r *Repository) UpdateCustomerGroup(ctx context.Context, g *models.CustomerGroup) (bool, error) {
+	query := `
+		UPDATE customer_groups
+		SET name = $2, domain_patterns = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM customer_groups o WHERE o.name = $2 AND o.id <> $1)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, g.ID, g.Name, g.DomainPatterns).Scan(&g.CreatedAt, &g.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar grupo de clientes: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteCustomerGroup remove um grupo; os clientes associados ficam sem grupo
+func (r *Repository) DeleteCustomerGroup(ctx context.Context, id int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("erro ao iniciar transação do grupo de clientes: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE customers SET group_id = NULL, group_source = NULL WHERE group_id = $1`, id); err != nil {
+		return false, fmt.Errorf("erro ao desassociar clientes do grupo: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM customer_groups WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover grupo de clientes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("erro ao confirmar remoção do grupo de clientes: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetCustomersByGroup retorna os clientes associados a um grupo
+func (r *Repository) GetCustomersByGroup(ctx context.Context, groupID int) ([]models.Customer, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+customerColumns+` FROM customers WHERE group_id = $1 ORDER BY customer_name`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar clientes do grupo: %w", err)
+	}
+	defer rows.Close()
+
+	var customers []models.Customer
+	for rows.Next() {
+		var c models.Customer
+		if err := scanCustomer(rows, &c); err != nil {
+			return nil, fmt.Errorf("erro ao escanear cliente: %w", err)
+		}
+		customers = append(customers, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de clientes do grupo: %w", err)
+	}
+
+	return customers, nil
+}
+
+// SetCustomerGroup associa um cliente a um grupo (ou remove a associação com groupID nulo),
+// registrando a origem; source vazio grava NULL. As associações manuais também são gravadas em
+// customer_group_assignments pelo customer_id, para sobreviverem às importações com
+// substituição. Retorna false se o cliente não existir.
+func (r *Repository) SetCustomerGroup(ctx context.Context, customerID int, groupID *int, source string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("erro ao iniciar transação do grupo de clientes: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var key string
+	err = tx.QueryRow(ctx, `UPDATE customers SET group_id = $2, group_source = NULLIF($3, '') WHERE id = $1 RETURNING customer_id`,
+		customerID, groupID, source).Scan(&key)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao associar cliente ao grupo: %w", err)
+	}
+
+	if source == models.GroupSourceManual && groupID != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO customer_group_assignments (customer_id, group_id) VALUES ($1, $2)
+			ON CONFLICT (customer_id) DO UPDATE SET group_id = EXCLUDED.group_id, created_at = CURRENT_TIMESTAMP
+		`, key, *groupID)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM customer_group_assignments WHERE customer_id = $1`, key)
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao registrar associação manual do cliente: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("erro ao confirmar associação do cliente: %w", err)
+	}
+	return true, nil
+}
+
+// RestoreCustomerGroupAssignments reaplica as associações manuais gravadas pelo customer_id aos
+// clientes que as perderam, como os recriados por uma importação com substituição
+func (r *Repository) RestoreCustomerGroupAssignments(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE customers c
+		SET group_id = a.group_id, group_source = '`+models.GroupSourceManual+`'
+		FROM customer_group_assignments a
+		WHERE a.customer_id = c.customer_id
+		  AND (c.group_id IS DISTINCT FROM a.group_id OR c.group_source IS DISTINCT FROM '`+models.GroupSourceManual+`')
+	`)
+	if err != nil {
+		return fmt.Errorf("erro ao restaurar associações manuais de clientes: %w", err)
+	}
+	return nil
+}
+
+// ApplyCustomerGroupPatterns grava as associações por padrão de domínio. Clientes associados
+// manualmente no meio tempo não são alterados.
+func (r *Repository) ApplyCustomerGroupPatterns(ctx context.Context, assignments map[int]*int) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação de grupos de clientes: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE customers
+		SET group_id = $2::int, group_source = CASE WHEN $2::int IS NULL THEN NULL ELSE '` + models.GroupSourcePattern + `' END
+		WHERE id = $1 AND group_source IS DISTINCT FROM '` + models.GroupSourceManual + `'
+	`
+	batch := &pgx.Batch{}
+	for customerID, groupID := range assignments {
+		batch.Queue(query, customerID, groupID)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for range assignments {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return fmt.Errorf("erro ao associar clientes por padrão: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("erro ao finalizar associação de clientes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("erro ao confirmar associação de clientes: %w", err)
+	}
+	return nil
+}
+
+// GetBillingByCustomerGroup retorna o faturamento por grupo de clientes; clientes sem grupo
+// aparecem individualmente
+func (r *Repository) GetBillingByCustomerGroup(ctx context.Context, asOf *time.Time) ([]models.CustomerGroupBillingReport, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+	_, customerJoin, _ := asOfJoins(asOf)
+
+	query := `
+		SELECT
+			cg.id,
+			` + dimensionRegistry["customer_group"] + ` as group_name,
+			COUNT(DISTINCT c.id) as customers,
+			SUM(u.billing_pre_tax_total) as total,
+			COUNT(*) as count
+		FROM usages u
+		JOIN ` + customerJoin + `
+		LEFT JOIN ` + customerGroupJoin + whereClause(where) + `
+		GROUP BY cg.id, 2, CASE WHEN cg.id IS NULL THEN c.id END
+		ORDER BY total DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar faturamento por grupo de clientes: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []models.CustomerGroupBillingReport
+	for rows.Next() {
+		var report models.CustomerGroupBillingReport
+		if err := rows.Scan(&report.GroupID, &report.GroupName, &report.Customers, &report.Total, &report.Count); err != nil {
+			return nil, fmt.Errorf("erro ao escanear resultado de faturamento por grupo de clientes: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de faturamento por grupo de clientes: %w", err)
+	}
+
+	return reports, nil
+}
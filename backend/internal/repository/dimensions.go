@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dimensionTable descreve a tabela de versões de uma dimensão
+type dimensionTable struct {
+	base     string   // tabela base com os atributos atuais
+	versions string   // tabela de versões
+	ref      string   // coluna com o id da entidade na tabela base
+	key      string   // chave natural
+	attrs    []string // atributos versionados
+}
+
+var dimensionTables = map[string]dimensionTable{
+	"partner": {
+		base: "partners", versions: "partner_versions", ref: "partner_ref", key: "partner_id",
+		attrs: []string{"partner_name", "mpn_id", "tier2_mpn_id"},
+	},
+	"customer": {
+		base: "customers", versions: "customer_versions", ref: "customer_ref", key: "customer_id",
+		attrs: []string{"customer_name", "customer_domain_name", "country"},
+	},
+	"product": {
+		base: "products", versions: "product_versions", ref: "product_ref", key: "product_id",
+		attrs: []string{"sku_id", "sku_name", "product_name", "meter_type", "category", "sub_category", "unit_type"},
+	},
+}
+
+// DimensionAttributes lista os atributos versionados da dimensão; nil se a dimensão não existir
+func DimensionAttributes(dimension string) []string {
+	return dimensionTables[dimension].attrs
+}
+
+// GetDimensionVersions retorna as versões das entidades informadas, por entidade e valid_from
+func (r *Repository) GetDimensionVersions(ctx context.Context, dimension string, entityIDs []int) ([]models.DimensionVersion, error) {
+	table, ok := dimensionTables[dimension]
+	if !ok {
+		return nil, fmt.Errorf("dimensão inválida: %s", dimension)
+	}
+	if len(entityIDs) == 0 {
+		return nil, nil
+	}
+
+	columns := make([]string, len(table.attrs))
+	for i, attr := range table.attrs {
+		columns[i] = fmt.Sprintf("COALESCE(%s, '')", attr)
+	}
+	query := fmt.Sprintf(`
+		SELECT id, %s, %s, %s, valid_from, valid_to, is_current, created_at
+		FROM %s
+		WHERE %s = ANY($1)
+		ORDER BY %s, valid_from, id
+	`, table.ref, table.key, strings.Join(columns, ", "), table.versions, table.ref, table.ref)
+
+	rows, err := r.db.Query(ctx, query, entityIDs)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar versões de %s: %w", dimension, err)
+	}
+	defer rows.Close()
+
+	var versions []models.DimensionVersion
+	for rows.Next() {
+		v := models.DimensionVersion{Dimension: dimension}
+		values := make([]string, len(table.attrs))
+		dest := []interface{}{&v.ID, &v.EntityID, &v.Key}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		dest = append(dest, &v.ValidFrom, &v.ValidTo, &v.IsCurrent, &v.CreatedAt)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("erro ao escanear versão de %s: %w", dimension, err)
+		}
+		v.Attributes = make(map[string]string, len(values))
+		for i, attr := range table.attrs {
+			v.Attributes[attr] = values[i]
+		}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de versões de %s: %w", dimension, err)
+	}
+
+	return versions, nil
+}
+
+// InsertDimensionVersion grava uma nova versão, corrente ou histórica conforme v.ValidTo. Se
+// closeID for informado, essa versão é encerrada em closeAt na mesma transação.
+func (r *Repository) InsertDimensionVersion(ctx context.Context, v *models.DimensionVersion, closeID int, closeAt time.Time) error {
+	table, ok := dimensionTables[v.Dimension]
+	if !ok {
+		return fmt.Errorf("dimensão inválida: %s", v.Dimension)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação de versão: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if closeID > 0 {
+		query := fmt.Sprintf(`UPDATE %s SET valid_to = $2, is_current = false WHERE id = $1`, table.versions)
+		if _, err := tx.Exec(ctx, query, closeID, closeAt); err != nil {
+			return fmt.Errorf("erro ao encerrar versão de %s: %w", v.Dimension, err)
+		}
+	}
+
+	columns := append([]string{table.ref, table.key}, table.attrs...)
+	columns = append(columns, "valid_from", "valid_to", "is_current")
+	placeholders := make([]string, len(columns))
+	args := []interface{}{v.EntityID, v.Key}
+	for _, attr := range table.attrs {
+		args = append(args, v.Attributes[attr])
+	}
+	args = append(args, v.ValidFrom, v.ValidTo, v.ValidTo == nil)
+	for i := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) RETURNING id, is_current, created_at`,
+		table.versions, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	if err := tx.QueryRow(ctx, query, args...).Scan(&v.ID, &v.IsCurrent, &v.CreatedAt); err != nil {
+		return fmt.Errorf("erro ao inserir versão de %s: %w", v.Dimension, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("erro ao confirmar versão de %s: %w", v.Dimension, err)
+	}
+	return nil
+}
+
+// RestoreDimensionAttributes regrava na tabela base os atributos da versão corrente
+func (r *Repository) RestoreDimensionAttributes(ctx context.Context, dimension string, entityID int, attrs map[string]string) error {
+	table, ok := dimensionTables[dimension]
+	if !ok {
+		return fmt.Errorf("dimensão inválida: %s", dimension)
+	}
+
+	sets := make([]string, len(table.attrs))
+	args := []interface{}{entityID}
+	for i, attr := range table.attrs {
+		args = append(args, attrs[attr])
+		sets[i] = fmt.Sprintf("%s = $%d", attr, i+2)
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $1`, table.base, strings.Join(sets, ", "))
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("erro ao restaurar atributos de %s: %w", dimension, err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const partnerColumns = `id, partner_id, partner_name, mpn_id, tier2_mpn_id, created_at, updated_at`
+
+func scanPartner(row pgx.Row, p *models.Partner) error {
+	return row.Scan(&p.ID, &p.PartnerID, &p.PartnerName, &p.MpnID, &p.Tier2MpnID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+const customerColumns = `id, customer_id, customer_name, customer_domain_name, country, group_id, COALESCE(group_source, ''), created_at, updated_at`
+
+func scanCustomer(row pgx.Row, c *models.Customer) error {
+	return row.Scan(&c.ID, &c.CustomerID, &c.CustomerName, &c.CustomerDomainName, &c.Country, &c.GroupID, &c.GroupSource,
+		&c.CreatedAt, &c.UpdatedAt)
+}
+
+const productColumns = `id, product_id, sku_id, sku_name, product_name, meter_type, category, sub_category, unit_type, created_at, updated_at`
+
+func scanProduct(row pgx.Row, p *models.Product) error {
+	return row.Scan(&p.ID, &p.ProductID, &p.SkuID, &p.SkuName, &p.ProductName, &p.MeterType,
+		&p.Category, &p.SubCategory, &p.UnitType, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// GetPartnerByID busca um parceiro; retorna nil se não existir
+func (r *Repository) GetPartnerByID(ctx context.Context, id int) (*models.Partner, error) {
+	var p models.Partner
+	err := scanPartner(r.db.QueryRow(ctx, `SELECT `+partnerColumns+` FROM partners WHERE id = $1`, id), &p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar parceiro: %w", err)
+	}
+	return &p, nil
+}
+
+// UpdatePartner atualiza um parceiro se updated_at ainda for expected; retorna false se o
+// parceiro não existir ou tiver sido alterado por outra requisição
+func (r *Repository) UpdatePartner(ctx context.Context, p *models.Partner, expected time.Time) (bool, error) {
+	query := `
+		UPDATE partners
+		SET partner_name = $2, mpn_id = $3, tier2_mpn_id = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND updated_at = $5
+		RETURNING ` + partnerColumns
+
+	err := scanPartner(r.db.QueryRow(ctx, query, p.ID, p.PartnerName, p.MpnID, p.Tier2MpnID, expected), p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar parceiro: %w", err)
+	}
+	return true, nil
+}
+
+// DeletePartner remove um parceiro sem usos vinculados; retorna false se não existir ou
+// ainda tiver usos
+func (r *Repository) DeletePartner(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM partners
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM usages WHERE partner_id = $1)
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover parceiro: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetCustomerByID busca um cliente; retorna nil se não existir
+func (r *Repository) GetCustomerByID(ctx context.Context, id int) (*models.Customer, error) {
+	var c models.Customer
+	err := scanCustomer(r.db.QueryRow(ctx, `SELECT `+customerColumns+` FROM customers WHERE id = $1`, id), &c)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar cliente: %w", err)
+	}
+	return &c, nil
+}
+
+// GetCustomerByCustomerID busca um cliente pelo customer_id de negócio; retorna nil se não existir
+func (r *Repository) GetCustomerByCustomerID(ctx context.Context, customerID string) (*models.Customer, error) {
+	var c models.Customer
+	err := scanCustomer(r.db.QueryRow(ctx, `SELECT `+customerColumns+` FROM customers WHERE customer_id = $1`, customerID), &c)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar cliente: %w", err)
+	}
+	return &c, nil
+}
+
+// UpdateCustomer atualiza um cliente se updated_at ainda for expected; retorna false se o
+// cliente não existir ou tiver sido alterado por outra requisição
+func (r *Repository) UpdateCustomer(ctx context.Context, c *models.Customer, expected time.Time) (bool, error) {
+	query := `
+		UPDATE customers
+		SET customer_name = $2, customer_domain_name = $3, country = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND updated_at = $5
+		RETURNING ` + customerColumns
+
+	err := scanCustomer(r.db.QueryRow(ctx, query, c.ID, c.CustomerName, c.CustomerDomainName, c.Country, expected), c)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar cliente: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteCustomer remove um cliente sem usos vinculados; retorna false se não existir ou
+// ainda tiver usos
+func (r *Repository) DeleteCustomer(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM customers
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM usages WHERE customer_id = $1)
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover cliente: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetProductByID busca um produto; retorna nil se não existir
+func (r *Repository) GetProductByID(ctx context.Context, id int) (*models.Product, error) {
+	var p models.Product
+	err := scanProduct(r.db.QueryRow(ctx, `SELECT `+productColumns+` FROM products WHERE id = $1`, id), &p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar produto: %w", err)
+	}
+	return &p, nil
+}
+
+// GetProductByProductID busca um produto pelo product_id de negócio; retorna nil se não existir
+func (r *Repository) GetProductByProductID(ctx context.Context, productID string) (*models.Product, error) {
+	var p models.Product
+	err := scanProduct(r.db.QueryRow(ctx, `SELECT `+productColumns+` FROM products WHERE product_id = $1`, productID), &p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar produto: %w", err)
+	}
+	return &p, nil
+}
+
+// UpdateProduct atualiza um produto se updated_at ainda for expected; retorna false se o
+// produto não existir ou tiver sido alterado por outra requisição
+func (r *Repository) UpdateProduct(ctx context.Context, p *models.Product, expected time.Time) (bool, error) {
+	query := `
+		UPDATE products
+		SET sku_id = $2, sku_name = $3, product_name = $4, meter_type = $5, category = $6,
+		    sub_category = $7, unit_type = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND updated_at = $9
+		RETURNING ` + productColumns
+
+	err := scanProduct(r.db.QueryRow(ctx, query, p.ID, p.SkuID, p.SkuName, p.ProductName, p.MeterType,
+		p.Category, p.SubCategory, p.UnitType, expected), p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar produto: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteProduct remove um produto sem usos vinculados; retorna false se não existir ou
+// ainda tiver usos
+func (r *Repository) DeleteProduct(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM products
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM usages WHERE product_id = $1)
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover produto: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
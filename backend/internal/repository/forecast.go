@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+)
+
+// forecastScopes mapeia o escopo da previsão para as colunas de identificação da entidade
+var forecastScopes = map[string]struct{ id, name string }{
+	"overall":  {id: "0", name: "'Total'"},
+	"customer": {id: "COALESCE(c.id, 0)", name: "COALESCE(c.customer_name, '')"},
+	"partner":  {id: "COALESCE(p.id, 0)", name: "COALESCE(p.partner_name, '')"},
+}
+
+// GetDailySpend retorna o gasto diário por entidade do escopo; entityID > 0 restringe a uma entidade
+func (r *Repository) GetDailySpend(ctx context.Context, scope string, entityID int) ([]models.DailySpend, error) {
+	cols, ok := forecastScopes[scope]
+	if !ok {
+		return nil, fmt.Errorf("escopo inválido: %s", scope)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as entity_id,
+			%s as entity_name,
+			u.usage_date as day,
+			SUM(u.billing_pre_tax_total)::float8 as total%s
+		WHERE ($1 = 0 OR %s = $1)
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 3
+	`, cols.id, cols.name, usageBaseFrom, cols.id)
+
+	rows, err := r.db.Query(ctx, query, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar gasto diário: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []models.DailySpend
+	for rows.Next() {
+		var d models.DailySpend
+		if err := rows.Scan(&d.EntityID, &d.EntityName, &d.Day, &d.Total); err != nil {
+			return nil, fmt.Errorf("erro ao escanear gasto diário: %w", err)
+		}
+		spend = append(spend, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de gasto diário: %w", err)
+	}
+
+	return spend, nil
+}
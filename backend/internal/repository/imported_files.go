@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+)
+
+// IsChecksumImported indica se um arquivo com o checksum já foi importado com sucesso
+func (r *Repository) IsChecksumImported(ctx context.Context, checksum string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM imported_files WHERE checksum = $1 AND status = 'processed')`, checksum).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("erro ao verificar checksum: %w", err)
+	}
+	return exists, nil
+}
+
+// InsertImportedFile registra o resultado do processamento de um arquivo
+func (r *Repository) InsertImportedFile(ctx context.Context, f *models.ImportedFile) error {
+	return insertImportedFile(ctx, r.db, f)
+}
+
+func insertImportedFile(ctx context.Context, q querier, f *models.ImportedFile) error {
+	query := `
+		INSERT INTO imported_files (source, file_name, checksum, status, usages, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	if err := q.QueryRow(ctx, query, f.Source, f.FileName, f.Checksum, f.Status, f.Usages, f.Error).Scan(&f.ID, &f.CreatedAt); err != nil {
+		return fmt.Errorf("erro ao registrar arquivo importado: %w", err)
+	}
+	return nil
+}
+
+// GetImportedFiles retorna os arquivos processados mais recentes
+func (r *Repository) GetImportedFiles(ctx context.Context, limit int) ([]models.ImportedFile, error) {
+	query := `
+		SELECT id, source, file_name, checksum, status, usages, error, created_at
+		FROM imported_files
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar arquivos importados: %w", err)
+	}
+	defer rows.Close()
+
+	var files []models.ImportedFile
+	for rows.Next() {
+		var f models.ImportedFile
+		if err := rows.Scan(&f.ID, &f.Source, &f.FileName, &f.Checksum, &f.Status, &f.Usages, &f.Error, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("erro ao escanear arquivo importado: %w", err)
+		}
+		files = append(files, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de arquivos importados: %w", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RefreshInvoices recalcula, a partir das usages, as faturas com os números informados:
+// período, moeda, total pré-impostos e quantidade de linhas. Linhas sem número são ignoradas, e
+// faturas que ficaram sem linhas são removidas.
+func (r *Repository) RefreshInvoices(ctx context.Context, invoiceNumbers []string) error {
+	return refreshInvoices(ctx, r.db, invoiceNumbers)
+}
+
+func refreshInvoices(ctx context.Context, q querier, invoiceNumbers []string) error {
+	if len(invoiceNumbers) == 0 {
+		return nil
+	}
+
+	orphans := `
+		DELETE FROM invoices i
+		WHERE i.invoice_number = ANY($1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM usages u
+			WHERE u.invoice_number = i.invoice_number AND u.partner_id = i.partner_id AND u.customer_id = i.customer_id
+		  )
+	`
+	if _, err := q.Exec(ctx, orphans, invoiceNumbers); err != nil {
+		return fmt.Errorf("erro ao remover faturas sem linhas: %w", err)
+	}
+
+	query := `
+		INSERT INTO invoices (invoice_number, partner_id, customer_id, period_start, period_end, currency, pre_tax_total, line_count)
+		SELECT u.invoice_number, u.partner_id, u.customer_id,
+		       MIN(COALESCE(u.charge_start_date, u.usage_date)), MAX(u.usage_date),
+		       MIN(NULLIF(u.billing_currency, '')), SUM(u.billing_pre_tax_total), COUNT(*)
+		FROM usages u
+		WHERE u.invoice_number = ANY($1) AND u.partner_id IS NOT NULL AND u.customer_id IS NOT NULL
+		GROUP BY u.invoice_number, u.partner_id, u.customer_id
+		ON CONFLICT (invoice_number, partner_id, customer_id) DO UPDATE SET
+			period_start = EXCLUDED.period_start,
+			period_end = EXCLUDED.period_end,
+			currency = EXCLUDED.currency,
+			pre_tax_total = EXCLUDED.pre_tax_total,
+			line_count = EXCLUDED.line_count,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	if _, err := q.Exec(ctx, query, invoiceNumbers); err != nil {
+		return fmt.Errorf("erro ao atualizar faturas: %w", err)
+	}
+	return nil
+}
+
+// SetInvoiceControlTotals grava os totais de controle informados no arquivo importado, sem
+// substituir um total informado pelo usuário
+func (r *Repository) SetInvoiceControlTotals(ctx context.Context, controls []models.InvoiceControl) error {
+	if len(controls) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE invoices
+		SET control_total = $4, control_source = 'file', updated_at = CURRENT_TIMESTAMP
+		WHERE invoice_number = $1 AND partner_id = $2 AND customer_id = $3
+		  AND (control_source IS NULL OR control_source = 'file')
+	`
+
+	batch := &pgx.Batch{}
+	for _, c := range controls {
+		batch.Queue(query, c.InvoiceNumber, c.PartnerID, c.CustomerID, c.Total)
+	}
+
+	results := r.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range controls {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("erro ao gravar total de controle: %w", err)
+		}
+	}
+	return nil
+}
+
+const invoiceSelect = `
+		SELECT i.id, i.invoice_number, i.partner_id, i.customer_id, COALESCE(p.partner_name, ''), COALESCE(c.customer_name, ''),
+		       i.period_start, i.period_end, i.currency, i.pre_tax_total::float8, i.line_count,
+		       i.control_total::float8, i.control_source, i.reconciliation_status, i.difference::float8, i.reconciled_at,
+		       i.created_at, i.updated_at
+		FROM invoices i
+		LEFT JOIN partners p ON i.partner_id = p.id
+		LEFT JOIN customers c ON i.customer_id = c.id`
+
+func scanInvoice(row pgx.Row, i *models.Invoice) error {
+	return row.Scan(
+		&i.ID, &i.InvoiceNumber, &i.PartnerID, &i.CustomerID, &i.PartnerName, &i.CustomerName,
+		&i.PeriodStart, &i.PeriodEnd, &i.Currency, &i.PreTaxTotal, &i.LineCount,
+		&i.ControlTotal, &i.ControlSource, &i.ReconciliationStatus, &i.Difference, &i.ReconciledAt,
+		&i.CreatedAt, &i.UpdatedAt,
+	)
+}
+
+func (r *Repository) queryInvoices(ctx context.Context, query string, args ...interface{}) ([]models.Invoice, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar faturas: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []models.Invoice
+	for rows.Next() {
+		var i models.Invoice
+		if err := scanInvoice(rows, &i); err != nil {
+			return nil, fmt.Errorf("erro ao escanear fatura: %w", err)
+		}
+		invoices = append(invoices, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de faturas: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// ListInvoices retorna as faturas de acordo com os filtros, mais recentes primeiro
+func (r *Repository) ListInvoices(ctx context.Context, filter models.InvoiceFilter) ([]models.Invoice, error) {
+	var where []string
+	var args []interface{}
+
+	if filter.PartnerID > 0 {
+		args = append(args, filter.PartnerID)
+		where = append(where, fmt.Sprintf("i.partner_id = $%d", len(args)))
+	}
+	if filter.CustomerID > 0 {
+		args = append(args, filter.CustomerID)
+		where = append(where, fmt.Sprintf("i.customer_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where = append(where, fmt.Sprintf("i.reconciliation_status = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where = append(where, fmt.Sprintf("i.period_end >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where = append(where, fmt.Sprintf("i.period_start < $%d", len(args)))
+	}
+
+	query := invoiceSelect
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n\t\tORDER BY i.period_start DESC, i.invoice_number, i.id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf("\n\t\tLIMIT $%d", len(args))
+	}
+
+	return r.queryInvoices(ctx, query, args...)
+}
+
+// GetInvoicesByNumbers retorna as faturas com os números informados
+func (r *Repository) GetInvoicesByNumbers(ctx context.Context, invoiceNumbers []string) ([]models.Invoice, error) {
+	if len(invoiceNumbers) == 0 {
+		return nil, nil
+	}
+	return r.queryInvoices(ctx, invoiceSelect+"\n\t\tWHERE i.invoice_number = ANY($1)\n\t\tORDER BY i.id", invoiceNumbers)
+}
+
+// GetInvoiceByID busca uma fatura; retorna nil se não existir
+func (r *Repository) GetInvoiceByID(ctx context.Context, id int) (*models.Invoice, error) {
+	var i models.Invoice
+	err := scanInvoice(r.db.QueryRow(ctx, invoiceSelect+"\n\t\tWHERE i.id = $1", id), &i)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar fatura: %w", err)
+	}
+	return &i, nil
+}
+
+// GetInvoiceLines retorna as usages que compõem a fatura
+func (r *Repository) GetInvoiceLines(ctx context.Context, invoice *models.Invoice) ([]models.Usage, error) {
+	query := `
+		SELECT u.id, u.invoice_number, u.charge_start_date, u.usage_date, u.quantity::float8,
+		       u.unit_price::float8, u.billing_pre_tax_total::float8, COALESCE(u.resource_location, ''), COALESCE(u.tags, ''),
+		       COALESCE(u.benefit_type, ''), COALESCE(u.billing_currency, ''), u.partner_id, u.customer_id, u.product_id,
+		       u.created_at, u.updated_at,
+		       pr.product_id, pr.product_name, pr.category
+		FROM usages u
+		LEFT JOIN products pr ON u.product_id = pr.id
+		WHERE u.invoice_number = $1 AND u.partner_id = $2 AND u.customer_id = $3
+		ORDER BY u.usage_date, u.id
+	`
+
+	rows, err := r.db.Query(ctx, query, invoice.InvoiceNumber, invoice.PartnerID, invoice.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar linhas da fatura: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []models.Usage
+	for rows.Next() {
+		var usage models.Usage
+		var product models.Product
+		err := rows.Scan(
+			&usage.ID, &usage.InvoiceNumber, &usage.ChargeStartDate, &usage.UsageDate,
+			&usage.Quantity, &usage.UnitPrice, &usage.BillingPreTaxTotal,
+			&usage.ResourceLocation, &usage.Tags, &usage.BenefitType, &usage.BillingCurrency,
+			&usage.PartnerID, &usage.CustomerID, &usage.ProductID,
+			&usage.CreatedAt, &usage.UpdatedAt,
+			&product.ProductID, &product.ProductName, &product.Category,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear linha da fatura: %w", err)
+		}
+		usage.Product = &product
+		lines = append(lines, usage)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de linhas da fatura: %w", err)
+	}
+
+	return lines, nil
+}
+
+// GetInvoiceLineStats soma as linhas atuais da fatura e lista as moedas encontradas
+func (r *Repository) GetInvoiceLineStats(ctx context.Context, invoice *models.Invoice) (float64, int, []string, error) {
+	query := `
+		SELECT COALESCE(SUM(billing_pre_tax_total), 0)::float8, COUNT(*),
+		       COALESCE(ARRAY_AGG(DISTINCT billing_currency ORDER BY billing_currency) FILTER (WHERE billing_currency <> ''), '{}')
+		FROM usages
+		WHERE invoice_number = $1 AND partner_id = $2 AND customer_id = $3
+	`
+
+	var sum float64
+	var count int
+	var currencies []string
+	err := r.db.QueryRow(ctx, query, invoice.InvoiceNumber, invoice.PartnerID, invoice.CustomerID).Scan(&sum, &count, &currencies)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("erro ao somar linhas da fatura: %w", err)
+	}
+	return sum, count, currencies, nil
+}
+
+// UpdateInvoiceReconciliation grava o resultado da conciliação e o total de controle usado
+func (r *Repository) UpdateInvoiceReconciliation(ctx context.Context, rec *models.InvoiceReconciliation) error {
+	query := `
+		UPDATE invoices
+		SET control_total = $2, control_source = $3, reconciliation_status = $4, difference = $5,
+		    pre_tax_total = $6, line_count = $7, reconciled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, rec.InvoiceID, rec.ControlTotal, rec.ControlSource, rec.Status, rec.Difference, rec.LineSum, rec.LineCount)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar conciliação da fatura: %w", err)
+	}
+	return nil
+}
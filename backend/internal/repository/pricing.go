@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const priceRuleColumns = `id, name, rule_type, customer_id, product_id, category, percent::float8, tiers,
+	effective_from, effective_to, active, created_at, updated_at`
+
+func scanPriceRule(row pgx.Row, p *models.PriceRule) error {
+	return row.Scan(&p.ID, &p.Name, &p.RuleType, &p.CustomerID, &p.ProductID, &p.Category, &p.Percent, &p.Tiers,
+		&p.EffectiveFrom, &p.EffectiveTo, &p.Active, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// GetPriceRules retorna as regras de preço. Com from e to, apenas as ativas vigentes em algum
+// dia do intervalo [from, to].
+func (r *Repository) GetPriceRules(ctx context.Context, from, to *time.Time) ([]models.PriceRule, error) {
+	var args []interface{}
+	var where []string
+	if from != nil || to != nil {
+		where = append(where, "active")
+	}
+	if from != nil {
+		args = append(args, *from)
+		where = append(where, fmt.Sprintf("(effective_to IS NULL OR effective_to > $%d)", len(args)))
+	}
+	if to != nil {
+		args = append(args, *to)
+		where = append(where, fmt.Sprintf("effective_from <= $%d", len(args)))
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT `+priceRuleColumns+` FROM price_rules`+whereClause(where)+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar regras de preço: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.PriceRule
+	for rows.Next() {
+		var p models.PriceRule
+		if err := scanPriceRule(rows, &p); err != nil {
+			return nil, fmt.Errorf("erro ao escanear regra de preço: %w", err)
+		}
+		rules = append(rules, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de regras de preço: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetPriceRuleByID busca uma regra de preço; retorna nil se não existir
+func (r *Repository) GetPriceRuleByID(ctx context.Context, id int) (*models.PriceRule, error) {
+	var p models.PriceRule
+	err := scanPriceRule(r.db.QueryRow(ctx, `SELECT `+priceRuleColumns+` FROM price_rules WHERE id = $1`, id), &p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar regra de preço: %w", err)
+	}
+	return &p, nil
+}
+
+// InsertPriceRule cria uma regra de preço
+func (r *Repository) InsertPriceRule(ctx context.Context, p *models.PriceRule) error {
+	query := `
+		INSERT INTO price_rules (name, rule_type, customer_id, product_id, category, percent, tiers,
+		                         effective_from, effective_to, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + priceRuleColumns
+
+	err := scanPriceRule(r.db.QueryRow(ctx, query, p.Name, p.RuleType, p.CustomerID, p.ProductID, p.Category,
+		p.Percent, p.Tiers, p.EffectiveFrom, p.EffectiveTo, p.Active), p)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir regra de preço: %w", err)
+	}
+	return nil
+}
+
+// UpdatePriceRule atualiza uma regra de preço; retorna false se não existir
+func (r *Repository) UpdatePriceRule(ctx context.Context, p *models.PriceRule) (bool, error) {
+	query := `
+		UPDATE price_rules
+		SET name = $2, rule_type = $3, customer_id = $4, product_id = $5, category = $6, percent = $7,
+		    tiers = $8, effective_from = $9, effective_to = $10, active = $11, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + priceRuleColumns
+
+	err := scanPriceRule(r.db.QueryRow(ctx, query, p.ID, p.Name, p.RuleType, p.CustomerID, p.ProductID, p.Category,
+		p.Percent, p.Tiers, p.EffectiveFrom, p.EffectiveTo, p.Active), p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar regra de preço: %w", err)
+	}
+	return true, nil
+}
+
+// DeletePriceRule remove uma regra de preço
+func (r *Repository) DeletePriceRule(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM price_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover regra de preço: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetPricingUsages retorna custo e quantidade por cliente, produto e dia no intervalo [from, to]
+func (r *Repository) GetPricingUsages(ctx context.Context, from, to *time.Time) ([]models.PricingUsage, error) {
+	var args []interface{}
+	var where []string
+	if from != nil {
+		args = append(args, *from)
+		where = append(where, fmt.Sprintf("u.usage_date >= $%d", len(args)))
+	}
+	if to != nil {
+		args = append(args, *to)
+		where = append(where, fmt.Sprintf("u.usage_date <= $%d", len(args)))
+	}
+
+	query := `
+		SELECT c.id, c.customer_id, c.customer_name, pr.id, pr.product_id, pr.product_name, COALESCE(pr.category, ''),
+		       u.usage_date, SUM(u.quantity)::float8, SUM(u.billing_pre_tax_total)::float8
+		FROM usages u
+		JOIN ` + customerJoin + `
+		JOIN ` + productJoin + whereClause(where) + `
+		GROUP BY c.id, c.customer_id, c.customer_name, pr.id, pr.product_id, pr.product_name, pr.category, u.usage_date
+		ORDER BY u.usage_date, c.id, pr.id
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar custos para precificação: %w", err)
+	}
+	defer rows.Close()
+
+	var usages []models.PricingUsage
+	for rows.Next() {
+		var u models.PricingUsage
+		if err := rows.Scan(&u.CustomerID, &u.CustomerKey, &u.Customer, &u.ProductID, &u.ProductKey, &u.Product,
+			&u.Category, &u.UsageDate, &u.Quantity, &u.Cost); err != nil {
+			return nil, fmt.Errorf("erro ao escanear custo para precificação: %w", err)
+		}
+		usages = append(usages, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de custos para precificação: %w", err)
+	}
+
+	return usages, nil
+}
+
+// GetPricingMonthlyQuantities retorna a quantidade por cliente, produto e mês de calendário, somando
+// o mês inteiro de cada mês tocado pelo intervalo [from, to]
+func (r *Repository) GetPricingMonthlyQuantities(ctx context.Context, from, to *time.Time) ([]models.PricingQuantity, error) {
+	var args []interface{}
+	var where []string
+	if from != nil {
+		args = append(args, *from)
+		where = append(where, fmt.Sprintf("u.usage_date >= date_trunc('month', $%d::date)", len(args)))
+	}
+	if to != nil {
+		args = append(args, *to)
+		where = append(where, fmt.Sprintf("u.usage_date < date_trunc('month', $%d::date) + INTERVAL '1 month'", len(args)))
+	}
+
+	query := `
+		SELECT u.customer_id, u.product_id, date_trunc('month', u.usage_date)::date, SUM(u.quantity)::float8
+		FROM usages u` + whereClause(where) + `
+		GROUP BY u.customer_id, u.product_id, 3
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar quantidades mensais para precificação: %w", err)
+	}
+	defer rows.Close()
+
+	var quantities []models.PricingQuantity
+	for rows.Next() {
+		var q models.PricingQuantity
+		if err := rows.Scan(&q.CustomerID, &q.ProductID, &q.Month, &q.Quantity); err != nil {
+			return nil, fmt.Errorf("erro ao escanear quantidade mensal para precificação: %w", err)
+		}
+		quantities = append(quantities, q)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de quantidades mensais para precificação: %w", err)
+	}
+
+	return quantities, nil
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const qualityRuleColumns = `id, name, rule_type, params, severity, active, created_at, updated_at`
+
+func scanQualityRule(row pgx.Row, q *models.QualityRule) error {
+	return row.Scan(&q.ID, &q.Name, &q.RuleType, &q.Params, &q.Severity, &q.Active, &q.CreatedAt, &q.UpdatedAt)
+}
+
+// GetQualityRules retorna as regras de qualidade; activeOnly filtra as regras ativas
+func (r *Repository) GetQualityRules(ctx context.Context, activeOnly bool) ([]models.QualityRule, error) {
+	query := `SELECT ` + qualityRuleColumns + ` FROM quality_rules`
+	if activeOnly {
+		query += ` WHERE active`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar regras de qualidade: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.QualityRule
+	for rows.Next() {
+		var q models.QualityRule
+		if err := scanQualityRule(rows, &q); err != nil {
+			return nil, fmt.Errorf("erro ao escanear regra de qualidade: %w", err)
+		}
+		rules = append(rules, q)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de regras de qualidade: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetQualityRuleByID busca uma regra de qualidade; retorna nil se não existir
+func (r *Repository) GetQualityRuleByID(ctx context.Context, id int) (*models.QualityRule, error) {
+	var q models.QualityRule
+	err := scanQualityRule(r.db.QueryRow(ctx, `SELECT `+qualityRuleColumns+` FROM quality_rules WHERE id = $1`, id), &q)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar regra de qualidade: %w", err)
+	}
+	return &q, nil
+}
+
+// InsertQualityRule cria uma regra de qualidade
+func (r *Repository) InsertQualityRule(ctx context.Context, q *models.QualityRule) error {
+	query := `
+		INSERT INTO quality_rules (name, rule_type, params, severity, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + qualityRuleColumns
+
+	err := scanQualityRule(r.db.QueryRow(ctx, query, q.Name, q.RuleType, q.Params, q.Severity, q.Active), q)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir regra de qualidade: %w", err)
+	}
+	return nil
+}
+
+// UpdateQualityRule atualiza uma regra de qualidade; retorna false se não existir
+func (r *Repository) UpdateQualityRule(ctx context.Context, q *models.QualityRule) (bool, error) {
+	query := `
+		UPDATE quality_rules
+		SET name = $2, rule_type = $3, params = $4, severity = $5, active = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + qualityRuleColumns
+
+	err := scanQualityRule(r.db.QueryRow(ctx, query, q.ID, q.Name, q.RuleType, q.Params, q.Severity, q.Active), q)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar regra de qualidade: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteQualityRule remove uma regra de qualidade; os resultados já gravados são mantidos
+func (r *Repository) DeleteQualityRule(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM quality_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover regra de qualidade: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// InsertQualityBatch grava o lote avaliado e suas violações
+func (r *Repository) InsertQualityBatch(ctx context.Context, b *models.QualityBatch) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação do lote de qualidade: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO quality_batches (file_name, checksum, rows_checked, rejected_rows, warned_rows)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, $4, $5)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRow(ctx, query, b.FileName, b.Checksum, b.RowsChecked, b.RejectedRows, b.WarnedRows).Scan(&b.ID, &b.CreatedAt); err != nil {
+		return fmt.Errorf("erro ao inserir lote de qualidade: %w", err)
+	}
+
+	if err := insertQualityResults(ctx, tx, b.ID, b.Results); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("erro ao confirmar lote de qualidade: %w", err)
+	}
+	return nil
+}
+
+// AppendQualityBatch soma ao lote batchID as contagens e as violações de mais um trecho da mesma
+// importação e devolve em b os totais acumulados
+func (r *Repository) AppendQualityBatch(ctx context.Context, batchID int, b *models.QualityBatch) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação do lote de qualidade: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE quality_batches
+		SET rows_checked = rows_checked + $2, rejected_rows = rejected_rows + $3, warned_rows = warned_rows + $4
+		WHERE id = $1
+		RETURNING ` + qualityBatchColumns
+	if err := scanQualityBatch(tx.QueryRow(ctx, query, batchID, b.RowsChecked, b.RejectedRows, b.WarnedRows), b); err != nil {
+		return fmt.Errorf("erro ao atualizar lote de qualidade: %w", err)
+	}
+
+	if err := insertQualityResults(ctx, tx, batchID, b.Results); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("erro ao confirmar lote de qualidade: %w", err)
+	}
+	return nil
+}
+
+func insertQualityResults(ctx context.Context, tx pgx.Tx, batchID int, qualityResults []models.QualityResult) error {
+	if len(qualityResults) == 0 {
+		return nil
+	}
+
+	resultQuery := `
+		INSERT INTO quality_results (batch_id, rule_id, rule_name, severity, row_number, invoice_number, customer_id, product_id, message)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9)
+	`
+	batch := &pgx.Batch{}
+	for _, res := range qualityResults {
+		batch.Queue(resultQuery, batchID, res.RuleID, res.RuleName, res.Severity, res.RowNumber,
+			res.InvoiceNumber, res.CustomerID, res.ProductID, res.Message)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for range qualityResults {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return fmt.Errorf("erro ao inserir resultado de qualidade: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("erro ao finalizar resultados de qualidade: %w", err)
+	}
+	return nil
+}
+
+const qualityBatchColumns = `id, COALESCE(file_name, ''), COALESCE(checksum, ''), rows_checked, rejected_rows, warned_rows, created_at`
+
+func scanQualityBatch(row pgx.Row, b *models.QualityBatch) error {
+	return row.Scan(&b.ID, &b.FileName, &b.Checksum, &b.RowsChecked, &b.RejectedRows, &b.WarnedRows, &b.CreatedAt)
+}
+
+// ListQualityBatches retorna os lotes avaliados, mais recentes primeiro
+func (r *Repository) ListQualityBatches(ctx context.Context, limit int) ([]models.QualityBatch, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+qualityBatchColumns+` FROM quality_batches ORDER BY id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar lotes de qualidade: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []models.QualityBatch
+	for rows.Next() {
+		var b models.QualityBatch
+		if err := scanQualityBatch(rows, &b); err != nil {
+			return nil, fmt.Errorf("erro ao escanear lote de qualidade: %w", err)
+		}
+		batches = append(batches, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de lotes de qualidade: %w", err)
+	}
+
+	return batches, nil
+}
+
+// GetQualityBatchByID busca um lote avaliado; retorna nil se não existir
+func (r *Repository) GetQualityBatchByID(ctx context.Context, id int) (*models.QualityBatch, error) {
+	var b models.QualityBatch
+	err := scanQualityBatch(r.db.QueryRow(ctx, `SELECT `+qualityBatchColumns+` FROM quality_batches WHERE id = $1`, id), &b)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar lote de qualidade: %w", err)
+	}
+	return &b, nil
+}
+
+// GetQualityResults retorna as violações de um lote; severity vazio traz todas
+func (r *Repository) GetQualityResults(ctx context.Context, batchID int, severity string) ([]models.QualityResult, error) {
+	query := `
+		SELECT id, batch_id, rule_id, rule_name, severity, row_number,
+		       COALESCE(invoice_number, ''), COALESCE(customer_id, ''), COALESCE(product_id, ''), message, created_at
+		FROM quality_results
+		WHERE batch_id = $1 AND ($2 = '' OR severity = $2)
+		ORDER BY row_number, id
+	`
+
+	rows, err := r.db.Query(ctx, query, batchID, severity)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar resultados de qualidade: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.QualityResult
+	for rows.Next() {
+		var res models.QualityResult
+		err := rows.Scan(&res.ID, &res.BatchID, &res.RuleID, &res.RuleName, &res.Severity, &res.RowNumber,
+			&res.InvoiceNumber, &res.CustomerID, &res.ProductID, &res.Message, &res.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear resultado de qualidade: %w", err)
+		}
+		results = append(results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de resultados de qualidade: %w", err)
+	}
+
+	return results, nil
+}
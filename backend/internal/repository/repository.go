@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,10 +20,17 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{db: db}
 }
 
+// querier é atendido pelo pool e por uma transação em andamento, para consultas que também
+// precisam rodar dentro de uma transação maior
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
 // GetAllCustomers retorna todos os clientes
 func (r *Repository) GetAllCustomers(ctx context.Context) ([]models.Customer, error) {
 	query := `
-		SELECT id, customer_id, customer_name, customer_domain_name, country, created_at, updated_at
+		SELECT id, customer_id, customer_name, customer_domain_name, country, group_id, COALESCE(group_source, ''), created_at, updated_at
 		FROM customers
 		ORDER BY customer_name
 	`
@@ -42,6 +50,8 @@ func (r *Repository) GetAllCustomers(ctx context.Context) ([]models.Customer, er
 			&customer.CustomerName,
 			&customer.CustomerDomainName,
 			&customer.Country,
+			&customer.GroupID,
+			&customer.GroupSource,
 			&customer.CreatedAt,
 			&customer.UpdatedAt,
 		)
@@ -54,30 +64,24 @@ func (r *Repository) GetAllCustomers(ctx context.Context) ([]models.Customer, er
 	return customers, nil
 }
 
-// parseUsageDate tenta converter uma string para time.Time
-func parseUsageDate(s string) (time.Time, error) {
-    layouts := []string{
-        "02-01-06",    // DD-MM-YY
-        "01-02-06",    // MM-DD-YY
-        "02/01/06",    // DD/MM/YY
-        "01/02/06",    // MM/DD/YY
-        "2006-01-02",  // YYYY-MM-DD
-        "01/02/2006",  // MM/DD/YYYY
-        "02/01/2006",  // DD/MM/YYYY
-    }
-
-    for _, layout := range layouts {
-        if t, err := time.Parse(layout, s); err == nil {
-            return t, nil
-        }
-    }
+// GetUsageByCustomer retorna o uso de um cliente específico
+func (r *Repository) GetUsageByCustomer(ctx context.Context, customerID int) ([]models.Usage, error) {
+	return r.getUsagesBy(ctx, "customer_id", customerID)
+}
 
-    return time.Time{}, fmt.Errorf("formato de data inválido: %s", s)
+// GetUsageByPartner retorna o uso de um parceiro específico
+func (r *Repository) GetUsageByPartner(ctx context.Context, partnerID int) ([]models.Usage, error) {
+	return r.getUsagesBy(ctx, "partner_id", partnerID)
 }
 
+// GetUsageByProduct retorna o uso de um produto específico
+func (r *Repository) GetUsageByProduct(ctx context.Context, productID int) ([]models.Usage, error) {
+	return r.getUsagesBy(ctx, "product_id", productID)
+}
 
-// GetUsageByCustomer retorna o uso de um cliente específico
-func (r *Repository) GetUsageByCustomer(ctx context.Context, customerID int) ([]models.Usage, error) {
+// getUsagesBy retorna os usos filtrados pela chave estrangeira informada (partner_id,
+// customer_id ou product_id), mais recentes primeiro
+func (r *Repository) getUsagesBy(ctx context.Context, column string, id int) ([]models.Usage, error) {
 	query := `
 		SELECT u.id, u.invoice_number, u.charge_start_date, u.usage_date, u.quantity, 
 		       u.unit_price, u.billing_pre_tax_total, u.resource_location, u.tags, 
@@ -89,13 +93,13 @@ func (r *Repository) GetUsageByCustomer(ctx context.Context, customerID int) ([]
 		LEFT JOIN partners p ON u.partner_id = p.id
 		LEFT JOIN customers c ON u.customer_id = c.id
 		LEFT JOIN products pr ON u.product_id = pr.id
-		WHERE u.customer_id = $1
+		WHERE u.` + column + ` = $1
 		ORDER BY u.usage_date DESC
 	`
 	
-	rows, err := r.db.Query(ctx, query, customerID)
+	rows, err := r.db.Query(ctx, query, id)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar uso do cliente: %w", err)
+		return nil, fmt.Errorf("erro ao buscar usos: %w", err)
 	}
 	defer rows.Close()
 
@@ -129,19 +133,23 @@ func (r *Repository) GetUsageByCustomer(ctx context.Context, customerID int) ([]
 	return usages, nil
 }
 
-// GetBillingMonthly retorna faturamento por mês
-func (r *Repository) GetBillingMonthly(ctx context.Context) ([]models.BillingReport, error) {
+// GetBillingMonthly retorna faturamento por mês; com asOf, como reportado naquele instante
+func (r *Repository) GetBillingMonthly(ctx context.Context, asOf *time.Time) ([]models.BillingReport, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+
 	query := `
 		SELECT 
-			TO_CHAR(usage_date, 'YYYY-MM') as month,
-			SUM(billing_pre_tax_total) as total,
+			TO_CHAR(u.usage_date, 'YYYY-MM') as month,
+			SUM(u.billing_pre_tax_total) as total,
 			COUNT(*) as count
-		FROM usages
-		GROUP BY TO_CHAR(usage_date, 'YYYY-MM')
+		FROM usages u` + whereClause(where) + `
+		GROUP BY TO_CHAR(u.usage_date, 'YYYY-MM')
 		ORDER BY month DESC
 	`
 	
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar faturamento mensal: %w", err)
 	}
@@ -164,8 +172,13 @@ func (r *Repository) GetBillingMonthly(ctx context.Context) ([]models.BillingRep
 	return reports, nil
 }
 
-// GetBillingByProduct retorna faturamento por produto
-func (r *Repository) GetBillingByProduct(ctx context.Context) ([]models.BillingByProduct, error) {
+// GetBillingByProduct retorna faturamento por produto; com asOf, como reportado naquele instante
+func (r *Repository) GetBillingByProduct(ctx context.Context, asOf *time.Time) ([]models.BillingByProduct, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+	_, _, productJoin := asOfJoins(asOf)
+
 	query := `
 		SELECT 
 			pr.id,
@@ -175,12 +188,12 @@ func (r *Repository) GetBillingByProduct(ctx context.Context) ([]models.BillingB
 			SUM(u.billing_pre_tax_total) as total,
 			COUNT(*) as count
 		FROM usages u
-		JOIN products pr ON u.product_id = pr.id
+		JOIN ` + productJoin + whereClause(where) + `
 		GROUP BY pr.id, pr.product_id, pr.product_name, pr.category
 		ORDER BY total DESC
 	`
 	
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar faturamento por produto: %w", err)
 	}
@@ -199,20 +212,25 @@ func (r *Repository) GetBillingByProduct(ctx context.Context) ([]models.BillingB
 	return reports, nil
 }
 
-// GetBillingByCategory retorna o faturamento por categoria
-func (r *Repository) GetBillingByCategory(ctx context.Context) ([]models.CategoryBillingReport, error) {
+// GetBillingByCategory retorna o faturamento por categoria; com asOf, como reportado naquele instante
+func (r *Repository) GetBillingByCategory(ctx context.Context, asOf *time.Time) ([]models.CategoryBillingReport, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+	_, _, productJoin := asOfJoins(asOf)
+
 	query := `
 		SELECT 
 			pr.category,
 			SUM(u.billing_pre_tax_total) as total,
 			COUNT(*) as count
 		FROM usages u
-		JOIN products pr ON u.product_id = pr.id
+		JOIN ` + productJoin + whereClause(where) + `
 		GROUP BY pr.category
 		ORDER BY total DESC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao consultar faturamento por categoria: %w", err)
 	}
@@ -230,19 +248,23 @@ func (r *Repository) GetBillingByCategory(ctx context.Context) ([]models.Categor
 	return reports, nil
 }
 
-// GetBillingByResource retorna o faturamento por recurso
-func (r *Repository) GetBillingByResource(ctx context.Context) ([]models.ResourceBillingReport, error) {
+// GetBillingByResource retorna o faturamento por recurso; com asOf, como reportado naquele instante
+func (r *Repository) GetBillingByResource(ctx context.Context, asOf *time.Time) ([]models.ResourceBillingReport, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+
 	query := `
 		SELECT 
 			u.resource_location as resource,
 			SUM(u.billing_pre_tax_total) as total,
 			COUNT(*) as count
-		FROM usages u
+		FROM usages u` + whereClause(where) + `
 		GROUP BY u.resource_location
 		ORDER BY total DESC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao consultar faturamento por recurso: %w", err)
 	}
@@ -260,8 +282,13 @@ func (r *Repository) GetBillingByResource(ctx context.Context) ([]models.Resourc
 	return reports, nil
 }
 
-// GetBillingByCustomer retorna o faturamento por cliente
-func (r *Repository) GetBillingByCustomer(ctx context.Context) ([]models.CustomerBillingReport, error) {
+// GetBillingByCustomer retorna o faturamento por cliente; com asOf, como reportado naquele instante
+func (r *Repository) GetBillingByCustomer(ctx context.Context, asOf *time.Time) ([]models.CustomerBillingReport, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+	_, customerJoin, _ := asOfJoins(asOf)
+
 	query := `
 		SELECT 
 			c.customer_id,
@@ -269,12 +296,12 @@ func (r *Repository) GetBillingByCustomer(ctx context.Context) ([]models.Custome
 			SUM(u.billing_pre_tax_total) as total,
 			COUNT(*) as count
 		FROM usages u
-		JOIN customers c ON u.customer_id = c.id
+		JOIN ` + customerJoin + whereClause(where) + `
 		GROUP BY c.customer_id, c.customer_name
 		ORDER BY total DESC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao consultar faturamento por cliente: %w", err)
 	}
@@ -292,8 +319,13 @@ func (r *Repository) GetBillingByCustomer(ctx context.Context) ([]models.Custome
 	return reports, nil
 }
 
-// GetKPIData retorna os dados de KPI do sistema
-func (r *Repository) GetKPIData(ctx context.Context) (*models.KPIData, error) {
+// GetKPIData retorna os dados de KPI do sistema; com asOf, como reportados naquele instante
+func (r *Repository) GetKPIData(ctx context.Context, asOf *time.Time) (*models.KPIData, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+	_, customerJoin, productJoin := asOfJoins(asOf)
+
 	query := `
 		WITH stats AS (
 			SELECT 
@@ -306,18 +338,18 @@ func (r *Repository) GetKPIData(ctx context.Context) (*models.KPIData, error) {
 			FROM 
 				usages u
 			JOIN 
-				products pr ON u.product_id = pr.id
+				` + productJoin + `
 			JOIN 
-				customers c ON u.customer_id = c.id
+				` + customerJoin + `
 			LEFT JOIN (
 				SELECT 
-					DATE_TRUNC('month', usage_date) as month,
-					SUM(billing_pre_tax_total) as total
+					DATE_TRUNC('month', u.usage_date) as month,
+					SUM(u.billing_pre_tax_total) as total
 				FROM 
-					usages
+					usages u` + whereClause(where) + `
 				GROUP BY 
-					DATE_TRUNC('month', usage_date)
-			) monthly ON TRUE
+					DATE_TRUNC('month', u.usage_date)
+			) monthly ON TRUE` + whereClause(where) + `
 		)
 		SELECT 
 			total_records,
@@ -333,7 +365,7 @@ func (r *Repository) GetKPIData(ctx context.Context) (*models.KPIData, error) {
 	var kpiData models.KPIData
 	var lastUpdated time.Time
 
-	err := r.db.QueryRow(ctx, query).Scan(
+	err := r.db.QueryRow(ctx, query, args...).Scan(
 		&kpiData.TotalRecords,
 		&kpiData.TotalCategories,
 		&kpiData.TotalResources,
@@ -354,8 +386,13 @@ func (r *Repository) GetKPIData(ctx context.Context) (*models.KPIData, error) {
 	return &kpiData, nil
 }
 
-// GetBillingByPartner retorna faturamento por parceiro
-func (r *Repository) GetBillingByPartner(ctx context.Context) ([]models.BillingByPartner, error) {
+// GetBillingByPartner retorna faturamento por parceiro; com asOf, como reportado naquele instante
+func (r *Repository) GetBillingByPartner(ctx context.Context, asOf *time.Time) ([]models.BillingByPartner, error) {
+	var args []interface{}
+	var where []string
+	addAsOfFilter(asOf, &args, &where)
+	partnerJoin, _, _ := asOfJoins(asOf)
+
 	query := `
 		SELECT 
 			p.partner_id,
@@ -363,12 +400,12 @@ func (r *Repository) GetBillingByPartner(ctx context.Context) ([]models.BillingB
 			SUM(u.billing_pre_tax_total) as total,
 			COUNT(*) as count
 		FROM usages u
-		JOIN partners p ON u.partner_id = p.id
+		JOIN ` + partnerJoin + whereClause(where) + `
 		GROUP BY p.partner_id, p.partner_name
 		ORDER BY total DESC
 	`
 	
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar faturamento por parceiro: %w", err)
 	}
@@ -486,8 +523,9 @@ func (r *Repository) InsertUsage(ctx context.Context, usage *models.Usage) error
 }
 
 
-// BulkInsertUsages insere múltiplos registros de uso em lote
-func (r *Repository) BulkInsertUsages(ctx context.Context, usages []models.Usage) error {
+// BulkInsertUsages insere múltiplos registros de uso em lote. Se file for informado, o arquivo é
+// registrado no histórico de importações na mesma transação dos usos.
+func (r *Repository) BulkInsertUsages(ctx context.Context, usages []models.Usage, file *models.ImportedFile) error {
 	if len(usages) == 0 {
 		log.Printf("⚠️ Nenhum registro de uso para inserir")
 		return nil
@@ -540,9 +578,14 @@ func (r *Repository) BulkInsertUsages(ctx context.Context, usages []models.Usage
 			usage.ResourceLocation,
 			usage.Tags,
 			usage.BenefitType,
+			usage.BillingCurrency,
 			usage.PartnerID,
 			usage.CustomerID,
 			usage.ProductID,
+			nullableID(usage.PartnerVersionID),
+			nullableID(usage.CustomerVersionID),
+			nullableID(usage.ProductVersionID),
+			tagMapValue(usage.TagMap),
 		}
 	}
 
@@ -567,9 +610,14 @@ func (r *Repository) BulkInsertUsages(ctx context.Context, usages []models.Usage
 			"resource_location",
 			"tags",
 			"benefit_type",
+			"billing_currency",
 			"partner_id",
 			"customer_id",
 			"product_id",
+			"partner_version_id",
+			"customer_version_id",
+			"product_version_id",
+			"tag_map",
 		},
 		pgx.CopyFromRows(rows),
 	)
@@ -579,6 +627,12 @@ func (r *Repository) BulkInsertUsages(ctx context.Context, usages []models.Usage
 		return fmt.Errorf("erro ao inserir usos em lote: %w", err)
 	}
 
+	if file != nil {
+		if err := insertImportedFile(ctx, tx, file); err != nil {
+			return err
+		}
+	}
+
 	// Commit da transação
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("❌ Erro ao finalizar transação: %v", err)
@@ -590,25 +644,76 @@ func (r *Repository) BulkInsertUsages(ctx context.Context, usages []models.Usage
 }
 
 
+// nullableID converte ids não informados (0) em NULL
+func nullableID(id int) interface{} {
+	if id <= 0 {
+		return nil
+	}
+	return id
+}
+
+// tagMapValue converte as tags interpretadas para a coluna JSONB; sem tags grava um objeto vazio
+func tagMapValue(tagMap map[string]string) map[string]string {
+	if tagMap == nil {
+		return map[string]string{}
+	}
+	return tagMap
+}
+
+// ClearAllData limpa todos os dados das tabelas e retorna a quantidade de usages removidos.
+// Tudo ocorre em uma transação, e as faturas das usages removidas são recalculadas junto.
+func (r *Repository) ClearAllData(ctx context.Context) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar transação de limpeza: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// As faturas afetadas são lidas antes da remoção para serem recalculadas sem as linhas
+	rows, err := tx.Query(ctx, `SELECT DISTINCT invoice_number FROM usages WHERE COALESCE(invoice_number, '') <> ''`)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar faturas das usages: %w", err)
+	}
+	var invoiceNumbers []string
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("erro ao escanear fatura das usages: %w", err)
+		}
+		invoiceNumbers = append(invoiceNumbers, number)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("erro após iteração de faturas das usages: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM usages")
+	if err != nil {
+		return 0, fmt.Errorf("erro ao remover usages: %w", err)
+	}
+	deletedUsages := tag.RowsAffected()
+
+	if err := refreshInvoices(ctx, tx, invoiceNumbers); err != nil {
+		return 0, err
+	}
 
-// ClearAllData limpa todos os dados das tabelas
-func (r *Repository) ClearAllData(ctx context.Context) error {
 	// Limpar dados na ordem correta (respeitando foreign keys)
 	queries := []string{
-		"DELETE FROM usages",
-		"DELETE FROM products", 
+		"DELETE FROM products",
 		"DELETE FROM customers",
 		"DELETE FROM partners",
 	}
-	
 	for _, query := range queries {
-		_, err := r.db.Exec(ctx, query)
-		if err != nil {
-			return fmt.Errorf("erro ao executar query %s: %w", query, err)
+		if _, err := tx.Exec(ctx, query); err != nil {
+			return 0, fmt.Errorf("erro ao executar query %s: %w", query, err)
 		}
 	}
-	
-	return nil
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("erro ao confirmar limpeza: %w", err)
+	}
+	return deletedUsages, nil
 }
 
 // CreateProcessingMetricsTable cria a tabela de métricas de processamento se não existir
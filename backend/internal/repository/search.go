@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// searchTarget descreve uma entidade pesquisável: tabela, campos comparados e quais deles são
+// título e subtítulo do resultado. Apenas estes nomes chegam ao SQL.
+type searchTarget struct {
+	table    string
+	fields   []string
+	title    string
+	subtitle string
+}
+
+// searchTargets mapeia os tipos de resultado da busca para as entidades
+var searchTargets = map[string]searchTarget{
+	"customer": {"customers", []string{"customer_name", "customer_domain_name"}, "customer_name", "customer_domain_name"},
+	"product":  {"products", []string{"product_name", "sku_name"}, "product_name", "sku_name"},
+	"partner":  {"partners", []string{"partner_name", "mpn_id", "tier2_mpn_id"}, "partner_name", "mpn_id"},
+}
+
+// IsSearchType informa se o tipo de resultado é pesquisável
+func IsSearchType(name string) bool {
+	_, ok := searchTargets[name]
+	return ok
+}
+
+// SearchTypes retorna os tipos pesquisáveis em ordem alfabética
+func SearchTypes() []string {
+	types := make([]string, 0, len(searchTargets))
+	for name := range searchTargets {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// escapeLike escapa os curingas de LIKE para buscar o texto literalmente
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// buildSearchQuery gera a consulta de um tipo. Casa quem contém o texto (ILIKE, $2) ou é
+// parecido com ele (trigram, $1); a pontuação é a maior similaridade entre os campos, com
+// bônus para o texto contido e maior para o início do título ($3). O filtro compara as colunas
+// sem COALESCE para usar os índices trigram da migração 021 (NULL apenas não casa).
+func buildSearchQuery(target searchTarget) string {
+	var columns, similarities, contains, filters []string
+	for _, f := range target.fields {
+		col := "COALESCE(" + f + ", '')"
+		columns = append(columns, col)
+		similarities = append(similarities, "similarity("+col+", $1)")
+		contains = append(contains, col+" ILIKE $2")
+		filters = append(filters, f+" ILIKE $2", f+" % $1")
+	}
+
+	return fmt.Sprintf(`
+		SELECT id, %s,
+		       (GREATEST(%s)
+		        + CASE WHEN COALESCE(%s, '') ILIKE $3 THEN 0.5
+		               WHEN %s THEN 0.25 ELSE 0 END)::float8 AS score
+		FROM %s
+		WHERE %s
+		ORDER BY score DESC, id
+		LIMIT $4`,
+		strings.Join(columns, ", "),
+		strings.Join(similarities, ", "),
+		target.title,
+		strings.Join(contains, " OR "),
+		target.table,
+		strings.Join(filters, " OR "))
+}
+
+// Search busca o texto nos tipos informados e retorna até limit resultados de cada tipo,
+// com os valores dos campos pesquisados
+func (r *Repository) Search(ctx context.Context, text string, types []string, limit int) ([]models.SearchResult, error) {
+	contains := "%" + escapeLike(text) + "%"
+	prefix := escapeLike(text) + "%"
+
+	var results []models.SearchResult
+	for _, name := range types {
+		target, ok := searchTargets[name]
+		if !ok {
+			return nil, fmt.Errorf("tipo de busca inválido: %s", name)
+		}
+
+		rows, err := r.db.Query(ctx, buildSearchQuery(target), text, contains, prefix, limit)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar %s: %w", target.table, err)
+		}
+
+		for rows.Next() {
+			result := models.SearchResult{Type: name, Fields: make(map[string]string, len(target.fields))}
+			values := make([]string, len(target.fields))
+			dest := []interface{}{&result.ID}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			dest = append(dest, &result.Score)
+
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("erro ao escanear resultado de busca: %w", err)
+			}
+			for i, f := range target.fields {
+				result.Fields[f] = values[i]
+			}
+			result.Title = result.Fields[target.title]
+			result.Subtitle = result.Fields[target.subtitle]
+			results = append(results, result)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("erro após iteração de resultados de busca: %w", err)
+		}
+	}
+
+	return results, nil
+}
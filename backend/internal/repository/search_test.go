@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	query := buildSearchQuery(searchTargets["partner"])
+
+	for _, want := range []string{
+		"FROM partners",
+		"similarity(COALESCE(mpn_id, ''), $1)",
+		"COALESCE(tier2_mpn_id, '') ILIKE $2",
+		"COALESCE(partner_name, '') ILIKE $3 THEN 0.5",
+		"WHERE partner_name ILIKE $2 OR partner_name % $1 OR mpn_id ILIKE $2",
+		"tier2_mpn_id % $1",
+		"LIMIT $4",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got:\n%s", want, query)
+		}
+	}
+
+	// o filtro usa as colunas puras, indexadas pela migração 021; COALESCE impediria o uso dos índices
+	where := query[strings.Index(query, "WHERE"):strings.Index(query, "ORDER BY")]
+	if strings.Contains(where, "COALESCE") {
+		t.Errorf("expected WHERE on bare columns, got:\n%s", where)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got := escapeLike(`50%_off\x`); got != `50\%\_off\\x` {
+		t.Errorf("escapeLike() = %q", got)
+	}
+}
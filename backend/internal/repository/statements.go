@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// statementSummaryColumns não inclui o conteúdo (data), apenas o sujeito gravado nele
+const statementSummaryColumns = `id, subject_key, data->'subject', period, version, checksum, total, created_at`
+
+func scanStatementSummary(row pgx.Row, s *models.Statement) error {
+	return row.Scan(&s.ID, &s.SubjectKey, &s.Subject, &s.Period, &s.Version, &s.Checksum, &s.Total, &s.CreatedAt)
+}
+
+const statementColumns = statementSummaryColumns + `, data`
+
+func scanStatement(row pgx.Row, s *models.Statement) error {
+	return row.Scan(&s.ID, &s.SubjectKey, &s.Subject, &s.Period, &s.Version, &s.Checksum, &s.Total, &s.CreatedAt, &s.Data)
+}
+
+// GetStatementLines retorna os usos do sujeito no intervalo [from, to), em ordem estável
+// (sem depender dos ids, que mudam quando um arquivo é reimportado)
+func (r *Repository) GetStatementLines(ctx context.Context, subject models.StatementSubject, from, to time.Time) ([]models.StatementLine, error) {
+	args := []interface{}{from, to}
+	where := []string{"u.usage_date >= $1", "u.usage_date < $2"}
+	switch subject.Type {
+	case models.StatementCustomer:
+		args = append(args, subject.CustomerID)
+		where = append(where, fmt.Sprintf("u.customer_id = $%d", len(args)))
+	case models.StatementCostCenter:
+		args = append(args, subject.TagKey, subject.TagValue)
+		where = append(where, fmt.Sprintf("u.tag_map @> jsonb_build_object($%d::text, $%d::text)", len(args)-1, len(args)))
+	default:
+		return nil, fmt.Errorf("tipo de demonstrativo inválido: %s", subject.Type)
+	}
+
+	query := `
+		SELECT u.usage_date, COALESCE(u.invoice_number, ''), COALESCE(pr.product_name, ''),
+		       COALESCE(pr.category, ''), COALESCE(u.resource_location, ''),
+		       u.quantity::float8, u.unit_price::float8, u.billing_pre_tax_total::float8
+		FROM usages u
+		LEFT JOIN ` + productJoin + whereClause(where) + `
+		ORDER BY 1, 2, 3, 5, 8, 6
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar linhas do demonstrativo: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []models.StatementLine
+	for rows.Next() {
+		var l models.StatementLine
+		if err := rows.Scan(&l.UsageDate, &l.InvoiceNumber, &l.Product, &l.Category, &l.ResourceLocation,
+			&l.Quantity, &l.UnitPrice, &l.Total); err != nil {
+			return nil, fmt.Errorf("erro ao escanear linha do demonstrativo: %w", err)
+		}
+		lines = append(lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de linhas do demonstrativo: %w", err)
+	}
+
+	return lines, nil
+}
+
+// GetLatestStatement retorna a última versão do demonstrativo do sujeito no período; nil se
+// ainda não houver
+func (r *Repository) GetLatestStatement(ctx context.Context, subjectKey, period string) (*models.Statement, error) {
+	var s models.Statement
+	err := scanStatement(r.db.QueryRow(ctx, `
+		SELECT `+statementColumns+` FROM statements
+		WHERE subject_key = $1 AND period = $2
+		ORDER BY version DESC
+		LIMIT 1
+	`, subjectKey, period), &s)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar demonstrativo: %w", err)
+	}
+	return &s, nil
+}
+
+// GetLatestStatementsByPeriods retorna a última versão de cada demonstrativo dos períodos
+func (r *Repository) GetLatestStatementsByPeriods(ctx context.Context, periods []string) ([]models.Statement, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (subject_key, period) `+statementColumns+` FROM statements
+		WHERE period = ANY($1)
+		ORDER BY subject_key, period, version DESC
+	`, periods)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar demonstrativos dos períodos: %w", err)
+	}
+	defer rows.Close()
+
+	var statements []models.Statement
+	for rows.Next() {
+		var s models.Statement
+		if err := scanStatement(rows, &s); err != nil {
+			return nil, fmt.Errorf("erro ao escanear demonstrativo: %w", err)
+		}
+		statements = append(statements, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de demonstrativos: %w", err)
+	}
+
+	return statements, nil
+}
+
+// InsertStatement grava o demonstrativo como a próxima versão do sujeito no período; retorna
+// false se outra requisição gravou a mesma versão ao mesmo tempo
+func (r *Repository) InsertStatement(ctx context.Context, s *models.Statement) (bool, error) {
+	query := `
+		INSERT INTO statements (subject_key, period, version, checksum, total, data)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5
+		FROM statements WHERE subject_key = $1 AND period = $2
+		ON CONFLICT (subject_key, period, version) DO NOTHING
+		RETURNING id, version, created_at
+	`
+	err := r.db.QueryRow(ctx, query, s.SubjectKey, s.Period, s.Checksum, s.Total, s.Data).Scan(&s.ID, &s.Version, &s.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao inserir demonstrativo: %w", err)
+	}
+	return true, nil
+}
+
+// GetStatementByID busca uma versão de demonstrativo com o conteúdo; retorna nil se não existir
+func (r *Repository) GetStatementByID(ctx context.Context, id int) (*models.Statement, error) {
+	var s models.Statement
+	err := scanStatement(r.db.QueryRow(ctx, `SELECT `+statementColumns+` FROM statements WHERE id = $1`, id), &s)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar demonstrativo: %w", err)
+	}
+	return &s, nil
+}
+
+// ListStatements lista as versões de demonstrativos, sem o conteúdo, das mais recentes para as
+// mais antigas
+func (r *Repository) ListStatements(ctx context.Context, q models.StatementQuery) ([]models.Statement, error) {
+	var args []interface{}
+	var where []string
+	if q.CustomerKey != "" {
+		args = append(args, q.CustomerKey)
+		where = append(where, fmt.Sprintf("data->'subject'->>'customer_key' = $%d", len(args)))
+	}
+	if q.TagKey != "" {
+		args = append(args, q.TagKey)
+		where = append(where, fmt.Sprintf("data->'subject'->>'tag_key' = $%d", len(args)))
+	}
+	if q.TagValue != "" {
+		args = append(args, q.TagValue)
+		where = append(where, fmt.Sprintf("data->'subject'->>'tag_value' = $%d", len(args)))
+	}
+	if q.Period != "" {
+		args = append(args, q.Period)
+		where = append(where, fmt.Sprintf("period = $%d", len(args)))
+	}
+
+	query := `SELECT ` + statementSummaryColumns + ` FROM statements` + whereClause(where) + `
+		ORDER BY period DESC, subject_key, version DESC`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar demonstrativos: %w", err)
+	}
+	defer rows.Close()
+
+	var statements []models.Statement
+	for rows.Next() {
+		var s models.Statement
+		if err := scanStatementSummary(rows, &s); err != nil {
+			return nil, fmt.Errorf("erro ao escanear demonstrativo: %w", err)
+		}
+		statements = append(statements, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de demonstrativos: %w", err)
+	}
+
+	return statements, nil
+}
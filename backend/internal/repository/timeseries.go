@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeseriesGranularities lista os valores aceitos por DATE_TRUNC
+var timeseriesGranularities = map[string]bool{
+	"day":     true,
+	"week":    true,
+	"month":   true,
+	"quarter": true,
+	"year":    true,
+}
+
+// GetBillingTimeseries retorna o faturamento agregado por período no intervalo [from, to);
+// com asOf, como reportado naquele instante
+func (r *Repository) GetBillingTimeseries(ctx context.Context, granularity, groupBy string, from, to time.Time, asOf *time.Time) ([]models.BillingTimeseriesPoint, error) {
+	if !timeseriesGranularities[granularity] {
+		return nil, fmt.Errorf("granularidade inválida: %s", granularity)
+	}
+
+	args := []interface{}{from, to}
+	where := []string{"u.usage_date >= $1", "u.usage_date < $2"}
+
+	groupExpr, keyExpr := "''", "''"
+	if groupBy != "" {
+		expr, ok := dimensionExpr(groupBy, &args)
+		if !ok {
+			return nil, fmt.Errorf("agrupamento inválido: %s", groupBy)
+		}
+		groupExpr = expr
+		if key, ok := dimensionKeys[groupBy]; ok {
+			keyExpr = key
+		}
+	}
+	addAsOfFilter(asOf, &args, &where)
+
+	query := fmt.Sprintf(`
+		SELECT
+			DATE_TRUNC('%s', u.usage_date)::date as period_start,
+			%s as grp,
+			%s as grp_key,
+			SUM(u.billing_pre_tax_total) as total,
+			COUNT(*) as count`+usageFromAsOf(asOf)+`
+		WHERE %s
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`, granularity, groupExpr, keyExpr, strings.Join(where, " AND "))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar série temporal de faturamento: %w", err)
+	}
+	defer rows.Close()
+
+	var points []models.BillingTimeseriesPoint
+	for rows.Next() {
+		var point models.BillingTimeseriesPoint
+		if err := rows.Scan(&point.PeriodStart, &point.Group, &point.GroupKey, &point.Total, &point.Count); err != nil {
+			return nil, fmt.Errorf("erro ao escanear série temporal de faturamento: %w", err)
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de série temporal de faturamento: %w", err)
+	}
+
+	return points, nil
+}
+
+// GetUsageDateRange retorna a menor e a maior usage_date registradas
+func (r *Repository) GetUsageDateRange(ctx context.Context) (*time.Time, *time.Time, error) {
+	query := `SELECT MIN(usage_date), MAX(usage_date) FROM usages`
+
+	var minDate, maxDate *time.Time
+	if err := r.db.QueryRow(ctx, query).Scan(&minDate, &maxDate); err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar intervalo de datas de uso: %w", err)
+	}
+
+	return minDate, maxDate, nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const webhookSubscriptionColumns = `id, url, events, secret, active, created_at, updated_at`
+
+func scanWebhookSubscription(row pgx.Row, s *models.WebhookSubscription) error {
+	return row.Scan(&s.ID, &s.URL, &s.Events, &s.Secret, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+}
+
+// GetAllWebhookSubscriptions retorna todas as assinaturas de webhook
+func (r *Repository) GetAllWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar assinaturas de webhook: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []models.WebhookSubscription
+	for rows.Next() {
+		var s models.WebhookSubscription
+		if err := scanWebhookSubscription(rows, &s); err != nil {
+			return nil, fmt.Errorf("erro ao escanear assinatura de webhook: %w", err)
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de assinaturas de webhook: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// GetWebhookSubscriptionByID busca uma assinatura; retorna nil se não existir
+func (r *Repository) GetWebhookSubscriptionByID(ctx context.Context, id int) (*models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	err := scanWebhookSubscription(r.db.QueryRow(ctx, `SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id), &s)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar assinatura de webhook: %w", err)
+	}
+	return &s, nil
+}
+
+// InsertWebhookSubscription cria uma assinatura
+func (r *Repository) InsertWebhookSubscription(ctx context.Context, s *models.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, events, secret, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookSubscriptionColumns
+
+	if err := scanWebhookSubscription(r.db.QueryRow(ctx, query, s.URL, s.Events, s.Secret, s.Active), s); err != nil {
+		return fmt.Errorf("erro ao inserir assinatura de webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhookSubscription atualiza uma assinatura; segredo vazio mantém o atual.
+// Retorna false se não existir.
+func (r *Repository) UpdateWebhookSubscription(ctx context.Context, s *models.WebhookSubscription) (bool, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, events = $3, secret = COALESCE(NULLIF($4, ''), secret), active = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + webhookSubscriptionColumns
+
+	err := scanWebhookSubscription(r.db.QueryRow(ctx, query, s.ID, s.URL, s.Events, s.Secret, s.Active), s)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao atualizar assinatura de webhook: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteWebhookSubscription remove uma assinatura e seu log de entregas; retorna false se não existir
+func (r *Repository) DeleteWebhookSubscription(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover assinatura de webhook: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// EnqueueWebhookDeliveries cria uma entrega pendente para cada assinatura ativa inscrita no evento
+func (r *Repository) EnqueueWebhookDeliveries(ctx context.Context, event string, payload []byte) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event, payload)
+		SELECT id, $1, $2
+		FROM webhook_subscriptions
+		WHERE active = true AND $1 = ANY(events)
+	`
+
+	tag, err := r.db.Exec(ctx, query, event, payload)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao enfileirar entregas de webhook: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ClaimDueWebhookDeliveries reserva as entregas pendentes já vencidas, adiando a próxima
+// tentativa pelo tempo de lease para que outro despachante não as envie em paralelo
+func (r *Repository) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE state = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second'
+		FROM due, webhook_subscriptions s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING d.id, d.subscription_id, d.event, d.payload, d.attempts, d.created_at, s.url, s.secret
+	`
+
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reservar entregas de webhook: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.Event, &d.Payload, &d.Attempts, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			return nil, fmt.Errorf("erro ao escanear entrega de webhook: %w", err)
+		}
+		d.State = "pending"
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de entregas de webhook: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt registra o resultado de uma tentativa de entrega.
+// Com nextAttempt nil a entrega é encerrada: 'delivered' sem erro, 'failed' com erro.
+func (r *Repository) RecordWebhookAttempt(ctx context.Context, id int, status int, deliveryErr error, nextAttempt *time.Time) error {
+	var responseStatus *int
+	if status > 0 {
+		responseStatus = &status
+	}
+
+	var err error
+	switch {
+	case deliveryErr == nil:
+		_, err = r.db.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET state = 'delivered', attempts = attempts + 1, response_status = $2, last_error = NULL,
+			    next_attempt_at = NULL, delivered_at = CURRENT_TIMESTAMP
+			WHERE id = $1`, id, responseStatus)
+	case nextAttempt != nil:
+		_, err = r.db.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, response_status = $2, last_error = $3, next_attempt_at = $4
+			WHERE id = $1`, id, responseStatus, deliveryErr.Error(), *nextAttempt)
+	default:
+		_, err = r.db.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET state = 'failed', attempts = attempts + 1, response_status = $2, last_error = $3, next_attempt_at = NULL
+			WHERE id = $1`, id, responseStatus, deliveryErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao registrar tentativa de webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries retorna o log de entregas de uma assinatura, mais recentes primeiro
+func (r *Repository) GetWebhookDeliveries(ctx context.Context, subscriptionID int, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT id, subscription_id, event, payload, state, attempts, response_status, last_error,
+		       next_attempt_at, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar entregas de webhook: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.Event, &d.Payload, &d.State, &d.Attempts, &d.ResponseStatus, &d.LastError,
+			&d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear entrega de webhook: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro após iteração de entregas de webhook: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// ErrObjectNotFound indica que o bucket ou a chave solicitada não existe
+var ErrObjectNotFound = errors.New("objeto não encontrado")
+
+// S3Config descreve um endpoint compatível com S3 (AWS, MinIO, Ceph...)
+type S3Config struct {
+	Endpoint  string // host:porta; aceita também http:// ou https://, que define UseSSL
+	AccessKey string
+	SecretKey string
+	Region    string
+	UseSSL    bool
+}
+
+// S3Source lista e busca objetos de um bucket, opcionalmente restritos a um prefixo
+type S3Source struct {
+	SourceName string
+	Bucket     string
+	Prefix     string
+	client     *minio.Client
+}
+
+// NewS3Source cria a fonte; a conexão só é feita nas operações
+func NewS3Source(name string, cfg S3Config, bucket, prefix string) (*S3Source, error) {
+	endpoint, secure := cfg.Endpoint, cfg.UseSSL
+	if strings.HasPrefix(endpoint, "https://") {
+		endpoint, secure = strings.TrimPrefix(endpoint, "https://"), true
+	} else if strings.HasPrefix(endpoint, "http://") {
+		endpoint, secure = strings.TrimPrefix(endpoint, "http://"), false
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// Endereçamento por caminho (endpoint/bucket/chave) funciona em qualquer serviço compatível
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       secure,
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao configurar cliente S3: %w", err)
+	}
+
+	return &S3Source{SourceName: name, Bucket: bucket, Prefix: prefix, client: client}, nil
+}
+
+func (s *S3Source) Name() string {
+	return s.SourceName
+}
+
+// ListObjects retorna os objetos imediatamente abaixo do prefixo, com a chave completa em Name
+func (s *S3Source) ListObjects(ctx context.Context, bucket, prefix string) ([]FileInfo, error) {
+	var files []FileInfo
+	for object := range s.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if object.Err != nil {
+			if isS3NotFound(object.Err) {
+				return nil, fmt.Errorf("%w: bucket %s", ErrObjectNotFound, bucket)
+			}
+			return nil, fmt.Errorf("erro ao listar objetos de %s/%s: %w", bucket, prefix, object.Err)
+		}
+		// Subprefixos (pastas) e marcadores de pasta não são arquivos
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+		files = append(files, FileInfo{Name: object.Key, Size: object.Size, ModTime: object.LastModified})
+	}
+	return files, nil
+}
+
+// Fetch abre o objeto para leitura em streaming; quem chama deve fechar o reader
+func (s *S3Source) Fetch(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar objeto %s/%s: %w", bucket, key, err)
+	}
+	// GetObject é preguiçoso; o Stat antecipa erros como chave inexistente
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrObjectNotFound, bucket, key)
+		}
+		return nil, fmt.Errorf("erro ao buscar objeto %s/%s: %w", bucket, key, err)
+	}
+	return object, nil
+}
+
+// Open inicia uma varredura do bucket e prefixo configurados
+func (s *S3Source) Open(ctx context.Context) (Session, error) {
+	prefix := s.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &s3Session{ctx: ctx, source: s, prefix: prefix}, nil
+}
+
+type s3Session struct {
+	ctx    context.Context
+	source *S3Source
+	prefix string
+}
+
+// List retorna os nomes relativos ao prefixo; processed/ e failed/ ficam de fora por serem subprefixos
+func (s *s3Session) List() ([]FileInfo, error) {
+	objects, err := s.source.ListObjects(s.ctx, s.source.Bucket, s.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileInfo, 0, len(objects))
+	for _, object := range objects {
+		object.Name = strings.TrimPrefix(object.Name, s.prefix)
+		if object.Name == "" || strings.HasPrefix(object.Name, ".") {
+			continue
+		}
+		files = append(files, object)
+	}
+	return files, nil
+}
+
+func (s *s3Session) ReadFile(name string) ([]byte, error) {
+	reader, err := s.source.Fetch(s.ctx, s.source.Bucket, s.prefix+name)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// Move copia o objeto para o subprefixo de destino e remove o original
+func (s *s3Session) Move(name, folder string) error {
+	bucket := s.source.Bucket
+	src := minio.CopySrcOptions{Bucket: bucket, Object: s.prefix + name}
+	dst := minio.CopyDestOptions{Bucket: bucket, Object: s.prefix + folder + "/" + movedName(name, time.Now())}
+
+	if _, err := s.source.client.CopyObject(s.ctx, dst, src); err != nil {
+		return fmt.Errorf("erro ao copiar objeto: %w", err)
+	}
+	if err := s.source.client.RemoveObject(s.ctx, bucket, s.prefix+name, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("erro ao remover objeto original: %w", err)
+	}
+	return nil
+}
+
+func (s *s3Session) Close() error {
+	return nil
+}
+
+func isS3NotFound(err error) bool {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 é um substituto mínimo de um serviço compatível com S3 (endereçamento por caminho):
+// suporta ListObjectsV2 com delimitador, HEAD/GET/DELETE de objetos e cópia via PUT.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string][]byte
+}
+
+type listResult struct {
+	XMLName        xml.Name       `xml:"ListBucketResult"`
+	Name           string         `xml:"Name"`
+	Prefix         string         `xml:"Prefix"`
+	KeyCount       int            `xml:"KeyCount"`
+	IsTruncated    bool           `xml:"IsTruncated"`
+	Contents       []listObject   `xml:"Contents"`
+	CommonPrefixes []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type listObject struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+var fakeModTime = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if parts[0] != f.bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	key := ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, r.URL.Query().Get("prefix"), r.URL.Query().Get("delimiter"))
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+		source = strings.TrimPrefix(strings.TrimPrefix(source, "/"), f.bucket+"/")
+		data, ok := f.objects[source]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		f.objects[key] = data
+		w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag><LastModified>2024-01-10T08:00:00.000Z</LastModified></CopyObjectResult>`))
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", fakeModTime.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix, delimiter string) {
+	result := listResult{Name: f.bucket, Prefix: prefix}
+	seenPrefixes := map[string]bool{}
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, prefix)
+		if delimiter != "" {
+			if i := strings.Index(rest, delimiter); i >= 0 {
+				p := prefix + rest[:i+1]
+				if !seenPrefixes[p] {
+					seenPrefixes[p] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: p})
+				}
+				continue
+			}
+		}
+		result.Contents = append(result.Contents, listObject{
+			Key:          key,
+			LastModified: fakeModTime.Format("2006-01-02T15:04:05.000Z"),
+			ETag:         `"etag"`,
+			Size:         len(f.objects[key]),
+		})
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(`<Error><Code>` + code + `</Code><Message>` + code + `</Message></Error>`))
+}
+
+func newFakeS3Source(t *testing.T, objects map[string][]byte, prefix string) (*S3Source, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{bucket: "exports", objects: objects}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	source, err := NewS3Source("s3", S3Config{Endpoint: server.URL, AccessKey: "minio", SecretKey: "minio123"}, "exports", prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source, fake
+}
+
+func TestS3SourceFetch(t *testing.T) {
+	source, _ := newFakeS3Source(t, map[string][]byte{"partner-center/janeiro.csv": []byte("partner_id\n1\n")}, "")
+
+	reader, err := source.Fetch(context.Background(), "exports", "partner-center/janeiro.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer reader.Close()
+	content, _ := io.ReadAll(reader)
+	if string(content) != "partner_id\n1\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	if _, err := source.Fetch(context.Background(), "exports", "nao-existe.csv"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("expected ErrObjectNotFound, got %v", err)
+	}
+}
+
+func TestPollS3Source(t *testing.T) {
+	source, fake := newFakeS3Source(t, map[string][]byte{
+		"partner-center/janeiro.csv":          []byte("dados de janeiro"),
+		"partner-center/quebrado.csv":         []byte("invalido"),
+		"partner-center/processed/antigo.csv": []byte("já importado"),
+		"outro-prefixo/nao-deve-ser-lido.csv": []byte("dados"),
+	}, "partner-center")
+
+	ledger := &memoryLedger{imported: map[string]bool{}}
+	var imported []string
+	result, err := New(ledger, fakeImport(&imported, ledger)).Poll(context.Background(), source)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Processed != 1 || result.Failed != 1 || len(imported) != 1 || imported[0] != "janeiro.csv" {
+		t.Errorf("unexpected result: %+v, imports %v", result, imported)
+	}
+
+	var processed, failed int
+	for key := range fake.objects {
+		switch {
+		case key == "partner-center/janeiro.csv" || key == "partner-center/quebrado.csv":
+			t.Errorf("object %s should have been moved", key)
+		case strings.HasPrefix(key, "partner-center/processed/"):
+			processed++
+		case strings.HasPrefix(key, "partner-center/failed/"):
+			failed++
+		}
+	}
+	if processed != 2 || failed != 1 {
+		t.Errorf("expected 2 processed and 1 failed objects, got %d and %d", processed, failed)
+	}
+}
@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"data-importer-api-go/internal/models"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ImportFunc importa o conteúdo de um arquivo pelo fluxo normal de upload. Em caso de sucesso,
+// record (com Status processed) deve ser gravado no histórico na mesma transação dos dados, para
+// que uma falha ao registrá-lo desfaça a importação em vez de permitir que ela se repita.
+type ImportFunc func(ctx context.Context, record *models.ImportedFile, r io.Reader) (models.ImportSummary, error)
+
+// Ledger guarda o histórico de arquivos importados, consultado pelo checksum do conteúdo
+type Ledger interface {
+	IsChecksumImported(ctx context.Context, checksum string) (bool, error)
+	RecordImportedFile(ctx context.Context, file *models.ImportedFile) error
+}
+
+// Status gravados no histórico para cada arquivo
+const (
+	StatusProcessed = "processed"
+	StatusFailed    = "failed"
+	StatusDuplicate = "duplicate"
+)
+
+// DefaultSettle é o tempo mínimo desde a última modificação para considerar um arquivo completo
+const DefaultSettle = 10 * time.Second
+
+// PollResult resume uma varredura de fonte
+type PollResult struct {
+	Processed int
+	Failed    int
+	Skipped   int
+}
+
+// Scheduler consulta as fontes configuradas segundo expressões cron
+type Scheduler struct {
+	cron     *cron.Cron
+	ledger   Ledger
+	importFn ImportFunc
+	// Settle evita importar arquivos que ainda estão sendo gravados na fonte
+	Settle time.Duration
+}
+
+// New cria um scheduler; as fontes são adicionadas com AddSource
+func New(ledger Ledger, importFn ImportFunc) *Scheduler {
+	return &Scheduler{
+		cron:     cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		ledger:   ledger,
+		importFn: importFn,
+		Settle:   DefaultSettle,
+	}
+}
+
+// AddSource agenda a varredura da fonte com uma expressão cron de 5 campos (ex.: "*/5 * * * *")
+func (s *Scheduler) AddSource(source Source, schedule string) error {
+	_, err := s.cron.AddFunc(schedule, func() {
+		result, err := s.Poll(context.Background(), source)
+		if err != nil {
+			log.Printf("Erro ao consultar fonte %s: %v", source.Name(), err)
+			return
+		}
+		if result.Processed+result.Failed+result.Skipped > 0 {
+			log.Printf("Fonte %s: %d importados, %d com falha, %d ignorados", source.Name(), result.Processed, result.Failed, result.Skipped)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("expressão cron inválida para %s: %w", source.Name(), err)
+	}
+	log.Printf("Fonte de importação %s agendada (%s)", source.Name(), schedule)
+	return nil
+}
+
+// Start inicia as varreduras agendadas em segundo plano
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop interrompe o agendamento e aguarda as varreduras em andamento
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+}
+
+// Poll varre a fonte uma vez: arquivos novos são importados e movidos para processed/ ou failed/;
+// arquivos cujo conteúdo já foi importado vão direto para processed/.
+func (s *Scheduler) Poll(ctx context.Context, source Source) (PollResult, error) {
+	var result PollResult
+
+	session, err := source.Open(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer session.Close()
+
+	files, err := session.List()
+	if err != nil {
+		return result, err
+	}
+
+	for _, file := range files {
+		if time.Since(file.ModTime) < s.Settle {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		status, err := s.processFile(ctx, source.Name(), session, file.Name)
+		if err != nil {
+			return result, err
+		}
+
+		folder := ProcessedDir
+		switch status {
+		case StatusProcessed:
+			result.Processed++
+		case StatusDuplicate:
+			result.Skipped++
+		default:
+			result.Failed++
+			folder = FailedDir
+		}
+		if err := session.Move(file.Name, folder); err != nil {
+			return result, fmt.Errorf("erro ao mover %s para %s: %w", file.Name, folder, err)
+		}
+	}
+
+	return result, nil
+}
+
+// processFile importa um arquivo e registra o resultado no histórico; o registro de uma importação
+// bem-sucedida é gravado pela própria ImportFunc. Só retorna erro quando o histórico não pode ser
+// consultado ou gravado; nesse caso o arquivo permanece na fonte para a próxima varredura.
+func (s *Scheduler) processFile(ctx context.Context, sourceName string, session Session, name string) (string, error) {
+	record := &models.ImportedFile{Source: sourceName, FileName: name}
+
+	content, err := session.ReadFile(name)
+	if err != nil {
+		return s.record(ctx, record, StatusFailed, fmt.Errorf("erro ao ler arquivo: %w", err))
+	}
+
+	sum := sha256.Sum256(content)
+	record.Checksum = hex.EncodeToString(sum[:])
+
+	imported, err := s.ledger.IsChecksumImported(ctx, record.Checksum)
+	if err != nil {
+		return "", err
+	}
+	if imported {
+		log.Printf("Arquivo %s da fonte %s já importado (checksum %s), ignorando", name, sourceName, record.Checksum[:12])
+		return s.record(ctx, record, StatusDuplicate, nil)
+	}
+
+	log.Printf("Importando %s da fonte %s", name, sourceName)
+	record.Status = StatusProcessed
+	summary, err := s.importFn(ctx, record, bytes.NewReader(content))
+	record.Usages = summary.Usages
+	if err != nil {
+		return s.record(ctx, record, StatusFailed, err)
+	}
+	return StatusProcessed, nil
+}
+
+func (s *Scheduler) record(ctx context.Context, record *models.ImportedFile, status string, importErr error) (string, error) {
+	record.Status = status
+	if importErr != nil {
+		message := importErr.Error()
+		record.Error = &message
+		log.Printf("Falha ao importar %s da fonte %s: %v", record.FileName, record.Source, importErr)
+	}
+	if err := s.ledger.RecordImportedFile(ctx, record); err != nil {
+		return "", err
+	}
+	return status, nil
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type memoryLedger struct {
+	imported map[string]bool
+	records  []models.ImportedFile
+	failing  bool // simula o banco indisponível ao gravar o histórico
+}
+
+func (l *memoryLedger) IsChecksumImported(ctx context.Context, checksum string) (bool, error) {
+	return l.imported[checksum], nil
+}
+
+func (l *memoryLedger) RecordImportedFile(ctx context.Context, file *models.ImportedFile) error {
+	if l.failing {
+		return errors.New("conexão recusada")
+	}
+	if file.Status == StatusProcessed {
+		l.imported[file.Checksum] = true
+	}
+	l.records = append(l.records, *file)
+	return nil
+}
+
+// fakeImport aceita qualquer arquivo cujo conteúdo não seja "invalido" e, como o fluxo real,
+// registra o arquivo importado no histórico junto com os dados
+func fakeImport(imported *[]string, ledger *memoryLedger) ImportFunc {
+	return func(ctx context.Context, record *models.ImportedFile, r io.Reader) (models.ImportSummary, error) {
+		content, _ := io.ReadAll(r)
+		if string(content) == "invalido" {
+			return models.ImportSummary{FileName: record.FileName}, errors.New("coluna obrigatória não encontrada")
+		}
+		record.Usages = 3
+		if err := ledger.RecordImportedFile(ctx, record); err != nil {
+			return models.ImportSummary{FileName: record.FileName}, err
+		}
+		*imported = append(*imported, record.FileName)
+		return models.ImportSummary{FileName: record.FileName, Usages: 3}, nil
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string, age time.Duration) {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(p, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countFiles(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			count++
+		}
+	}
+	return count
+}
+
+func TestPollLocalSource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "janeiro.csv", "partner_id,customer_id\n1,2\n", time.Minute)
+	writeFile(t, dir, "copia-janeiro.csv", "partner_id,customer_id\n1,2\n", time.Minute)
+	writeFile(t, dir, "quebrado.csv", "invalido", time.Minute)
+	writeFile(t, dir, "gravando.csv", "partner_id\n", 0)
+
+	ledger := &memoryLedger{imported: map[string]bool{}}
+	var imported []string
+	s := New(ledger, fakeImport(&imported, ledger))
+
+	result, err := s.Poll(context.Background(), NewLocalSource("inbox", dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Processed != 1 || result.Skipped != 1 || result.Failed != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(imported) != 1 {
+		t.Errorf("expected one import for identical content, got %v", imported)
+	}
+	if countFiles(t, filepath.Join(dir, ProcessedDir)) != 2 || countFiles(t, filepath.Join(dir, FailedDir)) != 1 {
+		t.Error("files were not moved to processed/ and failed/")
+	}
+	// O arquivo recente ainda pode estar sendo gravado e deve permanecer na caixa de entrada
+	if _, err := os.Stat(filepath.Join(dir, "gravando.csv")); err != nil {
+		t.Errorf("recent file should stay in the inbox: %v", err)
+	}
+	if len(ledger.records) != 3 {
+		t.Errorf("expected 3 ledger records, got %d", len(ledger.records))
+	}
+}
+
+func TestPollSkipsAlreadyImportedChecksum(t *testing.T) {
+	dir := t.TempDir()
+	ledger := &memoryLedger{imported: map[string]bool{}}
+	var imported []string
+	s := New(ledger, fakeImport(&imported, ledger))
+	source := NewLocalSource("inbox", dir)
+
+	writeFile(t, dir, "fevereiro.csv", "dados", time.Minute)
+	if _, err := s.Poll(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+
+	// O mesmo conteúdo reenviado em outra varredura não deve ser importado de novo
+	writeFile(t, dir, "fevereiro.csv", "dados", time.Minute)
+	result, err := s.Poll(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Skipped != 1 || len(imported) != 1 {
+		t.Errorf("expected reimport to be skipped, got %+v and imports %v", result, imported)
+	}
+}
+
+func TestPollKeepsFileWhenLedgerWriteFails(t *testing.T) {
+	dir := t.TempDir()
+	ledger := &memoryLedger{imported: map[string]bool{}, failing: true}
+	var imported []string
+	s := New(ledger, fakeImport(&imported, ledger))
+	source := NewLocalSource("inbox", dir)
+
+	// Sem o histórico gravado a importação é desfeita e o arquivo fica para a próxima varredura
+	writeFile(t, dir, "marco.csv", "dados de março", time.Minute)
+	if _, err := s.Poll(context.Background(), source); err == nil {
+		t.Fatal("expected error when the ledger cannot be written")
+	}
+	if len(imported) != 0 || countFiles(t, dir) != 1 {
+		t.Fatalf("expected the file to stay in the inbox without imports, got %v", imported)
+	}
+
+	ledger.failing = false
+	result, err := s.Poll(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Processed != 1 || len(imported) != 1 || len(ledger.records) != 1 {
+		t.Errorf("expected a single import on the next poll, got %+v, imports %v, records %d", result, imported, len(ledger.records))
+	}
+}
+
+func TestAddSourceRejectsInvalidSchedule(t *testing.T) {
+	s := New(&memoryLedger{imported: map[string]bool{}}, nil)
+	if err := s.AddSource(NewLocalSource("inbox", t.TempDir()), "a cada minuto"); err == nil {
+		t.Fatal("expected error for invalid cron expression")
+	}
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPConfig descreve o acesso a um servidor SFTP. A chave do servidor é sempre verificada, pelo
+// arquivo KnownHosts ou pela chave pública HostKey; sem nenhum dos dois a fonte não é criada.
+type SFTPConfig struct {
+	Addr       string // host:porta
+	User       string
+	Password   string
+	KeyFile    string // chave privada, alternativa à senha
+	KnownHosts string // arquivo known_hosts
+	HostKey    string // chave pública do servidor no formato authorized_keys, alternativa ao known_hosts
+	Dir        string
+	Timeout    time.Duration
+}
+
+// SFTPSource monitora um diretório em um servidor SFTP
+type SFTPSource struct {
+	SourceName string
+	Addr       string
+	Dir        string
+	config     *ssh.ClientConfig
+}
+
+// NewSFTPSource valida a configuração e prepara a autenticação; a conexão só é feita em Open
+func NewSFTPSource(name string, cfg SFTPConfig) (*SFTPSource, error) {
+	var auth []ssh.AuthMethod
+	if cfg.KeyFile != "" {
+		key, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler chave SFTP: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao interpretar chave SFTP: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("fonte SFTP %s sem senha ou chave configurada", name)
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case cfg.KnownHosts != "":
+		callback, err := knownhosts.New(cfg.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler known_hosts: %w", err)
+		}
+		hostKeyCallback = callback
+	case cfg.HostKey != "":
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao interpretar chave do servidor SFTP: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(key)
+	default:
+		return nil, fmt.Errorf("fonte SFTP %s sem known_hosts ou chave do servidor configurada", name)
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	return &SFTPSource{
+		SourceName: name,
+		Addr:       cfg.Addr,
+		Dir:        dir,
+		config: &ssh.ClientConfig{
+			User:            cfg.User,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         timeout,
+		},
+	}, nil
+}
+
+func (s *SFTPSource) Name() string {
+	return s.SourceName
+}
+
+// Open conecta ao servidor e garante a existência das subpastas de destino
+func (s *SFTPSource) Open(ctx context.Context) (Session, error) {
+	dialer := net.Dialer{Timeout: s.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar em %s: %w", s.Addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, s.Addr, s.config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro na autenticação SSH em %s: %w", s.Addr, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("erro ao iniciar sessão SFTP: %w", err)
+	}
+
+	session := &sftpSession{ssh: sshClient, client: client, dir: s.Dir}
+	for _, folder := range []string{ProcessedDir, FailedDir} {
+		if err := client.MkdirAll(joinRemote(s.Dir, folder)); err != nil {
+			session.Close()
+			return nil, fmt.Errorf("erro ao criar pasta remota %s: %w", folder, err)
+		}
+	}
+
+	return session, nil
+}
+
+type sftpSession struct {
+	ssh    *ssh.Client
+	client *sftp.Client
+	dir    string
+}
+
+func (s *sftpSession) List() ([]FileInfo, error) {
+	entries, err := s.client.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diretório remoto %s: %w", s.dir, err)
+	}
+
+	var files []FileInfo
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files = append(files, FileInfo{Name: entry.Name(), Size: entry.Size(), ModTime: entry.ModTime()})
+	}
+	return files, nil
+}
+
+func (s *sftpSession) ReadFile(name string) ([]byte, error) {
+	f, err := s.client.Open(joinRemote(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func (s *sftpSession) Move(name, folder string) error {
+	return s.client.Rename(joinRemote(s.dir, name), joinRemote(s.dir, folder, movedName(name, time.Now())))
+}
+
+func (s *sftpSession) Close() error {
+	s.client.Close()
+	return s.ssh.Close()
+}
@@ -0,0 +1,207 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// startSFTPServer sobe um servidor SSH/SFTP local, com usuário "importer" e senha "segredo", e
+// retorna o endereço e a chave pública do servidor no formato authorized_keys
+func startSFTPServer(t *testing.T, root string) (string, string) {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == "importer" && string(password) == "segredo" {
+				return nil, nil
+			}
+			return nil, errors.New("acesso negado")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config, root)
+		}
+	}()
+
+	return listener.Addr().String(), string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+}
+
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig, root string) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "apenas sessões")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+			}
+		}()
+
+		server, err := sftp.NewServer(channel, sftp.WithServerWorkingDirectory(root))
+		if err != nil {
+			return
+		}
+		server.Serve()
+		server.Close()
+	}
+}
+
+func TestPollSFTPSource(t *testing.T) {
+	root := t.TempDir()
+	inbox := filepath.Join(root, "inbox")
+	if err := os.MkdirAll(inbox, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, inbox, "marco.csv", "dados de março", time.Minute)
+	writeFile(t, inbox, "quebrado.csv", "invalido", time.Minute)
+
+	addr, hostKey := startSFTPServer(t, root)
+	source, err := NewSFTPSource("sftp-teste", SFTPConfig{
+		Addr:     addr,
+		User:     "importer",
+		Password: "segredo",
+		HostKey:  hostKey,
+		Dir:      "inbox",
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSFTPSource: %v", err)
+	}
+
+	ledger := &memoryLedger{imported: map[string]bool{}}
+	var imported []string
+	result, err := New(ledger, fakeImport(&imported, ledger)).Poll(context.Background(), source)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Processed != 1 || result.Failed != 1 || len(imported) != 1 || imported[0] != "marco.csv" {
+		t.Errorf("unexpected result: %+v, imports %v", result, imported)
+	}
+	if countFiles(t, inbox) != 0 || countFiles(t, filepath.Join(inbox, ProcessedDir)) != 1 || countFiles(t, filepath.Join(inbox, FailedDir)) != 1 {
+		t.Error("remote files were not moved to processed/ and failed/")
+	}
+}
+
+func TestSFTPSourceRejectsWrongPassword(t *testing.T) {
+	addr, hostKey := startSFTPServer(t, t.TempDir())
+	source, err := NewSFTPSource("sftp-teste", SFTPConfig{
+		Addr:     addr,
+		User:     "importer",
+		Password: "errada",
+		HostKey:  hostKey,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSFTPSource: %v", err)
+	}
+
+	if _, err := source.Open(context.Background()); err == nil {
+		t.Fatal("expected authentication error")
+	}
+}
+
+func TestSFTPSourceRequiresHostKeyVerification(t *testing.T) {
+	addr, _ := startSFTPServer(t, t.TempDir())
+
+	_, err := NewSFTPSource("sftp-teste", SFTPConfig{Addr: addr, User: "importer", Password: "segredo"})
+	if err == nil {
+		t.Fatal("expected configuration error without known_hosts or host key")
+	}
+}
+
+func TestSFTPSourceRejectsUnknownHostKey(t *testing.T) {
+	addr, _ := startSFTPServer(t, t.TempDir())
+	_, otherHostKey := startSFTPServer(t, t.TempDir())
+
+	source, err := NewSFTPSource("sftp-teste", SFTPConfig{
+		Addr:     addr,
+		User:     "importer",
+		Password: "segredo",
+		HostKey:  otherHostKey,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSFTPSource: %v", err)
+	}
+
+	if _, err := source.Open(context.Background()); err == nil {
+		t.Fatal("expected host key mismatch error")
+	}
+}
+
+func TestSFTPSourceUsesKnownHosts(t *testing.T) {
+	root := t.TempDir()
+	addr, hostKey := startSFTPServer(t, root)
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{addr}, key)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewSFTPSource("sftp-teste", SFTPConfig{
+		Addr:       addr,
+		User:       "importer",
+		Password:   "segredo",
+		KnownHosts: knownHosts,
+		Timeout:    5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSFTPSource: %v", err)
+	}
+
+	session, err := source.Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	session.Close()
+}
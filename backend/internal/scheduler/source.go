@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Subpastas de destino dos arquivos após o processamento
+const (
+	ProcessedDir = "processed"
+	FailedDir    = "failed"
+)
+
+// FileInfo descreve um arquivo disponível em uma fonte
+type FileInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Source é uma origem de arquivos consultada periodicamente pelo scheduler
+type Source interface {
+	Name() string
+	Open(ctx context.Context) (Session, error)
+}
+
+// Session é uma conexão aberta com a fonte durante uma varredura
+type Session interface {
+	// List retorna os arquivos regulares no diretório monitorado, sem as subpastas de destino
+	List() ([]FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	// Move transfere o arquivo para a subpasta indicada, prefixando o nome com o horário
+	Move(name, folder string) error
+	Close() error
+}
+
+// movedName evita colisões quando um arquivo com o mesmo nome chega mais de uma vez
+func movedName(name string, at time.Time) string {
+	return at.UTC().Format("20060102T150405") + "-" + name
+}
+
+// LocalSource monitora um diretório local (caixa de entrada)
+type LocalSource struct {
+	SourceName string
+	Dir        string
+}
+
+// NewLocalSource cria uma fonte para o diretório informado
+func NewLocalSource(name, dir string) *LocalSource {
+	return &LocalSource{SourceName: name, Dir: dir}
+}
+
+func (s *LocalSource) Name() string {
+	return s.SourceName
+}
+
+// Open garante a existência das subpastas de destino; o diretório não precisa de conexão
+func (s *LocalSource) Open(ctx context.Context) (Session, error) {
+	for _, folder := range []string{ProcessedDir, FailedDir} {
+		if err := os.MkdirAll(filepath.Join(s.Dir, folder), 0o755); err != nil {
+			return nil, fmt.Errorf("erro ao criar pasta %s: %w", folder, err)
+		}
+	}
+	return localSession{dir: s.Dir}, nil
+}
+
+type localSession struct {
+	dir string
+}
+
+func (l localSession) List() ([]FileInfo, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diretório %s: %w", l.dir, err)
+	}
+
+	var files []FileInfo
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, FileInfo{Name: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+func (l localSession) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, name))
+}
+
+func (l localSession) Move(name, folder string) error {
+	return os.Rename(filepath.Join(l.dir, name), filepath.Join(l.dir, folder, movedName(name, time.Now())))
+}
+
+func (l localSession) Close() error {
+	return nil
+}
+
+// joinRemote monta caminhos remotos sempre com "/", independente do sistema local
+func joinRemote(elem ...string) string {
+	return path.Join(elem...)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/repository"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultAggregationLimit = 100
+	maxAggregationLimit     = 10000
+	maxAggregationDims      = 5
+)
+
+// Aggregate executa uma consulta de agregação ad-hoc validando dimensões, medidas e filtros
+func (s *Service) Aggregate(ctx context.Context, q models.AggregationQuery) (*models.AggregationResult, error) {
+	if err := validateAggregationQuery(&q); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.Aggregate(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao executar agregação: %w", err)
+	}
+	if rows == nil {
+		rows = []models.AggregationRow{}
+	}
+
+	return &models.AggregationResult{
+		Dimensions: q.Dimensions,
+		Measures:   q.Measures,
+		Rows:       rows,
+	}, nil
+}
+
+// validateAggregationQuery normaliza a consulta e rejeita nomes fora do registro
+func validateAggregationQuery(q *models.AggregationQuery) error {
+	q.Dimensions = uniqueStrings(q.Dimensions)
+	q.Measures = uniqueStrings(q.Measures)
+
+	if len(q.Measures) == 0 {
+		return fmt.Errorf("%w: informe ao menos uma medida", ErrInvalidParameter)
+	}
+	if len(q.Dimensions) > maxAggregationDims {
+		return fmt.Errorf("%w: no máximo %d dimensões são permitidas", ErrInvalidParameter, maxAggregationDims)
+	}
+	for _, dim := range q.Dimensions {
+		if !repository.IsDimension(dim) {
+			return fmt.Errorf("%w: dimensão desconhecida %q", ErrInvalidParameter, dim)
+		}
+	}
+	for _, m := range q.Measures {
+		if !repository.IsMeasure(m) {
+			return fmt.Errorf("%w: medida desconhecida %q", ErrInvalidParameter, m)
+		}
+	}
+	for dim, values := range q.Filters {
+		if !repository.IsDimension(dim) {
+			return fmt.Errorf("%w: filtro desconhecido %q", ErrInvalidParameter, dim)
+		}
+		if len(values) == 0 {
+			delete(q.Filters, dim)
+		}
+	}
+
+	if q.Sort != "" {
+		field := strings.TrimPrefix(q.Sort, "-")
+		if !containsString(q.Dimensions, field) && !containsString(q.Measures, field) {
+			return fmt.Errorf("%w: sort deve referenciar uma dimensão ou medida selecionada", ErrInvalidParameter)
+		}
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultAggregationLimit
+	}
+	if q.Limit > maxAggregationLimit {
+		q.Limit = maxAggregationLimit
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return fmt.Errorf("%w: from deve ser anterior a to", ErrInvalidParameter)
+	}
+
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
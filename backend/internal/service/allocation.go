@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/tags"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// allocationPercentTolerance é a folga aceita na soma dos percentuais de uma regra
+const allocationPercentTolerance = 0.01
+
+// GetAllocationRules retorna as regras de rateio
+func (s *Service) GetAllocationRules(ctx context.Context) ([]models.AllocationRule, error) {
+	rules, err := s.repo.GetAllocationRules(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regras de rateio: %w", err)
+	}
+	if rules == nil {
+		rules = []models.AllocationRule{}
+	}
+	return rules, nil
+}
+
+// GetAllocationRule retorna uma regra de rateio; nil se não existir
+func (s *Service) GetAllocationRule(ctx context.Context, id int) (*models.AllocationRule, error) {
+	rule, err := s.repo.GetAllocationRuleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regra de rateio: %w", err)
+	}
+	return rule, nil
+}
+
+// CreateAllocationRule valida e cria uma regra de rateio
+func (s *Service) CreateAllocationRule(ctx context.Context, rule *models.AllocationRule) error {
+	if err := validateAllocationRule(rule); err != nil {
+		return err
+	}
+
+	created, err := s.repo.InsertAllocationRule(ctx, rule)
+	if err != nil {
+		return fmt.Errorf("erro no service ao criar regra de rateio: %w", err)
+	}
+	if !created {
+		return fmt.Errorf("%w: já existe uma regra chamada %q", ErrConflict, rule.Name)
+	}
+	return nil
+}
+
+// UpdateAllocationRule valida e atualiza uma regra de rateio; retorna false se não existir
+func (s *Service) UpdateAllocationRule(ctx context.Context, rule *models.AllocationRule) (bool, error) {
+	if err := validateAllocationRule(rule); err != nil {
+		return false, err
+	}
+
+	updated, err := s.repo.UpdateAllocationRule(ctx, rule)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar regra de rateio: %w", err)
+	}
+	if !updated {
+		current, err := s.repo.GetAllocationRuleByID(ctx, rule.ID)
+		if err != nil {
+			return false, fmt.Errorf("erro no service ao atualizar regra de rateio: %w", err)
+		}
+		if current == nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: já existe uma regra chamada %q", ErrConflict, rule.Name)
+	}
+	return true, nil
+}
+
+// DeleteAllocationRule remove uma regra de rateio; retorna false se não existir
+func (s *Service) DeleteAllocationRule(ctx context.Context, id int) (bool, error) {
+	deleted, err := s.repo.DeleteAllocationRule(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover regra de rateio: %w", err)
+	}
+	return deleted, nil
+}
+
+// GetChargeback retorna o custo por valor da tag (centro de custo) após aplicar as regras de
+// rateio ativas da tag
+func (s *Service) GetChargeback(ctx context.Context, q models.ChargebackQuery) (*models.ChargebackReport, error) {
+	q.TagKey = tags.NormalizeKey(q.TagKey)
+	if q.TagKey == "" {
+		return nil, fmt.Errorf("%w: tag_key é obrigatório", ErrInvalidParameter)
+	}
+	if q.From != nil && q.To != nil && q.To.Before(*q.From) {
+		return nil, fmt.Errorf("%w: to deve ser igual ou posterior a from", ErrInvalidParameter)
+	}
+
+	totals, err := s.repo.GetBillingByTagValue(ctx, q.TagKey, q.From, q.To, q.AsOf)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar faturamento por tag: %w", err)
+	}
+	rules, err := s.repo.GetAllocationRules(ctx, q.TagKey)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regras de rateio: %w", err)
+	}
+
+	items, unallocated := allocateCosts(totals, rules)
+	report := &models.ChargebackReport{
+		TagKey:      q.TagKey,
+		From:        q.From,
+		To:          q.To,
+		Unallocated: unallocated,
+		Items:       items,
+	}
+	for _, total := range totals {
+		report.Total += total
+	}
+	report.Total = roundMoney(report.Total)
+	return report, nil
+}
+
+// validateAllocationRule normaliza a regra e exige percentuais positivos somando 100
+func validateAllocationRule(rule *models.AllocationRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.TagKey = tags.NormalizeKey(rule.TagKey)
+	rule.SourceValue = strings.TrimSpace(rule.SourceValue)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name é obrigatório", ErrInvalidParameter)
+	}
+	if rule.TagKey == "" {
+		return fmt.Errorf("%w: tag_key é obrigatório", ErrInvalidParameter)
+	}
+	for field, value := range map[string]string{"name": rule.Name, "tag_key": rule.TagKey, "source_value": rule.SourceValue} {
+		if utf8.RuneCountInString(value) > 255 {
+			return fmt.Errorf("%w: %s deve ter no máximo 255 caracteres", ErrInvalidParameter, field)
+		}
+	}
+	if len(rule.Splits) == 0 {
+		return fmt.Errorf("%w: informe ao menos um split", ErrInvalidParameter)
+	}
+
+	seen := make(map[string]bool)
+	var sum float64
+	for i := range rule.Splits {
+		split := &rule.Splits[i]
+		split.Value = strings.TrimSpace(split.Value)
+		if split.Value == "" {
+			return fmt.Errorf("%w: value é obrigatório em cada split", ErrInvalidParameter)
+		}
+		if split.Value == rule.SourceValue {
+			return fmt.Errorf("%w: o split %q não pode ser o próprio source_value", ErrInvalidParameter, split.Value)
+		}
+		if seen[split.Value] {
+			return fmt.Errorf("%w: split duplicado %q", ErrInvalidParameter, split.Value)
+		}
+		if split.Percent <= 0 {
+			return fmt.Errorf("%w: percent deve ser positivo (split %q)", ErrInvalidParameter, split.Value)
+		}
+		seen[split.Value] = true
+		sum += split.Percent
+	}
+	if math.Abs(sum-100) > allocationPercentTolerance {
+		return fmt.Errorf("%w: os percentuais devem somar 100 (soma %.2f)", ErrInvalidParameter, sum)
+	}
+	return nil
+}
+
+// allocateCosts distribui o custo direto de cada valor de origem entre os splits da primeira
+// regra que o seleciona (as regras chegam em ordem de id). Só o custo direto é rateado: o que
+// um valor recebe por rateio não é redistribuído. As fatias são arredondadas em centavos e a
+// diferença fica no último split, para que o total não mude. Retorna os itens por total
+// decrescente e o custo sem a tag que nenhuma regra distribuiu.
+func allocateCosts(totals map[string]float64, rules []models.AllocationRule) ([]models.ChargebackItem, float64) {
+	direct := make(map[string]float64, len(totals))
+	for value, total := range totals {
+		direct[value] = total
+	}
+	allocated := make(map[string]float64)
+
+	consumed := make(map[string]bool)
+	for _, rule := range rules {
+		amount := direct[rule.SourceValue]
+		if consumed[rule.SourceValue] || amount == 0 || len(rule.Splits) == 0 {
+			continue
+		}
+		consumed[rule.SourceValue] = true
+		direct[rule.SourceValue] = 0
+
+		remaining := amount
+		for i, split := range rule.Splits {
+			share := remaining
+			if i < len(rule.Splits)-1 {
+				share = roundMoney(amount * split.Percent / 100)
+			}
+			allocated[split.Value] += share
+			remaining -= share
+		}
+	}
+
+	values := make(map[string]bool)
+	for value := range direct {
+		values[value] = true
+	}
+	for value := range allocated {
+		values[value] = true
+	}
+
+	items := []models.ChargebackItem{}
+	for value := range values {
+		if value == "" || (direct[value] == 0 && allocated[value] == 0) {
+			continue
+		}
+		items = append(items, models.ChargebackItem{
+			Value:     value,
+			Direct:    roundMoney(direct[value]),
+			Allocated: roundMoney(allocated[value]),
+			Total:     roundMoney(direct[value] + allocated[value]),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Total != items[j].Total {
+			return items[i].Total > items[j].Total
+		}
+		return items[i].Value < items[j].Value
+	})
+
+	return items, roundMoney(direct[""])
+}
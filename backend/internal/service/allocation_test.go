@@ -0,0 +1,88 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAllocateCosts(t *testing.T) {
+	totals := map[string]float64{
+		"":       100,
+		"shared": 10,
+		"CC1":    50,
+		"CC3":    5,
+	}
+	rules := []models.AllocationRule{
+		{ID: 1, SourceValue: "", Splits: []models.AllocationSplit{{Value: "CC1", Percent: 33.33}, {Value: "CC2", Percent: 66.67}}},
+		{ID: 2, SourceValue: "shared", Splits: []models.AllocationSplit{{Value: "CC1", Percent: 50}, {Value: "CC2", Percent: 50}}},
+		// a origem já foi rateada pela regra 2
+		{ID: 3, SourceValue: "shared", Splits: []models.AllocationSplit{{Value: "CC3", Percent: 100}}},
+		// sem custo direto na origem
+		{ID: 4, SourceValue: "unused", Splits: []models.AllocationSplit{{Value: "CC3", Percent: 100}}},
+	}
+
+	items, unallocated := allocateCosts(totals, rules)
+
+	want := []models.ChargebackItem{
+		{Value: "CC1", Direct: 50, Allocated: 38.33, Total: 88.33},
+		{Value: "CC2", Direct: 0, Allocated: 71.67, Total: 71.67},
+		{Value: "CC3", Direct: 5, Allocated: 0, Total: 5},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("allocateCosts() = %+v, want %+v", items, want)
+	}
+	if unallocated != 0 {
+		t.Errorf("expected nothing unallocated, got %v", unallocated)
+	}
+
+	var sum float64
+	for _, item := range items {
+		sum += item.Total
+	}
+	if roundMoney(sum) != 165 {
+		t.Errorf("expected allocation to preserve the total 165, got %v", sum)
+	}
+}
+
+func TestAllocateCostsWithoutRules(t *testing.T) {
+	items, unallocated := allocateCosts(map[string]float64{"": 12.5, "CC1": 3}, nil)
+
+	if unallocated != 12.5 {
+		t.Errorf("expected 12.5 unallocated, got %v", unallocated)
+	}
+	if len(items) != 1 || items[0].Value != "CC1" || items[0].Total != 3 {
+		t.Errorf("unexpected items: %+v", items)
+	}
+}
+
+func TestValidateAllocationRule(t *testing.T) {
+	rule := models.AllocationRule{
+		Name:   " Compartilhado ",
+		TagKey: " CostCenter ",
+		Splits: []models.AllocationSplit{{Value: " CC1 ", Percent: 60}, {Value: "CC2", Percent: 40.005}},
+	}
+	if err := validateAllocationRule(&rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.Name != "Compartilhado" || rule.TagKey != "costcenter" || rule.Splits[0].Value != "CC1" {
+		t.Errorf("expected normalized rule, got %+v", rule)
+	}
+
+	invalid := []models.AllocationRule{
+		{TagKey: "cc", Splits: []models.AllocationSplit{{Value: "CC1", Percent: 100}}},
+		{Name: "r", Splits: []models.AllocationSplit{{Value: "CC1", Percent: 100}}},
+		{Name: "r", TagKey: "cc"},
+		{Name: "r", TagKey: "cc", Splits: []models.AllocationSplit{{Value: "CC1", Percent: 90}}},
+		{Name: "r", TagKey: "cc", Splits: []models.AllocationSplit{{Value: "CC1", Percent: 50}, {Value: "CC1", Percent: 50}}},
+		{Name: "r", TagKey: "cc", Splits: []models.AllocationSplit{{Value: "CC1", Percent: 110}, {Value: "CC2", Percent: -10}}},
+		{Name: "r", TagKey: "cc", SourceValue: "shared", Splits: []models.AllocationSplit{{Value: "shared", Percent: 100}}},
+		{Name: "r", TagKey: "cc", Splits: []models.AllocationSplit{{Value: " ", Percent: 100}}},
+	}
+	for _, r := range invalid {
+		if err := validateAllocationRule(&r); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("expected ErrInvalidParameter for %+v, got %v", r, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// anomalyWindow define a janela móvel usada em cada granularidade
+type anomalyWindow struct {
+	granularity string
+	size        int // quantidade de períodos anteriores considerados
+	minHistory  int // mínimo de períodos anteriores para avaliar um ponto
+}
+
+var anomalyWindows = []anomalyWindow{
+	{granularity: "day", size: 30, minHistory: 7},
+	{granularity: "month", size: 12, minHistory: 3},
+}
+
+// Limiares do escore robusto (desvio em relação à mediana dividido pelo MAD escalado)
+const (
+	anomalyThresholdLow    = 3.5
+	anomalyThresholdMedium = 6.0
+	anomalyThresholdHigh   = 10.0
+
+	// madScale torna o MAD comparável ao desvio padrão em dados normais
+	madScale = 1.4826
+	// minRelativeSpread evita divisões por quase zero em séries constantes
+	minRelativeSpread = 0.10
+	minAbsoluteSpread = 0.01
+)
+
+var validSeverities = map[string]bool{
+	"low":    true,
+	"medium": true,
+	"high":   true,
+}
+
+// DetectAnomalies avalia os períodos completos de todas as séries diárias e mensais de cada
+// cliente × produto e grava os desvios
+func (s *Service) DetectAnomalies(ctx context.Context) (int, error) {
+	return s.detectAnomalies(ctx, nil)
+}
+
+// detectAnomalies avalia as séries; com scope, apenas as séries e os períodos tocados por uma
+// importação, lendo só o histórico necessário para a base. Anomalias de períodos reavaliados que
+// deixaram de ser anômalos são removidas.
+func (s *Service) detectAnomalies(ctx context.Context, scope map[seriesKey]dateRange) (int, error) {
+	if scope != nil && len(scope) == 0 {
+		return 0, nil
+	}
+
+	var customerIDs, productIDs []int
+	var earliest time.Time
+	for key, r := range scope {
+		customerIDs = append(customerIDs, key.customer)
+		productIDs = append(productIDs, key.product)
+		if earliest.IsZero() || r.from.Before(earliest) {
+			earliest = r.from
+		}
+	}
+	cutoff := dateOnly(time.Now())
+
+	total := 0
+	for _, w := range anomalyWindows {
+		var from *time.Time
+		if scope != nil {
+			start := historyStart(scopeStart(earliest, w.granularity), w)
+			from = &start
+		}
+
+		points, err := s.repo.GetUsageSeries(ctx, w.granularity, customerIDs, productIDs, from)
+		if err != nil {
+			return total, fmt.Errorf("erro no service ao buscar séries para detecção: %w", err)
+		}
+
+		anomalies, evaluated := detectSeriesAnomalies(points, w, scope, cutoff)
+		if err := s.repo.ReplaceAnomalies(ctx, w.granularity, clearedPeriods(evaluated, anomalies), anomalies); err != nil {
+			return total, fmt.Errorf("erro no service ao gravar anomalias: %w", err)
+		}
+		total += len(anomalies)
+	}
+
+	return total, nil
+}
+
+// ListAnomalies retorna as anomalias filtradas
+func (s *Service) ListAnomalies(ctx context.Context, filter models.AnomalyFilter) ([]models.Anomaly, error) {
+	if filter.Severity != "" && !validSeverities[filter.Severity] {
+		return nil, fmt.Errorf("%w: severity deve ser low, medium ou high", ErrInvalidParameter)
+	}
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+
+	anomalies, err := s.repo.ListAnomalies(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar anomalias: %w", err)
+	}
+	if anomalies == nil {
+		anomalies = []models.Anomaly{}
+	}
+	return anomalies, nil
+}
+
+// AcknowledgeAnomaly marca uma anomalia como reconhecida pelo usuário
+func (s *Service) AcknowledgeAnomaly(ctx context.Context, id int, username string) (*models.Anomaly, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: ID da anomalia inválido", ErrInvalidParameter)
+	}
+
+	anomaly, err := s.repo.AcknowledgeAnomaly(ctx, id, username)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao reconhecer anomalia: %w", err)
+	}
+	return anomaly, nil
+}
+
+// detectSeriesAnomalies aplica a mediana/MAD móvel sobre pontos ordenados por cliente, produto e período.
+// Cada ponto é comparado apenas com os períodos anteriores, para que o próprio pico não distorça a base.
+// Só são avaliados períodos completos até cutoff e, com scope, os períodos tocados de cada série.
+// Retorna as anomalias e todos os períodos avaliados.
+func detectSeriesAnomalies(points []models.UsageSeriesPoint, w anomalyWindow, scope map[seriesKey]dateRange, cutoff time.Time) ([]models.Anomaly, []models.UsageSeriesPoint) {
+	var anomalies []models.Anomaly
+	var evaluated []models.UsageSeriesPoint
+
+	start := 0
+	for start < len(points) {
+		end := start + 1
+		for end < len(points) && points[end].CustomerID == points[start].CustomerID && points[end].ProductID == points[start].ProductID {
+			end++
+		}
+
+		key := seriesKey{points[start].CustomerID, points[start].ProductID}
+		touched, inScope := scope[key]
+		if scope != nil && !inScope {
+			start = end
+			continue
+		}
+
+		series := fillSeriesGaps(points[start:end], w.granularity)
+		for i, p := range series {
+			if nextPeriod(p.PeriodStart, w.granularity).After(cutoff) {
+				break
+			}
+			if inScope && (p.PeriodStart.Before(scopeStart(touched.from, w.granularity)) || p.PeriodStart.After(touched.to)) {
+				continue
+			}
+			evaluated = append(evaluated, p)
+			if i < w.minHistory {
+				continue
+			}
+
+			from := i - w.size
+			if from < 0 {
+				from = 0
+			}
+
+			history := make([]float64, 0, i-from)
+			for _, h := range series[from:i] {
+				history = append(history, h.Total)
+			}
+
+			baseline, spread := robustBaseline(history)
+			score := (p.Total - baseline) / spread
+			severity := anomalySeverity(score)
+			if severity == "" {
+				continue
+			}
+
+			anomalies = append(anomalies, models.Anomaly{
+				CustomerID:  p.CustomerID,
+				ProductID:   p.ProductID,
+				Granularity: w.granularity,
+				PeriodStart: p.PeriodStart,
+				Value:       p.Total,
+				Baseline:    baseline,
+				Score:       math.Round(score*100) / 100,
+				Method:      "mad",
+				Severity:    severity,
+			})
+		}
+
+		start = end
+	}
+
+	return anomalies, evaluated
+}
+
+// fillSeriesGaps insere períodos zerados entre os pontos da série, para que a janela móvel conte
+// períodos de calendário e as quedas a zero sejam comparadas com o histórico. A série não é
+// estendida além do seu último ponto: uma série encerrada não é uma queda.
+func fillSeriesGaps(series []models.UsageSeriesPoint, granularity string) []models.UsageSeriesPoint {
+	if len(series) == 0 {
+		return series
+	}
+
+	filled := make([]models.UsageSeriesPoint, 0, len(series))
+	for i, p := range series {
+		if i > 0 {
+			for gap := nextPeriod(series[i-1].PeriodStart, granularity); gap.Before(p.PeriodStart); gap = nextPeriod(gap, granularity) {
+				filled = append(filled, models.UsageSeriesPoint{CustomerID: p.CustomerID, ProductID: p.ProductID, PeriodStart: gap})
+			}
+		}
+		filled = append(filled, p)
+	}
+
+	return filled
+}
+
+// scopeStart é o primeiro período reavaliado para uma importação que começa em from: o período de
+// from e o anterior, que pode ter se completado desde a importação passada
+func scopeStart(from time.Time, granularity string) time.Time {
+	return shiftPeriod(truncatePeriod(from, granularity), granularity, "previous_period")
+}
+
+// historyStart é o início do histórico usado como base para avaliar a partir de start
+func historyStart(start time.Time, w anomalyWindow) time.Time {
+	if w.granularity == "month" {
+		return start.AddDate(0, -w.size, 0)
+	}
+	return start.AddDate(0, 0, -w.size)
+}
+
+// clearedPeriods retorna os períodos avaliados que não são anômalos
+func clearedPeriods(evaluated []models.UsageSeriesPoint, anomalies []models.Anomaly) []models.UsageSeriesPoint {
+	anomalous := make(map[string]bool, len(anomalies))
+	for _, a := range anomalies {
+		anomalous[fmt.Sprintf("%d|%d|%s", a.CustomerID, a.ProductID, a.PeriodStart.Format("2006-01-02"))] = true
+	}
+
+	var cleared []models.UsageSeriesPoint
+	for _, p := range evaluated {
+		if !anomalous[fmt.Sprintf("%d|%d|%s", p.CustomerID, p.ProductID, p.PeriodStart.Format("2006-01-02"))] {
+			cleared = append(cleared, p)
+		}
+	}
+	return cleared
+}
+
+// robustBaseline retorna a mediana e a dispersão robusta (MAD escalado, com piso) dos valores
+func robustBaseline(values []float64) (float64, float64) {
+	median := medianOf(values)
+
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	spread := medianOf(deviations) * madScale
+
+	floor := math.Max(math.Abs(median)*minRelativeSpread, minAbsoluteSpread)
+	if spread < floor {
+		spread = floor
+	}
+
+	return median, spread
+}
+
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// anomalySeverity classifica o escore; retorna vazio quando não há anomalia
+func anomalySeverity(score float64) string {
+	abs := math.Abs(score)
+	switch {
+	case abs >= anomalyThresholdHigh:
+		return "high"
+	case abs >= anomalyThresholdMedium:
+		return "medium"
+	case abs >= anomalyThresholdLow:
+		return "low"
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"testing"
+	"time"
+)
+
+func dailySeries(customerID, productID int, totals ...float64) []models.UsageSeriesPoint {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]models.UsageSeriesPoint, len(totals))
+	for i, total := range totals {
+		points[i] = models.UsageSeriesPoint{
+			CustomerID:  customerID,
+			ProductID:   productID,
+			PeriodStart: start.AddDate(0, 0, i),
+			Total:       total,
+		}
+	}
+	return points
+}
+
+// farFuture torna completos todos os períodos das séries de teste
+var farFuture = date(2100, time.January, 1)
+
+func TestDetectSeriesAnomaliesFlagsSpike(t *testing.T) {
+	w := anomalyWindow{granularity: "day", size: 30, minHistory: 7}
+	points := dailySeries(1, 1, 10, 11, 9, 10, 12, 10, 11, 10, 95)
+
+	anomalies, _ := detectSeriesAnomalies(points, w, nil, farFuture)
+	if len(anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, got %d: %+v", len(anomalies), anomalies)
+	}
+	if anomalies[0].Value != 95 || anomalies[0].Severity != "high" || anomalies[0].Baseline != 10 {
+		t.Errorf("unexpected anomaly: %+v", anomalies[0])
+	}
+}
+
+func TestDetectSeriesAnomaliesSeparatesSeries(t *testing.T) {
+	w := anomalyWindow{granularity: "day", size: 30, minHistory: 7}
+	// A segunda série começa alta, mas não deve ser comparada com a primeira
+	points := append(dailySeries(1, 1, 10, 10, 10, 10, 10, 10, 10, 10), dailySeries(1, 2, 500, 510, 490, 500, 505, 495, 500, 500)...)
+
+	if anomalies, _ := detectSeriesAnomalies(points, w, nil, farFuture); len(anomalies) != 0 {
+		t.Errorf("expected no anomalies, got %+v", anomalies)
+	}
+}
+
+func TestDetectSeriesAnomaliesRequiresHistory(t *testing.T) {
+	w := anomalyWindow{granularity: "month", size: 12, minHistory: 3}
+	points := dailySeries(1, 1, 10, 1000)
+
+	if anomalies, _ := detectSeriesAnomalies(points, w, nil, farFuture); len(anomalies) != 0 {
+		t.Errorf("expected no anomalies without enough history, got %+v", anomalies)
+	}
+}
+
+func TestDetectSeriesAnomaliesFlagsDropInsideSeries(t *testing.T) {
+	w := anomalyWindow{granularity: "day", size: 30, minHistory: 7}
+	// O cliente 1 não tem registro no 9º dia e volta no 10º; o cliente 2 encerra a série no 8º dia
+	points := append(dailySeries(1, 1, 10, 11, 9, 10, 12, 10, 11, 10, 0, 10), dailySeries(2, 1, 5, 5, 5, 5, 5, 5, 5, 5)...)
+	points = append(points[:8], points[9:]...) // remove o registro do 9º dia
+
+	anomalies, _ := detectSeriesAnomalies(points, w, nil, farFuture)
+	if len(anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, got %d: %+v", len(anomalies), anomalies)
+	}
+	if anomalies[0].CustomerID != 1 || anomalies[0].Value != 0 || !anomalies[0].PeriodStart.Equal(date(2024, time.January, 9)) || anomalies[0].Score >= 0 {
+		t.Errorf("unexpected anomaly: %+v", anomalies[0])
+	}
+}
+
+func TestDetectSeriesAnomaliesDoesNotPadEndedSeries(t *testing.T) {
+	w := anomalyWindow{granularity: "day", size: 30, minHistory: 7}
+	// O cliente 1 para de gastar no 8º dia; o cliente 2 continua até o 12º
+	points := append(dailySeries(1, 1, 10, 11, 9, 10, 12, 10, 11, 10), dailySeries(2, 1, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5)...)
+
+	if anomalies, _ := detectSeriesAnomalies(points, w, nil, farFuture); len(anomalies) != 0 {
+		t.Errorf("expected no anomalies after a series ends, got %+v", anomalies)
+	}
+}
+
+func TestDetectSeriesAnomaliesSkipsIncompletePeriods(t *testing.T) {
+	w := anomalyWindow{granularity: "day", size: 30, minHistory: 7}
+	points := dailySeries(1, 1, 10, 11, 9, 10, 12, 10, 11, 10, 1)
+
+	// O 9º dia ainda está em andamento: não é avaliado nem considerado para limpeza
+	anomalies, evaluated := detectSeriesAnomalies(points, w, nil, date(2024, time.January, 9))
+	if len(anomalies) != 0 {
+		t.Errorf("expected the running day to be skipped, got %+v", anomalies)
+	}
+	if len(evaluated) != 8 {
+		t.Errorf("expected 8 complete periods evaluated, got %d", len(evaluated))
+	}
+
+	monthly := anomalyWindow{granularity: "month", size: 12, minHistory: 3}
+	months := []models.UsageSeriesPoint{{CustomerID: 1, ProductID: 1, PeriodStart: date(2024, time.May, 1), Total: 1}}
+	if _, evaluated := detectSeriesAnomalies(months, monthly, nil, date(2024, time.May, 31)); len(evaluated) != 0 {
+		t.Errorf("expected May to be incomplete on May 31, got %+v", evaluated)
+	}
+	if _, evaluated := detectSeriesAnomalies(months, monthly, nil, date(2024, time.June, 1)); len(evaluated) != 1 {
+		t.Errorf("expected May to be complete on June 1, got %+v", evaluated)
+	}
+}
+
+func TestDetectSeriesAnomaliesLimitsToScope(t *testing.T) {
+	w := anomalyWindow{granularity: "day", size: 30, minHistory: 7}
+	// Ambas as séries têm um pico no 9º dia, mas só os dias 10 a 12 do cliente 1 foram importados
+	points := append(dailySeries(1, 1, 10, 11, 9, 10, 12, 10, 11, 10, 95, 10, 10, 10), dailySeries(2, 1, 10, 11, 9, 10, 12, 10, 11, 10, 95)...)
+	scope := map[seriesKey]dateRange{{1, 1}: {from: date(2024, time.January, 10), to: date(2024, time.January, 12)}}
+
+	anomalies, evaluated := detectSeriesAnomalies(points, w, scope, farFuture)
+	// O dia anterior ao importado é reavaliado, pois pode ter se completado desde a última importação
+	if len(anomalies) != 1 || anomalies[0].CustomerID != 1 || !anomalies[0].PeriodStart.Equal(date(2024, time.January, 9)) {
+		t.Errorf("expected only the spike of customer 1, got %+v", anomalies)
+	}
+	if len(evaluated) != 4 {
+		t.Errorf("expected days 9 to 12 of customer 1 to be evaluated, got %+v", evaluated)
+	}
+
+	cleared := clearedPeriods(evaluated, anomalies)
+	if len(cleared) != 3 || cleared[0].PeriodStart.Equal(date(2024, time.January, 9)) {
+		t.Errorf("expected the three normal days to be cleared, got %+v", cleared)
+	}
+}
+
+func TestFillSeriesGapsCountsCalendarPeriods(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	series := []models.UsageSeriesPoint{
+		{CustomerID: 1, ProductID: 1, PeriodStart: start, Total: 10},
+		{CustomerID: 1, ProductID: 1, PeriodStart: start.AddDate(0, 0, 3), Total: 20},
+	}
+
+	filled := fillSeriesGaps(series, "day")
+	want := []float64{10, 0, 0, 20}
+	if len(filled) != len(want) {
+		t.Fatalf("expected %d points, got %+v", len(want), filled)
+	}
+	for i, p := range filled {
+		if p.Total != want[i] || !p.PeriodStart.Equal(start.AddDate(0, 0, i)) || p.CustomerID != 1 || p.ProductID != 1 {
+			t.Errorf("point %d = %+v, want total %v on %s", i, p, want[i], start.AddDate(0, 0, i).Format("2006-01-02"))
+		}
+	}
+
+	monthly := fillSeriesGaps([]models.UsageSeriesPoint{{PeriodStart: start}, {PeriodStart: start.AddDate(0, 2, 0)}}, "month")
+	if len(monthly) != 3 || !monthly[1].PeriodStart.Equal(start.AddDate(0, 1, 0)) {
+		t.Errorf("unexpected monthly series: %+v", monthly)
+	}
+}
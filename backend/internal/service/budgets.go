@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/notifier"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxAlertAttempts limita as tentativas de entrega de um alerta de orçamento
+const maxAlertAttempts = 3
+
+var defaultBudgetThresholds = []int{50, 80, 100}
+
+var validBudgetScopes = map[string]bool{
+	"customer": true,
+	"partner":  true,
+	"category": true,
+}
+
+// GetAllBudgets retorna todos os orçamentos
+func (s *Service) GetAllBudgets(ctx context.Context) ([]models.Budget, error) {
+	budgets, err := s.repo.GetAllBudgets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar orçamentos: %w", err)
+	}
+	if budgets == nil {
+		budgets = []models.Budget{}
+	}
+	return budgets, nil
+}
+
+// GetBudget retorna um orçamento; nil se não existir
+func (s *Service) GetBudget(ctx context.Context, id int) (*models.Budget, error) {
+	budget, err := s.repo.GetBudgetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar orçamento: %w", err)
+	}
+	return budget, nil
+}
+
+// CreateBudget valida e cria um orçamento
+func (s *Service) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	if err := validateBudget(budget); err != nil {
+		return err
+	}
+	if err := s.repo.InsertBudget(ctx, budget); err != nil {
+		return fmt.Errorf("erro no service ao criar orçamento: %w", err)
+	}
+	return nil
+}
+
+// UpdateBudget valida e atualiza um orçamento; retorna false se não existir
+func (s *Service) UpdateBudget(ctx context.Context, budget *models.Budget) (bool, error) {
+	if err := validateBudget(budget); err != nil {
+		return false, err
+	}
+	found, err := s.repo.UpdateBudget(ctx, budget)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar orçamento: %w", err)
+	}
+	return found, nil
+}
+
+// DeleteBudget remove um orçamento; retorna false se não existir
+func (s *Service) DeleteBudget(ctx context.Context, id int) (bool, error) {
+	found, err := s.repo.DeleteBudget(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover orçamento: %w", err)
+	}
+	return found, nil
+}
+
+// GetBudgetAlerts retorna os alertas de um orçamento
+func (s *Service) GetBudgetAlerts(ctx context.Context, budgetID int) ([]models.BudgetAlert, error) {
+	alerts, err := s.repo.GetBudgetAlerts(ctx, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar alertas de orçamento: %w", err)
+	}
+	if alerts == nil {
+		alerts = []models.BudgetAlert{}
+	}
+	return alerts, nil
+}
+
+// EvaluateBudgets compara o gasto do mês de referência (mês da última usage_date) com cada
+// orçamento ativo e registra os limiares cruzados. Retorna a quantidade de alertas novos.
+func (s *Service) EvaluateBudgets(ctx context.Context) (int, error) {
+	_, maxDate, err := s.repo.GetUsageDateRange(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("erro no service ao buscar mês de referência: %w", err)
+	}
+
+	var months []time.Time
+	if maxDate != nil {
+		months = append(months, truncatePeriod(*maxDate, "month"))
+	}
+	return s.evaluateBudgets(ctx, months)
+}
+
+// evaluateBudgets compara o gasto de cada mês com cada orçamento ativo, registra os limiares
+// cruzados e dispara em segundo plano a entrega dos alertas pendentes
+func (s *Service) evaluateBudgets(ctx context.Context, months []time.Time) (int, error) {
+	created := 0
+	if len(months) > 0 {
+		budgets, err := s.repo.GetAllBudgets(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("erro no service ao buscar orçamentos: %w", err)
+		}
+
+		for _, month := range months {
+			for _, budget := range budgets {
+				if !budget.Active {
+					continue
+				}
+
+				spend, err := s.repo.GetBudgetSpend(ctx, budget.Scope, budget.ScopeRef, month, nextPeriod(month, "month"))
+				if err != nil {
+					return created, fmt.Errorf("erro no service ao calcular gasto do orçamento %d: %w", budget.ID, err)
+				}
+
+				for _, threshold := range crossedThresholds(spend, budget.Amount, budget.Thresholds) {
+					alert := models.BudgetAlert{
+						BudgetID:  budget.ID,
+						Month:     month,
+						Threshold: threshold,
+						Spend:     spend,
+						Amount:    budget.Amount,
+					}
+					isNew, err := s.repo.InsertBudgetAlert(ctx, &alert)
+					if err != nil {
+						return created, fmt.Errorf("erro no service ao registrar alerta: %w", err)
+					}
+					if isNew {
+						created++
+					}
+				}
+			}
+		}
+	}
+
+	// A entrega depende do notificador externo e não deve atrasar a importação nem a requisição
+	go func() {
+		if _, err := s.DeliverBudgetAlerts(context.Background()); err != nil {
+			fmt.Printf("Erro ao entregar alertas de orçamento: %v\n", err)
+		}
+	}()
+
+	return created, nil
+}
+
+// DeliverBudgetAlerts envia os alertas pendentes ou com falha e registra o resultado. Uma entrega
+// já em andamento não é repetida em paralelo, para que o mesmo alerta não seja enviado duas vezes.
+// Retorna a quantidade de alertas entregues.
+func (s *Service) DeliverBudgetAlerts(ctx context.Context) (int, error) {
+	if !s.alertDelivery.TryLock() {
+		return 0, nil
+	}
+	defer s.alertDelivery.Unlock()
+
+	alerts, err := s.repo.GetUndeliveredBudgetAlerts(ctx, maxAlertAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("erro no service ao buscar alertas pendentes: %w", err)
+	}
+
+	delivered := 0
+	for _, alert := range alerts {
+		deliveryErr := s.notifier.Notify(ctx, notifier.Event{
+			Type:       "budget.threshold_crossed",
+			OccurredAt: time.Now(),
+			Data:       alert,
+		})
+		if deliveryErr != nil {
+			fmt.Printf("Falha ao notificar alerta de orçamento %d: %v\n", alert.ID, deliveryErr)
+		} else {
+			delivered++
+		}
+		if err := s.repo.UpdateBudgetAlertDelivery(ctx, alert.ID, deliveryErr); err != nil {
+			return delivered, fmt.Errorf("erro no service ao registrar entrega do alerta: %w", err)
+		}
+	}
+
+	return delivered, nil
+}
+
+// RunBudgetAlertDispatcher reenvia periodicamente os alertas pendentes até o contexto ser cancelado
+func (s *Service) RunBudgetAlertDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeliverBudgetAlerts(ctx); err != nil {
+				fmt.Printf("Erro ao entregar alertas de orçamento: %v\n", err)
+			}
+		}
+	}
+}
+
+// importedMonths retorna, em ordem, os meses tocados pelos usos de uma importação
+func importedMonths(series map[seriesKey]dateRange) []time.Time {
+	seen := make(map[time.Time]bool)
+	var months []time.Time
+	for _, r := range series {
+		for month := truncatePeriod(r.from, "month"); !month.After(r.to); month = nextPeriod(month, "month") {
+			if !seen[month] {
+				seen[month] = true
+				months = append(months, month)
+			}
+		}
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+	return months
+}
+
+// crossedThresholds retorna os limiares (em % do orçamento) atingidos pelo gasto
+func crossedThresholds(spend, amount float64, thresholds []int) []int {
+	var crossed []int
+	if amount <= 0 {
+		return crossed
+	}
+	for _, t := range thresholds {
+		if spend >= amount*float64(t)/100 {
+			crossed = append(crossed, t)
+		}
+	}
+	return crossed
+}
+
+// validateBudget normaliza e valida os campos de um orçamento
+func validateBudget(budget *models.Budget) error {
+	budget.Name = strings.TrimSpace(budget.Name)
+	budget.ScopeRef = strings.TrimSpace(budget.ScopeRef)
+
+	if budget.Name == "" {
+		return fmt.Errorf("%w: name é obrigatório", ErrInvalidParameter)
+	}
+	if !validBudgetScopes[budget.Scope] {
+		return fmt.Errorf("%w: scope deve ser customer, partner ou category", ErrInvalidParameter)
+	}
+	if budget.ScopeRef == "" {
+		return fmt.Errorf("%w: scope_ref é obrigatório", ErrInvalidParameter)
+	}
+	if budget.Amount <= 0 {
+		return fmt.Errorf("%w: amount deve ser maior que zero", ErrInvalidParameter)
+	}
+
+	if len(budget.Thresholds) == 0 {
+		budget.Thresholds = append([]int(nil), defaultBudgetThresholds...)
+	}
+	seen := make(map[int]bool)
+	thresholds := make([]int, 0, len(budget.Thresholds))
+	for _, t := range budget.Thresholds {
+		if t <= 0 || t > 1000 {
+			return fmt.Errorf("%w: thresholds devem estar entre 1 e 1000", ErrInvalidParameter)
+		}
+		if !seen[t] {
+			seen[t] = true
+			thresholds = append(thresholds, t)
+		}
+	}
+	sort.Ints(thresholds)
+	budget.Thresholds = thresholds
+
+	return nil
+}
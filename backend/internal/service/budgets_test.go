@@ -0,0 +1,82 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCrossedThresholds(t *testing.T) {
+	tests := []struct {
+		spend, amount float64
+		want          []int
+	}{
+		{spend: 400, amount: 1000, want: nil},
+		{spend: 500, amount: 1000, want: []int{50}},
+		{spend: 850, amount: 1000, want: []int{50, 80}},
+		{spend: 1200, amount: 1000, want: []int{50, 80, 100}},
+		{spend: 1200, amount: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		got := crossedThresholds(tt.spend, tt.amount, []int{50, 80, 100})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("crossedThresholds(%v, %v) = %v, want %v", tt.spend, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBudgetNormalizesThresholds(t *testing.T) {
+	budget := models.Budget{Name: " Cliente A ", Scope: "customer", ScopeRef: "abc", Amount: 100, Thresholds: []int{100, 50, 100, 80}}
+	if err := validateBudget(&budget); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if budget.Name != "Cliente A" || !reflect.DeepEqual(budget.Thresholds, []int{50, 80, 100}) {
+		t.Errorf("unexpected normalized budget: %+v", budget)
+	}
+
+	budget = models.Budget{Name: "Parceiro", Scope: "partner", ScopeRef: "p1", Amount: 100}
+	if err := validateBudget(&budget); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(budget.Thresholds, defaultBudgetThresholds) {
+		t.Errorf("expected default thresholds, got %v", budget.Thresholds)
+	}
+}
+
+func TestValidateBudgetRejectsInvalid(t *testing.T) {
+	invalid := []models.Budget{
+		{Name: "", Scope: "customer", ScopeRef: "abc", Amount: 100},
+		{Name: "x", Scope: "product", ScopeRef: "abc", Amount: 100},
+		{Name: "x", Scope: "customer", ScopeRef: " ", Amount: 100},
+		{Name: "x", Scope: "customer", ScopeRef: "abc", Amount: 0},
+		{Name: "x", Scope: "customer", ScopeRef: "abc", Amount: 100, Thresholds: []int{0}},
+	}
+
+	for _, budget := range invalid {
+		if err := validateBudget(&budget); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("expected ErrInvalidParameter for %+v, got %v", budget, err)
+		}
+	}
+}
+
+func TestImportedMonthsCoversEveryTouchedMonth(t *testing.T) {
+	series := map[seriesKey]dateRange{
+		{1, 1}: {from: date(2024, time.January, 20), to: date(2024, time.March, 2)},
+		{2, 1}: {from: date(2024, time.February, 10), to: date(2024, time.February, 11)},
+		{3, 1}: {from: date(2023, time.December, 31), to: date(2023, time.December, 31)},
+	}
+
+	months := importedMonths(series)
+	want := []time.Time{date(2023, time.December, 1), date(2024, time.January, 1), date(2024, time.February, 1), date(2024, time.March, 1)}
+	if len(months) != len(want) {
+		t.Fatalf("expected %d months, got %v", len(want), months)
+	}
+	for i := range want {
+		if !months[i].Equal(want[i]) {
+			t.Errorf("month %d = %s, want %s", i, months[i], want[i])
+		}
+	}
+}
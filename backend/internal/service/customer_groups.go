@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// GetCustomerGroups retorna os grupos de clientes
+func (s *Service) GetCustomerGroups(ctx context.Context) ([]models.CustomerGroup, error) {
+	groups, err := s.repo.GetCustomerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar grupos de clientes: %w", err)
+	}
+	if groups == nil {
+		groups = []models.CustomerGroup{}
+	}
+	return groups, nil
+}
+
+// GetCustomerGroup retorna um grupo com seus clientes; nil se não existir
+func (s *Service) GetCustomerGroup(ctx context.Context, id int) (*models.CustomerGroupDetail, error) {
+	group, err := s.repo.GetCustomerGroupByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar grupo de clientes: %w", err)
+	}
+	if group == nil {
+		return nil, nil
+	}
+
+	customers, err := s.repo.GetCustomersByGroup(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar clientes do grupo: %w", err)
+	}
+	if customers == nil {
+		customers = []models.Customer{}
+	}
+	return &models.CustomerGroupDetail{CustomerGroup: *group, Customers: customers}, nil
+}
+
+// CreateCustomerGroup valida e cria um grupo, associando os clientes pelos padrões de domínio
+func (s *Service) CreateCustomerGroup(ctx context.Context, group *models.CustomerGroup) error {
+	if err := validateCustomerGroup(group); err != nil {
+		return err
+	}
+
+	created, err := s.repo.InsertCustomerGroup(ctx, group)
+	if err != nil {
+		return fmt.Errorf("erro no service ao criar grupo de clientes: %w", err)
+	}
+	if !created {
+		return fmt.Errorf("%w: já existe um grupo chamado %q", ErrConflict, group.Name)
+	}
+
+	return s.refreshCustomerGroup(ctx, group)
+}
+
+// UpdateCustomerGroup valida e atualiza um grupo, reaplicando os padrões de domínio; retorna
+// false se não existir
+func (s *Service) UpdateCustomerGroup(ctx context.Context, group *models.CustomerGroup) (bool, error) {
+	if err := validateCustomerGroup(group); err != nil {
+		return false, err
+	}
+
+	updated, err := s.repo.UpdateCustomerGroup(ctx, group)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar grupo de clientes: %w", err)
+	}
+	if !updated {
+		current, err := s.repo.GetCustomerGroupByID(ctx, group.ID)
+		if err != nil {
+			return false, fmt.Errorf("erro no service ao atualizar grupo de clientes: %w", err)
+		}
+		if current == nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: já existe um grupo chamado %q", ErrConflict, group.Name)
+	}
+
+	return true, s.refreshCustomerGroup(ctx, group)
+}
+
+// DeleteCustomerGroup remove um grupo; os clientes voltam a ser associados pelos padrões dos
+// demais grupos. Retorna false se não existir.
+func (s *Service) DeleteCustomerGroup(ctx context.Context, id int) (bool, error) {
+	deleted, err := s.repo.DeleteCustomerGroup(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover grupo de clientes: %w", err)
+	}
+	if !deleted {
+		return false, nil
+	}
+
+	if _, err := s.ApplyCustomerGroupPatterns(ctx); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// SetCustomerGroup associa manualmente um cliente a um grupo. Com groupID nulo a associação
+// manual é desfeita e o cliente volta a seguir os padrões de domínio. Retorna false se o
+// cliente não existir.
+func (s *Service) SetCustomerGroup(ctx context.Context, customerID int, groupID *int) (bool, error) {
+	source := ""
+	if groupID != nil {
+		group, err := s.repo.GetCustomerGroupByID(ctx, *groupID)
+		if err != nil {
+			return false, fmt.Errorf("erro no service ao buscar grupo de clientes: %w", err)
+		}
+		if group == nil {
+			return false, fmt.Errorf("%w: grupo %d não encontrado", ErrInvalidParameter, *groupID)
+		}
+		source = models.GroupSourceManual
+	}
+
+	found, err := s.repo.SetCustomerGroup(ctx, customerID, groupID, source)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao associar cliente ao grupo: %w", err)
+	}
+	if !found {
+		return false, nil
+	}
+
+	if groupID == nil {
+		if _, err := s.ApplyCustomerGroupPatterns(ctx); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// ApplyCustomerGroupPatterns restaura as associações manuais e reassocia os demais clientes
+// conforme os padrões de domínio dos grupos, retornando quantos clientes mudaram de grupo
+func (s *Service) ApplyCustomerGroupPatterns(ctx context.Context) (int, error) {
+	// As associações manuais vêm primeiro, para que os clientes recriados não caiam nos padrões
+	if err := s.repo.RestoreCustomerGroupAssignments(ctx); err != nil {
+		return 0, fmt.Errorf("erro no service ao aplicar grupos de clientes: %w", err)
+	}
+
+	groups, err := s.repo.GetCustomerGroups(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("erro no service ao aplicar grupos de clientes: %w", err)
+	}
+	customers, err := s.repo.GetAllCustomers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("erro no service ao aplicar grupos de clientes: %w", err)
+	}
+
+	assignments := planGroupAssignments(groups, customers)
+	if err := s.repo.ApplyCustomerGroupPatterns(ctx, assignments); err != nil {
+		return 0, fmt.Errorf("erro no service ao aplicar grupos de clientes: %w", err)
+	}
+	return len(assignments), nil
+}
+
+// GetBillingByCustomerGroup retorna o faturamento por grupo de clientes
+func (s *Service) GetBillingByCustomerGroup(ctx context.Context, asOf *time.Time) ([]models.CustomerGroupBillingReport, error) {
+	reports, err := s.repo.GetBillingByCustomerGroup(ctx, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar faturamento por grupo de clientes: %w", err)
+	}
+	if reports == nil {
+		reports = []models.CustomerGroupBillingReport{}
+	}
+	return reports, nil
+}
+
+// refreshCustomerGroup reaplica os padrões e recarrega a contagem de clientes do grupo
+func (s *Service) refreshCustomerGroup(ctx context.Context, group *models.CustomerGroup) error {
+	if _, err := s.ApplyCustomerGroupPatterns(ctx); err != nil {
+		return err
+	}
+	current, err := s.repo.GetCustomerGroupByID(ctx, group.ID)
+	if err != nil {
+		return fmt.Errorf("erro no service ao buscar grupo de clientes: %w", err)
+	}
+	if current != nil {
+		*group = *current
+	}
+	return nil
+}
+
+// validateCustomerGroup normaliza nome e padrões (minúsculos, sem duplicatas) e valida a sintaxe glob
+func validateCustomerGroup(group *models.CustomerGroup) error {
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return fmt.Errorf("%w: name é obrigatório", ErrInvalidParameter)
+	}
+	if len([]rune(group.Name)) > 255 {
+		return fmt.Errorf("%w: name deve ter no máximo 255 caracteres", ErrInvalidParameter)
+	}
+
+	patterns := make([]string, 0, len(group.DomainPatterns))
+	seen := make(map[string]bool)
+	for _, p := range group.DomainPatterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" || seen[p] {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil || strings.Contains(p, "/") {
+			return fmt.Errorf("%w: padrão de domínio inválido: %s", ErrInvalidParameter, p)
+		}
+		seen[p] = true
+		patterns = append(patterns, p)
+	}
+	group.DomainPatterns = patterns
+	return nil
+}
+
+// matchCustomerGroup retorna o grupo cujo padrão casa com o domínio. Havendo mais de um, vence
+// o padrão mais longo (mais específico) e, depois, o grupo de menor id.
+func matchCustomerGroup(groups []models.CustomerGroup, domain string) *int {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return nil
+	}
+
+	var best *models.CustomerGroup
+	bestLen := -1
+	for i := range groups {
+		for _, p := range groups[i].DomainPatterns {
+			if ok, _ := path.Match(p, domain); !ok {
+				continue
+			}
+			if len(p) > bestLen || (len(p) == bestLen && groups[i].ID < best.ID) {
+				best, bestLen = &groups[i], len(p)
+			}
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	id := best.ID
+	return &id
+}
+
+// planGroupAssignments calcula as associações por padrão que mudam, ignorando clientes
+// associados manualmente
+func planGroupAssignments(groups []models.CustomerGroup, customers []models.Customer) map[int]*int {
+	assignments := make(map[int]*int)
+	for _, c := range customers {
+		if c.GroupSource == models.GroupSourceManual {
+			continue
+		}
+		target := matchCustomerGroup(groups, c.CustomerDomainName)
+		if sameGroup(c.GroupID, target) {
+			continue
+		}
+		assignments[c.ID] = target
+	}
+	return assignments
+}
+
+func sameGroup(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"errors"
+	"testing"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestValidateCustomerGroup(t *testing.T) {
+	group := models.CustomerGroup{Name: " Contoso ", DomainPatterns: []string{" *.Contoso.com ", "contoso.com", "*.contoso.com", ""}}
+	if err := validateCustomerGroup(&group); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if group.Name != "Contoso" || len(group.DomainPatterns) != 2 || group.DomainPatterns[0] != "*.contoso.com" {
+		t.Errorf("unexpected normalized group: %+v", group)
+	}
+
+	invalid := []models.CustomerGroup{
+		{Name: "  "},
+		{Name: "x", DomainPatterns: []string{"[contoso"}},
+		{Name: "x", DomainPatterns: []string{"contoso.com/tenant"}},
+	}
+	for _, g := range invalid {
+		if err := validateCustomerGroup(&g); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("validateCustomerGroup(%+v) err = %v, want ErrInvalidParameter", g, err)
+		}
+	}
+}
+
+func TestMatchCustomerGroup(t *testing.T) {
+	groups := []models.CustomerGroup{
+		{ID: 2, DomainPatterns: []string{"*.contoso.com"}},
+		{ID: 1, DomainPatterns: []string{"*.com"}},
+		{ID: 3, DomainPatterns: []string{"emea.contoso.com"}},
+		{ID: 4, DomainPatterns: []string{"?.contoso.com"}},
+	}
+
+	tests := []struct {
+		domain string
+		want   *int
+	}{
+		{"br.contoso.com", intPtr(2)},
+		{"EMEA.Contoso.com.", intPtr(3)},
+		{"fabrikam.com", intPtr(1)},
+		{"x.contoso.com", intPtr(2)}, // empate em tamanho: menor id
+		{"contoso.org", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := matchCustomerGroup(groups, tt.domain)
+		if !sameGroup(got, tt.want) {
+			t.Errorf("matchCustomerGroup(%q) = %v, want %v", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestPlanGroupAssignments(t *testing.T) {
+	groups := []models.CustomerGroup{{ID: 1, DomainPatterns: []string{"*.contoso.com"}}}
+	customers := []models.Customer{
+		{ID: 10, CustomerDomainName: "br.contoso.com"},                                             // novo no grupo
+		{ID: 11, CustomerDomainName: "us.contoso.com", GroupID: intPtr(1), GroupSource: "pattern"}, // já associado
+		{ID: 12, CustomerDomainName: "fabrikam.com", GroupID: intPtr(1), GroupSource: "pattern"},   // deixou de casar
+		{ID: 13, CustomerDomainName: "fabrikam.com", GroupID: intPtr(1), GroupSource: "manual"},    // manual prevalece
+		{ID: 14, CustomerDomainName: "fabrikam.com"},
+	}
+
+	got := planGroupAssignments(groups, customers)
+	if len(got) != 2 {
+		t.Fatalf("assignments = %v", got)
+	}
+	if !sameGroup(got[10], intPtr(1)) {
+		t.Errorf("customer 10 = %v, want 1", got[10])
+	}
+	if target, ok := got[12]; !ok || target != nil {
+		t.Errorf("customer 12 = %v (present %v), want nil", target, ok)
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/repository"
+	"fmt"
+	"time"
+)
+
+// dimensionRecord é o estado de uma entidade recebido na importação
+type dimensionRecord struct {
+	entityID int
+	key      string
+	attrs    map[string]string
+}
+
+func partnerRecord(p models.Partner) dimensionRecord {
+	return dimensionRecord{entityID: p.ID, key: p.PartnerID, attrs: map[string]string{
+		"partner_name": p.PartnerName,
+		"mpn_id":       p.MpnID,
+		"tier2_mpn_id": p.Tier2MpnID,
+	}}
+}
+
+func customerRecord(c models.Customer) dimensionRecord {
+	return dimensionRecord{entityID: c.ID, key: c.CustomerID, attrs: map[string]string{
+		"customer_name":        c.CustomerName,
+		"customer_domain_name": c.CustomerDomainName,
+		"country":              c.Country,
+	}}
+}
+
+func productRecord(p models.Product) dimensionRecord {
+	return dimensionRecord{entityID: p.ID, key: p.ProductID, attrs: map[string]string{
+		"sku_id":       p.SkuID,
+		"sku_name":     p.SkuName,
+		"product_name": p.ProductName,
+		"meter_type":   p.MeterType,
+		"category":     p.Category,
+		"sub_category": p.SubCategory,
+		"unit_type":    p.UnitType,
+	}}
+}
+
+// GetDimensionVersions retorna o histórico de versões de um parceiro, cliente ou produto;
+// retorna nil se a entidade não tiver versões
+func (s *Service) GetDimensionVersions(ctx context.Context, dimension string, id int) ([]models.DimensionVersion, error) {
+	if repository.DimensionAttributes(dimension) == nil {
+		return nil, fmt.Errorf("%w: dimensão deve ser partner, customer ou product", ErrInvalidParameter)
+	}
+
+	versions, err := s.repo.GetDimensionVersions(ctx, dimension, []int{id})
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar versões: %w", err)
+	}
+	return versions, nil
+}
+
+// versionDimensions abre novas versões para as entidades cujos atributos mudaram e liga cada
+// usage à versão vigente na sua usage_date
+func (s *Service) versionDimensions(ctx context.Context, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) error {
+	today := dateOnly(time.Now())
+
+	partnerRecords := make([]dimensionRecord, 0, len(partners))
+	for _, p := range partners {
+		partnerRecords = append(partnerRecords, partnerRecord(p))
+	}
+	partnerVersions, err := s.versionDimension(ctx, "partner", partnerRecords,
+		firstUsageDates(usages, func(u models.Usage) int { return u.PartnerID }), today)
+	if err != nil {
+		return err
+	}
+
+	customerRecords := make([]dimensionRecord, 0, len(customers))
+	for _, c := range customers {
+		customerRecords = append(customerRecords, customerRecord(c))
+	}
+	customerVersions, err := s.versionDimension(ctx, "customer", customerRecords,
+		firstUsageDates(usages, func(u models.Usage) int { return u.CustomerID }), today)
+	if err != nil {
+		return err
+	}
+
+	productRecords := make([]dimensionRecord, 0, len(products))
+	for _, p := range products {
+		productRecords = append(productRecords, productRecord(p))
+	}
+	productVersions, err := s.versionDimension(ctx, "product", productRecords,
+		firstUsageDates(usages, func(u models.Usage) int { return u.ProductID }), today)
+	if err != nil {
+		return err
+	}
+
+	for i := range usages {
+		usages[i].PartnerVersionID = versionAt(partnerVersions[usages[i].PartnerID], usages[i].UsageDate)
+		usages[i].CustomerVersionID = versionAt(customerVersions[usages[i].CustomerID], usages[i].UsageDate)
+		usages[i].ProductVersionID = versionAt(productVersions[usages[i].ProductID], usages[i].UsageDate)
+	}
+	return nil
+}
+
+// versionDimension grava as versões necessárias de uma dimensão e retorna o histórico por entidade
+func (s *Service) versionDimension(ctx context.Context, dimension string, records []dimensionRecord, firstDates map[int]time.Time, today time.Time) (map[int][]models.DimensionVersion, error) {
+	// a última ocorrência de cada entidade prevalece, como no upsert da tabela base
+	latest := make(map[int]dimensionRecord, len(records))
+	ids := make([]int, 0, len(records))
+	for _, rec := range records {
+		if rec.entityID <= 0 {
+			continue
+		}
+		if _, seen := latest[rec.entityID]; !seen {
+			ids = append(ids, rec.entityID)
+		}
+		latest[rec.entityID] = rec
+	}
+
+	existing, err := s.repo.GetDimensionVersions(ctx, dimension, ids)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao versionar %s: %w", dimension, err)
+	}
+	history := make(map[int][]models.DimensionVersion, len(ids))
+	for _, v := range existing {
+		history[v.EntityID] = append(history[v.EntityID], v)
+	}
+
+	for _, id := range ids {
+		rec := latest[id]
+		effective, ok := firstDates[id]
+		if !ok {
+			effective = today
+		}
+
+		plan, changed := planVersion(history[id], rec.attrs, effective)
+		if !changed {
+			continue
+		}
+
+		v := models.DimensionVersion{
+			Dimension:  dimension,
+			EntityID:   id,
+			Key:        rec.key,
+			Attributes: rec.attrs,
+			ValidFrom:  plan.validFrom,
+			ValidTo:    plan.validTo,
+			IsCurrent:  plan.validTo == nil,
+		}
+		if err := s.repo.InsertDimensionVersion(ctx, &v, plan.closeID, plan.validFrom); err != nil {
+			return nil, fmt.Errorf("erro no service ao versionar %s: %w", dimension, err)
+		}
+
+		versions := history[id]
+		for i := range versions {
+			if versions[i].ID == plan.closeID {
+				closeAt := plan.validFrom
+				versions[i].ValidTo = &closeAt
+				versions[i].IsCurrent = false
+			}
+		}
+		history[id] = append(versions, v)
+
+		// Uma versão histórica não muda os atributos atuais, que o upsert da importação sobrescreveu
+		if current := currentVersion(history[id]); !v.IsCurrent && current != nil {
+			if err := s.repo.RestoreDimensionAttributes(ctx, dimension, id, current.Attributes); err != nil {
+				return nil, fmt.Errorf("erro no service ao versionar %s: %w", dimension, err)
+			}
+		}
+	}
+
+	return history, nil
+}
+
+// firstUsageDates retorna a menor usage_date de cada entidade no lote
+func firstUsageDates(usages []models.Usage, entityID func(models.Usage) int) map[int]time.Time {
+	dates := make(map[int]time.Time)
+	for _, u := range usages {
+		id := entityID(u)
+		if id <= 0 || u.UsageDate.IsZero() {
+			continue
+		}
+		day := dateOnly(u.UsageDate)
+		if first, ok := dates[id]; !ok || day.Before(first) {
+			dates[id] = day
+		}
+	}
+	return dates
+}
+
+func currentVersion(versions []models.DimensionVersion) *models.DimensionVersion {
+	for i := range versions {
+		if versions[i].IsCurrent {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+// versionPlan descreve a versão a gravar: vigente a partir de validFrom e, quando validTo é
+// informado, histórica até validTo. closeID é a versão encerrada em validFrom.
+type versionPlan struct {
+	validFrom time.Time
+	validTo   *time.Time
+	closeID   int
+}
+
+// planVersion decide se os atributos recebidos exigem uma nova versão e onde ela entra no
+// histórico. A versão vigente em effective é encerrada nessa data; se ela já estava encerrada, a
+// nova versão é histórica e termina onde a encerrada terminava, sem alterar as versões
+// posteriores. Antes da primeira versão, a nova termina no início da primeira.
+func planVersion(versions []models.DimensionVersion, attrs map[string]string, effective time.Time) (versionPlan, bool) {
+	effective = dateOnly(effective)
+
+	var containing, next *models.DimensionVersion
+	for i := range versions {
+		v := &versions[i]
+		from := dateOnly(v.ValidFrom)
+		switch {
+		case !from.After(effective) && (v.ValidTo == nil || effective.Before(dateOnly(*v.ValidTo))):
+			if containing == nil || v.ID > containing.ID {
+				containing = v
+			}
+		case from.After(effective):
+			if next == nil || from.Before(dateOnly(next.ValidFrom)) {
+				next = v
+			}
+		}
+	}
+
+	switch {
+	case containing != nil:
+		if sameAttributes(containing.Attributes, attrs) {
+			return versionPlan{}, false
+		}
+		plan := versionPlan{validFrom: effective, closeID: containing.ID}
+		if containing.ValidTo != nil {
+			to := dateOnly(*containing.ValidTo)
+			plan.validTo = &to
+		}
+		return plan, true
+	case next != nil:
+		if sameAttributes(next.Attributes, attrs) {
+			return versionPlan{}, false
+		}
+		to := dateOnly(next.ValidFrom)
+		return versionPlan{validFrom: effective, validTo: &to}, true
+	}
+	return versionPlan{validFrom: effective}, true
+}
+
+func sameAttributes(current, attrs map[string]string) bool {
+	for name, value := range attrs {
+		if current[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// versionAt retorna o id da versão vigente em date: a de maior valid_from até a data, com
+// desempate pela mais recente. Datas anteriores ao histórico usam a primeira versão.
+func versionAt(versions []models.DimensionVersion, date time.Time) int {
+	if len(versions) == 0 {
+		return 0
+	}
+
+	day := dateOnly(date)
+	found := -1
+	for i, v := range versions {
+		if dateOnly(v.ValidFrom).After(day) {
+			continue
+		}
+		if found < 0 || v.ValidFrom.After(versions[found].ValidFrom) ||
+			(v.ValidFrom.Equal(versions[found].ValidFrom) && v.ID > versions[found].ID) {
+			found = i
+		}
+	}
+	if found < 0 {
+		first := 0
+		for i, v := range versions {
+			if v.ValidFrom.Before(versions[first].ValidFrom) {
+				first = i
+			}
+		}
+		return versions[first].ID
+	}
+	return versions[found].ID
+}
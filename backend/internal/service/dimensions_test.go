@@ -0,0 +1,88 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"testing"
+	"time"
+)
+
+func TestPlanVersion(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+	at := func(d int) *time.Time { v := day(d); return &v }
+	// Contoso vale de 10 a 20; Contoso Ltda é a versão corrente desde 20
+	history := []models.DimensionVersion{
+		{ID: 1, Attributes: map[string]string{"customer_name": "Contoso", "country": "BR"}, ValidFrom: day(10), ValidTo: at(20)},
+		{ID: 2, Attributes: map[string]string{"customer_name": "Contoso Ltda", "country": "BR"}, ValidFrom: day(20), IsCurrent: true},
+	}
+
+	tests := []struct {
+		name      string
+		versions  []models.DimensionVersion
+		attrs     map[string]string
+		effective time.Time
+		want      versionPlan
+		changed   bool
+	}{
+		{"first version", nil, map[string]string{"country": "BR"}, day(5).Add(13 * time.Hour), versionPlan{validFrom: day(5)}, true},
+		{"unchanged current", history, map[string]string{"customer_name": "Contoso Ltda", "country": "BR"}, day(25), versionPlan{}, false},
+		{"changed after current", history, map[string]string{"customer_name": "Contoso Ltda", "country": "US"}, day(25), versionPlan{validFrom: day(25), closeID: 2}, true},
+		{"unchanged historical", history, map[string]string{"customer_name": "Contoso", "country": "BR"}, day(12), versionPlan{}, false},
+		{"changed inside historical", history, map[string]string{"customer_name": "Contoso SA", "country": "BR"}, day(15), versionPlan{validFrom: day(15), validTo: at(20), closeID: 1}, true},
+		{"changed before first", history, map[string]string{"customer_name": "Contoso SA", "country": "BR"}, day(1), versionPlan{validFrom: day(1), validTo: at(10)}, true},
+		{"same as first before first", history, map[string]string{"customer_name": "Contoso", "country": "BR"}, day(1), versionPlan{}, false},
+	}
+
+	for _, tt := range tests {
+		plan, changed := planVersion(tt.versions, tt.attrs, tt.effective)
+		if changed != tt.changed || !plan.validFrom.Equal(tt.want.validFrom) || plan.closeID != tt.want.closeID ||
+			(plan.validTo == nil) != (tt.want.validTo == nil) || (plan.validTo != nil && !plan.validTo.Equal(*tt.want.validTo)) {
+			t.Errorf("%s: planVersion = (%+v, %v), want (%+v, %v)", tt.name, plan, changed, tt.want, tt.changed)
+		}
+	}
+}
+
+func TestVersionAt(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+	versions := []models.DimensionVersion{
+		{ID: 1, ValidFrom: day(5)},
+		{ID: 2, ValidFrom: day(15)},
+		{ID: 3, ValidFrom: day(15)}, // substitui a versão 2 desde o seu início
+		{ID: 4, ValidFrom: day(25)},
+	}
+
+	tests := []struct {
+		date time.Time
+		want int
+	}{
+		{day(1), 1},
+		{day(5), 1},
+		{day(14).Add(23 * time.Hour), 1},
+		{day(15).Add(8 * time.Hour), 3},
+		{day(24), 3},
+		{day(25), 4},
+		{day(31), 4},
+	}
+
+	for _, tt := range tests {
+		if got := versionAt(versions, tt.date); got != tt.want {
+			t.Errorf("versionAt(%v) = %d, want %d", tt.date, got, tt.want)
+		}
+	}
+	if got := versionAt(nil, day(1)); got != 0 {
+		t.Errorf("versionAt(nil) = %d, want 0", got)
+	}
+}
+
+func TestFirstUsageDates(t *testing.T) {
+	usages := []models.Usage{
+		{CustomerID: 1, UsageDate: time.Date(2024, time.March, 9, 10, 0, 0, 0, time.UTC)},
+		{CustomerID: 1, UsageDate: time.Date(2024, time.March, 3, 18, 0, 0, 0, time.UTC)},
+		{CustomerID: 2},
+		{CustomerID: 0, UsageDate: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	dates := firstUsageDates(usages, func(u models.Usage) int { return u.CustomerID })
+	if len(dates) != 1 || !dates[1].Equal(time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("dates = %v", dates)
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// entityField descreve um campo editável de parceiro, cliente ou produto para validação
+type entityField struct {
+	name     string
+	value    *string
+	max      int
+	required bool
+}
+
+// validateEntityFields remove espaços das pontas e valida obrigatoriedade e tamanho máximo. Com
+// only, apenas os campos listados são validados (PATCH); os demais mantêm o valor gravado.
+func validateEntityFields(fields []entityField, only []string) error {
+	for _, f := range fields {
+		if !entityFieldSelected(f.name, only) {
+			continue
+		}
+		*f.value = strings.TrimSpace(*f.value)
+		if f.required && *f.value == "" {
+			return fmt.Errorf("%w: %s é obrigatório", ErrInvalidParameter, f.name)
+		}
+		if utf8.RuneCountInString(*f.value) > f.max {
+			return fmt.Errorf("%w: %s deve ter no máximo %d caracteres", ErrInvalidParameter, f.name, f.max)
+		}
+	}
+	return nil
+}
+
+// entityFieldSelected informa se o campo deve ser validado; only nil seleciona todos
+func entityFieldSelected(name string, only []string) bool {
+	if only == nil {
+		return true
+	}
+	for _, f := range only {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePartner(p *models.Partner, only []string) error {
+	return validateEntityFields([]entityField{
+		{"partner_name", &p.PartnerName, 255, true},
+		{"mpn_id", &p.MpnID, 255, false},
+		{"tier2_mpn_id", &p.Tier2MpnID, 255, false},
+	}, only)
+}
+
+func validateCustomer(c *models.Customer, only []string) error {
+	err := validateEntityFields([]entityField{
+		{"customer_name", &c.CustomerName, 255, true},
+		{"customer_domain_name", &c.CustomerDomainName, 255, false},
+		{"country", &c.Country, 100, false},
+	}, only)
+	if err != nil {
+		return err
+	}
+	if entityFieldSelected("customer_domain_name", only) && c.CustomerDomainName != "" && !validDomainName(c.CustomerDomainName) {
+		return fmt.Errorf("%w: customer_domain_name inválido: %s", ErrInvalidParameter, c.CustomerDomainName)
+	}
+	return nil
+}
+
+func validateProduct(p *models.Product, only []string) error {
+	return validateEntityFields([]entityField{
+		{"sku_id", &p.SkuID, 255, true},
+		{"sku_name", &p.SkuName, 255, true},
+		{"product_name", &p.ProductName, 255, true},
+		{"meter_type", &p.MeterType, 100, false},
+		{"category", &p.Category, 100, false},
+		{"sub_category", &p.SubCategory, 100, false},
+		{"unit_type", &p.UnitType, 50, false},
+	}, only)
+}
+
+// recordEntityVersion abre uma versão para a edição manual, vigente a partir de hoje. Falhas
+// são apenas registradas: a tabela base já foi atualizada.
+func (s *Service) recordEntityVersion(ctx context.Context, dimension string, rec dimensionRecord) {
+	if _, err := s.versionDimension(ctx, dimension, []dimensionRecord{rec}, nil, dateOnly(time.Now())); err != nil {
+		fmt.Printf("Aviso ao versionar %s %d: %v\n", dimension, rec.entityID, err)
+	}
+}
+
+// GetPartner retorna um parceiro; nil se não existir
+func (s *Service) GetPartner(ctx context.Context, id int) (*models.Partner, error) {
+	partner, err := s.repo.GetPartnerByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar parceiro: %w", err)
+	}
+	return partner, nil
+}
+
+// UpdatePartner valida e grava um parceiro se ele não tiver sido alterado desde expected; fields lista
+// os campos alterados no PATCH (nil valida todos). Retorna false se não existir
+func (s *Service) UpdatePartner(ctx context.Context, partner *models.Partner, fields []string, expected time.Time) (bool, error) {
+	if err := validatePartner(partner, fields); err != nil {
+		return false, err
+	}
+
+	updated, err := s.repo.UpdatePartner(ctx, partner, expected)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar parceiro: %w", err)
+	}
+	if !updated {
+		current, err := s.repo.GetPartnerByID(ctx, partner.ID)
+		if err != nil {
+			return false, fmt.Errorf("erro no service ao atualizar parceiro: %w", err)
+		}
+		if current == nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: parceiro alterado em %s", ErrConflict, current.UpdatedAt.Format(time.RFC3339Nano))
+	}
+
+	s.recordEntityVersion(ctx, "partner", partnerRecord(*partner))
+	return true, nil
+}
+
+// DeletePartner remove um parceiro sem usos; retorna false se não existir
+func (s *Service) DeletePartner(ctx context.Context, id int) (bool, error) {
+	deleted, err := s.repo.DeletePartner(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover parceiro: %w", err)
+	}
+	if deleted {
+		return true, nil
+	}
+
+	partner, err := s.repo.GetPartnerByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover parceiro: %w", err)
+	}
+	if partner == nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: parceiro possui usos vinculados", ErrConflict)
+}
+
+// GetUsageByPartner retorna o uso de um parceiro específico
+func (s *Service) GetUsageByPartner(ctx context.Context, partnerID int) ([]models.Usage, error) {
+	usages, err := s.repo.GetUsageByPartner(ctx, partnerID)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar uso do parceiro: %w", err)
+	}
+	if usages == nil {
+		usages = []models.Usage{}
+	}
+	return usages, nil
+}
+
+// GetCustomer retorna um cliente; nil se não existir
+func (s *Service) GetCustomer(ctx context.Context, id int) (*models.Customer, error) {
+	customer, err := s.repo.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar cliente: %w", err)
+	}
+	return customer, nil
+}
+
+// UpdateCustomer valida e grava um cliente se ele não tiver sido alterado desde expected; fields lista
+// os campos alterados no PATCH (nil valida todos). Retorna false se não existir
+func (s *Service) UpdateCustomer(ctx context.Context, customer *models.Customer, fields []string, expected time.Time) (bool, error) {
+	if err := validateCustomer(customer, fields); err != nil {
+		return false, err
+	}
+
+	updated, err := s.repo.UpdateCustomer(ctx, customer, expected)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar cliente: %w", err)
+	}
+	if !updated {
+		current, err := s.repo.GetCustomerByID(ctx, customer.ID)
+		if err != nil {
+			return false, fmt.Errorf("erro no service ao atualizar cliente: %w", err)
+		}
+		if current == nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: cliente alterado em %s", ErrConflict, current.UpdatedAt.Format(time.RFC3339Nano))
+	}
+
+	s.recordEntityVersion(ctx, "customer", customerRecord(*customer))
+	if _, err := s.ApplyCustomerGroupPatterns(ctx); err != nil {
+		fmt.Printf("Aviso ao aplicar grupos de clientes: %v\n", err)
+	}
+	return true, nil
+}
+
+// DeleteCustomer remove um cliente sem usos; retorna false se não existir
+func (s *Service) DeleteCustomer(ctx context.Context, id int) (bool, error) {
+	deleted, err := s.repo.DeleteCustomer(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover cliente: %w", err)
+	}
+	if deleted {
+		return true, nil
+	}
+
+	customer, err := s.repo.GetCustomerByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover cliente: %w", err)
+	}
+	if customer == nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: cliente possui usos vinculados", ErrConflict)
+}
+
+// GetProduct retorna um produto; nil se não existir
+func (s *Service) GetProduct(ctx context.Context, id int) (*models.Product, error) {
+	product, err := s.repo.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar produto: %w", err)
+	}
+	return product, nil
+}
+
+// UpdateProduct valida e grava um produto se ele não tiver sido alterado desde expected; fields lista
+// os campos alterados no PATCH (nil valida todos). Retorna false se não existir
+func (s *Service) UpdateProduct(ctx context.Context, product *models.Product, fields []string, expected time.Time) (bool, error) {
+	if err := validateProduct(product, fields); err != nil {
+		return false, err
+	}
+
+	updated, err := s.repo.UpdateProduct(ctx, product, expected)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar produto: %w", err)
+	}
+	if !updated {
+		current, err := s.repo.GetProductByID(ctx, product.ID)
+		if err != nil {
+			return false, fmt.Errorf("erro no service ao atualizar produto: %w", err)
+		}
+		if current == nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: produto alterado em %s", ErrConflict, current.UpdatedAt.Format(time.RFC3339Nano))
+	}
+
+	s.recordEntityVersion(ctx, "product", productRecord(*product))
+	return true, nil
+}
+
+// DeleteProduct remove um produto sem usos; retorna false se não existir
+func (s *Service) DeleteProduct(ctx context.Context, id int) (bool, error) {
+	deleted, err := s.repo.DeleteProduct(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover produto: %w", err)
+	}
+	if deleted {
+		return true, nil
+	}
+
+	product, err := s.repo.GetProductByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover produto: %w", err)
+	}
+	if product == nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: produto possui usos vinculados", ErrConflict)
+}
+
+// GetUsageByProduct retorna o uso de um produto específico
+func (s *Service) GetUsageByProduct(ctx context.Context, productID int) ([]models.Usage, error) {
+	usages, err := s.repo.GetUsageByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar uso do produto: %w", err)
+	}
+	if usages == nil {
+		usages = []models.Usage{}
+	}
+	return usages, nil
+}
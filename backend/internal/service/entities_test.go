@@ -0,0 +1,67 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateEntities(t *testing.T) {
+	partner := models.Partner{PartnerName: "  Contoso  ", MpnID: " 123 "}
+	if err := validatePartner(&partner, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if partner.PartnerName != "Contoso" || partner.MpnID != "123" {
+		t.Errorf("fields not trimmed: %+v", partner)
+	}
+
+	invalid := []error{
+		validatePartner(&models.Partner{PartnerName: "   "}, nil),
+		validatePartner(&models.Partner{PartnerName: strings.Repeat("a", 256)}, nil),
+		validateCustomer(&models.Customer{}, nil),
+		validateCustomer(&models.Customer{CustomerName: "Contoso", CustomerDomainName: "contoso"}, nil),
+		validateCustomer(&models.Customer{CustomerName: "Contoso", Country: strings.Repeat("b", 101)}, nil),
+		validateProduct(&models.Product{SkuID: "S1", SkuName: "Sku"}, nil),
+		validateProduct(&models.Product{SkuID: "S1", SkuName: "Sku", ProductName: "VM", UnitType: strings.Repeat("h", 51)}, nil),
+	}
+	for i, err := range invalid {
+		if !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("case %d: err = %v, want ErrInvalidParameter", i, err)
+		}
+	}
+
+	customer := models.Customer{CustomerName: "Contoso", CustomerDomainName: "contoso.com", Country: "Brazil"}
+	if err := validateCustomer(&customer, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// o tamanho é contado em caracteres, como no VARCHAR
+	product := models.Product{SkuID: "S1", SkuName: "Sku", ProductName: strings.Repeat("ç", 255)}
+	if err := validateProduct(&product, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateEntitiesOnlyPatchedFields(t *testing.T) {
+	// cliente importado com domínio fora das regras de edição e país longo demais
+	customer := models.Customer{CustomerName: "Contoso", CustomerDomainName: "contoso", Country: strings.Repeat("b", 101)}
+	if err := validateCustomer(&customer, []string{"customer_name"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateCustomer(&customer, []string{"customer_domain_name"}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("err = %v, want ErrInvalidParameter", err)
+	}
+
+	product := models.Product{SkuID: "S1", SkuName: "", ProductName: "VM"}
+	if err := validateProduct(&product, []string{"category"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// um campo obrigatório informado vazio continua sendo rejeitado
+	if err := validateProduct(&product, []string{"sku_name"}); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("err = %v, want ErrInvalidParameter", err)
+	}
+	// sem lista (PUT), todos os campos são validados
+	if err := validateProduct(&product, nil); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("err = %v, want ErrInvalidParameter", err)
+	}
+}
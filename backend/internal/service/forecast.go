@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"math"
+	"time"
+)
+
+// z95 é o quantil normal usado nas bandas de confiança de 95%
+const z95 = 1.96
+
+var validForecastScopes = map[string]bool{
+	"overall":  true,
+	"customer": true,
+	"partner":  true,
+}
+
+// GetForecast projeta o fechamento do mês de referência e o próximo trimestre para o escopo.
+//
+// Modelo: a série mensal de cada entidade (meses completos, mais a projeção do mês
+// corrente quando ele está incompleto) é ajustada com suavização exponencial de Holt
+// (nível + tendência), escolhendo alpha e beta em grade pelo menor erro quadrático de
+// previsão um passo à frente. A banda usa o desvio desses resíduos, crescendo com √h.
+// O mês corrente é projetado pela média diária até a data de referência.
+func (s *Service) GetForecast(ctx context.Context, scope string, entityID int) (*models.ForecastReport, error) {
+	if scope == "" {
+		scope = "overall"
+	}
+	if !validForecastScopes[scope] {
+		return nil, fmt.Errorf("%w: scope deve ser overall, customer ou partner", ErrInvalidParameter)
+	}
+
+	report := &models.ForecastReport{Scope: scope, Forecasts: []models.SpendForecast{}}
+
+	_, maxDate, err := s.repo.GetUsageDateRange(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar data de referência: %w", err)
+	}
+	if maxDate == nil {
+		return report, nil
+	}
+	asOf := truncatePeriod(*maxDate, "day")
+	report.AsOf = asOf.Format("2006-01-02")
+
+	spend, err := s.repo.GetDailySpend(ctx, scope, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar gasto diário: %w", err)
+	}
+
+	// Os dados vêm ordenados por entidade; agrupar preservando a ordem
+	start := 0
+	for start < len(spend) {
+		end := start + 1
+		for end < len(spend) && spend[end].EntityID == spend[start].EntityID {
+			end++
+		}
+
+		forecast := forecastEntity(spend[start:end], asOf)
+		if scope != "overall" {
+			forecast.EntityID = spend[start].EntityID
+		}
+		report.Forecasts = append(report.Forecasts, forecast)
+		start = end
+	}
+
+	return report, nil
+}
+
+// forecastEntity calcula a previsão de uma entidade a partir do seu gasto diário
+func forecastEntity(days []models.DailySpend, asOf time.Time) models.SpendForecast {
+	refMonth := truncatePeriod(asOf, "month")
+	daysInMonth := nextPeriod(refMonth, "month").AddDate(0, 0, -1).Day()
+
+	monthly := make(map[string]float64)
+	daily := make([]float64, asOf.Day())
+	firstMonth := refMonth
+	for _, d := range days {
+		if d.Day.After(asOf) {
+			continue
+		}
+		month := truncatePeriod(d.Day, "month")
+		monthly[month.Format("2006-01")] += d.Total
+		if month.Before(firstMonth) {
+			firstMonth = month
+		}
+		if month.Equal(refMonth) {
+			daily[d.Day.Day()-1] += d.Total
+		}
+	}
+
+	monthEnd := projectMonthEnd(daily, daysInMonth)
+	monthEnd.Month = refMonth.Format("2006-01")
+
+	// Série mensal com meses sem gasto preenchidos com zero; o mês corrente entra pela projeção
+	var series []float64
+	for m := firstMonth; m.Before(refMonth); m = nextPeriod(m, "month") {
+		series = append(series, monthly[m.Format("2006-01")])
+	}
+	series = append(series, monthEnd.Projected)
+
+	fit := fitHolt(series)
+
+	quarterStart := nextPeriod(truncatePeriod(refMonth, "quarter"), "quarter")
+	quarter := models.QuarterForecast{Quarter: periodLabel(quarterStart, "quarter")}
+	variance := 0.0
+	for m := quarterStart; m.Before(nextPeriod(quarterStart, "quarter")); m = nextPeriod(m, "month") {
+		h := monthsBetween(refMonth, m)
+		value := fit.level + float64(h)*fit.trend
+		half := z95 * fit.sigma * math.Sqrt(float64(h))
+		quarter.Months = append(quarter.Months, models.ForecastPoint{
+			Period:   m.Format("2006-01"),
+			Forecast: roundMoney(math.Max(value, 0)),
+			Lower:    roundMoney(math.Max(value-half, 0)),
+			Upper:    roundMoney(math.Max(value+half, 0)),
+		})
+		quarter.Forecast += value
+		variance += fit.sigma * fit.sigma * float64(h)
+	}
+	half := z95 * math.Sqrt(variance)
+	quarter.Lower = roundMoney(math.Max(quarter.Forecast-half, 0))
+	quarter.Upper = roundMoney(math.Max(quarter.Forecast+half, 0))
+	quarter.Forecast = roundMoney(math.Max(quarter.Forecast, 0))
+
+	name := ""
+	if len(days) > 0 {
+		name = days[0].EntityName
+	}
+
+	return models.SpendForecast{
+		EntityName:    name,
+		Model:         "holt",
+		Alpha:         fit.alpha,
+		Beta:          fit.beta,
+		HistoryMonths: len(series),
+		MonthEnd:      monthEnd,
+		NextQuarter:   quarter,
+	}
+}
+
+// projectMonthEnd projeta o fechamento do mês pela média diária dos dias decorridos
+func projectMonthEnd(daily []float64, daysInMonth int) models.MonthEndProjection {
+	elapsed := len(daily)
+	actual, mean, std := 0.0, 0.0, 0.0
+	for _, v := range daily {
+		actual += v
+	}
+	if elapsed > 0 {
+		mean = actual / float64(elapsed)
+	}
+	if elapsed > 1 {
+		for _, v := range daily {
+			std += (v - mean) * (v - mean)
+		}
+		std = math.Sqrt(std / float64(elapsed-1))
+	}
+
+	remaining := float64(daysInMonth - elapsed)
+	projected := actual + mean*remaining
+	half := z95 * std * math.Sqrt(remaining)
+
+	return models.MonthEndProjection{
+		ActualToDate: roundMoney(actual),
+		DaysElapsed:  elapsed,
+		DaysInMonth:  daysInMonth,
+		Projected:    roundMoney(projected),
+		Lower:        roundMoney(math.Max(projected-half, actual)),
+		Upper:        roundMoney(projected + half),
+	}
+}
+
+// holtFit guarda o estado final e os parâmetros do ajuste de Holt
+type holtFit struct {
+	level, trend float64
+	alpha, beta  float64
+	sigma        float64
+}
+
+// fitHolt ajusta suavização exponencial dupla escolhendo alpha e beta em grade de 0.1 a 0.9
+func fitHolt(series []float64) holtFit {
+	switch len(series) {
+	case 0:
+		return holtFit{}
+	case 1:
+		return holtFit{level: series[0]}
+	}
+
+	best := holtFit{sigma: math.Inf(1)}
+	for ai := 1; ai <= 9; ai++ {
+		for bi := 1; bi <= 9; bi++ {
+			alpha, beta := float64(ai)/10, float64(bi)/10
+			level, trend := series[0], series[1]-series[0]
+			sse := 0.0
+			for _, y := range series[1:] {
+				predicted := level + trend
+				sse += (y - predicted) * (y - predicted)
+				newLevel := alpha*y + (1-alpha)*(level+trend)
+				trend = beta*(newLevel-level) + (1-beta)*trend
+				level = newLevel
+			}
+			sigma := math.Sqrt(sse / float64(len(series)-1))
+			if sigma < best.sigma {
+				best = holtFit{level: level, trend: trend, alpha: alpha, beta: beta, sigma: sigma}
+			}
+		}
+	}
+
+	return best
+}
+
+// monthsBetween retorna a quantidade de meses de from até to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
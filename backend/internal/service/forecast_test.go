@@ -0,0 +1,57 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFitHoltLinearSeries(t *testing.T) {
+	fit := fitHolt([]float64{100, 110, 120, 130, 140})
+
+	if math.Abs(fit.level-140) > 1e-9 || math.Abs(fit.trend-10) > 1e-9 {
+		t.Errorf("expected level 140 and trend 10, got %+v", fit)
+	}
+	if fit.sigma != 0 {
+		t.Errorf("expected zero residuals for a perfect trend, got %v", fit.sigma)
+	}
+}
+
+func TestProjectMonthEnd(t *testing.T) {
+	projection := projectMonthEnd([]float64{10, 10, 10, 10, 10}, 30)
+
+	if projection.ActualToDate != 50 || projection.Projected != 300 {
+		t.Errorf("unexpected projection: %+v", projection)
+	}
+	if projection.Lower != 300 || projection.Upper != 300 {
+		t.Errorf("expected no band for constant spend, got %+v", projection)
+	}
+}
+
+func TestForecastEntity(t *testing.T) {
+	var days []models.DailySpend
+	// Três meses completos com crescimento de 100 por mês e metade de junho
+	for m, total := range []float64{300, 400, 500} {
+		days = append(days, models.DailySpend{EntityName: "Total", Day: time.Date(2024, time.March+time.Month(m), 10, 0, 0, 0, 0, time.UTC), Total: total})
+	}
+	for d := 1; d <= 15; d++ {
+		days = append(days, models.DailySpend{EntityName: "Total", Day: time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC), Total: 20})
+	}
+
+	forecast := forecastEntity(days, time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC))
+
+	if forecast.MonthEnd.Month != "2024-06" || forecast.MonthEnd.Projected != 600 {
+		t.Errorf("unexpected month end: %+v", forecast.MonthEnd)
+	}
+	if forecast.HistoryMonths != 4 {
+		t.Errorf("expected 4 history months, got %d", forecast.HistoryMonths)
+	}
+	if forecast.NextQuarter.Quarter != "2024-Q3" || len(forecast.NextQuarter.Months) != 3 {
+		t.Fatalf("unexpected quarter: %+v", forecast.NextQuarter)
+	}
+	// Tendência perfeita de +100/mês: julho 700, agosto 800, setembro 900
+	if forecast.NextQuarter.Forecast != 2400 {
+		t.Errorf("expected quarter forecast 2400, got %v", forecast.NextQuarter.Forecast)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"time"
+)
+
+// seriesKey identifica uma série de gasto cliente × produto
+type seriesKey struct {
+	customer, product int
+}
+
+// dateRange é um intervalo fechado de datas de uso
+type dateRange struct {
+	from, to time.Time
+}
+
+// ImportSession acumula o que os lotes de uma mesma importação gravaram, para que as rotinas que
+// dependem do arquivo inteiro rodem uma vez em FinishImport, e não a cada lote
+type ImportSession struct {
+	// fileName e checksum identificam o arquivo importado no lote de qualidade
+	fileName string
+	checksum string
+	// qualityBatchID é o lote de qualidade da importação, criado pelo primeiro trecho avaliado
+	qualityBatchID int
+	// series guarda, para cada cliente × produto importado, o intervalo de datas tocado
+	series map[seriesKey]dateRange
+	// invoices acumula as faturas e os totais de controle de todos os lotes
+	invoices *invoiceSet
+	// result soma o que cada lote gravou
+	result models.ImportResult
+}
+
+// NewImportSession inicia a importação em lotes do arquivo fileName, a ser concluída com FinishImport
+func (s *Service) NewImportSession(fileName string) *ImportSession {
+	return &ImportSession{fileName: fileName, series: make(map[seriesKey]dateRange), invoices: newInvoiceSet()}
+}
+
+// add registra os usos gravados por um lote
+func (is *ImportSession) add(usages []models.Usage) {
+	for _, u := range usages {
+		if u.UsageDate.IsZero() {
+			continue
+		}
+		key := seriesKey{u.CustomerID, u.ProductID}
+		r, ok := is.series[key]
+		if !ok {
+			is.series[key] = dateRange{from: u.UsageDate, to: u.UsageDate}
+			continue
+		}
+		if u.UsageDate.Before(r.from) {
+			r.from = u.UsageDate
+		}
+		if u.UsageDate.After(r.to) {
+			r.to = u.UsageDate
+		}
+		is.series[key] = r
+	}
+}
+
+// Result retorna o que os lotes da sessão gravaram até aqui
+func (is *ImportSession) Result() models.ImportResult {
+	return is.result
+}
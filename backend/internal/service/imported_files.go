@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+)
+
+// IsChecksumImported indica se um conteúdo já foi importado com sucesso por alguma fonte
+func (s *Service) IsChecksumImported(ctx context.Context, checksum string) (bool, error) {
+	imported, err := s.repo.IsChecksumImported(ctx, checksum)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao verificar checksum: %w", err)
+	}
+	return imported, nil
+}
+
+// RecordImportedFile registra o resultado do processamento de um arquivo agendado
+func (s *Service) RecordImportedFile(ctx context.Context, file *models.ImportedFile) error {
+	if err := s.repo.InsertImportedFile(ctx, file); err != nil {
+		return fmt.Errorf("erro no service ao registrar arquivo importado: %w", err)
+	}
+	return nil
+}
+
+// GetImportedFiles retorna o histórico de arquivos processados pelas fontes agendadas
+func (s *Service) GetImportedFiles(ctx context.Context, limit int) ([]models.ImportedFile, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	files, err := s.repo.GetImportedFiles(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar arquivos importados: %w", err)
+	}
+	if files == nil {
+		files = []models.ImportedFile{}
+	}
+	return files, nil
+}
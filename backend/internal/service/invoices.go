@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// invoiceTolerance é a diferença aceita entre a soma das linhas e o total de controle,
+// absorvendo o arredondamento das linhas em centavos
+const invoiceTolerance = 0.01
+
+var validInvoiceStatuses = map[string]bool{
+	models.InvoiceUnreconciled: true,
+	models.InvoiceMatched:      true,
+	models.InvoiceDiscrepancy:  true,
+}
+
+// Origem do total de controle usado na conciliação
+const (
+	controlSourceFile = "file"
+	controlSourceUser = "user"
+)
+
+// ListInvoices retorna as faturas filtradas
+func (s *Service) ListInvoices(ctx context.Context, filter models.InvoiceFilter) ([]models.Invoice, error) {
+	if filter.Status != "" && !validInvoiceStatuses[filter.Status] {
+		return nil, fmt.Errorf("%w: status deve ser unreconciled, matched ou discrepancy", ErrInvalidParameter)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("%w: from deve ser anterior a to", ErrInvalidParameter)
+	}
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 100
+	}
+
+	invoices, err := s.repo.ListInvoices(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar faturas: %w", err)
+	}
+	if invoices == nil {
+		invoices = []models.Invoice{}
+	}
+	return invoices, nil
+}
+
+// GetInvoice retorna a fatura com suas linhas; nil se não existir
+func (s *Service) GetInvoice(ctx context.Context, id int) (*models.InvoiceDetail, error) {
+	invoice, err := s.repo.GetInvoiceByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar fatura: %w", err)
+	}
+	if invoice == nil {
+		return nil, nil
+	}
+
+	lines, err := s.repo.GetInvoiceLines(ctx, invoice)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar linhas da fatura: %w", err)
+	}
+	if lines == nil {
+		lines = []models.Usage{}
+	}
+	return &models.InvoiceDetail{Invoice: *invoice, Lines: lines}, nil
+}
+
+// ReconcileInvoice compara a soma atual das linhas com o total de controle. controlTotal, se
+// informado, substitui o total do arquivo; sem nenhum dos dois a conciliação é rejeitada.
+// Retorna nil se a fatura não existir.
+func (s *Service) ReconcileInvoice(ctx context.Context, id int, controlTotal *float64) (*models.InvoiceReconciliation, error) {
+	invoice, err := s.repo.GetInvoiceByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar fatura: %w", err)
+	}
+	if invoice == nil {
+		return nil, nil
+	}
+
+	control, source := invoice.ControlTotal, controlSourceFile
+	if invoice.ControlSource != nil {
+		source = *invoice.ControlSource
+	}
+	if controlTotal != nil {
+		control, source = controlTotal, controlSourceUser
+	}
+	if control == nil {
+		return nil, fmt.Errorf("%w: a fatura não tem total de controle; informe control_total", ErrInvalidParameter)
+	}
+
+	return s.reconcile(ctx, invoice, *control, source)
+}
+
+// reconcile recalcula a soma das linhas, grava o resultado e emite invoice.discrepancy quando
+// a fatura não fecha
+func (s *Service) reconcile(ctx context.Context, invoice *models.Invoice, control float64, source string) (*models.InvoiceReconciliation, error) {
+	lineSum, lineCount, currencies, err := s.repo.GetInvoiceLineStats(ctx, invoice)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao somar linhas da fatura: %w", err)
+	}
+
+	rec := reconcileInvoice(lineSum, lineCount, control, currencies)
+	rec.InvoiceID = invoice.ID
+	rec.InvoiceNumber = invoice.InvoiceNumber
+	rec.ControlSource = source
+
+	if err := s.repo.UpdateInvoiceReconciliation(ctx, &rec); err != nil {
+		return nil, fmt.Errorf("erro no service ao gravar conciliação: %w", err)
+	}
+	if rec.Status == models.InvoiceDiscrepancy {
+		s.PublishEvent(ctx, EventInvoiceDiscrepancy, rec)
+	}
+	return &rec, nil
+}
+
+// refreshInvoices deriva as faturas das usages importadas e concilia as que têm total de
+// controle no arquivo. Falhas são apenas registradas para não invalidar a importação.
+func (s *Service) refreshInvoices(ctx context.Context, set *invoiceSet) {
+	numbers, controls := set.list()
+	if len(numbers) == 0 {
+		return
+	}
+
+	if err := s.repo.RefreshInvoices(ctx, numbers); err != nil {
+		fmt.Printf("Erro ao atualizar faturas: %v\n", err)
+		return
+	}
+	if err := s.repo.SetInvoiceControlTotals(ctx, controls); err != nil {
+		fmt.Printf("Erro ao gravar totais de controle: %v\n", err)
+		return
+	}
+
+	invoices, err := s.repo.GetInvoicesByNumbers(ctx, numbers)
+	if err != nil {
+		fmt.Printf("Erro ao buscar faturas importadas: %v\n", err)
+		return
+	}
+
+	discrepancies := 0
+	for i := range invoices {
+		invoice := &invoices[i]
+		if invoice.ControlTotal == nil {
+			continue
+		}
+		source := controlSourceFile
+		if invoice.ControlSource != nil {
+			source = *invoice.ControlSource
+		}
+		rec, err := s.reconcile(ctx, invoice, *invoice.ControlTotal, source)
+		if err != nil {
+			fmt.Printf("Erro ao conciliar fatura %s: %v\n", invoice.InvoiceNumber, err)
+			continue
+		}
+		if rec.Status == models.InvoiceDiscrepancy {
+			discrepancies++
+		}
+	}
+	fmt.Printf("Faturas atualizadas: %d (%d com divergência)\n", len(invoices), discrepancies)
+}
+
+// invoiceSet acumula, ao longo dos lotes de uma importação, os números de fatura e os totais de
+// controle do arquivo. Quando as linhas de uma fatura trazem totais diferentes, vale o primeiro.
+type invoiceSet struct {
+	numbers  map[string]bool
+	seen     map[models.InvoiceControl]bool
+	controls []models.InvoiceControl
+}
+
+func newInvoiceSet() *invoiceSet {
+	return &invoiceSet{numbers: make(map[string]bool), seen: make(map[models.InvoiceControl]bool)}
+}
+
+// add registra as faturas das usages de um lote
+func (set *invoiceSet) add(usages []models.Usage) {
+	for _, u := range usages {
+		number := strings.TrimSpace(u.InvoiceNumber)
+		if number == "" || u.PartnerID <= 0 || u.CustomerID <= 0 {
+			continue
+		}
+		set.numbers[number] = true
+		if !u.InvoiceControlTotal.Valid {
+			continue
+		}
+
+		key := models.InvoiceControl{InvoiceNumber: number, PartnerID: u.PartnerID, CustomerID: u.CustomerID}
+		if set.seen[key] {
+			continue
+		}
+		set.seen[key] = true
+		key.Total = u.InvoiceControlTotal.Float64
+		set.controls = append(set.controls, key)
+	}
+}
+
+// list retorna os números de fatura em ordem e os totais de controle na ordem do arquivo
+func (set *invoiceSet) list() ([]string, []models.InvoiceControl) {
+	numbers := make([]string, 0, len(set.numbers))
+	for number := range set.numbers {
+		numbers = append(numbers, number)
+	}
+	sort.Strings(numbers)
+	return numbers, set.controls
+}
+
+// reconcileInvoice compara a soma das linhas com o total de controle; divergências de valor
+// acima da tolerância e linhas em mais de uma moeda marcam a fatura como discrepancy
+func reconcileInvoice(lineSum float64, lineCount int, control float64, currencies []string) models.InvoiceReconciliation {
+	lineSum = roundMoney(lineSum)
+	rec := models.InvoiceReconciliation{
+		LineSum:      lineSum,
+		LineCount:    lineCount,
+		ControlTotal: control,
+		Difference:   roundMoney(lineSum - control),
+		Tolerance:    invoiceTolerance,
+		Currencies:   currencies,
+		Status:       models.InvoiceMatched,
+	}
+	if rec.Currencies == nil {
+		rec.Currencies = []string{}
+	}
+
+	if math.Abs(rec.Difference) > invoiceTolerance {
+		rec.Issues = append(rec.Issues, fmt.Sprintf("soma das linhas (%.2f) difere do total de controle (%.2f) em %.2f", lineSum, control, rec.Difference))
+	}
+	if lineCount == 0 {
+		rec.Issues = append(rec.Issues, "fatura sem linhas")
+	}
+	if len(currencies) > 1 {
+		rec.Issues = append(rec.Issues, fmt.Sprintf("linhas em mais de uma moeda: %s", strings.Join(currencies, ", ")))
+	}
+	if len(rec.Issues) > 0 {
+		rec.Status = models.InvoiceDiscrepancy
+	}
+	return rec
+}
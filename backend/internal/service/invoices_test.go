@@ -0,0 +1,72 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestReconcileInvoice(t *testing.T) {
+	tests := []struct {
+		name       string
+		lineSum    float64
+		lineCount  int
+		control    float64
+		currencies []string
+		wantStatus string
+		wantIssues int
+	}{
+		{"matched", 100.004, 3, 100, []string{"BRL"}, models.InvoiceMatched, 0},
+		{"within tolerance", 100.01, 3, 100, []string{"BRL"}, models.InvoiceMatched, 0},
+		{"amount discrepancy", 98.5, 3, 100, []string{"BRL"}, models.InvoiceDiscrepancy, 1},
+		{"mixed currencies", 100, 2, 100, []string{"BRL", "USD"}, models.InvoiceDiscrepancy, 1},
+		{"no lines", 0, 0, 50, nil, models.InvoiceDiscrepancy, 2},
+	}
+
+	for _, tt := range tests {
+		rec := reconcileInvoice(tt.lineSum, tt.lineCount, tt.control, tt.currencies)
+		if rec.Status != tt.wantStatus || len(rec.Issues) != tt.wantIssues {
+			t.Errorf("%s: status = %s, issues = %v", tt.name, rec.Status, rec.Issues)
+		}
+		if rec.Currencies == nil {
+			t.Errorf("%s: currencies should not be nil", tt.name)
+		}
+	}
+
+	rec := reconcileInvoice(98.5, 3, 100, nil)
+	if rec.Difference != -1.5 || rec.LineSum != 98.5 {
+		t.Errorf("difference = %v, line sum = %v", rec.Difference, rec.LineSum)
+	}
+}
+
+func TestInvoiceSetAcrossBatches(t *testing.T) {
+	total := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	usages := []models.Usage{
+		{InvoiceNumber: "F2", PartnerID: 1, CustomerID: 10, InvoiceControlTotal: total(300)},
+		{InvoiceNumber: " F1 ", PartnerID: 1, CustomerID: 10},
+		{InvoiceNumber: "F2", PartnerID: 1, CustomerID: 10, InvoiceControlTotal: total(999)},
+	}
+	// O segundo lote da mesma importação não substitui o total já lido no primeiro
+	nextBatch := []models.Usage{
+		{InvoiceNumber: "F2", PartnerID: 1, CustomerID: 10, InvoiceControlTotal: total(777)},
+		{InvoiceNumber: "F2", PartnerID: 1, CustomerID: 11, InvoiceControlTotal: total(50)},
+		{InvoiceNumber: "", PartnerID: 1, CustomerID: 10, InvoiceControlTotal: total(1)},
+		{InvoiceNumber: "F3", PartnerID: 0, CustomerID: 10},
+	}
+
+	set := newInvoiceSet()
+	set.add(usages)
+	set.add(nextBatch)
+	numbers, controls := set.list()
+	if !reflect.DeepEqual(numbers, []string{"F1", "F2"}) {
+		t.Errorf("numbers = %v", numbers)
+	}
+	want := []models.InvoiceControl{
+		{InvoiceNumber: "F2", PartnerID: 1, CustomerID: 10, Total: 300},
+		{InvoiceNumber: "F2", PartnerID: 1, CustomerID: 11, Total: 50},
+	}
+	if !reflect.DeepEqual(controls, want) {
+		t.Errorf("controls = %+v, want %+v", controls, want)
+	}
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Agrupamentos aceitos pelo relatório de margem
+var validMarginGroups = map[string]bool{
+	"customer":         true,
+	"product":          true,
+	"customer_product": true,
+}
+
+// GetPriceRules retorna as regras de preço
+func (s *Service) GetPriceRules(ctx context.Context) ([]models.PriceRule, error) {
+	rules, err := s.repo.GetPriceRules(ctx, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regras de preço: %w", err)
+	}
+	if rules == nil {
+		rules = []models.PriceRule{}
+	}
+	return rules, nil
+}
+
+// GetPriceRule retorna uma regra de preço; nil se não existir
+func (s *Service) GetPriceRule(ctx context.Context, id int) (*models.PriceRule, error) {
+	rule, err := s.repo.GetPriceRuleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regra de preço: %w", err)
+	}
+	return rule, nil
+}
+
+// CreatePriceRule valida e cria uma regra de preço
+func (s *Service) CreatePriceRule(ctx context.Context, rule *models.PriceRule) error {
+	if err := s.validatePriceRuleScope(ctx, rule); err != nil {
+		return err
+	}
+	if err := s.repo.InsertPriceRule(ctx, rule); err != nil {
+		return fmt.Errorf("erro no service ao criar regra de preço: %w", err)
+	}
+	return nil
+}
+
+// UpdatePriceRule valida e atualiza uma regra de preço; retorna false se não existir
+func (s *Service) UpdatePriceRule(ctx context.Context, rule *models.PriceRule) (bool, error) {
+	if err := s.validatePriceRuleScope(ctx, rule); err != nil {
+		return false, err
+	}
+	updated, err := s.repo.UpdatePriceRule(ctx, rule)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar regra de preço: %w", err)
+	}
+	return updated, nil
+}
+
+// DeletePriceRule remove uma regra de preço; retorna false se não existir
+func (s *Service) DeletePriceRule(ctx context.Context, id int) (bool, error) {
+	deleted, err := s.repo.DeletePriceRule(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover regra de preço: %w", err)
+	}
+	return deleted, nil
+}
+
+// GetMarginReport calcula na hora o preço de revenda dos usos do intervalo com as regras
+// vigentes em cada usage_date e retorna receita, custo e margem agrupados
+func (s *Service) GetMarginReport(ctx context.Context, q models.MarginQuery) (*models.MarginReport, error) {
+	if q.GroupBy == "" {
+		q.GroupBy = "customer"
+	}
+	if !validMarginGroups[q.GroupBy] {
+		return nil, fmt.Errorf("%w: group_by deve ser customer, product ou customer_product", ErrInvalidParameter)
+	}
+	if q.From != nil && q.To != nil && q.To.Before(*q.From) {
+		return nil, fmt.Errorf("%w: to deve ser igual ou posterior a from", ErrInvalidParameter)
+	}
+
+	usages, err := s.repo.GetPricingUsages(ctx, q.From, q.To)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar custos para precificação: %w", err)
+	}
+	quantities, err := s.repo.GetPricingMonthlyQuantities(ctx, q.From, q.To)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar quantidades mensais para precificação: %w", err)
+	}
+	rules, err := s.repo.GetPriceRules(ctx, q.From, q.To)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regras de preço: %w", err)
+	}
+
+	report := buildMarginReport(priceUsages(usages, quantities, rules), q.GroupBy)
+	report.From = q.From
+	report.To = q.To
+	return report, nil
+}
+
+// validatePriceRuleScope valida a regra e confirma que o cliente e o produto do escopo existem
+func (s *Service) validatePriceRuleScope(ctx context.Context, rule *models.PriceRule) error {
+	if err := validatePriceRule(rule); err != nil {
+		return err
+	}
+	if rule.CustomerID != nil {
+		customer, err := s.repo.GetCustomerByCustomerID(ctx, *rule.CustomerID)
+		if err != nil {
+			return fmt.Errorf("erro no service ao buscar cliente: %w", err)
+		}
+		if customer == nil {
+			return fmt.Errorf("%w: cliente %s não encontrado", ErrInvalidParameter, *rule.CustomerID)
+		}
+	}
+	if rule.ProductID != nil {
+		product, err := s.repo.GetProductByProductID(ctx, *rule.ProductID)
+		if err != nil {
+			return fmt.Errorf("erro no service ao buscar produto: %w", err)
+		}
+		if product == nil {
+			return fmt.Errorf("%w: produto %s não encontrado", ErrInvalidParameter, *rule.ProductID)
+		}
+	}
+	return nil
+}
+
+// validatePriceRule normaliza a regra: percentual fixo ou faixas (ordenadas, começando em 0),
+// markup acima de -100% e desconto entre 0 e 100%
+func validatePriceRule(rule *models.PriceRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name é obrigatório", ErrInvalidParameter)
+	}
+	if utf8.RuneCountInString(rule.Name) > 255 {
+		return fmt.Errorf("%w: name deve ter no máximo 255 caracteres", ErrInvalidParameter)
+	}
+	if rule.RuleType != models.PriceRuleMarkup && rule.RuleType != models.PriceRuleDiscount {
+		return fmt.Errorf("%w: rule_type deve ser markup ou discount", ErrInvalidParameter)
+	}
+
+	if rule.Category != nil {
+		category := strings.TrimSpace(*rule.Category)
+		rule.Category = &category
+		if category == "" {
+			rule.Category = nil
+		}
+	}
+	var err error
+	if rule.CustomerID, err = priceRuleKey(rule.CustomerID, "customer_id"); err != nil {
+		return err
+	}
+	if rule.ProductID, err = priceRuleKey(rule.ProductID, "product_id"); err != nil {
+		return err
+	}
+
+	if (rule.Percent == nil) == (len(rule.Tiers) == 0) {
+		return fmt.Errorf("%w: informe percent ou tiers", ErrInvalidParameter)
+	}
+	percents := make([]float64, 0, len(rule.Tiers)+1)
+	if rule.Percent != nil {
+		percents = append(percents, *rule.Percent)
+	} else {
+		sort.Slice(rule.Tiers, func(i, j int) bool { return rule.Tiers[i].MinQuantity < rule.Tiers[j].MinQuantity })
+		if rule.Tiers[0].MinQuantity != 0 {
+			return fmt.Errorf("%w: a primeira faixa deve começar em min_quantity 0", ErrInvalidParameter)
+		}
+		for i, tier := range rule.Tiers {
+			if i > 0 && tier.MinQuantity == rule.Tiers[i-1].MinQuantity {
+				return fmt.Errorf("%w: faixa duplicada em min_quantity %v", ErrInvalidParameter, tier.MinQuantity)
+			}
+			percents = append(percents, tier.Percent)
+		}
+	}
+	for _, p := range percents {
+		if rule.RuleType == models.PriceRuleMarkup && p <= -100 {
+			return fmt.Errorf("%w: markup deve ser maior que -100", ErrInvalidParameter)
+		}
+		if rule.RuleType == models.PriceRuleDiscount && (p < 0 || p > 100) {
+			return fmt.Errorf("%w: desconto deve estar entre 0 e 100", ErrInvalidParameter)
+		}
+	}
+
+	if rule.EffectiveFrom.IsZero() {
+		return fmt.Errorf("%w: effective_from é obrigatório", ErrInvalidParameter)
+	}
+	rule.EffectiveFrom = dateOnly(rule.EffectiveFrom)
+	if rule.EffectiveTo != nil {
+		to := dateOnly(*rule.EffectiveTo)
+		if !to.After(rule.EffectiveFrom) {
+			return fmt.Errorf("%w: effective_to deve ser posterior a effective_from", ErrInvalidParameter)
+		}
+		rule.EffectiveTo = &to
+	}
+	return nil
+}
+
+// priceRuleKey normaliza a chave de negócio do escopo; nula vale para todos, vazia é inválida
+func priceRuleKey(key *string, field string) (*string, error) {
+	if key == nil {
+		return nil, nil
+	}
+	value := strings.TrimSpace(*key)
+	if value == "" {
+		return nil, fmt.Errorf("%w: %s não pode ser vazio", ErrInvalidParameter, field)
+	}
+	return &value, nil
+}
+
+// pricedUsage é um custo diário com a receita de revenda calculada
+type pricedUsage struct {
+	models.PricingUsage
+	revenue float64
+	priced  bool
+}
+
+// priceUsages aplica a cada custo o markup e, depois, o desconto da regra mais específica
+// vigente na usage_date. As faixas usam a quantidade do cliente e produto no mês de calendário
+// inteiro (quantities), mesmo quando o período consultado cobre só parte do mês.
+// Sem markup vigente o uso é revendido a preço de custo.
+func priceUsages(usages []models.PricingUsage, quantities []models.PricingQuantity, rules []models.PriceRule) []pricedUsage {
+	type monthKey struct {
+		customer, product int
+		month             string
+	}
+	monthly := make(map[monthKey]float64, len(quantities))
+	for _, q := range quantities {
+		monthly[monthKey{q.CustomerID, q.ProductID, q.Month.Format("2006-01")}] += q.Quantity
+	}
+
+	priced := make([]pricedUsage, len(usages))
+	for i, u := range usages {
+		quantity := monthly[monthKey{u.CustomerID, u.ProductID, u.UsageDate.Format("2006-01")}]
+		price := pricedUsage{PricingUsage: u, revenue: u.Cost}
+		if markup := selectPriceRule(rules, models.PriceRuleMarkup, u); markup != nil {
+			price.revenue *= 1 + rulePercent(*markup, quantity)/100
+			price.priced = true
+		}
+		if discount := selectPriceRule(rules, models.PriceRuleDiscount, u); discount != nil {
+			price.revenue *= 1 - rulePercent(*discount, quantity)/100
+		}
+		priced[i] = price
+	}
+	return priced
+}
+
+// selectPriceRule retorna a regra do tipo vigente e aplicável ao uso com maior especificidade;
+// no empate vence a de effective_from mais recente e, depois, a de maior id
+func selectPriceRule(rules []models.PriceRule, ruleType string, u models.PricingUsage) *models.PriceRule {
+	var best *models.PriceRule
+	for i := range rules {
+		rule := &rules[i]
+		if rule.RuleType != ruleType || !priceRuleApplies(*rule, u) {
+			continue
+		}
+		if best == nil || priceRuleBeats(*rule, *best) {
+			best = rule
+		}
+	}
+	return best
+}
+
+func priceRuleBeats(a, b models.PriceRule) bool {
+	if sa, sb := priceRuleSpecificity(a), priceRuleSpecificity(b); sa != sb {
+		return sa > sb
+	}
+	if !a.EffectiveFrom.Equal(b.EffectiveFrom) {
+		return a.EffectiveFrom.After(b.EffectiveFrom)
+	}
+	return a.ID > b.ID
+}
+
+// priceRuleSpecificity pondera o escopo: cliente pesa mais que produto, que pesa mais que
+// categoria; assim cliente+produto vence cliente, que vence qualquer regra de tabela
+func priceRuleSpecificity(rule models.PriceRule) int {
+	specificity := 0
+	if rule.CustomerID != nil {
+		specificity += 4
+	}
+	if rule.ProductID != nil {
+		specificity += 2
+	}
+	if rule.Category != nil {
+		specificity++
+	}
+	return specificity
+}
+
+// priceRuleApplies informa se a regra está ativa e vigente na usage_date e o uso está no seu escopo
+func priceRuleApplies(rule models.PriceRule, u models.PricingUsage) bool {
+	if !rule.Active {
+		return false
+	}
+	if u.UsageDate.Before(rule.EffectiveFrom) || (rule.EffectiveTo != nil && !u.UsageDate.Before(*rule.EffectiveTo)) {
+		return false
+	}
+	if rule.CustomerID != nil && *rule.CustomerID != u.CustomerKey {
+		return false
+	}
+	if rule.ProductID != nil && *rule.ProductID != u.ProductKey {
+		return false
+	}
+	if rule.Category != nil && *rule.Category != u.Category {
+		return false
+	}
+	return true
+}
+
+// rulePercent retorna o percentual da regra para a quantidade mensal: o fixo, ou o da maior
+// faixa cujo mínimo foi atingido
+func rulePercent(rule models.PriceRule, quantity float64) float64 {
+	if rule.Percent != nil {
+		return *rule.Percent
+	}
+	percent := 0.0
+	for _, tier := range rule.Tiers {
+		if quantity >= tier.MinQuantity {
+			percent = tier.Percent
+		}
+	}
+	return percent
+}
+
+// buildMarginReport agrupa os usos precificados e calcula margem e margem percentual sobre a
+// receita
+func buildMarginReport(priced []pricedUsage, groupBy string) *models.MarginReport {
+	type groupKey struct{ customer, product int }
+	index := make(map[groupKey]int)
+	report := &models.MarginReport{GroupBy: groupBy, Rows: []models.MarginRow{}}
+
+	for _, p := range priced {
+		key := groupKey{}
+		row := models.MarginRow{}
+		if groupBy != "product" {
+			key.customer = p.CustomerID
+			row.CustomerID, row.Customer = p.CustomerID, p.PricingUsage.Customer
+		}
+		if groupBy != "customer" {
+			key.product = p.ProductID
+			row.ProductID, row.Product = p.ProductID, p.PricingUsage.Product
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Rows)
+			index[key] = i
+			report.Rows = append(report.Rows, row)
+		}
+		report.Rows[i].Quantity += p.Quantity
+		report.Rows[i].Cost += p.Cost
+		report.Rows[i].Revenue += p.revenue
+
+		report.Totals.Quantity += p.Quantity
+		report.Totals.Cost += p.Cost
+		report.Totals.Revenue += p.revenue
+		if !p.priced {
+			report.UnpricedCost += p.Cost
+		}
+	}
+
+	for i := range report.Rows {
+		finishMarginRow(&report.Rows[i])
+	}
+	finishMarginRow(&report.Totals)
+	report.UnpricedCost = roundMoney(report.UnpricedCost)
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Revenue != report.Rows[j].Revenue {
+			return report.Rows[i].Revenue > report.Rows[j].Revenue
+		}
+		if report.Rows[i].Customer != report.Rows[j].Customer {
+			return report.Rows[i].Customer < report.Rows[j].Customer
+		}
+		return report.Rows[i].Product < report.Rows[j].Product
+	})
+	return report
+}
+
+// finishMarginRow arredonda os valores e calcula a margem
+func finishMarginRow(row *models.MarginRow) {
+	row.Cost = roundMoney(row.Cost)
+	row.Revenue = roundMoney(row.Revenue)
+	row.Margin = roundMoney(row.Revenue - row.Cost)
+	row.MarginPct = nil
+	if row.Revenue != 0 {
+		pct := roundMoney(row.Margin / row.Revenue * 100)
+		row.MarginPct = &pct
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func strPtr(v string) *string { return &v }
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSelectPriceRule(t *testing.T) {
+	until := day(2024, 2, 1)
+	rules := []models.PriceRule{
+		{ID: 1, RuleType: models.PriceRuleMarkup, Percent: floatPtr(10), EffectiveFrom: day(2023, 1, 1), Active: true},
+		{ID: 2, RuleType: models.PriceRuleMarkup, Category: strPtr("Compute"), Percent: floatPtr(15), EffectiveFrom: day(2023, 1, 1), Active: true},
+		{ID: 3, RuleType: models.PriceRuleMarkup, ProductID: strPtr("P9"), Percent: floatPtr(20), EffectiveFrom: day(2023, 1, 1), Active: true},
+		{ID: 4, RuleType: models.PriceRuleMarkup, CustomerID: strPtr("C7"), Percent: floatPtr(5), EffectiveFrom: day(2023, 1, 1), EffectiveTo: &until, Active: true},
+		{ID: 5, RuleType: models.PriceRuleMarkup, CustomerID: strPtr("C7"), Percent: floatPtr(30), EffectiveFrom: day(2023, 1, 1), Active: false},
+	}
+
+	cases := []struct {
+		name string
+		u    models.PricingUsage
+		want int
+	}{
+		{"global", models.PricingUsage{CustomerID: 1, CustomerKey: "C1", ProductID: 1, ProductKey: "P1", Category: "Storage", UsageDate: day(2024, 1, 10)}, 1},
+		{"category", models.PricingUsage{CustomerID: 1, CustomerKey: "C1", ProductID: 1, ProductKey: "P1", Category: "Compute", UsageDate: day(2024, 1, 10)}, 2},
+		{"product beats category", models.PricingUsage{CustomerID: 1, CustomerKey: "C1", ProductID: 9, ProductKey: "P9", Category: "Compute", UsageDate: day(2024, 1, 10)}, 3},
+		{"customer beats product", models.PricingUsage{CustomerID: 7, CustomerKey: "C7", ProductID: 9, ProductKey: "P9", Category: "Compute", UsageDate: day(2024, 1, 31)}, 4},
+		{"effective_to is exclusive", models.PricingUsage{CustomerID: 7, CustomerKey: "C7", ProductID: 9, ProductKey: "P9", Category: "Compute", UsageDate: day(2024, 2, 1)}, 3},
+		{"not yet effective", models.PricingUsage{CustomerID: 1, CustomerKey: "C1", ProductID: 1, ProductKey: "P1", UsageDate: day(2022, 12, 31)}, 0},
+	}
+	for _, tc := range cases {
+		got := selectPriceRule(rules, models.PriceRuleMarkup, tc.u)
+		gotID := 0
+		if got != nil {
+			gotID = got.ID
+		}
+		if gotID != tc.want {
+			t.Errorf("%s: expected rule %d, got %d", tc.name, tc.want, gotID)
+		}
+	}
+}
+
+func TestPriceUsagesWithTiersAndDiscount(t *testing.T) {
+	rules := []models.PriceRule{
+		{ID: 1, RuleType: models.PriceRuleMarkup, EffectiveFrom: day(2024, 1, 1), Active: true,
+			Tiers: []models.PriceTier{{MinQuantity: 0, Percent: 20}, {MinQuantity: 100, Percent: 10}}},
+		{ID: 2, RuleType: models.PriceRuleDiscount, CustomerID: strPtr("C2"), Percent: floatPtr(50), EffectiveFrom: day(2024, 1, 1), Active: true},
+	}
+	usages := []models.PricingUsage{
+		// cliente 1: 60 + 50 no mês atinge a faixa de 100
+		{CustomerID: 1, CustomerKey: "C1", ProductID: 1, ProductKey: "P1", UsageDate: day(2024, 1, 1), Quantity: 60, Cost: 100},
+		{CustomerID: 1, CustomerKey: "C1", ProductID: 1, ProductKey: "P1", UsageDate: day(2024, 1, 20), Quantity: 50, Cost: 100},
+		// cliente 2: faixa inicial e desconto de 50% sobre o preço com markup
+		{CustomerID: 2, CustomerKey: "C2", ProductID: 1, ProductKey: "P1", UsageDate: day(2024, 1, 5), Quantity: 10, Cost: 100},
+		// sem regra vigente: revendido a preço de custo
+		{CustomerID: 2, CustomerKey: "C2", ProductID: 1, ProductKey: "P1", UsageDate: day(2023, 12, 31), Quantity: 1, Cost: 40},
+	}
+
+	// a quantidade das faixas vem do mês inteiro, não só dos dias consultados
+	quantities := []models.PricingQuantity{
+		{CustomerID: 1, ProductID: 1, Month: day(2024, 1, 1), Quantity: 110},
+		{CustomerID: 2, ProductID: 1, Month: day(2024, 1, 1), Quantity: 10},
+		{CustomerID: 2, ProductID: 1, Month: day(2023, 12, 1), Quantity: 1},
+	}
+
+	priced := priceUsages(usages, quantities, rules)
+	want := []float64{110, 110, 60, 40}
+	for i, p := range priced {
+		if roundMoney(p.revenue) != want[i] {
+			t.Errorf("usage %d: expected revenue %v, got %v", i, want[i], p.revenue)
+		}
+	}
+	if priced[3].priced {
+		t.Error("expected usage without markup to be unpriced")
+	}
+
+	report := buildMarginReport(priced, "customer")
+	if report.Totals.Cost != 340 || report.Totals.Revenue != 320 || report.Totals.Margin != -20 {
+		t.Errorf("unexpected totals: %+v", report.Totals)
+	}
+	if report.UnpricedCost != 40 {
+		t.Errorf("expected unpriced cost 40, got %v", report.UnpricedCost)
+	}
+	if len(report.Rows) != 2 || report.Rows[0].CustomerID != 1 || report.Rows[0].Margin != 20 {
+		t.Errorf("unexpected rows: %+v", report.Rows)
+	}
+	if pct := report.Rows[0].MarginPct; pct == nil || *pct != 9.09 {
+		t.Errorf("expected margin pct 9.09, got %v", pct)
+	}
+}
+
+func TestPriceUsagesUsesFullMonthQuantity(t *testing.T) {
+	rules := []models.PriceRule{
+		{ID: 1, RuleType: models.PriceRuleMarkup, EffectiveFrom: day(2024, 1, 1), Active: true,
+			Tiers: []models.PriceTier{{MinQuantity: 0, Percent: 20}, {MinQuantity: 100, Percent: 10}}},
+	}
+	// consulta de 20 a 31/01: o cliente consumiu 90 antes do dia 20, e o mês inteiro atinge a faixa de 100
+	usages := []models.PricingUsage{
+		{CustomerID: 1, CustomerKey: "C1", ProductID: 1, ProductKey: "P1", UsageDate: day(2024, 1, 20), Quantity: 50, Cost: 100},
+	}
+	quantities := []models.PricingQuantity{
+		{CustomerID: 1, ProductID: 1, Month: day(2024, 1, 1), Quantity: 140},
+	}
+
+	priced := priceUsages(usages, quantities, rules)
+	if got := roundMoney(priced[0].revenue); got != 110 {
+		t.Errorf("expected revenue 110 from the full-month tier, got %v", got)
+	}
+}
+
+func TestPriceRulesSurviveReplaceImport(t *testing.T) {
+	rules := []models.PriceRule{
+		{ID: 1, RuleType: models.PriceRuleMarkup, CustomerID: strPtr("C7"), ProductID: strPtr("P9"), Percent: floatPtr(25),
+			EffectiveFrom: day(2024, 1, 1), Active: true},
+	}
+	// a importação com substituição recria cliente e produto com outros ids internos
+	before := models.PricingUsage{CustomerID: 7, CustomerKey: "C7", ProductID: 9, ProductKey: "P9", UsageDate: day(2024, 1, 10), Cost: 100}
+	after := models.PricingUsage{CustomerID: 31, CustomerKey: "C7", ProductID: 58, ProductKey: "P9", UsageDate: day(2024, 1, 10), Cost: 100}
+
+	priced := priceUsages([]models.PricingUsage{before, after}, nil, rules)
+	for i, p := range priced {
+		if !p.priced || roundMoney(p.revenue) != 125 {
+			t.Errorf("usage %d: expected the rule to apply by business key, got %+v", i, p)
+		}
+	}
+}
+
+func TestValidatePriceRule(t *testing.T) {
+	rule := models.PriceRule{
+		Name:          " Tabela ",
+		RuleType:      models.PriceRuleMarkup,
+		Category:      strPtr("  "),
+		Tiers:         []models.PriceTier{{MinQuantity: 100, Percent: 10}, {MinQuantity: 0, Percent: 20}},
+		EffectiveFrom: time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC),
+	}
+	if err := validatePriceRule(&rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.Name != "Tabela" || rule.Category != nil || rule.Tiers[0].MinQuantity != 0 || !rule.EffectiveFrom.Equal(day(2024, 1, 1)) {
+		t.Errorf("expected normalized rule, got %+v", rule)
+	}
+
+	base := func() models.PriceRule {
+		return models.PriceRule{Name: "r", RuleType: models.PriceRuleDiscount, Percent: floatPtr(10), EffectiveFrom: day(2024, 1, 1)}
+	}
+	before := day(2023, 12, 31)
+	invalid := []func(*models.PriceRule){
+		func(r *models.PriceRule) { r.Name = "" },
+		func(r *models.PriceRule) { r.RuleType = "fixed" },
+		func(r *models.PriceRule) { r.Percent = nil },
+		func(r *models.PriceRule) { r.Tiers = []models.PriceTier{{Percent: 5}} },
+		func(r *models.PriceRule) { r.Percent = floatPtr(120) },
+		func(r *models.PriceRule) { r.RuleType, r.Percent = models.PriceRuleMarkup, floatPtr(-100) },
+		func(r *models.PriceRule) { r.Percent, r.Tiers = nil, []models.PriceTier{{MinQuantity: 5, Percent: 5}} },
+		func(r *models.PriceRule) {
+			r.Percent, r.Tiers = nil, []models.PriceTier{{MinQuantity: 0, Percent: 5}, {MinQuantity: 0, Percent: 6}}
+		},
+		func(r *models.PriceRule) { r.EffectiveFrom = time.Time{} },
+		func(r *models.PriceRule) { r.EffectiveTo = &before },
+		func(r *models.PriceRule) { r.CustomerID = strPtr("  ") },
+	}
+	for i, mutate := range invalid {
+		r := base()
+		mutate(&r)
+		if err := validatePriceRule(&r); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("case %d: expected ErrInvalidParameter, got %v", i, err)
+		}
+	}
+}
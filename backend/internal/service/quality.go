@@ -0,0 +1,448 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"data-importer-api-go/internal/models"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tipos de regra de qualidade suportados
+const (
+	ruleAmountConsistency  = "amount_consistency"
+	ruleUsageDateNotFuture = "usage_date_not_future"
+	ruleUsageDateInPeriod  = "usage_date_in_period"
+	ruleCountryISO3166     = "country_iso3166"
+	ruleDomainName         = "domain_name"
+	ruleNegativeTotal      = "negative_total"
+)
+
+var validQualitySeverities = map[string]bool{
+	models.QualityReject: true,
+	models.QualityWarn:   true,
+}
+
+// qualityParams reúne os parâmetros aceitos pelas regras; cada tipo usa apenas os seus
+type qualityParams struct {
+	// amount_consistency: diferença absoluta aceita (padrão 0,01) e relativa ao valor esperado
+	Tolerance         *float64 `json:"tolerance"`
+	RelativeTolerance float64  `json:"relative_tolerance"`
+	// usage_date_not_future: dias além de hoje ainda aceitos (fusos e cortes de faturamento)
+	MaxDaysAhead int `json:"max_days_ahead"`
+	// country_iso3166 e domain_name: aceita o campo vazio (padrão true)
+	AllowEmpty *bool `json:"allow_empty"`
+	// negative_total: benefit_types que admitem total negativo
+	AllowedBenefitTypes []string `json:"allowed_benefit_types"`
+}
+
+// qualityRow é a linha avaliada: a usage e o cliente a que ela se refere
+type qualityRow struct {
+	usage    *models.Usage
+	customer *models.Customer
+}
+
+// qualityCheck retorna a descrição da violação, ou "" quando a linha atende à regra
+type qualityCheck func(row qualityRow) string
+
+type compiledQualityRule struct {
+	rule  models.QualityRule
+	check qualityCheck
+}
+
+// GetQualityRules retorna todas as regras de qualidade
+func (s *Service) GetQualityRules(ctx context.Context) ([]models.QualityRule, error) {
+	rules, err := s.repo.GetQualityRules(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regras de qualidade: %w", err)
+	}
+	if rules == nil {
+		rules = []models.QualityRule{}
+	}
+	return rules, nil
+}
+
+// GetQualityRule retorna uma regra de qualidade; nil se não existir
+func (s *Service) GetQualityRule(ctx context.Context, id int) (*models.QualityRule, error) {
+	rule, err := s.repo.GetQualityRuleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar regra de qualidade: %w", err)
+	}
+	return rule, nil
+}
+
+// CreateQualityRule valida e cria uma regra de qualidade
+func (s *Service) CreateQualityRule(ctx context.Context, rule *models.QualityRule) error {
+	if err := validateQualityRule(rule); err != nil {
+		return err
+	}
+	if err := s.repo.InsertQualityRule(ctx, rule); err != nil {
+		return fmt.Errorf("erro no service ao criar regra de qualidade: %w", err)
+	}
+	return nil
+}
+
+// UpdateQualityRule valida e atualiza uma regra de qualidade; retorna false se não existir
+func (s *Service) UpdateQualityRule(ctx context.Context, rule *models.QualityRule) (bool, error) {
+	if err := validateQualityRule(rule); err != nil {
+		return false, err
+	}
+	found, err := s.repo.UpdateQualityRule(ctx, rule)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar regra de qualidade: %w", err)
+	}
+	return found, nil
+}
+
+// DeleteQualityRule remove uma regra de qualidade; retorna false se não existir
+func (s *Service) DeleteQualityRule(ctx context.Context, id int) (bool, error) {
+	found, err := s.repo.DeleteQualityRule(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover regra de qualidade: %w", err)
+	}
+	return found, nil
+}
+
+// ListQualityBatches retorna os lotes avaliados, mais recentes primeiro
+func (s *Service) ListQualityBatches(ctx context.Context, limit int) ([]models.QualityBatch, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	batches, err := s.repo.ListQualityBatches(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar lotes de qualidade: %w", err)
+	}
+	if batches == nil {
+		batches = []models.QualityBatch{}
+	}
+	return batches, nil
+}
+
+// GetQualityBatch retorna o lote com suas violações, filtradas pela severidade se informada;
+// nil se não existir
+func (s *Service) GetQualityBatch(ctx context.Context, id int, severity string) (*models.QualityBatch, error) {
+	if severity != "" && !validQualitySeverities[severity] {
+		return nil, fmt.Errorf("%w: severity deve ser reject ou warn", ErrInvalidParameter)
+	}
+
+	batch, err := s.repo.GetQualityBatchByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar lote de qualidade: %w", err)
+	}
+	if batch == nil {
+		return nil, nil
+	}
+
+	results, err := s.repo.GetQualityResults(ctx, id, severity)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar resultados de qualidade: %w", err)
+	}
+	if results == nil {
+		results = []models.QualityResult{}
+	}
+	batch.Results = results
+	return batch, nil
+}
+
+// applyQualityRules avalia as regras ativas nas usages do lote, grava o resultado no lote de
+// qualidade da importação e retorna as linhas (índices em usages) a descartar. Sem regras ativas
+// nada é gravado.
+func (s *Service) applyQualityRules(ctx context.Context, session *ImportSession, customers []models.Customer, usages []models.Usage) (map[int]bool, error) {
+	rules, err := s.repo.GetQualityRules(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar regras de qualidade: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	compiled := make([]compiledQualityRule, 0, len(rules))
+	for _, rule := range rules {
+		check, err := compileQualityRule(rule, now)
+		if err != nil {
+			fmt.Printf("Regra de qualidade %q ignorada: %v\n", rule.Name, err)
+			continue
+		}
+		compiled = append(compiled, compiledQualityRule{rule: rule, check: check})
+	}
+
+	customerByID := make(map[string]*models.Customer, len(customers))
+	for i := range customers {
+		customerByID[customers[i].CustomerID] = &customers[i]
+	}
+
+	batch, rejected := evaluateQuality(compiled, usages, customerByID)
+	session.result.Rejected += batch.RejectedRows
+	session.result.Warned += batch.WarnedRows
+
+	// Os trechos de uma mesma importação somam-se ao lote de qualidade criado pelo primeiro
+	var saveErr error
+	if session.qualityBatchID == 0 {
+		batch.FileName = session.fileName
+		batch.Checksum = session.checksum
+		saveErr = s.repo.InsertQualityBatch(ctx, &batch)
+	} else {
+		saveErr = s.repo.AppendQualityBatch(ctx, session.qualityBatchID, &batch)
+	}
+	if saveErr != nil {
+		fmt.Printf("Erro ao gravar resultado das regras de qualidade: %v\n", saveErr)
+		return rejected, nil
+	}
+
+	batchID := batch.ID
+	session.qualityBatchID = batchID
+	session.result.QualityBatchID = &batchID
+	fmt.Printf("Regras de qualidade (lote %d): %d linhas rejeitadas, %d com aviso\n",
+		batch.ID, batch.RejectedRows, batch.WarnedRows)
+	return rejected, nil
+}
+
+// evaluateQuality aplica as regras a cada usage e resume o lote; cada resultado aponta a linha de origem no arquivo
+func evaluateQuality(rules []compiledQualityRule, usages []models.Usage, customers map[string]*models.Customer) (models.QualityBatch, map[int]bool) {
+	batch := models.QualityBatch{RowsChecked: len(usages)}
+	rejected := make(map[int]bool)
+	warned := make(map[int]bool)
+
+	for i := range usages {
+		row := qualityRow{usage: &usages[i], customer: customers[usages[i].CustomerIDStr]}
+		for _, r := range rules {
+			message := r.check(row)
+			if message == "" {
+				continue
+			}
+
+			ruleID := r.rule.ID
+			batch.Results = append(batch.Results, models.QualityResult{
+				RuleID:        &ruleID,
+				RuleName:      r.rule.Name,
+				Severity:      r.rule.Severity,
+				RowNumber:     sourceRow(usages[i], i),
+				InvoiceNumber: usages[i].InvoiceNumber,
+				CustomerID:    usages[i].CustomerIDStr,
+				ProductID:     usages[i].ProductIDStr,
+				Message:       message,
+			})
+			if r.rule.Severity == models.QualityReject {
+				rejected[i] = true
+			} else {
+				warned[i] = true
+			}
+		}
+	}
+
+	batch.RejectedRows = len(rejected)
+	for i := range warned {
+		if !rejected[i] {
+			batch.WarnedRows++
+		}
+	}
+	return batch, rejected
+}
+
+// sourceRow retorna a linha de origem do usage; sem ela (importações que não vêm de arquivo), a posição a partir de 1
+func sourceRow(u models.Usage, i int) int {
+	if u.SourceRow > 0 {
+		return u.SourceRow
+	}
+	return i + 1
+}
+
+func validateQualityRule(rule *models.QualityRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name é obrigatório", ErrInvalidParameter)
+	}
+	if rule.Severity == "" {
+		rule.Severity = models.QualityWarn
+	}
+	if !validQualitySeverities[rule.Severity] {
+		return fmt.Errorf("%w: severity deve ser reject ou warn", ErrInvalidParameter)
+	}
+	if len(bytes.TrimSpace(rule.Params)) == 0 || string(bytes.TrimSpace(rule.Params)) == "null" {
+		rule.Params = json.RawMessage(`{}`)
+	}
+	if _, err := compileQualityRule(*rule, time.Now()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidParameter, err)
+	}
+	return nil
+}
+
+// compileQualityRule interpreta os parâmetros da regra e retorna a verificação correspondente
+func compileQualityRule(rule models.QualityRule, now time.Time) (qualityCheck, error) {
+	var params qualityParams
+	if len(rule.Params) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(rule.Params))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&params); err != nil {
+			return nil, fmt.Errorf("params inválidos: %v", err)
+		}
+	}
+	allowEmpty := params.AllowEmpty == nil || *params.AllowEmpty
+
+	switch rule.RuleType {
+	case ruleAmountConsistency:
+		tolerance := 0.01
+		if params.Tolerance != nil {
+			tolerance = *params.Tolerance
+		}
+		if tolerance < 0 || params.RelativeTolerance < 0 {
+			return nil, fmt.Errorf("tolerance e relative_tolerance não podem ser negativos")
+		}
+		return func(row qualityRow) string {
+			u := row.usage
+			expected := u.Quantity * u.UnitPrice
+			allowed := math.Max(tolerance, params.RelativeTolerance*math.Abs(expected))
+			// margem para o ruído de ponto flutuante do produto
+			if diff := math.Abs(u.BillingPreTaxTotal - expected); diff > allowed+1e-9 {
+				return fmt.Sprintf("billing_pre_tax_total %.2f difere de quantity × unit_price (%.2f) em %.2f",
+					u.BillingPreTaxTotal, expected, diff)
+			}
+			return ""
+		}, nil
+
+	case ruleUsageDateNotFuture:
+		if params.MaxDaysAhead < 0 {
+			return nil, fmt.Errorf("max_days_ahead não pode ser negativo")
+		}
+		limit := dateOnly(now).AddDate(0, 0, params.MaxDaysAhead)
+		return func(row qualityRow) string {
+			if dateOnly(row.usage.UsageDate).After(limit) {
+				return fmt.Sprintf("usage_date %s está no futuro", row.usage.UsageDate.Format("2006-01-02"))
+			}
+			return ""
+		}, nil
+
+	case ruleUsageDateInPeriod:
+		return func(row qualityRow) string {
+			u := row.usage
+			day := dateOnly(u.UsageDate)
+			if u.ChargeStartDate.Valid && day.Before(dateOnly(u.ChargeStartDate.Time)) {
+				return fmt.Sprintf("usage_date %s anterior ao início do período (%s)",
+					u.UsageDate.Format("2006-01-02"), u.ChargeStartDate.Time.Format("2006-01-02"))
+			}
+			if u.ChargeEndDate.Valid && day.After(dateOnly(u.ChargeEndDate.Time)) {
+				return fmt.Sprintf("usage_date %s posterior ao fim do período (%s)",
+					u.UsageDate.Format("2006-01-02"), u.ChargeEndDate.Time.Format("2006-01-02"))
+			}
+			return ""
+		}, nil
+
+	case ruleCountryISO3166:
+		return func(row qualityRow) string {
+			if row.customer == nil {
+				return ""
+			}
+			country := strings.ToUpper(strings.TrimSpace(row.customer.Country))
+			if country == "" {
+				if allowEmpty {
+					return ""
+				}
+				return "country vazio"
+			}
+			if !isoCountries[country] {
+				return fmt.Sprintf("country %q não é um código ISO-3166 alfa-2", row.customer.Country)
+			}
+			return ""
+		}, nil
+
+	case ruleDomainName:
+		return func(row qualityRow) string {
+			if row.customer == nil {
+				return ""
+			}
+			domain := strings.TrimSpace(row.customer.CustomerDomainName)
+			if domain == "" {
+				if allowEmpty {
+					return ""
+				}
+				return "customer_domain_name vazio"
+			}
+			if !validDomainName(domain) {
+				return fmt.Sprintf("customer_domain_name %q mal formado", domain)
+			}
+			return ""
+		}, nil
+
+	case ruleNegativeTotal:
+		allowed := make(map[string]bool, len(params.AllowedBenefitTypes))
+		for _, t := range params.AllowedBenefitTypes {
+			allowed[strings.ToLower(strings.TrimSpace(t))] = true
+		}
+		return func(row qualityRow) string {
+			u := row.usage
+			if u.BillingPreTaxTotal < 0 && !allowed[strings.ToLower(strings.TrimSpace(u.BenefitType))] {
+				return fmt.Sprintf("total negativo (%.2f) não permitido para benefit_type %q", u.BillingPreTaxTotal, u.BenefitType)
+			}
+			return ""
+		}, nil
+	}
+
+	return nil, fmt.Errorf("rule_type desconhecido: %s (use %s, %s, %s, %s, %s ou %s)", rule.RuleType,
+		ruleAmountConsistency, ruleUsageDateNotFuture, ruleUsageDateInPeriod, ruleCountryISO3166, ruleDomainName, ruleNegativeTotal)
+}
+
+// dateOnly descarta o horário, mantendo o dia do calendário
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var domainLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// validDomainName aceita nomes com ao menos dois rótulos e TLD não numérico (ex.: contoso.onmicrosoft.com)
+func validDomainName(domain string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	if len(domain) > 253 {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if !domainLabel.MatchString(label) {
+			return false
+		}
+	}
+	tld := labels[len(labels)-1]
+	return strings.Trim(tld, "0123456789") != ""
+}
+
+// isoCountries são os códigos ISO 3166-1 alfa-2 atribuídos
+var isoCountries = func() map[string]bool {
+	codes := strings.Fields(`
+		AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+		BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+		CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+		DE DJ DK DM DO DZ
+		EC EE EG EH ER ES ET
+		FI FJ FK FM FO FR
+		GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+		HK HM HN HR HT HU
+		ID IE IL IM IN IO IQ IR IS IT
+		JE JM JO JP
+		KE KG KH KI KM KN KP KR KW KY KZ
+		LA LB LC LI LK LR LS LT LU LV LY
+		MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+		NA NC NE NF NG NI NL NO NP NR NU NZ
+		OM
+		PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+		QA
+		RE RO RS RU RW
+		SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+		TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+		UA UG UM US UY UZ
+		VA VC VE VG VI VN VU
+		WF WS
+		YE YT
+		ZA ZM ZW`)
+	m := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		m[c] = true
+	}
+	return m
+}()
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustCompile(t *testing.T, ruleType, params string, now time.Time) qualityCheck {
+	t.Helper()
+	check, err := compileQualityRule(models.QualityRule{RuleType: ruleType, Params: json.RawMessage(params)}, now)
+	if err != nil {
+		t.Fatalf("compileQualityRule(%s, %s): %v", ruleType, params, err)
+	}
+	return check
+}
+
+func TestQualityRules(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 15, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+
+	amount := mustCompile(t, ruleAmountConsistency, `{"tolerance": 0.01, "relative_tolerance": 0.01}`, now)
+	future := mustCompile(t, ruleUsageDateNotFuture, `{"max_days_ahead": 1}`, now)
+	period := mustCompile(t, ruleUsageDateInPeriod, `{}`, now)
+	country := mustCompile(t, ruleCountryISO3166, `{}`, now)
+	countryRequired := mustCompile(t, ruleCountryISO3166, `{"allow_empty": false}`, now)
+	domain := mustCompile(t, ruleDomainName, `{}`, now)
+	negative := mustCompile(t, ruleNegativeTotal, `{"allowed_benefit_types": ["Credit"]}`, now)
+
+	tests := []struct {
+		name     string
+		check    qualityCheck
+		usage    models.Usage
+		customer *models.Customer
+		fails    bool
+	}{
+		{"amount matches", amount, models.Usage{Quantity: 3, UnitPrice: 0.1, BillingPreTaxTotal: 0.3}, nil, false},
+		{"amount within relative tolerance", amount, models.Usage{Quantity: 100, UnitPrice: 10, BillingPreTaxTotal: 1009}, nil, false},
+		{"amount off", amount, models.Usage{Quantity: 2, UnitPrice: 10, BillingPreTaxTotal: 25}, nil, true},
+		{"today", future, models.Usage{UsageDate: day(10)}, nil, false},
+		{"within days ahead", future, models.Usage{UsageDate: day(11).Add(23 * time.Hour)}, nil, false},
+		{"future", future, models.Usage{UsageDate: day(12)}, nil, true},
+		{"inside period", period, models.Usage{UsageDate: day(5), ChargeStartDate: sql.NullTime{Time: day(1), Valid: true}, ChargeEndDate: sql.NullTime{Time: day(31), Valid: true}}, nil, false},
+		{"before period", period, models.Usage{UsageDate: day(1).AddDate(0, 0, -1), ChargeStartDate: sql.NullTime{Time: day(1), Valid: true}}, nil, true},
+		{"after period", period, models.Usage{UsageDate: day(20), ChargeEndDate: sql.NullTime{Time: day(19), Valid: true}}, nil, true},
+		{"no period", period, models.Usage{UsageDate: day(20)}, nil, false},
+		{"iso country", country, models.Usage{}, &models.Customer{Country: "br"}, false},
+		{"country name", country, models.Usage{}, &models.Customer{Country: "Brazil"}, true},
+		{"empty country allowed", country, models.Usage{}, &models.Customer{}, false},
+		{"empty country required", countryRequired, models.Usage{}, &models.Customer{}, true},
+		{"no customer", countryRequired, models.Usage{}, nil, false},
+		{"valid domain", domain, models.Usage{}, &models.Customer{CustomerDomainName: "contoso.onmicrosoft.com"}, false},
+		{"domain without tld", domain, models.Usage{}, &models.Customer{CustomerDomainName: "contoso"}, true},
+		{"domain with spaces", domain, models.Usage{}, &models.Customer{CustomerDomainName: "con toso.com"}, true},
+		{"domain with bad hyphen", domain, models.Usage{}, &models.Customer{CustomerDomainName: "-contoso.com"}, true},
+		{"negative credit", negative, models.Usage{BillingPreTaxTotal: -5, BenefitType: "credit"}, nil, false},
+		{"negative charge", negative, models.Usage{BillingPreTaxTotal: -5, BenefitType: "Charge"}, nil, true},
+		{"positive charge", negative, models.Usage{BillingPreTaxTotal: 5}, nil, false},
+	}
+
+	for _, tt := range tests {
+		usage := tt.usage
+		message := tt.check(qualityRow{usage: &usage, customer: tt.customer})
+		if (message != "") != tt.fails {
+			t.Errorf("%s: message = %q, want fails = %v", tt.name, message, tt.fails)
+		}
+	}
+}
+
+func TestValidateQualityRule(t *testing.T) {
+	rule := models.QualityRule{Name: " Datas ", RuleType: ruleUsageDateNotFuture}
+	if err := validateQualityRule(&rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.Name != "Datas" || rule.Severity != models.QualityWarn || string(rule.Params) != "{}" {
+		t.Errorf("unexpected normalized rule: %+v", rule)
+	}
+
+	invalid := []models.QualityRule{
+		{RuleType: ruleDomainName},
+		{Name: "x", RuleType: "unknown"},
+		{Name: "x", RuleType: ruleDomainName, Severity: "error"},
+		{Name: "x", RuleType: ruleAmountConsistency, Params: json.RawMessage(`{"tolerance": -1}`)},
+		{Name: "x", RuleType: ruleAmountConsistency, Params: json.RawMessage(`{"tolerence": 1}`)},
+		{Name: "x", RuleType: ruleUsageDateNotFuture, Params: json.RawMessage(`{"max_days_ahead": -2}`)},
+	}
+	for _, rule := range invalid {
+		if err := validateQualityRule(&rule); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("validateQualityRule(%+v) err = %v, want ErrInvalidParameter", rule, err)
+		}
+	}
+}
+
+func TestEvaluateQuality(t *testing.T) {
+	now := time.Now()
+	rules := []compiledQualityRule{
+		{rule: models.QualityRule{ID: 1, Name: "amount", Severity: models.QualityWarn}, check: mustCompile(t, ruleAmountConsistency, `{}`, now)},
+		{rule: models.QualityRule{ID: 2, Name: "negative", Severity: models.QualityReject}, check: mustCompile(t, ruleNegativeTotal, `{}`, now)},
+		{rule: models.QualityRule{ID: 3, Name: "country", Severity: models.QualityWarn}, check: mustCompile(t, ruleCountryISO3166, `{}`, now)},
+	}
+	usages := []models.Usage{
+		{Quantity: 1, UnitPrice: 10, BillingPreTaxTotal: 10, CustomerIDStr: "C1"},
+		{Quantity: 1, UnitPrice: 10, BillingPreTaxTotal: 12, CustomerIDStr: "C1", InvoiceNumber: "F1"},
+		{Quantity: 1, UnitPrice: -10, BillingPreTaxTotal: -10, CustomerIDStr: "C2"},
+		{Quantity: 1, UnitPrice: 10, BillingPreTaxTotal: -10, CustomerIDStr: "C2"},
+	}
+	customers := map[string]*models.Customer{
+		"C1": {CustomerID: "C1", Country: "US"},
+		"C2": {CustomerID: "C2", Country: "United States"},
+	}
+
+	batch, rejected := evaluateQuality(rules, usages, customers)
+	if batch.RowsChecked != 4 || batch.RejectedRows != 2 || batch.WarnedRows != 1 {
+		t.Errorf("batch = %+v", batch)
+	}
+	if !rejected[2] || !rejected[3] || rejected[0] || rejected[1] {
+		t.Errorf("rejected = %v", rejected)
+	}
+	// linha 2: amount; linha 3: negative + country; linha 4: amount + negative + country
+	if len(batch.Results) != 6 {
+		t.Fatalf("results = %+v", batch.Results)
+	}
+	first := batch.Results[0]
+	if first.RowNumber != 2 || first.RuleName != "amount" || first.InvoiceNumber != "F1" || *first.RuleID != 1 {
+		t.Errorf("first result = %+v", first)
+	}
+	if !strings.Contains(batch.Results[len(batch.Results)-1].Message, "United States") {
+		t.Errorf("last result = %+v", batch.Results[len(batch.Results)-1])
+	}
+}
+
+func TestEvaluateQualityReportsSourceRow(t *testing.T) {
+	rules := []compiledQualityRule{
+		{rule: models.QualityRule{ID: 2, Name: "negative", Severity: models.QualityReject}, check: mustCompile(t, ruleNegativeTotal, `{}`, time.Now())},
+	}
+	// os workers do upload entregam as linhas fora de ordem; a linha reportada é a do arquivo
+	usages := []models.Usage{
+		{Quantity: 1, UnitPrice: 10, BillingPreTaxTotal: 10, SourceRow: 7},
+		{Quantity: 1, UnitPrice: -10, BillingPreTaxTotal: -10, SourceRow: 3},
+	}
+
+	batch, rejected := evaluateQuality(rules, usages, nil)
+	if !rejected[1] || len(batch.Results) != 1 {
+		t.Fatalf("rejected = %v, results = %+v", rejected, batch.Results)
+	}
+	if batch.Results[0].RowNumber != 3 {
+		t.Errorf("RowNumber = %d, want 3", batch.Results[0].RowNumber)
+	}
+}
+
+func TestISOCountries(t *testing.T) {
+	if len(isoCountries) != 249 {
+		t.Errorf("len(isoCountries) = %d, want 249", len(isoCountries))
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/repository"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	minSearchLength    = 2
+	maxSearchLength    = 100
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search busca o texto em clientes, produtos e parceiros e retorna os resultados de todos os
+// tipos ordenados pela pontuação, com os trechos encontrados destacados
+func (s *Service) Search(ctx context.Context, query string, types []string, limit int) (*models.SearchResponse, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if n := utf8.RuneCountInString(query); n < minSearchLength || n > maxSearchLength {
+		return nil, fmt.Errorf("%w: q deve ter entre %d e %d caracteres", ErrInvalidParameter, minSearchLength, maxSearchLength)
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, fmt.Errorf("%w: limit deve estar entre 1 e %d", ErrInvalidParameter, maxSearchLimit)
+	}
+
+	types = uniqueStrings(types)
+	if len(types) == 0 {
+		types = repository.SearchTypes()
+	}
+	for _, t := range types {
+		if !repository.IsSearchType(t) {
+			return nil, fmt.Errorf("%w: tipo desconhecido %q, use %s", ErrInvalidParameter, t, strings.Join(repository.SearchTypes(), ", "))
+		}
+	}
+
+	results, err := s.repo.Search(ctx, query, types, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar: %w", err)
+	}
+
+	return &models.SearchResponse{Query: query, Results: rankSearchResults(results, searchTerms(query), limit)}, nil
+}
+
+// rankSearchResults destaca os termos, ordena por pontuação (depois tipo e título) e mantém
+// os limit primeiros
+func rankSearchResults(results []models.SearchResult, terms []string, limit int) []models.SearchResult {
+	for i := range results {
+		r := &results[i]
+		r.Score = math.Round(r.Score*10000) / 10000
+		r.Highlights = make(map[string]string)
+		for field, value := range r.Fields {
+			if marked, ok := highlightTerms(value, terms); ok {
+				r.Highlights[field] = marked
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Type != results[j].Type {
+			return results[i].Type < results[j].Type
+		}
+		return results[i].Title < results[j].Title
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []models.SearchResult{}
+	}
+	return results
+}
+
+// searchTerms separa o texto buscado em termos minúsculos, sem repetição
+func searchTerms(query string) []string {
+	return uniqueStrings(strings.Fields(strings.ToLower(query)))
+}
+
+// highlightTerms escapa o valor como HTML e envolve em <mark> os trechos que contêm algum dos
+// termos, sem diferenciar maiúsculas. Retorna false se nenhum termo for encontrado.
+func highlightTerms(value string, terms []string) (string, bool) {
+	runes := []rune(value)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(runes))
+	found := false
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == term {
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+				found = true
+			}
+		}
+	}
+	if !found {
+		return "", false
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			segment = "<mark>" + segment + "</mark>"
+		}
+		b.WriteString(segment)
+		i = j
+	}
+	return b.String(), true
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"testing"
+)
+
+func TestHighlightTerms(t *testing.T) {
+	cases := []struct {
+		value string
+		terms []string
+		want  string
+		ok    bool
+	}{
+		{"Contoso Ltd", []string{"cont"}, "<mark>Cont</mark>oso Ltd", true},
+		{"contoso.com.br", []string{"oso", "com"}, "cont<mark>oso</mark>.<mark>com</mark>.br", true},
+		{"São Paulo Serviços", []string{"serviç"}, "São Paulo <mark>Serviç</mark>os", true},
+		{"AT&T <Corp>", []string{"corp"}, "AT&amp;T &lt;<mark>Corp</mark>&gt;", true},
+		{"aaaa", []string{"aa"}, "<mark>aaaa</mark>", true},
+		{"Fabrikam", []string{"contoso"}, "", false},
+	}
+	for _, tc := range cases {
+		got, ok := highlightTerms(tc.value, tc.terms)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("highlightTerms(%q, %v) = %q, %v; want %q, %v", tc.value, tc.terms, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestRankSearchResults(t *testing.T) {
+	results := []models.SearchResult{
+		{Type: "product", ID: 1, Title: "Contoso VM", Score: 0.41234, Fields: map[string]string{"product_name": "Contoso VM", "sku_name": "D2s"}},
+		{Type: "customer", ID: 2, Title: "Contoso", Score: 0.9, Fields: map[string]string{"customer_name": "Contoso", "customer_domain_name": "contoso.com"}},
+		{Type: "partner", ID: 3, Title: "Kontoso", Score: 0.3, Fields: map[string]string{"partner_name": "Kontoso"}},
+	}
+
+	ranked := rankSearchResults(results, searchTerms("CONTOSO"), 2)
+
+	if len(ranked) != 2 || ranked[0].Type != "customer" || ranked[1].Type != "product" {
+		t.Fatalf("unexpected ranking: %+v", ranked)
+	}
+	if ranked[1].Score != 0.4123 {
+		t.Errorf("expected rounded score, got %v", ranked[1].Score)
+	}
+	if len(ranked[0].Highlights) != 2 || ranked[0].Highlights["customer_domain_name"] != "<mark>contoso</mark>.com" {
+		t.Errorf("unexpected highlights: %v", ranked[0].Highlights)
+	}
+	if _, ok := ranked[1].Highlights["sku_name"]; ok {
+		t.Error("expected fields without the term not to be highlighted")
+	}
+
+	if empty := rankSearchResults(nil, []string{"x"}, 10); empty == nil || len(empty) != 0 {
+		t.Errorf("expected empty slice, got %v", empty)
+	}
+}
@@ -3,19 +3,38 @@ package service
 import (
 	"context"
 	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/notifier"
 	"data-importer-api-go/internal/repository"
+	"data-importer-api-go/internal/tags"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 	
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrInvalidParameter indica um parâmetro de consulta inválido informado pelo cliente
+var ErrInvalidParameter = errors.New("parâmetro inválido")
+
+// ErrConflict indica que o registro foi alterado por outra requisição ou ainda está em uso
+var ErrConflict = errors.New("conflito")
+
 type Service struct {
-	repo *repository.Repository
+	repo     *repository.Repository
+	notifier notifier.Notifier
+
+	// alertDelivery serializa as entregas de alertas de orçamento
+	alertDelivery sync.Mutex
 }
 
 func NewService(repo *repository.Repository) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, notifier: notifier.LogNotifier{}}
+}
+
+// SetNotifier define o destino dos alertas gerados pelo service
+func (s *Service) SetNotifier(n notifier.Notifier) {
+	s.notifier = n
 }
 
 // GetAllCustomers retorna todos os clientes
@@ -69,8 +88,8 @@ func (s *Service) GetUsageByCustomer(ctx context.Context, customerID int) ([]mod
 }
 
 // GetBillingMonthly retorna faturamento por mês
-func (s *Service) GetBillingMonthly(ctx context.Context) ([]models.BillingReport, error) {
-	reports, err := s.repo.GetBillingMonthly(ctx)
+func (s *Service) GetBillingMonthly(ctx context.Context, asOf *time.Time) ([]models.BillingReport, error) {
+	reports, err := s.repo.GetBillingMonthly(ctx, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("erro no service ao buscar faturamento mensal: %w", err)
 	}
@@ -78,8 +97,8 @@ func (s *Service) GetBillingMonthly(ctx context.Context) ([]models.BillingReport
 }
 
 // GetBillingByCategory retorna faturamento por categoria
-func (s *Service) GetBillingByCategory(ctx context.Context) ([]models.CategoryBillingReport, error) {
-	reports, err := s.repo.GetBillingByCategory(ctx)
+func (s *Service) GetBillingByCategory(ctx context.Context, asOf *time.Time) ([]models.CategoryBillingReport, error) {
+	reports, err := s.repo.GetBillingByCategory(ctx, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("erro no service ao buscar faturamento por categoria: %w", err)
 	}
@@ -87,8 +106,8 @@ func (s *Service) GetBillingByCategory(ctx context.Context) ([]models.CategoryBi
 }
 
 // GetBillingByResource retorna faturamento por recurso
-func (s *Service) GetBillingByResource(ctx context.Context) ([]models.ResourceBillingReport, error) {
-	reports, err := s.repo.GetBillingByResource(ctx)
+func (s *Service) GetBillingByResource(ctx context.Context, asOf *time.Time) ([]models.ResourceBillingReport, error) {
+	reports, err := s.repo.GetBillingByResource(ctx, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("erro no service ao buscar faturamento por recurso: %w", err)
 	}
@@ -96,8 +115,8 @@ func (s *Service) GetBillingByResource(ctx context.Context) ([]models.ResourceBi
 }
 
 // GetBillingByCustomer retorna faturamento por cliente
-func (s *Service) GetBillingByCustomer(ctx context.Context) ([]models.CustomerBillingReport, error) {
-	reports, err := s.repo.GetBillingByCustomer(ctx)
+func (s *Service) GetBillingByCustomer(ctx context.Context, asOf *time.Time) ([]models.CustomerBillingReport, error) {
+	reports, err := s.repo.GetBillingByCustomer(ctx, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("erro no service ao buscar faturamento por cliente: %w", err)
 	}
@@ -105,9 +124,9 @@ func (s *Service) GetBillingByCustomer(ctx context.Context) ([]models.CustomerBi
 }
 
 // GetKPIData retorna os dados de KPI do sistema
-func (s *Service) GetKPIData(ctx context.Context) (*models.KPIData, error) {
+func (s *Service) GetKPIData(ctx context.Context, asOf *time.Time) (*models.KPIData, error) {
 	// Obter dados de KPI do repositório
-	kpiData, err := s.repo.GetKPIData(ctx)
+	kpiData, err := s.repo.GetKPIData(ctx, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("erro no service ao buscar dados de KPI: %w", err)
 	}
@@ -129,8 +148,8 @@ func (s *Service) GetKPIData(ctx context.Context) (*models.KPIData, error) {
 }
 
 // GetBillingByProduct retorna faturamento por produto
-func (s *Service) GetBillingByProduct(ctx context.Context) ([]models.BillingByProduct, error) {
-	reports, err := s.repo.GetBillingByProduct(ctx)
+func (s *Service) GetBillingByProduct(ctx context.Context, asOf *time.Time) ([]models.BillingByProduct, error) {
+	reports, err := s.repo.GetBillingByProduct(ctx, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("erro no service ao buscar faturamento por produto: %w", err)
 	}
@@ -138,8 +157,8 @@ func (s *Service) GetBillingByProduct(ctx context.Context) ([]models.BillingByPr
 }
 
 // GetBillingByPartner retorna faturamento por parceiro
-func (s *Service) GetBillingByPartner(ctx context.Context) ([]models.BillingByPartner, error) {
-	reports, err := s.repo.GetBillingByPartner(ctx)
+func (s *Service) GetBillingByPartner(ctx context.Context, asOf *time.Time) ([]models.BillingByPartner, error) {
+	reports, err := s.repo.GetBillingByPartner(ctx, asOf)
 	if err != nil {
 		return nil, fmt.Errorf("erro no service ao buscar faturamento por parceiro: %w", err)
 	}
@@ -147,7 +166,32 @@ func (s *Service) GetBillingByPartner(ctx context.Context) ([]models.BillingByPa
 }
 
 // ProcessImportData processa dados de importação com inserção em lote
-func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) error {
+func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) (models.ImportResult, error) {
+	return s.ProcessImportDataForFile(ctx, "", nil, partners, customers, products, usages)
+}
+
+// ProcessImportDataForFile é ProcessImportData para o arquivo fileName. Com file, o arquivo é
+// registrado no histórico de arquivos importados na mesma transação dos usos: só consta como
+// importado se os usos foram gravados. Retorna o que foi efetivamente gravado.
+func (s *Service) ProcessImportDataForFile(ctx context.Context, fileName string, file *models.ImportedFile, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) (models.ImportResult, error) {
+	session := s.NewImportSession(fileName)
+	if file != nil {
+		session.checksum = file.Checksum
+	}
+	if err := s.processImport(ctx, session, file, partners, customers, products, usages); err != nil {
+		return session.Result(), err
+	}
+	s.FinishImport(ctx, session)
+	return session.Result(), nil
+}
+
+// ProcessImportBatch grava um lote de uma importação maior. As rotinas que dependem do arquivo
+// inteiro ficam para FinishImport, chamado uma vez após o último lote.
+func (s *Service) ProcessImportBatch(ctx context.Context, session *ImportSession, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) error {
+	return s.processImport(ctx, session, nil, partners, customers, products, usages)
+}
+
+func (s *Service) processImport(ctx context.Context, session *ImportSession, file *models.ImportedFile, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) error {
 	// Iniciar contagem de tempo de processamento
 	startTime := time.Now()
 	
@@ -156,6 +200,13 @@ func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partn
 	fmt.Printf("Processando importação: %d partners, %d customers, %d products, %d usages\n", 
 		len(partners), len(customers), len(products), len(usages))
 
+	// Regras de qualidade configuráveis; linhas com violação de severidade reject são descartadas
+	// antes de qualquer gravação, para que um erro aqui não deixe cadastros de uma importação abortada
+	rejected, err := s.applyQualityRules(ctx, session, customers, usages)
+	if err != nil {
+		return err
+	}
+
 	// Inserir partners individualmente para obter IDs
 	partnerIDMap := make(map[string]int)
 	for i := range partners {
@@ -201,6 +252,10 @@ func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partn
 		}
 	}
 
+	session.result.Partners += len(partnerIDMap)
+	session.result.Customers += len(customerIDMap)
+	session.result.Products += len(productIDMap)
+
 	// Verificar se temos IDs mapeados
 	fmt.Printf("Mapeamento de IDs: %d partners, %d customers, %d products\n", 
 		len(partnerIDMap), len(customerIDMap), len(productIDMap))
@@ -210,19 +265,29 @@ func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partn
 		fmt.Printf("Aviso: Um ou mais mapas de ID estão vazios. Isso pode causar problemas na importação.\n")
 	}
 
+	// Associar clientes novos ou alterados aos grupos pelos padrões de domínio
+	if _, err := s.ApplyCustomerGroupPatterns(ctx); err != nil {
+		fmt.Printf("Aviso ao aplicar grupos de clientes: %v\n", err)
+	}
+
 	// Atualizar usages com os IDs corretos
 	validUsages := make([]models.Usage, 0, len(usages))
 	for i := range usages {
+		if rejected[i] {
+			fmt.Printf("Usage rejeitado pelas regras de qualidade (linha %d)\n", sourceRow(usages[i], i))
+			continue
+		}
+
 		// Verificar se temos os campos temporários preenchidos
 		if usages[i].PartnerIDStr == "" || usages[i].CustomerIDStr == "" || usages[i].ProductIDStr == "" {
-			fmt.Printf("Usage ignorado: campos de ID temporários vazios (linha %d)\n", i+1)
+			fmt.Printf("Usage ignorado: campos de ID temporários vazios (linha %d)\n", sourceRow(usages[i], i))
 			continue
 		}
 
 		// Buscar partner_id baseado no partner_id do usage
 		partnerID, partnerExists := partnerIDMap[usages[i].PartnerIDStr]
 		if !partnerExists {
-			fmt.Printf("Partner ID não encontrado para: %s (linha %d)\n", usages[i].PartnerIDStr, i+1)
+			fmt.Printf("Partner ID não encontrado para: %s (linha %d)\n", usages[i].PartnerIDStr, sourceRow(usages[i], i))
 			continue
 		}
 		usages[i].PartnerID = partnerID
@@ -230,7 +295,7 @@ func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partn
 		// Buscar customer_id baseado no customer_id do usage
 		customerID, customerExists := customerIDMap[usages[i].CustomerIDStr]
 		if !customerExists {
-			fmt.Printf("Customer ID não encontrado para: %s (linha %d)\n", usages[i].CustomerIDStr, i+1)
+			fmt.Printf("Customer ID não encontrado para: %s (linha %d)\n", usages[i].CustomerIDStr, sourceRow(usages[i], i))
 			continue
 		}
 		usages[i].CustomerID = customerID
@@ -238,14 +303,17 @@ func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partn
 		// Buscar product_id baseado no product_id do usage
 		productID, productExists := productIDMap[usages[i].ProductIDStr]
 		if !productExists {
-			fmt.Printf("Product ID não encontrado para: %s (linha %d)\n", usages[i].ProductIDStr, i+1)
+			fmt.Printf("Product ID não encontrado para: %s (linha %d)\n", usages[i].ProductIDStr, sourceRow(usages[i], i))
 			continue
 		}
 		usages[i].ProductID = productID
 
+		// Interpretar as tags para filtros, agrupamentos e rateio por centro de custo
+		usages[i].TagMap = tags.Parse(usages[i].Tags)
+
 		// Verificar se a quantidade é válida
 		if usages[i].Quantity <= 0 {
-			fmt.Printf("Usage ignorado: quantidade inválida %.2f (linha %d)\n", usages[i].Quantity, i+1)
+			fmt.Printf("Usage ignorado: quantidade inválida %.2f (linha %d)\n", usages[i].Quantity, sourceRow(usages[i], i))
 			continue
 		}
 
@@ -255,16 +323,32 @@ func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partn
 			usages[i].PartnerID, usages[i].CustomerID, usages[i].ProductID, usages[i].Quantity)
 	}
 
+	// Versionar parceiros, clientes e produtos e ligar cada usage à versão vigente na sua data
+	if err := s.versionDimensions(ctx, partners, customers, products, validUsages); err != nil {
+		return err
+	}
+
 	// Inserir usages em lote
+	if file != nil {
+		file.Usages = len(validUsages)
+	}
 	if len(validUsages) > 0 {
 		fmt.Printf("Inserindo %d usages válidos em lote\n", len(validUsages))
-		if err := s.repo.BulkInsertUsages(ctx, validUsages); err != nil {
+		if err := s.repo.BulkInsertUsages(ctx, validUsages, file); err != nil {
 			fmt.Printf("Erro ao inserir usos em lote: %v\n", err)
 			return fmt.Errorf("erro ao inserir usos em lote: %w", err)
 		}
 		fmt.Printf("Inserção em lote concluída com sucesso!\n")
+		session.add(validUsages)
+		session.invoices.add(validUsages)
+		session.result.Usages += len(validUsages)
 	} else {
 		fmt.Printf("Nenhum usage válido para inserir\n")
+		if file != nil {
+			if err := s.repo.InsertImportedFile(ctx, file); err != nil {
+				return fmt.Errorf("erro no service ao registrar arquivo importado: %w", err)
+			}
+		}
 	}
 
 	// Calcular e salvar a métrica de tempo de processamento
@@ -291,12 +375,40 @@ func (s *Service) ProcessImportData(ctx context.Context, partners []models.Partn
 	return nil
 }
 
+// FinishImport executa, uma vez por importação, as rotinas que dependem dos dados gravados em
+// todos os lotes da sessão. Falhas são apenas registradas para não invalidar a importação.
+func (s *Service) FinishImport(ctx context.Context, session *ImportSession) {
+	// A conciliação só faz sentido com todas as linhas de cada fatura gravadas
+	s.refreshInvoices(ctx, session.invoices)
+
+	if count, err := s.detectAnomalies(ctx, session.series); err != nil {
+		fmt.Printf("Erro na detecção de anomalias: %v\n", err)
+	} else {
+		fmt.Printf("Detecção de anomalias concluída: %d anomalias registradas\n", count)
+	}
+
+	months := importedMonths(session.series)
+	if count, err := s.evaluateBudgets(ctx, months); err != nil {
+		fmt.Printf("Erro na avaliação de orçamentos: %v\n", err)
+	} else {
+		fmt.Printf("Avaliação de orçamentos concluída: %d novos alertas\n", count)
+	}
+
+	// Os demonstrativos são revisados uma vez, com todos os lotes gravados
+	s.reviseStatements(ctx, months)
+}
+
 // ProcessImportDataWithReplace processa dados de importação substituindo dados existentes
-func (s *Service) ProcessImportDataWithReplace(ctx context.Context, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) error {
+func (s *Service) ProcessImportDataWithReplace(ctx context.Context, partners []models.Partner, customers []models.Customer, products []models.Product, usages []models.Usage) (models.ImportResult, error) {
 	// Limpar dados existentes antes de inserir novos
-	if err := s.repo.ClearAllData(ctx); err != nil {
-		return fmt.Errorf("erro ao limpar dados existentes: %w", err)
+	deleted, err := s.repo.ClearAllData(ctx)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("erro ao limpar dados existentes: %w", err)
 	}
+	s.PublishEvent(ctx, EventBatchDeleted, map[string]interface{}{
+		"reason": "replace",
+		"usages": deleted,
+	})
 
 	// Processar dados normalmente
 	return s.ProcessImportData(ctx, partners, customers, products, usages)
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/tags"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statementPeriodLayout é o formato do período mensal dos demonstrativos
+const statementPeriodLayout = "2006-01"
+
+// StatementRequest identifica o demonstrativo a gerar: um cliente (CustomerID) ou um centro de
+// custo (TagKey e TagValue) em um mês
+type StatementRequest struct {
+	CustomerID int
+	TagKey     string
+	TagValue   string
+	Period     string
+}
+
+// GenerateStatement gera o demonstrativo do mês e o grava como nova versão se o conteúdo mudou
+// desde a última; created é false quando a última versão já estava atualizada
+func (s *Service) GenerateStatement(ctx context.Context, req StatementRequest) (statement *models.Statement, created bool, err error) {
+	start, err := time.Parse(statementPeriodLayout, req.Period)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: period deve estar no formato YYYY-MM", ErrInvalidParameter)
+	}
+
+	subject, err := s.statementSubject(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := s.buildStatementData(ctx, subject, start)
+	if err != nil {
+		return nil, false, err
+	}
+	return s.storeStatement(ctx, data)
+}
+
+// GetStatement retorna uma versão de demonstrativo; nil se não existir
+func (s *Service) GetStatement(ctx context.Context, id int) (*models.Statement, error) {
+	statement, err := s.repo.GetStatementByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar demonstrativo: %w", err)
+	}
+	return statement, nil
+}
+
+// ListStatements lista as versões de demonstrativos, sem o conteúdo
+func (s *Service) ListStatements(ctx context.Context, q models.StatementQuery) ([]models.Statement, error) {
+	if q.Period != "" {
+		if _, err := time.Parse(statementPeriodLayout, q.Period); err != nil {
+			return nil, fmt.Errorf("%w: period deve estar no formato YYYY-MM", ErrInvalidParameter)
+		}
+	}
+	q.TagKey = tags.NormalizeKey(q.TagKey)
+	if q.CustomerID > 0 {
+		// As versões guardam o customer_id de negócio, que não muda quando o cliente é reimportado
+		customer, err := s.repo.GetCustomerByID(ctx, q.CustomerID)
+		if err != nil {
+			return nil, fmt.Errorf("erro no service ao buscar cliente: %w", err)
+		}
+		if customer == nil {
+			return []models.Statement{}, nil
+		}
+		q.CustomerKey = customer.CustomerID
+	}
+
+	statements, err := s.repo.ListStatements(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao listar demonstrativos: %w", err)
+	}
+	if statements == nil {
+		statements = []models.Statement{}
+	}
+	return statements, nil
+}
+
+// reviseStatements regenera os demonstrativos já emitidos para os meses importados e os meses
+// seguintes. Os que mudaram ganham uma nova versão; os de clientes que não existem mais são
+// mantidos como estão. Falhas são apenas registradas.
+func (s *Service) reviseStatements(ctx context.Context, months []time.Time) {
+	periods := statementPeriods(months)
+	if len(periods) == 0 {
+		return
+	}
+
+	statements, err := s.repo.GetLatestStatementsByPeriods(ctx, periods)
+	if err != nil {
+		fmt.Printf("Erro ao buscar demonstrativos para revisão: %v\n", err)
+		return
+	}
+
+	revised := 0
+	for _, current := range statements {
+		start, err := time.Parse(statementPeriodLayout, current.Period)
+		if err != nil {
+			continue
+		}
+		subject, ok, err := s.resolveStatementSubject(ctx, current.Subject)
+		if err != nil {
+			fmt.Printf("Erro ao revisar demonstrativo %s %s: %v\n", current.SubjectKey, current.Period, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		data, err := s.buildStatementData(ctx, subject, start)
+		if err != nil {
+			fmt.Printf("Erro ao revisar demonstrativo %s %s: %v\n", current.SubjectKey, current.Period, err)
+			continue
+		}
+		if _, created, err := s.storeStatement(ctx, data); err != nil {
+			fmt.Printf("Erro ao revisar demonstrativo %s %s: %v\n", current.SubjectKey, current.Period, err)
+		} else if created {
+			revised++
+		}
+	}
+	if revised > 0 {
+		fmt.Printf("Demonstrativos revisados: %d\n", revised)
+	}
+}
+
+// resolveStatementSubject atualiza o id interno de um cliente gravado no demonstrativo pelo seu
+// customer_id, que muda quando o cliente é recriado; ok é false se o cliente não existir mais
+func (s *Service) resolveStatementSubject(ctx context.Context, subject models.StatementSubject) (resolved models.StatementSubject, ok bool, err error) {
+	if subject.Type != models.StatementCustomer {
+		return subject, true, nil
+	}
+	customer, err := s.repo.GetCustomerByCustomerID(ctx, subject.CustomerKey)
+	if err != nil {
+		return subject, false, fmt.Errorf("erro no service ao buscar cliente: %w", err)
+	}
+	if customer == nil {
+		return subject, false, nil
+	}
+	subject.CustomerID = customer.ID
+	return subject, true, nil
+}
+
+// statementSubject valida o sujeito do pedido e resolve o nome exibido
+func (s *Service) statementSubject(ctx context.Context, req StatementRequest) (models.StatementSubject, error) {
+	tagKey := tags.NormalizeKey(req.TagKey)
+	tagValue := strings.TrimSpace(req.TagValue)
+
+	switch {
+	case req.CustomerID > 0 && tagKey == "" && tagValue == "":
+		customer, err := s.repo.GetCustomerByID(ctx, req.CustomerID)
+		if err != nil {
+			return models.StatementSubject{}, fmt.Errorf("erro no service ao buscar cliente: %w", err)
+		}
+		if customer == nil {
+			return models.StatementSubject{}, fmt.Errorf("%w: cliente %d não encontrado", ErrInvalidParameter, req.CustomerID)
+		}
+		return models.StatementSubject{
+			Type:        models.StatementCustomer,
+			CustomerID:  customer.ID,
+			CustomerKey: customer.CustomerID,
+			Name:        customer.CustomerName,
+		}, nil
+	case req.CustomerID <= 0 && tagKey != "" && tagValue != "":
+		return models.StatementSubject{
+			Type:     models.StatementCostCenter,
+			TagKey:   tagKey,
+			TagValue: tagValue,
+			Name:     tagValue,
+		}, nil
+	default:
+		return models.StatementSubject{}, fmt.Errorf("%w: informe customer_id ou tag_key e tag_value", ErrInvalidParameter)
+	}
+}
+
+// buildStatementData monta o conteúdo do demonstrativo do mês iniciado em start, comparando
+// com o mês anterior
+func (s *Service) buildStatementData(ctx context.Context, subject models.StatementSubject, start time.Time) (*models.StatementData, error) {
+	end := start.AddDate(0, 1, 0)
+	previousStart := start.AddDate(0, -1, 0)
+
+	lines, err := s.repo.GetStatementLines(ctx, subject, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar linhas do demonstrativo: %w", err)
+	}
+	previous, err := s.repo.GetStatementLines(ctx, subject, previousStart, start)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar linhas do demonstrativo: %w", err)
+	}
+
+	var allocated, previousAllocated float64
+	if subject.Type == models.StatementCostCenter {
+		if allocated, err = s.allocatedCost(ctx, subject, start, end); err != nil {
+			return nil, err
+		}
+		if previousAllocated, err = s.allocatedCost(ctx, subject, previousStart, start); err != nil {
+			return nil, err
+		}
+	}
+
+	data := &models.StatementData{
+		Subject:     subject,
+		Period:      start.Format(statementPeriodLayout),
+		PeriodStart: start,
+		PeriodEnd:   end.AddDate(0, 0, -1),
+	}
+	summarizeStatement(data, lines, previous, allocated, previousAllocated)
+	return data, nil
+}
+
+// allocatedCost retorna o custo que o centro de custo recebe pelas regras de rateio em [from, to)
+func (s *Service) allocatedCost(ctx context.Context, subject models.StatementSubject, from, to time.Time) (float64, error) {
+	last := to.AddDate(0, 0, -1)
+	totals, err := s.repo.GetBillingByTagValue(ctx, subject.TagKey, &from, &last, nil)
+	if err != nil {
+		return 0, fmt.Errorf("erro no service ao buscar faturamento por tag: %w", err)
+	}
+	rules, err := s.repo.GetAllocationRules(ctx, subject.TagKey)
+	if err != nil {
+		return 0, fmt.Errorf("erro no service ao buscar regras de rateio: %w", err)
+	}
+
+	items, _ := allocateCosts(totals, rules)
+	for _, item := range items {
+		if item.Value == subject.TagValue {
+			return item.Allocated, nil
+		}
+	}
+	return 0, nil
+}
+
+// storeStatement grava o conteúdo como nova versão se ele difere da última versão do sujeito
+// no período
+func (s *Service) storeStatement(ctx context.Context, data *models.StatementData) (*models.Statement, bool, error) {
+	checksum, err := statementChecksum(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("erro no service ao gerar demonstrativo: %w", err)
+	}
+	key := statementSubjectKey(data.Subject)
+
+	latest, err := s.repo.GetLatestStatement(ctx, key, data.Period)
+	if err != nil {
+		return nil, false, fmt.Errorf("erro no service ao buscar demonstrativo: %w", err)
+	}
+	if latest != nil && latest.Checksum == checksum {
+		return latest, false, nil
+	}
+
+	statement := &models.Statement{
+		SubjectKey: key,
+		Subject:    data.Subject,
+		Period:     data.Period,
+		Checksum:   checksum,
+		Total:      data.Total,
+		Data:       data,
+	}
+	inserted, err := s.repo.InsertStatement(ctx, statement)
+	if err != nil {
+		return nil, false, fmt.Errorf("erro no service ao gravar demonstrativo: %w", err)
+	}
+	if !inserted {
+		return nil, false, fmt.Errorf("%w: demonstrativo gerado ao mesmo tempo por outra requisição", ErrConflict)
+	}
+	return statement, true, nil
+}
+
+// statementSubjectKey identifica o sujeito entre as versões: customer:<customer_id de negócio>
+// ou tag:<chave>=<valor>
+func statementSubjectKey(subject models.StatementSubject) string {
+	if subject.Type == models.StatementCustomer {
+		return "customer:" + subject.CustomerKey
+	}
+	return "tag:" + subject.TagKey + "=" + subject.TagValue
+}
+
+// statementChecksum resume o conteúdo do demonstrativo; versões com o mesmo checksum são iguais.
+// O id interno do cliente fica de fora: ele muda a cada reimportação sem mudar o conteúdo.
+func statementChecksum(data *models.StatementData) (string, error) {
+	content := *data
+	content.Subject.CustomerID = 0
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// statementPeriods retorna os meses (YYYY-MM) afetados por uma importação, em ordem: cada mês
+// importado e o seguinte, cuja comparação com o mês anterior também muda
+func statementPeriods(months []time.Time) []string {
+	seen := make(map[string]bool)
+	var periods []string
+	for _, month := range months {
+		for _, m := range []time.Time{month, month.AddDate(0, 1, 0)} {
+			period := m.Format(statementPeriodLayout)
+			if !seen[period] {
+				seen[period] = true
+				periods = append(periods, period)
+			}
+		}
+	}
+	sort.Strings(periods)
+	return periods
+}
+
+// summarizeStatement preenche totais, comparação com o período anterior e totais por produto
+// e categoria a partir das linhas
+func summarizeStatement(data *models.StatementData, lines, previous []models.StatementLine, allocated, previousAllocated float64) {
+	if lines == nil {
+		lines = []models.StatementLine{}
+	}
+	data.Lines = lines
+
+	var direct, previousDirect float64
+	for _, l := range lines {
+		direct += l.Total
+	}
+	for _, l := range previous {
+		previousDirect += l.Total
+	}
+
+	data.Direct = roundMoney(direct)
+	data.Allocated = roundMoney(allocated)
+	data.Total = roundMoney(direct + allocated)
+	data.PreviousTotal = roundMoney(previousDirect + previousAllocated)
+	data.Delta = roundMoney(data.Total - data.PreviousTotal)
+	data.DeltaPct = nil
+	if data.PreviousTotal != 0 {
+		pct := roundMoney(data.Delta / data.PreviousTotal * 100)
+		data.DeltaPct = &pct
+	}
+
+	data.Products = statementGroups(lines, previous, func(l models.StatementLine) string { return l.Product })
+	data.Categories = statementGroups(lines, previous, func(l models.StatementLine) string { return l.Category })
+}
+
+// statementGroups totaliza as linhas dos dois períodos pela chave, em ordem de total decrescente
+func statementGroups(lines, previous []models.StatementLine, key func(models.StatementLine) string) []models.StatementGroup {
+	index := make(map[string]int)
+	groups := []models.StatementGroup{}
+	group := func(name string) *models.StatementGroup {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, models.StatementGroup{Name: name})
+		}
+		return &groups[i]
+	}
+
+	for _, l := range lines {
+		group(key(l)).Total += l.Total
+	}
+	for _, l := range previous {
+		group(key(l)).PreviousTotal += l.Total
+	}
+
+	for i := range groups {
+		groups[i].Total = roundMoney(groups[i].Total)
+		groups[i].PreviousTotal = roundMoney(groups[i].PreviousTotal)
+		groups[i].Delta = roundMoney(groups[i].Total - groups[i].PreviousTotal)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
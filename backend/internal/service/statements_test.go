@@ -0,0 +1,108 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummarizeStatement(t *testing.T) {
+	lines := []models.StatementLine{
+		{Product: "VM", Category: "Compute", Total: 60},
+		{Product: "Storage", Category: "Storage", Total: 30},
+		{Product: "VM", Category: "Compute", Total: 10},
+	}
+	previous := []models.StatementLine{
+		{Product: "VM", Category: "Compute", Total: 50},
+		{Product: "SQL", Category: "Databases", Total: 30},
+	}
+
+	data := &models.StatementData{}
+	summarizeStatement(data, lines, previous, 20, 0)
+
+	if data.Direct != 100 || data.Allocated != 20 || data.Total != 120 {
+		t.Errorf("unexpected totals: direct %v, allocated %v, total %v", data.Direct, data.Allocated, data.Total)
+	}
+	if data.PreviousTotal != 80 || data.Delta != 40 || data.DeltaPct == nil || *data.DeltaPct != 50 {
+		t.Errorf("unexpected comparison: previous %v, delta %v, pct %v", data.PreviousTotal, data.Delta, data.DeltaPct)
+	}
+
+	wantProducts := []models.StatementGroup{
+		{Name: "VM", Total: 70, PreviousTotal: 50, Delta: 20},
+		{Name: "Storage", Total: 30, PreviousTotal: 0, Delta: 30},
+		{Name: "SQL", Total: 0, PreviousTotal: 30, Delta: -30},
+	}
+	if !reflect.DeepEqual(data.Products, wantProducts) {
+		t.Errorf("products = %+v, want %+v", data.Products, wantProducts)
+	}
+	if len(data.Categories) != 3 || data.Categories[0].Name != "Compute" {
+		t.Errorf("unexpected categories: %+v", data.Categories)
+	}
+}
+
+func TestSummarizeStatementWithoutPreviousPeriod(t *testing.T) {
+	data := &models.StatementData{}
+	summarizeStatement(data, nil, nil, 0, 0)
+
+	if data.DeltaPct != nil {
+		t.Errorf("expected no delta_pct without previous total, got %v", *data.DeltaPct)
+	}
+	if data.Lines == nil || data.Products == nil || data.Categories == nil {
+		t.Error("expected empty slices instead of nil")
+	}
+}
+
+func TestStatementChecksum(t *testing.T) {
+	build := func(total float64) *models.StatementData {
+		data := &models.StatementData{
+			Subject: models.StatementSubject{Type: models.StatementCustomer, CustomerID: 7, CustomerKey: "C-001", Name: "Contoso"},
+			Period:  "2024-01",
+		}
+		summarizeStatement(data, []models.StatementLine{{Product: "VM", Total: total}}, nil, 0, 0)
+		return data
+	}
+
+	a, _ := statementChecksum(build(10))
+	b, _ := statementChecksum(build(10))
+	c, _ := statementChecksum(build(11))
+	if a != b {
+		t.Error("expected identical content to produce the same checksum")
+	}
+	if a == c {
+		t.Error("expected changed content to produce a different checksum")
+	}
+
+	// o cliente recriado por uma reimportação ganha outro id interno, sem mudar o conteúdo
+	reimported := build(10)
+	reimported.Subject.CustomerID = 42
+	if d, _ := statementChecksum(reimported); d != a {
+		t.Error("expected the internal customer id to be left out of the checksum")
+	}
+	if reimported.Subject.CustomerID != 42 {
+		t.Error("expected the checksum to leave the statement untouched")
+	}
+}
+
+func TestStatementSubjectKey(t *testing.T) {
+	if got := statementSubjectKey(models.StatementSubject{Type: models.StatementCustomer, CustomerID: 7, CustomerKey: "C-001"}); got != "customer:C-001" {
+		t.Errorf("unexpected customer key %q", got)
+	}
+	got := statementSubjectKey(models.StatementSubject{Type: models.StatementCostCenter, TagKey: "costcenter", TagValue: "CC1"})
+	if got != "tag:costcenter=CC1" {
+		t.Errorf("unexpected cost center key %q", got)
+	}
+}
+
+func TestStatementPeriods(t *testing.T) {
+	months := []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	// o mês seguinte entra porque sua comparação com o mês anterior muda
+	want := []string{"2024-01", "2024-02", "2024-03", "2024-12", "2025-01"}
+	if got := statementPeriods(months); !reflect.DeepEqual(got, want) {
+		t.Errorf("statementPeriods() = %v", got)
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var validGranularities = map[string]bool{
+	"day":     true,
+	"week":    true,
+	"month":   true,
+	"quarter": true,
+	"year":    true,
+}
+
+var validTimeseriesGroups = map[string]bool{
+	"partner":           true,
+	"customer":          true,
+	"product":           true,
+	"category":          true,
+	"resource_location": true,
+	"customer_group":    true,
+}
+
+// maxFilledTimeseriesPoints limita os buckets gerados por fill_gaps (períodos × grupos), para que
+// um intervalo longo em granularidade fina não monte uma série sem limite em memória
+const maxFilledTimeseriesPoints = 50000
+
+var validComparisons = map[string]bool{
+	"previous_period": true,
+	"previous_year":   true,
+}
+
+// GetBillingTimeseries retorna o faturamento por período, com preenchimento de lacunas e comparação opcionais
+func (s *Service) GetBillingTimeseries(ctx context.Context, q models.BillingTimeseriesQuery) (*models.BillingTimeseriesReport, error) {
+	if q.Granularity == "" {
+		q.Granularity = "month"
+	}
+	if !validGranularities[q.Granularity] {
+		return nil, fmt.Errorf("%w: granularity deve ser day, week, month, quarter ou year", ErrInvalidParameter)
+	}
+	if q.GroupBy != "" && !validTimeseriesGroups[q.GroupBy] && repository.TagKey(q.GroupBy) == "" {
+		return nil, fmt.Errorf("%w: group_by deve ser partner, customer, customer_group, product, category, resource_location ou tag:<chave>", ErrInvalidParameter)
+	}
+	if q.Compare != "" && !validComparisons[q.Compare] {
+		return nil, fmt.Errorf("%w: compare deve ser previous_period ou previous_year", ErrInvalidParameter)
+	}
+
+	report := &models.BillingTimeseriesReport{
+		Granularity: q.Granularity,
+		GroupBy:     q.GroupBy,
+		Compare:     q.Compare,
+		Points:      []models.BillingTimeseriesPoint{},
+	}
+
+	// Sem intervalo explícito, usar o intervalo dos dados existentes
+	if q.From == nil || q.To == nil {
+		minDate, maxDate, err := s.repo.GetUsageDateRange(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("erro no service ao buscar intervalo de datas: %w", err)
+		}
+		if minDate == nil || maxDate == nil {
+			return report, nil
+		}
+		if q.From == nil {
+			q.From = minDate
+		}
+		if q.To == nil {
+			q.To = maxDate
+		}
+	}
+
+	from := truncatePeriod(*q.From, q.Granularity)
+	to := nextPeriod(truncatePeriod(*q.To, q.Granularity), q.Granularity)
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from deve ser anterior a to", ErrInvalidParameter)
+	}
+	report.From = from.Format("2006-01-02")
+	report.To = to.AddDate(0, 0, -1).Format("2006-01-02")
+
+	points, err := s.repo.GetBillingTimeseries(ctx, q.Granularity, q.GroupBy, from, to, q.AsOf)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar série temporal: %w", err)
+	}
+
+	if q.FillGaps {
+		if filledTimeseriesSize(points, q.Granularity, from, to) > maxFilledTimeseriesPoints {
+			return nil, fmt.Errorf("%w: fill_gaps geraria mais de %d pontos; reduza o intervalo ou use uma granularidade maior",
+				ErrInvalidParameter, maxFilledTimeseriesPoints)
+		}
+		points = fillTimeseriesGaps(points, q.Granularity, from, to)
+	}
+
+	if q.Compare != "" {
+		cmpFrom := shiftPeriod(from, q.Granularity, q.Compare)
+		cmpTo := shiftPeriod(to, q.Granularity, q.Compare)
+		previous, err := s.repo.GetBillingTimeseries(ctx, q.Granularity, q.GroupBy, cmpFrom, cmpTo, q.AsOf)
+		if err != nil {
+			return nil, fmt.Errorf("erro no service ao buscar série temporal de comparação: %w", err)
+		}
+		applyTimeseriesComparison(points, previous, q.Granularity, q.Compare)
+	}
+
+	for i := range points {
+		points[i].Period = periodLabel(points[i].PeriodStart, q.Granularity)
+	}
+	if points != nil {
+		report.Points = points
+	}
+
+	return report, nil
+}
+
+// filledTimeseriesSize retorna quantos pontos fillTimeseriesGaps produziria; a contagem para assim
+// que passa do limite
+func filledTimeseriesSize(points []models.BillingTimeseriesPoint, granularity string, from, to time.Time) int {
+	groups := make(map[string]bool)
+	for _, p := range points {
+		groups[timeseriesGroup(p)] = true
+	}
+	if len(groups) == 0 {
+		groups[""] = true
+	}
+
+	periods := 0
+	for start := from; start.Before(to) && periods*len(groups) <= maxFilledTimeseriesPoints; start = nextPeriod(start, granularity) {
+		periods++
+	}
+	return periods * len(groups)
+}
+
+// fillTimeseriesGaps insere buckets zerados para períodos sem dados em cada grupo
+func fillTimeseriesGaps(points []models.BillingTimeseriesPoint, granularity string, from, to time.Time) []models.BillingTimeseriesPoint {
+	existing := make(map[string]bool, len(points))
+	groups := []models.BillingTimeseriesPoint{}
+	seenGroups := make(map[string]bool)
+	for _, p := range points {
+		existing[timeseriesKey(timeseriesGroup(p), p.PeriodStart)] = true
+		if !seenGroups[timeseriesGroup(p)] {
+			seenGroups[timeseriesGroup(p)] = true
+			groups = append(groups, models.BillingTimeseriesPoint{Group: p.Group, GroupKey: p.GroupKey})
+		}
+	}
+	if len(groups) == 0 {
+		groups = append(groups, models.BillingTimeseriesPoint{})
+	}
+
+	for start := from; start.Before(to); start = nextPeriod(start, granularity) {
+		for _, group := range groups {
+			if !existing[timeseriesKey(timeseriesGroup(group), start)] {
+				points = append(points, models.BillingTimeseriesPoint{PeriodStart: start, Group: group.Group, GroupKey: group.GroupKey})
+			}
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		if !points[i].PeriodStart.Equal(points[j].PeriodStart) {
+			return points[i].PeriodStart.Before(points[j].PeriodStart)
+		}
+		if points[i].Group != points[j].Group {
+			return points[i].Group < points[j].Group
+		}
+		return points[i].GroupKey < points[j].GroupKey
+	})
+
+	return points
+}
+
+// applyTimeseriesComparison preenche o total anterior e as variações de cada bucket
+func applyTimeseriesComparison(points, previous []models.BillingTimeseriesPoint, granularity, compare string) {
+	previousTotals := make(map[string]float64, len(previous))
+	for _, p := range previous {
+		previousTotals[timeseriesKey(timeseriesGroup(p), p.PeriodStart)] = p.Total
+	}
+
+	for i := range points {
+		prevStart := shiftPeriod(points[i].PeriodStart, granularity, compare)
+		prevTotal := previousTotals[timeseriesKey(timeseriesGroup(points[i]), prevStart)]
+		delta := points[i].Total - prevTotal
+
+		points[i].PreviousTotal = &prevTotal
+		points[i].Delta = &delta
+		if prevTotal != 0 {
+			pct := delta / prevTotal * 100
+			points[i].DeltaPct = &pct
+		}
+	}
+}
+
+// timeseriesGroup identifica o grupo do ponto: a chave de negócio, quando houver, mais o rótulo
+func timeseriesGroup(p models.BillingTimeseriesPoint) string {
+	return p.GroupKey + "|" + p.Group
+}
+
+func timeseriesKey(group string, start time.Time) string {
+	return group + "|" + start.Format("2006-01-02")
+}
+
+// truncatePeriod retorna o início do período que contém t, seguindo a semântica de DATE_TRUNC
+func truncatePeriod(t time.Time, granularity string) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case "week":
+		// Semanas ISO começam na segunda-feira
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "quarter":
+		month := time.Month((int(t.Month())-1)/3*3 + 1)
+		return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+	case "year":
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// nextPeriod retorna o início do período seguinte
+func nextPeriod(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	case "quarter":
+		return start.AddDate(0, 3, 0)
+	case "year":
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// shiftPeriod desloca o início de um período para o período de comparação
+func shiftPeriod(start time.Time, granularity, compare string) time.Time {
+	if compare == "previous_year" {
+		if granularity == "week" {
+			// 52 semanas mantêm o alinhamento na segunda-feira
+			return start.AddDate(0, 0, -364)
+		}
+		return start.AddDate(-1, 0, 0)
+	}
+
+	switch granularity {
+	case "week":
+		return start.AddDate(0, 0, -7)
+	case "month":
+		return start.AddDate(0, -1, 0)
+	case "quarter":
+		return start.AddDate(0, -3, 0)
+	case "year":
+		return start.AddDate(-1, 0, 0)
+	}
+	return start.AddDate(0, 0, -1)
+}
+
+// periodLabel formata o rótulo legível de um período
+func periodLabel(start time.Time, granularity string) string {
+	switch granularity {
+	case "week":
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		return start.Format("2006-01")
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case "year":
+		return start.Format("2006")
+	}
+	return start.Format("2006-01-02")
+}
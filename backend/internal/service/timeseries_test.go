@@ -0,0 +1,118 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTruncatePeriod(t *testing.T) {
+	day := date(2024, time.May, 15) // quarta-feira
+	cases := map[string]time.Time{
+		"day":     date(2024, time.May, 15),
+		"week":    date(2024, time.May, 13),
+		"month":   date(2024, time.May, 1),
+		"quarter": date(2024, time.April, 1),
+		"year":    date(2024, time.January, 1),
+	}
+	for granularity, want := range cases {
+		if got := truncatePeriod(day, granularity); !got.Equal(want) {
+			t.Errorf("truncatePeriod(%s) = %s, want %s", granularity, got, want)
+		}
+	}
+}
+
+func TestPeriodLabel(t *testing.T) {
+	start := date(2024, time.April, 1)
+	cases := map[string]string{
+		"day":     "2024-04-01",
+		"week":    "2024-W14",
+		"month":   "2024-04",
+		"quarter": "2024-Q2",
+		"year":    "2024",
+	}
+	for granularity, want := range cases {
+		if got := periodLabel(start, granularity); got != want {
+			t.Errorf("periodLabel(%s) = %s, want %s", granularity, got, want)
+		}
+	}
+}
+
+func TestFillTimeseriesGaps(t *testing.T) {
+	points := []models.BillingTimeseriesPoint{
+		{PeriodStart: date(2024, time.January, 1), Group: "A", Total: 10, Count: 1},
+		{PeriodStart: date(2024, time.March, 1), Group: "B", Total: 5, Count: 1},
+	}
+
+	filled := fillTimeseriesGaps(points, "month", date(2024, time.January, 1), date(2024, time.April, 1))
+	if len(filled) != 6 {
+		t.Fatalf("expected 6 points (3 months x 2 groups), got %d", len(filled))
+	}
+	if filled[0].Group != "A" || filled[0].Total != 10 {
+		t.Errorf("expected first point to be group A with total 10, got %+v", filled[0])
+	}
+	if !filled[5].PeriodStart.Equal(date(2024, time.March, 1)) || filled[5].Group != "B" {
+		t.Errorf("expected last point to be 2024-03 group B, got %+v", filled[5])
+	}
+}
+
+func TestFillTimeseriesGapsKeepsSameNamedGroupsApart(t *testing.T) {
+	points := []models.BillingTimeseriesPoint{
+		{PeriodStart: date(2024, time.January, 1), Group: "Contoso", GroupKey: "C1", Total: 10},
+		{PeriodStart: date(2024, time.February, 1), Group: "Contoso", GroupKey: "C2", Total: 5},
+	}
+
+	filled := fillTimeseriesGaps(points, "month", date(2024, time.January, 1), date(2024, time.March, 1))
+	if len(filled) != 4 {
+		t.Fatalf("expected 4 points (2 months x 2 customers), got %+v", filled)
+	}
+	if filled[0].GroupKey != "C1" || filled[0].Total != 10 || filled[1].GroupKey != "C2" || filled[1].Total != 0 {
+		t.Errorf("unexpected January points: %+v", filled[:2])
+	}
+}
+
+func TestFilledTimeseriesSize(t *testing.T) {
+	points := []models.BillingTimeseriesPoint{
+		{PeriodStart: date(2024, time.January, 1), Group: "A"},
+		{PeriodStart: date(2024, time.January, 1), Group: "B"},
+	}
+	if got := filledTimeseriesSize(points, "month", date(2024, time.January, 1), date(2024, time.April, 1)); got != 6 {
+		t.Errorf("expected 6 points, got %d", got)
+	}
+
+	// décadas em granularidade diária passam do limite sem percorrer o intervalo inteiro
+	got := filledTimeseriesSize(points, "day", date(1900, time.January, 1), date(2024, time.January, 1))
+	if got <= maxFilledTimeseriesPoints || got > maxFilledTimeseriesPoints+2 {
+		t.Errorf("expected count to stop just past the cap, got %d", got)
+	}
+}
+
+func TestApplyTimeseriesComparison(t *testing.T) {
+	points := []models.BillingTimeseriesPoint{
+		{PeriodStart: date(2024, time.February, 1), Total: 150},
+		{PeriodStart: date(2024, time.March, 1), Total: 50},
+	}
+	previous := []models.BillingTimeseriesPoint{
+		{PeriodStart: date(2024, time.January, 1), Total: 100},
+		{PeriodStart: date(2024, time.February, 1), Total: 150},
+	}
+
+	applyTimeseriesComparison(points, previous, "month", "previous_period")
+
+	if *points[0].PreviousTotal != 100 || *points[0].Delta != 50 || *points[0].DeltaPct != 50 {
+		t.Errorf("unexpected comparison for February: %+v", points[0])
+	}
+	if *points[1].Delta != -100 {
+		t.Errorf("expected delta -100 for March, got %v", *points[1].Delta)
+	}
+
+	yearly := []models.BillingTimeseriesPoint{{PeriodStart: date(2024, time.March, 1), Total: 0}}
+	applyTimeseriesComparison(yearly, nil, "month", "previous_year")
+	if yearly[0].DeltaPct != nil {
+		t.Errorf("expected no percentage when previous total is zero, got %v", *yearly[0].DeltaPct)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/repository"
+	"fmt"
+)
+
+const (
+	defaultTopN      = 10
+	defaultParetoPct = 80.0
+	maxTopN          = 1000
+)
+
+// topNMetrics lista as métricas aditivas, para as quais participação e acumulado fazem sentido
+var topNMetrics = map[string]bool{
+	"sum_billing":  true,
+	"sum_quantity": true,
+	"count":        true,
+}
+
+// GetTopN retorna as N maiores entidades de uma dimensão, com participação acumulada e cauda longa
+func (s *Service) GetTopN(ctx context.Context, q models.TopNQuery) (*models.TopNReport, error) {
+	if q.Metric == "" {
+		q.Metric = "sum_billing"
+	}
+	if q.N <= 0 {
+		q.N = defaultTopN
+	}
+	if q.N > maxTopN {
+		q.N = maxTopN
+	}
+	if q.ThresholdPct <= 0 || q.ThresholdPct > 100 {
+		q.ThresholdPct = defaultParetoPct
+	}
+	if !repository.IsDimension(q.Dimension) {
+		return nil, fmt.Errorf("%w: dimensão desconhecida %q", ErrInvalidParameter, q.Dimension)
+	}
+	if !topNMetrics[q.Metric] {
+		return nil, fmt.Errorf("%w: metric deve ser sum_billing, sum_quantity ou count", ErrInvalidParameter)
+	}
+
+	// Buscar todas as entidades: o acumulado e a cauda dependem do conjunto completo
+	rows, err := s.repo.Aggregate(ctx, models.AggregationQuery{
+		Dimensions: []string{q.Dimension},
+		Measures:   []string{q.Metric},
+		Sort:       "-" + q.Metric,
+		From:       q.From,
+		To:         q.To,
+		AsOf:       q.AsOf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar ranking: %w", err)
+	}
+
+	report := buildTopNReport(rows, q.Dimension, q.Metric, q.N, q.ThresholdPct)
+	report.Dimension = q.Dimension
+	report.Metric = q.Metric
+	return report, nil
+}
+
+// buildTopNReport monta o ranking a partir das linhas já ordenadas de forma decrescente pela métrica.
+// Cada linha é uma entidade: partner, customer e product são distinguidos pela chave, não pelo nome.
+func buildTopNReport(rows []models.AggregationRow, dimension, metric string, n int, thresholdPct float64) *models.TopNReport {
+	values := make([]float64, len(rows))
+	for i, row := range rows {
+		values[i] = row.Measures[metric]
+	}
+
+	report := &models.TopNReport{
+		N:           n,
+		EntityCount: len(values),
+		Items:       []models.TopNEntry{},
+		Pareto:      models.ParetoSummary{ThresholdPct: thresholdPct},
+	}
+
+	for _, v := range values {
+		report.Total += v
+	}
+
+	share := func(v float64) float64 {
+		if report.Total == 0 {
+			return 0
+		}
+		return v / report.Total * 100
+	}
+
+	cumulative := 0.0
+	for i, v := range values {
+		cumulative += v
+		cumulativeShare := share(cumulative)
+		entityPct := float64(i+1) / float64(len(values)) * 100
+
+		if report.Pareto.EntitiesToThreshold == 0 && report.Total != 0 && cumulativeShare >= thresholdPct {
+			report.Pareto.EntitiesToThreshold = i + 1
+			report.Pareto.EntitySharePct = entityPct
+		}
+
+		if i < n {
+			report.Items = append(report.Items, models.TopNEntry{
+				Rank:            i + 1,
+				Key:             rows[i].Dimensions[dimension],
+				ID:              rows[i].Keys[dimension],
+				Value:           v,
+				Share:           share(v),
+				CumulativeShare: cumulativeShare,
+				EntityPct:       entityPct,
+			})
+		}
+	}
+
+	if len(values) > n {
+		other := &models.TopNOther{EntityCount: len(values) - n}
+		for _, v := range values[n:] {
+			other.Value += v
+		}
+		other.Share = share(other.Value)
+		report.Other = other
+	}
+
+	return report
+}
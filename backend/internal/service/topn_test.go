@@ -0,0 +1,74 @@
+package service
+
+import (
+	"data-importer-api-go/internal/models"
+	"testing"
+)
+
+// topNRows monta linhas de agregação da dimensão "d" com a métrica "m", na ordem informada
+func topNRows(labels []string, values []float64) []models.AggregationRow {
+	rows := make([]models.AggregationRow, len(labels))
+	for i := range labels {
+		rows[i] = models.AggregationRow{
+			Dimensions: map[string]string{"d": labels[i]},
+			Measures:   map[string]float64{"m": values[i]},
+		}
+	}
+	return rows
+}
+
+func TestBuildTopNReport(t *testing.T) {
+	rows := topNRows([]string{"A", "B", "C", "D", "E"}, []float64{50, 30, 10, 6, 4})
+
+	report := buildTopNReport(rows, "d", "m", 2, 80)
+
+	if report.Total != 100 || report.EntityCount != 5 {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+	if len(report.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(report.Items))
+	}
+	if report.Items[1].CumulativeShare != 80 || report.Items[1].EntityPct != 40 {
+		t.Errorf("unexpected second item: %+v", report.Items[1])
+	}
+	if report.Other == nil || report.Other.EntityCount != 3 || report.Other.Value != 20 {
+		t.Errorf("unexpected other bucket: %+v", report.Other)
+	}
+	if report.Pareto.EntitiesToThreshold != 2 || report.Pareto.EntitySharePct != 40 {
+		t.Errorf("unexpected pareto summary: %+v", report.Pareto)
+	}
+}
+
+func TestBuildTopNReportWithoutTail(t *testing.T) {
+	report := buildTopNReport(topNRows([]string{"A"}, []float64{10}), "d", "m", 5, 80)
+	if report.Other != nil {
+		t.Errorf("expected no other bucket, got %+v", report.Other)
+	}
+	if report.Items[0].Share != 100 {
+		t.Errorf("expected full share, got %v", report.Items[0].Share)
+	}
+}
+
+func TestBuildTopNReportKeepsSameNamedCustomersApart(t *testing.T) {
+	rows := []models.AggregationRow{
+		{Dimensions: map[string]string{"customer": "Contoso"}, Keys: map[string]string{"customer": "C1"}, Measures: map[string]float64{"sum_billing": 60}},
+		{Dimensions: map[string]string{"customer": "Contoso"}, Keys: map[string]string{"customer": "C2"}, Measures: map[string]float64{"sum_billing": 30}},
+		{Dimensions: map[string]string{"customer": "Fabrikam"}, Keys: map[string]string{"customer": "C3"}, Measures: map[string]float64{"sum_billing": 10}},
+	}
+
+	report := buildTopNReport(rows, "customer", "sum_billing", 1, 80)
+
+	if report.EntityCount != 3 {
+		t.Fatalf("expected 3 entities, got %d", report.EntityCount)
+	}
+	if report.Items[0].Key != "Contoso" || report.Items[0].ID != "C1" || report.Items[0].Share != 60 {
+		t.Errorf("unexpected first item: %+v", report.Items[0])
+	}
+	// os dois Contoso são entidades distintas: 60% não atinge 80% com uma só
+	if report.Pareto.EntitiesToThreshold != 2 {
+		t.Errorf("unexpected pareto summary: %+v", report.Pareto)
+	}
+	if report.Other == nil || report.Other.EntityCount != 2 || report.Other.Value != 40 {
+		t.Errorf("unexpected other bucket: %+v", report.Other)
+	}
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"data-importer-api-go/internal/models"
+	"data-importer-api-go/internal/notifier"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Eventos de webhook emitidos pela API
+const (
+	EventImportCompleted = "import.completed"
+	EventImportFailed    = "import.failed"
+	EventBatchDeleted    = "batch.deleted"
+	// EventInvoiceDiscrepancy é emitido quando a soma das linhas de uma fatura não fecha com o total de controle
+	EventInvoiceDiscrepancy = "invoice.discrepancy"
+)
+
+var validWebhookEvents = map[string]bool{
+	EventImportCompleted:    true,
+	EventImportFailed:       true,
+	EventBatchDeleted:       true,
+	EventInvoiceDiscrepancy: true,
+}
+
+// Política de reenvio: backoff exponencial a partir de 30s, limitado a 1h, em até 6 tentativas
+const (
+	maxWebhookAttempts  = 6
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = time.Hour
+	webhookDispatchSize = 50
+	// webhookLease reserva uma entrega enquanto ela é enviada, evitando envio duplicado
+	webhookLease = 2 * time.Minute
+)
+
+// webhookHTTPClient só conecta a endereços públicos: a verificação é feita no IP efetivamente
+// discado, o que cobre redirecionamentos e nomes que passam a resolver para a rede interna
+var webhookHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: webhookDialControl}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// lookupWebhookHost resolve o host de uma assinatura; substituível nos testes
+var lookupWebhookHost = net.DefaultResolver.LookupIPAddr
+
+// GetAllWebhookSubscriptions retorna as assinaturas sem expor os segredos
+func (s *Service) GetAllWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.repo.GetAllWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar assinaturas de webhook: %w", err)
+	}
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// GetWebhookSubscription retorna uma assinatura sem o segredo; nil se não existir
+func (s *Service) GetWebhookSubscription(ctx context.Context, id int) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetWebhookSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar assinatura de webhook: %w", err)
+	}
+	if subscription != nil {
+		subscription.Secret = ""
+	}
+	return subscription, nil
+}
+
+// CreateWebhookSubscription valida e cria uma assinatura. Sem segredo informado, um é gerado;
+// o segredo só é devolvido nesta resposta.
+func (s *Service) CreateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	if err := validateWebhookSubscription(ctx, subscription); err != nil {
+		return err
+	}
+	if subscription.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return fmt.Errorf("erro no service ao gerar segredo: %w", err)
+		}
+		subscription.Secret = secret
+	}
+
+	if err := s.repo.InsertWebhookSubscription(ctx, subscription); err != nil {
+		return fmt.Errorf("erro no service ao criar assinatura de webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhookSubscription valida e atualiza uma assinatura; retorna false se não existir
+func (s *Service) UpdateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) (bool, error) {
+	if err := validateWebhookSubscription(ctx, subscription); err != nil {
+		return false, err
+	}
+
+	found, err := s.repo.UpdateWebhookSubscription(ctx, subscription)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao atualizar assinatura de webhook: %w", err)
+	}
+	subscription.Secret = ""
+	return found, nil
+}
+
+// DeleteWebhookSubscription remove uma assinatura; retorna false se não existir
+func (s *Service) DeleteWebhookSubscription(ctx context.Context, id int) (bool, error) {
+	found, err := s.repo.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("erro no service ao remover assinatura de webhook: %w", err)
+	}
+	return found, nil
+}
+
+// GetWebhookDeliveries retorna o log de entregas de uma assinatura
+func (s *Service) GetWebhookDeliveries(ctx context.Context, subscriptionID int, limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	deliveries, err := s.repo.GetWebhookDeliveries(ctx, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro no service ao buscar entregas de webhook: %w", err)
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// PublishEvent registra uma entrega para cada assinatura inscrita no evento e dispara o envio
+// em segundo plano. Falhas são apenas registradas para não interromper o fluxo que gerou o evento.
+func (s *Service) PublishEvent(ctx context.Context, eventType string, data interface{}) {
+	payload, err := json.Marshal(notifier.Event{Type: eventType, OccurredAt: time.Now(), Data: data})
+	if err != nil {
+		fmt.Printf("Erro ao serializar evento %s: %v\n", eventType, err)
+		return
+	}
+
+	count, err := s.repo.EnqueueWebhookDeliveries(ctx, eventType, payload)
+	if err != nil {
+		fmt.Printf("Erro ao enfileirar evento %s: %v\n", eventType, err)
+		return
+	}
+	if count == 0 {
+		return
+	}
+
+	fmt.Printf("Evento %s enfileirado para %d assinaturas\n", eventType, count)
+	go func() {
+		if _, err := s.DispatchWebhooks(context.Background()); err != nil {
+			fmt.Printf("Erro ao despachar webhooks: %v\n", err)
+		}
+	}()
+}
+
+// DispatchWebhooks envia as entregas pendentes já vencidas e agenda as próximas tentativas.
+// Retorna a quantidade de entregas concluídas com sucesso.
+func (s *Service) DispatchWebhooks(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.ClaimDueWebhookDeliveries(ctx, webhookDispatchSize, webhookLease)
+	if err != nil {
+		return 0, fmt.Errorf("erro no service ao buscar entregas pendentes: %w", err)
+	}
+
+	delivered := 0
+	for _, d := range deliveries {
+		sender := &notifier.WebhookNotifier{URL: d.URL, Secret: d.Secret, Client: webhookHTTPClient}
+		header := http.Header{}
+		header.Set("X-Webhook-Delivery", strconv.Itoa(d.ID))
+
+		status, deliveryErr := sender.Send(ctx, d.Event, d.Payload, header)
+
+		var nextAttempt *time.Time
+		if deliveryErr != nil {
+			fmt.Printf("Falha na entrega %d do webhook %d (tentativa %d): %v\n", d.ID, d.SubscriptionID, d.Attempts+1, deliveryErr)
+			if d.Attempts+1 < maxWebhookAttempts {
+				next := time.Now().Add(webhookBackoff(d.Attempts + 1))
+				nextAttempt = &next
+			}
+		} else {
+			delivered++
+		}
+
+		if err := s.repo.RecordWebhookAttempt(ctx, d.ID, status, deliveryErr, nextAttempt); err != nil {
+			return delivered, fmt.Errorf("erro no service ao registrar entrega: %w", err)
+		}
+	}
+
+	return delivered, nil
+}
+
+// RunWebhookDispatcher reprocessa periodicamente as entregas pendentes até o contexto ser cancelado
+func (s *Service) RunWebhookDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DispatchWebhooks(ctx); err != nil {
+				fmt.Printf("Erro ao despachar webhooks: %v\n", err)
+			}
+		}
+	}
+}
+
+// webhookBackoff retorna o intervalo até a próxima tentativa após a tentativa de número attempt
+func webhookBackoff(attempt int) time.Duration {
+	delay := webhookBaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
+
+// validateWebhookSubscription normaliza e valida URL e eventos de uma assinatura
+func validateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	subscription.URL = strings.TrimSpace(subscription.URL)
+	parsed, err := url.Parse(subscription.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: url deve ser um endereço http ou https", ErrInvalidParameter)
+	}
+	if err := validateWebhookHost(ctx, parsed.Hostname()); err != nil {
+		return err
+	}
+
+	events := uniqueStrings(subscription.Events)
+	if len(events) == 0 {
+		return fmt.Errorf("%w: events deve ter ao menos um evento", ErrInvalidParameter)
+	}
+	for _, event := range events {
+		if !validWebhookEvents[event] {
+			return fmt.Errorf("%w: evento desconhecido: %s", ErrInvalidParameter, event)
+		}
+	}
+	subscription.Events = events
+
+	return nil
+}
+
+// validateWebhookHost resolve o host e rejeita endereços internos (loopback, link-local, redes
+// privadas), para que uma assinatura não faça a API enviar requisições à própria infraestrutura
+func validateWebhookHost(ctx context.Context, host string) error {
+	addrs, err := lookupWebhookHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: não foi possível resolver o host %s", ErrInvalidParameter, host)
+	}
+	for _, addr := range addrs {
+		if blockedWebhookIP(addr.IP) {
+			return fmt.Errorf("%w: url aponta para um endereço interno (%s)", ErrInvalidParameter, addr.IP)
+		}
+	}
+	return nil
+}
+
+// webhookDialControl recusa a conexão quando o IP resolvido no envio é interno
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || blockedWebhookIP(ip) {
+		return fmt.Errorf("conexão a endereço interno bloqueada: %s", host)
+	}
+	return nil
+}
+
+// blockedWebhookIP indica se o IP pertence a uma faixa que não pode receber webhooks
+func blockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
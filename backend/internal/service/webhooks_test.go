@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"data-importer-api-go/internal/models"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1: 30 * time.Second,
+		2: time.Minute,
+		3: 2 * time.Minute,
+		5: 8 * time.Minute,
+		9: time.Hour,
+	}
+
+	for attempt, want := range tests {
+		if got := webhookBackoff(attempt); got != want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+// stubWebhookLookup resolve os hosts dos testes sem depender de DNS
+func stubWebhookLookup(t *testing.T, hosts map[string]string) {
+	original := lookupWebhookHost
+	lookupWebhookHost = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		ip, ok := hosts[host]
+		if !ok {
+			return nil, errors.New("host desconhecido")
+		}
+		return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+	}
+	t.Cleanup(func() { lookupWebhookHost = original })
+}
+
+func TestValidateWebhookSubscription(t *testing.T) {
+	stubWebhookLookup(t, map[string]string{"bi.example.com": "203.0.113.10", "example.com": "203.0.113.20"})
+
+	subscription := models.WebhookSubscription{
+		URL:    " https://bi.example.com/hooks ",
+		Events: []string{"import.completed", "import.failed", "import.completed"},
+	}
+	if err := validateWebhookSubscription(context.Background(), &subscription); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if subscription.URL != "https://bi.example.com/hooks" || !reflect.DeepEqual(subscription.Events, []string{"import.completed", "import.failed"}) {
+		t.Errorf("unexpected normalized subscription: %+v", subscription)
+	}
+
+	invalid := []models.WebhookSubscription{
+		{URL: "ftp://example.com", Events: []string{"import.completed"}},
+		{URL: "https://", Events: []string{"import.completed"}},
+		{URL: "https://example.com", Events: nil},
+		{URL: "https://example.com", Events: []string{"usage.created"}},
+	}
+	for _, s := range invalid {
+		if err := validateWebhookSubscription(context.Background(), &s); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("expected ErrInvalidParameter for %+v, got %v", s, err)
+		}
+	}
+}
+
+func TestValidateWebhookSubscriptionRejectsInternalHosts(t *testing.T) {
+	stubWebhookLookup(t, map[string]string{
+		"localhost":            "127.0.0.1",
+		"metadata.internal":    "169.254.169.254",
+		"intranet.example.com": "10.0.0.5",
+		"v6.example.com":       "::1",
+	})
+
+	urls := []string{
+		"http://localhost:8080/hooks",
+		"http://metadata.internal/latest",
+		"https://intranet.example.com/hooks",
+		"https://v6.example.com/hooks",
+		"http://192.168.1.1/hooks",
+		"http://unresolvable.example.com/hooks",
+	}
+	for _, u := range urls {
+		subscription := models.WebhookSubscription{URL: u, Events: []string{"import.completed"}}
+		if err := validateWebhookSubscription(context.Background(), &subscription); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("expected ErrInvalidParameter for %s, got %v", u, err)
+		}
+	}
+}
+
+func TestWebhookDialControlBlocksInternalAddresses(t *testing.T) {
+	blocked := []string{"127.0.0.1:80", "10.1.2.3:443", "172.16.0.1:443", "169.254.169.254:80", "[::1]:80", "[fe80::1]:443", "0.0.0.0:80"}
+	for _, address := range blocked {
+		if err := webhookDialControl("tcp", address, nil); err == nil {
+			t.Errorf("expected %s to be blocked", address)
+		}
+	}
+	if err := webhookDialControl("tcp", "203.0.113.10:443", nil); err != nil {
+		t.Errorf("expected public address to be allowed, got %v", err)
+	}
+}
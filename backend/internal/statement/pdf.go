@@ -0,0 +1,187 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Página A4 em pontos e margens usadas pelo demonstrativo
+const (
+	pageWidth   = 595.28
+	pageHeight  = 841.89
+	pageMargin  = 40.0
+	bottomLimit = pageMargin + 20
+)
+
+// pdfDocument gera um PDF simples de texto com as fontes padrão Helvetica e Helvetica-Bold,
+// que todo leitor de PDF possui, dispensando a incorporação de fontes
+type pdfDocument struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	y       float64
+	encoder *encoding.Encoder
+	// header é repetido no topo de cada nova página aberta por quebra automática
+	header func()
+}
+
+func newPDFDocument() *pdfDocument {
+	d := &pdfDocument{encoder: encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder())}
+	d.addPage()
+	return d
+}
+
+func (d *pdfDocument) addPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+	d.y = pageHeight - pageMargin
+	if d.header != nil {
+		d.header()
+	}
+}
+
+// ensure abre uma nova página se não couber uma linha de altura height
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height < bottomLimit {
+		d.addPage()
+	}
+}
+
+// text escreve um texto na posição (x, y); align "right" alinha a borda direita em x
+func (d *pdfDocument) text(x, y, size float64, bold bool, align, value string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	if align == "right" {
+		x -= textWidth(value, size)
+	}
+	encoded, err := d.encoder.String(value)
+	if err != nil {
+		encoded = value
+	}
+	fmt.Fprintf(d.current, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapePDFText(encoded))
+}
+
+// line escreve um texto na posição vertical corrente e avança para a próxima linha
+func (d *pdfDocument) line(size float64, bold bool, value string) {
+	d.ensure(size + 4)
+	d.y -= size + 4
+	d.text(pageMargin, d.y, size, bold, "", value)
+}
+
+// space avança a posição vertical
+func (d *pdfDocument) space(height float64) {
+	d.y -= height
+}
+
+// pdfColumn é uma coluna de tabela: largura em pontos e alinhamento
+type pdfColumn struct {
+	width float64
+	right bool
+}
+
+// row escreve uma linha de tabela, truncando os textos que não cabem na coluna
+func (d *pdfDocument) row(columns []pdfColumn, size float64, bold bool, values []string) {
+	d.ensure(size + 4)
+	d.y -= size + 4
+	x := pageMargin
+	for i, col := range columns {
+		if i >= len(values) {
+			break
+		}
+		value := fitText(values[i], col.width-4, size)
+		if col.right {
+			d.text(x+col.width-4, d.y, size, bold, "right", value)
+		} else {
+			d.text(x, d.y, size, bold, "", value)
+		}
+		x += col.width
+	}
+}
+
+// rule desenha uma linha horizontal na posição corrente
+func (d *pdfDocument) rule() {
+	d.y -= 3
+	fmt.Fprintf(d.current, "0.5 w %.2f %.2f m %.2f %.2f l S\n", pageMargin, d.y, pageWidth-pageMargin, d.y)
+}
+
+// writeTo grava o documento: catálogo, árvore de páginas, fontes e, para cada página, o
+// objeto da página e seu conteúdo, seguidos da tabela xref
+func (d *pdfDocument) writeTo(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// escapePDFText escapa os caracteres especiais de strings literais do PDF
+func escapePDFText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", " ", "\n", " ")
+	return replacer.Replace(value)
+}
+
+// textWidth estima a largura do texto em Helvetica. Algarismos e pontuação numérica usam as
+// larguras exatas da fonte, para alinhar valores à direita; os demais caracteres, uma média.
+func textWidth(value string, size float64) float64 {
+	var units float64
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			units += 556
+		case r == '.' || r == ',' || r == ' ':
+			units += 278
+		case r == '-':
+			units += 333
+		case r == '%':
+			units += 889
+		default:
+			units += 556
+		}
+	}
+	return units * size / 1000
+}
+
+// fitText trunca o texto com reticências para caber na largura
+func fitText(value string, width, size float64) string {
+	if textWidth(value, size) <= width {
+		return value
+	}
+	runes := []rune(value)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
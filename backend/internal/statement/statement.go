@@ -0,0 +1,128 @@
+// Package statement gera os documentos dos demonstrativos de custos (chargeback/showback)
+// em XLSX e PDF a partir de uma versão gravada.
+package statement
+
+import (
+	"data-importer-api-go/internal/models"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrNoData indica um demonstrativo sem conteúdo (ex.: carregado apenas com o resumo)
+var ErrNoData = errors.New("demonstrativo sem conteúdo")
+
+// subjectLabel descreve o sujeito do demonstrativo
+func subjectLabel(subject models.StatementSubject) string {
+	if subject.Type == models.StatementCostCenter {
+		return fmt.Sprintf("Centro de custo: %s (%s)", subject.TagValue, subject.TagKey)
+	}
+	return "Cliente: " + subject.Name
+}
+
+// formatMoney formata um valor com separador de milhar e duas casas decimais no padrão
+// brasileiro (1.234,56)
+func formatMoney(value float64) string {
+	negative := value < 0
+	cents := int64(math.Round(math.Abs(value) * 100))
+	integer := fmt.Sprintf("%d", cents/100)
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	result := fmt.Sprintf("%s,%02d", grouped.String(), cents%100)
+	if negative && cents > 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+// formatDecimal formata um valor com a quantidade de casas informada e vírgula decimal
+func formatDecimal(value float64, places int) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', places, 64), ".", ",", 1)
+}
+
+// formatDeltaPct formata a variação percentual; vazio quando não há base de comparação
+func formatDeltaPct(pct *float64) string {
+	if pct == nil {
+		return "-"
+	}
+	return formatDecimal(*pct, 2) + "%"
+}
+
+// WritePDF grava o demonstrativo em PDF
+func WritePDF(w io.Writer, st *models.Statement) error {
+	if st == nil || st.Data == nil {
+		return ErrNoData
+	}
+	data := st.Data
+
+	doc := newPDFDocument()
+	doc.line(16, true, "Demonstrativo de custos")
+	doc.space(4)
+	doc.line(10, false, subjectLabel(data.Subject))
+	doc.line(10, false, fmt.Sprintf("Período: %s (%s a %s)", data.Period,
+		data.PeriodStart.Format("02/01/2006"), data.PeriodEnd.Format("02/01/2006")))
+	doc.line(10, false, fmt.Sprintf("Versão %d, gerada em %s", st.Version, st.CreatedAt.Format("02/01/2006 15:04")))
+	doc.space(8)
+
+	summary := []pdfColumn{{width: 200}, {width: 120, right: true}}
+	doc.row(summary, 10, false, []string{"Custo direto", formatMoney(data.Direct)})
+	if data.Subject.Type == models.StatementCostCenter {
+		doc.row(summary, 10, false, []string{"Recebido por rateio", formatMoney(data.Allocated)})
+	}
+	doc.row(summary, 10, true, []string{"Total", formatMoney(data.Total)})
+	doc.row(summary, 10, false, []string{"Período anterior", formatMoney(data.PreviousTotal)})
+	doc.row(summary, 10, false, []string{"Variação", formatMoney(data.Delta) + " (" + formatDeltaPct(data.DeltaPct) + ")"})
+
+	groupColumns := []pdfColumn{{width: 255}, {width: 85, right: true}, {width: 85, right: true}, {width: 90, right: true}}
+	for _, section := range []struct {
+		title  string
+		label  string
+		groups []models.StatementGroup
+	}{
+		{"Por produto", "Produto", data.Products},
+		{"Por categoria", "Categoria", data.Categories},
+	} {
+		doc.space(10)
+		doc.line(12, true, section.title)
+		doc.row(groupColumns, 9, true, []string{section.label, "Total", "Anterior", "Variação"})
+		doc.rule()
+		for _, g := range section.groups {
+			doc.row(groupColumns, 9, false, []string{g.Name, formatMoney(g.Total), formatMoney(g.PreviousTotal), formatMoney(g.Delta)})
+		}
+	}
+
+	lineColumns := []pdfColumn{{width: 55}, {width: 75}, {width: 150}, {width: 70}, {width: 50, right: true},
+		{width: 55, right: true}, {width: 60, right: true}}
+	lineHeader := []string{"Data", "Fatura", "Produto", "Local", "Qtd.", "Preço", "Total"}
+	doc.space(10)
+	doc.line(12, true, "Linhas")
+	doc.row(lineColumns, 8, true, lineHeader)
+	doc.rule()
+	doc.header = func() {
+		doc.row(lineColumns, 8, true, lineHeader)
+		doc.rule()
+	}
+	for _, l := range data.Lines {
+		doc.row(lineColumns, 8, false, []string{
+			l.UsageDate.Format("02/01/2006"),
+			l.InvoiceNumber,
+			l.Product,
+			l.ResourceLocation,
+			formatDecimal(l.Quantity, 2),
+			formatDecimal(l.UnitPrice, 4),
+			formatMoney(l.Total),
+		})
+	}
+
+	return doc.writeTo(w)
+}
@@ -0,0 +1,107 @@
+package statement
+
+import (
+	"bytes"
+	"data-importer-api-go/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func testStatement(lines int) *models.Statement {
+	pct := 25.0
+	data := &models.StatementData{
+		Subject:       models.StatementSubject{Type: models.StatementCostCenter, TagKey: "costcenter", TagValue: "CC1", Name: "CC1"},
+		Period:        "2024-01",
+		PeriodStart:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:     time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		Direct:        100,
+		Allocated:     25,
+		Total:         125,
+		PreviousTotal: 100,
+		Delta:         25,
+		DeltaPct:      &pct,
+		Products:      []models.StatementGroup{{Name: "Máquinas Virtuais (Linux)", Total: 100, PreviousTotal: 100}},
+		Categories:    []models.StatementGroup{{Name: "Compute", Total: 100, PreviousTotal: 100}},
+	}
+	for i := 0; i < lines; i++ {
+		data.Lines = append(data.Lines, models.StatementLine{
+			UsageDate: data.PeriodStart, InvoiceNumber: "INV-1", Product: "Máquinas Virtuais (Linux)",
+			Category: "Compute", Quantity: 1, UnitPrice: 0.0125, Total: 100 / float64(lines),
+		})
+	}
+	return &models.Statement{ID: 1, Version: 2, Period: "2024-01", Data: data, CreatedAt: data.PeriodEnd}
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, testStatement(200)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("expected a PDF document, got prefix %q", out[:20])
+	}
+	if !strings.Contains(out, `(Demonstrativo de custos)`) {
+		t.Error("expected title in PDF content")
+	}
+	// parênteses escapados e acentos em Windows-1252
+	if !strings.Contains(out, "(M\xe1quinas Virtuais \\(Linux\\))") {
+		t.Error("expected escaped, WinAnsi-encoded product name")
+	}
+	if !strings.Contains(out, "/Count 3") && !strings.Contains(out, "/Count 4") {
+		t.Error("expected 200 lines to span several pages")
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, testStatement(3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("could not read generated XLSX: %v", err)
+	}
+	defer f.Close()
+
+	if got := f.GetSheetList(); strings.Join(got, ",") != "Resumo,Produtos,Categorias,Linhas" {
+		t.Errorf("unexpected sheets: %v", got)
+	}
+	if v, _ := f.GetCellValue("Resumo", "B9", excelize.Options{RawCellValue: true}); v != "125" {
+		t.Errorf("expected total 125 in Resumo!B9, got %q", v)
+	}
+	rows, _ := f.GetRows("Linhas")
+	if len(rows) != 4 {
+		t.Errorf("expected header and 3 lines, got %d rows", len(rows))
+	}
+}
+
+func TestWriteWithoutData(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, &models.Statement{}); err != ErrNoData {
+		t.Errorf("expected ErrNoData, got %v", err)
+	}
+	if err := WriteXLSX(&buf, nil); err != ErrNoData {
+		t.Errorf("expected ErrNoData, got %v", err)
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	cases := map[float64]string{
+		0:            "0,00",
+		1234.5:       "1.234,50",
+		-1234567.891: "-1.234.567,89",
+		999.999:      "1.000,00",
+		-0.001:       "0,00",
+	}
+	for value, want := range cases {
+		if got := formatMoney(value); got != want {
+			t.Errorf("formatMoney(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
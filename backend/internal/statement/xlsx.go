@@ -0,0 +1,140 @@
+package statement
+
+import (
+	"data-importer-api-go/internal/models"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Planilhas do demonstrativo em XLSX
+const (
+	sheetSummary    = "Resumo"
+	sheetProducts   = "Produtos"
+	sheetCategories = "Categorias"
+	sheetLines      = "Linhas"
+)
+
+// WriteXLSX grava o demonstrativo em XLSX: resumo, totais por produto e por categoria e as
+// linhas detalhadas, com os valores como números
+func WriteXLSX(w io.Writer, st *models.Statement) error {
+	if st == nil || st.Data == nil {
+		return ErrNoData
+	}
+	data := st.Data
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", sheetSummary); err != nil {
+		return fmt.Errorf("erro ao criar planilha de resumo: %w", err)
+	}
+	for _, name := range []string{sheetProducts, sheetCategories, sheetLines} {
+		if _, err := f.NewSheet(name); err != nil {
+			return fmt.Errorf("erro ao criar planilha %s: %w", name, err)
+		}
+	}
+
+	moneyFormat := "#,##0.00"
+	money, err := f.NewStyle(&excelize.Style{CustomNumFmt: &moneyFormat})
+	if err != nil {
+		return fmt.Errorf("erro ao criar estilo monetário: %w", err)
+	}
+	dateFormat := "dd/mm/yyyy"
+	date, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return fmt.Errorf("erro ao criar estilo de data: %w", err)
+	}
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("erro ao criar estilo de cabeçalho: %w", err)
+	}
+
+	summary := [][]interface{}{
+		{"Demonstrativo de custos"},
+		{subjectLabel(data.Subject)},
+		{"Período", data.Period},
+		{"Versão", st.Version},
+		{"Gerado em", st.CreatedAt.Format("02/01/2006 15:04")},
+		{},
+		{"Custo direto", data.Direct},
+		{"Recebido por rateio", data.Allocated},
+		{"Total", data.Total},
+		{"Período anterior", data.PreviousTotal},
+		{"Variação", data.Delta},
+	}
+	if data.DeltaPct != nil {
+		summary = append(summary, []interface{}{"Variação (%)", *data.DeltaPct})
+	}
+	if err := setRows(f, sheetSummary, summary); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetSummary, "A1", "A1", bold)
+	f.SetCellStyle(sheetSummary, "B7", "B11", money)
+	f.SetColWidth(sheetSummary, "A", "A", 24)
+	f.SetColWidth(sheetSummary, "B", "B", 16)
+
+	for _, section := range []struct {
+		sheet  string
+		label  string
+		groups []models.StatementGroup
+	}{
+		{sheetProducts, "Produto", data.Products},
+		{sheetCategories, "Categoria", data.Categories},
+	} {
+		rows := [][]interface{}{{section.label, "Total", "Período anterior", "Variação"}}
+		for _, g := range section.groups {
+			rows = append(rows, []interface{}{g.Name, g.Total, g.PreviousTotal, g.Delta})
+		}
+		if err := setRows(f, section.sheet, rows); err != nil {
+			return err
+		}
+		f.SetCellStyle(section.sheet, "A1", "D1", bold)
+		if len(rows) > 1 {
+			f.SetCellStyle(section.sheet, "B2", fmt.Sprintf("D%d", len(rows)), money)
+		}
+		f.SetColWidth(section.sheet, "A", "A", 40)
+		f.SetColWidth(section.sheet, "B", "D", 16)
+	}
+
+	rows := [][]interface{}{{"Data", "Fatura", "Produto", "Categoria", "Local", "Quantidade", "Preço unitário", "Total"}}
+	for _, l := range data.Lines {
+		rows = append(rows, []interface{}{l.UsageDate, l.InvoiceNumber, l.Product, l.Category, l.ResourceLocation,
+			l.Quantity, l.UnitPrice, l.Total})
+	}
+	if err := setRows(f, sheetLines, rows); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetLines, "A1", "H1", bold)
+	if len(rows) > 1 {
+		f.SetCellStyle(sheetLines, "A2", fmt.Sprintf("A%d", len(rows)), date)
+		f.SetCellStyle(sheetLines, "H2", fmt.Sprintf("H%d", len(rows)), money)
+	}
+	f.SetColWidth(sheetLines, "A", "B", 14)
+	f.SetColWidth(sheetLines, "C", "C", 40)
+	f.SetColWidth(sheetLines, "D", "H", 16)
+
+	f.SetActiveSheet(0)
+	if _, err := f.WriteTo(w); err != nil {
+		return fmt.Errorf("erro ao gravar XLSX: %w", err)
+	}
+	return nil
+}
+
+// setRows grava as linhas a partir da célula A1 da planilha
+func setRows(f *excelize.File, sheet string, rows [][]interface{}) error {
+	for i, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return fmt.Errorf("erro ao gravar linha %d da planilha %s: %w", i+1, sheet, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Package tags interpreta a coluna de tags das exportações (tags do Azure) em pares
+// chave/valor normalizados, usados para filtrar, agrupar e ratear custos.
+package tags
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// NormalizeKey normaliza a chave de uma tag. As chaves de tag do Azure não diferenciam
+// maiúsculas, então CostCenter e costcenter são a mesma tag.
+func NormalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// Parse interpreta o texto de tags e retorna os pares com chaves normalizadas. Aceita um
+// objeto JSON ({"CostCenter": "CC1"}), o mesmo objeto sem chaves ("CostCenter": "CC1", ...)
+// como nas exportações CSV do Azure, e pares chave=valor separados por ; ou vírgula. Retorna
+// nil quando nenhum par é reconhecido.
+func Parse(raw string) map[string]string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(raw, "{") {
+		return fromJSON(raw)
+	}
+	if strings.HasPrefix(raw, `"`) {
+		if result := fromJSON("{" + raw + "}"); result != nil {
+			return result
+		}
+	}
+	return fromPairs(raw)
+}
+
+func fromJSON(raw string) map[string]string {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &object); err != nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(object))
+	for key, value := range object {
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			// números, booleanos e estruturas ficam com a representação JSON
+			text = string(value)
+		}
+		add(result, key, text)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func fromPairs(raw string) map[string]string {
+	separator := ","
+	if strings.Contains(raw, ";") {
+		separator = ";"
+	}
+
+	result := make(map[string]string)
+	for _, pair := range strings.Split(raw, separator) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			key, value, ok = strings.Cut(pair, ":")
+		}
+		if !ok {
+			continue
+		}
+		add(result, strings.Trim(strings.TrimSpace(key), `"`), strings.Trim(strings.TrimSpace(value), `"`))
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func add(result map[string]string, key, value string) {
+	key = NormalizeKey(key)
+	if key == "" {
+		return
+	}
+	result[key] = strings.TrimSpace(value)
+}
@@ -0,0 +1,31 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want map[string]string
+	}{
+		{"empty", "  ", nil},
+		{"json object", `{"CostCenter": "CC1", "Project": " Apollo "}`, map[string]string{"costcenter": "CC1", "project": "Apollo"}},
+		{"json non-string values", `{"budget": 100, "shared": true}`, map[string]string{"budget": "100", "shared": "true"}},
+		{"azure csv without braces", `"CostCenter": "CC1","env": "prod"`, map[string]string{"costcenter": "CC1", "env": "prod"}},
+		{"key=value with semicolons", "CostCenter=CC1; project=a,b", map[string]string{"costcenter": "CC1", "project": "a,b"}},
+		{"key:value with commas", "costcenter:CC2, env:dev", map[string]string{"costcenter": "CC2", "env": "dev"}},
+		{"later duplicate wins", "env=dev;ENV=prod", map[string]string{"env": "prod"}},
+		{"invalid json", `{"costcenter": `, nil},
+		{"opaque text", "sem tags", nil},
+		{"empty key ignored", "=x;a=1", map[string]string{"a": "1"}},
+	}
+
+	for _, tt := range tests {
+		if got := Parse(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Parse(%q) = %v, want %v", tt.name, tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package uploads
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"data-importer-api-go/internal/models"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound indica um ID de upload inexistente ou já removido
+	ErrNotFound = errors.New("upload não encontrado")
+	// ErrOffsetMismatch indica que o cliente enviou um chunk fora da posição esperada
+	ErrOffsetMismatch = errors.New("offset não confere com o recebido até agora")
+	// ErrChecksumMismatch indica um chunk ou arquivo corrompido na transmissão
+	ErrChecksumMismatch = errors.New("checksum não confere")
+	// ErrTooLarge indica um chunk que ultrapassa o tamanho declarado do arquivo
+	ErrTooLarge = errors.New("chunk ultrapassa o tamanho declarado")
+	// ErrNotWritable indica um upload que já foi concluído e não aceita mais chunks
+	ErrNotWritable = errors.New("upload não aceita mais chunks")
+)
+
+// Estados de um upload
+const (
+	StateUploading = "uploading"
+	StateImporting = "importing"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+var idPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Chunk registra um trecho recebido e o hash usado para conferi-lo na montagem
+type Chunk struct {
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Upload é o estado persistido de um upload retomável
+type Upload struct {
+	ID        string                `json:"id"`
+	FileName  string                `json:"file_name"`
+	Size      int64                 `json:"size"`
+	Offset    int64                 `json:"offset"`
+	Checksum  string                `json:"checksum,omitempty"`
+	State     string                `json:"state"`
+	Error     string                `json:"error,omitempty"`
+	Summary   *models.ImportSummary `json:"summary,omitempty"`
+	Chunks    []Chunk               `json:"-"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+// record é o formato gravado em disco; inclui os chunks, omitidos nas respostas da API
+type record struct {
+	Upload
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Store guarda os uploads em disco: um diretório por ID com os chunks e um meta.json
+type Store struct {
+	dir   string
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+}
+
+// uploadLock conta quem usa ou aguarda o lock, para removê-lo do mapa quando ficar livre
+type uploadLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewStore cria o diretório base se necessário. Uploads que estavam em importação quando o
+// processo anterior parou não têm mais quem os conclua e são marcados como falhos.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de uploads: %w", err)
+	}
+
+	s := &Store{dir: dir, locks: make(map[string]*uploadLock)}
+	if err := s.failInterrupted(func(*Upload) bool { return true }, "importação interrompida pelo reinício do servidor"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// lock serializa as operações sobre um mesmo upload
+func (s *Store) lock(id string) func() {
+	s.mu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &uploadLock{}
+		s.locks[id] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		s.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.locks, id)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Store) uploadDir(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+// Create inicia um upload; checksum é o SHA-256 (hex) opcional do arquivo completo
+func (s *Store) Create(fileName string, size int64, checksum string) (*Upload, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("erro ao gerar ID do upload: %w", err)
+	}
+
+	now := time.Now()
+	upload := &Upload{
+		ID:        hex.EncodeToString(buf),
+		FileName:  filepath.Base(fileName),
+		Size:      size,
+		Checksum:  strings.ToLower(checksum),
+		State:     StateUploading,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := os.MkdirAll(s.uploadDir(upload.ID), 0o750); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do upload: %w", err)
+	}
+	if err := s.save(upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get retorna o estado atual do upload
+func (s *Store) Get(id string) (*Upload, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	unlock := s.lock(id)
+	defer unlock()
+	return s.load(id)
+}
+
+// WriteChunk grava o trecho que começa em offset. O chunk só é aceito se o offset for o
+// esperado, se não ultrapassar o tamanho declarado e, quando informado, se o SHA-256 conferir.
+func (s *Store) WriteChunk(id string, offset int64, r io.Reader, checksum string) (*Upload, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	unlock := s.lock(id)
+	defer unlock()
+
+	upload, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.State != StateUploading {
+		return upload, ErrNotWritable
+	}
+	if offset != upload.Offset {
+		return upload, ErrOffsetMismatch
+	}
+
+	chunkPath := filepath.Join(s.uploadDir(id), fmt.Sprintf("%020d.chunk", offset))
+	tmp, err := os.CreateTemp(s.uploadDir(id), "chunk-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar chunk: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Lê no máximo um byte além do restante para detectar chunks grandes demais
+	hash := sha256.New()
+	remaining := upload.Size - upload.Offset
+	n, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, remaining+1))
+	closeErr := tmp.Close()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao receber chunk: %w", err)
+	}
+	if closeErr != nil {
+		return nil, fmt.Errorf("erro ao gravar chunk: %w", closeErr)
+	}
+	if n > remaining {
+		return upload, ErrTooLarge
+	}
+	if n == 0 {
+		return upload, nil
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != "" && !strings.EqualFold(checksum, sum) {
+		return upload, ErrChecksumMismatch
+	}
+
+	if err := os.Rename(tmp.Name(), chunkPath); err != nil {
+		return nil, fmt.Errorf("erro ao gravar chunk: %w", err)
+	}
+
+	upload.Chunks = append(upload.Chunks, Chunk{Offset: offset, Size: n, SHA256: sum})
+	upload.Offset += n
+	upload.UpdatedAt = time.Now()
+	if err := s.save(upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Assemble concatena os chunks de um upload completo no arquivo final, conferindo cada
+// chunk e o checksum do arquivo, e marca o upload como em importação.
+// Retorna o caminho do arquivo montado.
+func (s *Store) Assemble(id string) (string, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	upload, err := s.load(id)
+	if err != nil {
+		return "", err
+	}
+	if upload.State != StateUploading || upload.Offset != upload.Size {
+		return "", ErrNotWritable
+	}
+
+	assembled := filepath.Join(s.uploadDir(id), "file")
+	out, err := os.Create(assembled)
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar arquivo final: %w", err)
+	}
+
+	fileHash := sha256.New()
+	for _, chunk := range upload.Chunks {
+		if err := appendChunk(out, fileHash, filepath.Join(s.uploadDir(id), fmt.Sprintf("%020d.chunk", chunk.Offset)), chunk); err != nil {
+			out.Close()
+			return "", s.failAssembly(upload, assembled, err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("erro ao gravar arquivo final: %w", err)
+	}
+
+	if upload.Checksum != "" && hex.EncodeToString(fileHash.Sum(nil)) != upload.Checksum {
+		return "", s.failAssembly(upload, assembled, fmt.Errorf("%w: arquivo completo", ErrChecksumMismatch))
+	}
+
+	// Com o arquivo montado os chunks não são mais necessários
+	for _, chunk := range upload.Chunks {
+		os.Remove(filepath.Join(s.uploadDir(id), fmt.Sprintf("%020d.chunk", chunk.Offset)))
+	}
+
+	upload.State = StateImporting
+	upload.UpdatedAt = time.Now()
+	if err := s.save(upload); err != nil {
+		return "", err
+	}
+	return assembled, nil
+}
+
+// failAssembly marca o upload como falho; um arquivo que não confere não pode ser retomado
+func (s *Store) failAssembly(upload *Upload, assembled string, cause error) error {
+	os.Remove(assembled)
+	upload.State = StateFailed
+	upload.Error = cause.Error()
+	upload.UpdatedAt = time.Now()
+	if err := s.save(upload); err != nil {
+		return err
+	}
+	return cause
+}
+
+func appendChunk(out io.Writer, fileHash io.Writer, path string, chunk Chunk) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir chunk %d: %w", chunk.Offset, err)
+	}
+	defer in.Close()
+
+	chunkHash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, fileHash, chunkHash), in); err != nil {
+		return fmt.Errorf("erro ao montar chunk %d: %w", chunk.Offset, err)
+	}
+	if hex.EncodeToString(chunkHash.Sum(nil)) != chunk.SHA256 {
+		return fmt.Errorf("%w: chunk %d corrompido em disco", ErrChecksumMismatch, chunk.Offset)
+	}
+	return nil
+}
+
+// Finish registra o resultado da importação e remove o arquivo montado
+func (s *Store) Finish(id string, summary models.ImportSummary, importErr error) error {
+	unlock := s.lock(id)
+	defer unlock()
+
+	upload, err := s.load(id)
+	if err != nil {
+		return err
+	}
+
+	upload.Summary = &summary
+	upload.State = StateCompleted
+	if importErr != nil {
+		upload.State = StateFailed
+		upload.Error = importErr.Error()
+	}
+	upload.UpdatedAt = time.Now()
+	os.Remove(filepath.Join(s.uploadDir(id), "file"))
+	return s.save(upload)
+}
+
+// Delete cancela um upload e remove seus arquivos
+func (s *Store) Delete(id string) error {
+	if !idPattern.MatchString(id) {
+		return ErrNotFound
+	}
+	unlock := s.lock(id)
+	defer unlock()
+
+	if _, err := s.load(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.uploadDir(id))
+}
+
+// Cleanup remove uploads sem atividade há mais de maxAge e retorna quantos foram removidos.
+// Uma importação sem conclusão há mais de maxAge é marcada como falha e removida na próxima
+// limpeza após o mesmo prazo, para que o cliente ainda possa consultar o resultado.
+func (s *Store) Cleanup(maxAge time.Duration) (int, error) {
+	stale := func(upload *Upload) bool { return time.Since(upload.UpdatedAt) >= maxAge }
+	if err := s.failInterrupted(stale, fmt.Sprintf("importação não concluída em %s", maxAge)); err != nil {
+		return 0, err
+	}
+
+	ids, err := s.list()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, id := range ids {
+		upload, err := s.Get(id)
+		if err != nil || upload.State == StateImporting || !stale(upload) {
+			continue
+		}
+		if err := s.Delete(upload.ID); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// failInterrupted marca como falhos os uploads em importação selecionados por match
+func (s *Store) failInterrupted(match func(*Upload) bool, reason string) error {
+	ids, err := s.list()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := s.failIfImporting(id, match, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) failIfImporting(id string, match func(*Upload) bool, reason string) error {
+	unlock := s.lock(id)
+	defer unlock()
+
+	upload, err := s.load(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if upload.State != StateImporting || !match(upload) {
+		return nil
+	}
+
+	upload.State = StateFailed
+	upload.Error = reason
+	upload.UpdatedAt = time.Now()
+	os.Remove(filepath.Join(s.uploadDir(id), "file"))
+	return s.save(upload)
+}
+
+// list retorna os IDs dos uploads gravados no diretório base
+func (s *Store) list() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar uploads: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() && idPattern.MatchString(entry.Name()) {
+			ids = append(ids, entry.Name())
+		}
+	}
+	return ids, nil
+}
+
+func (s *Store) load(id string) (*Upload, error) {
+	data, err := os.ReadFile(filepath.Join(s.uploadDir(id), "meta.json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("erro ao ler upload: %w", err)
+	}
+
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar upload: %w", err)
+	}
+	upload := rec.Upload
+	upload.Chunks = rec.Chunks
+	return &upload, nil
+}
+
+// save grava os metadados de forma atômica (arquivo temporário + rename)
+func (s *Store) save(upload *Upload) error {
+	data, err := json.Marshal(record{Upload: *upload, Chunks: upload.Chunks})
+	if err != nil {
+		return fmt.Errorf("erro ao serializar upload: %w", err)
+	}
+
+	metaPath := filepath.Join(s.uploadDir(upload.ID), "meta.json")
+	if err := os.WriteFile(metaPath+".tmp", data, 0o640); err != nil {
+		return fmt.Errorf("erro ao gravar upload: %w", err)
+	}
+	if err := os.Rename(metaPath+".tmp", metaPath); err != nil {
+		return fmt.Errorf("erro ao gravar upload: %w", err)
+	}
+	return nil
+}
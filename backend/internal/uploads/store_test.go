@@ -0,0 +1,198 @@
+package uploads
+
+import (
+	"crypto/sha256"
+	"data-importer-api-go/internal/models"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestResumableUpload(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "partner_id,customer_id\n1,2\n3,4\n"
+	upload, err := store.Create("../../janeiro.csv", int64(len(content)), sha(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.FileName != "janeiro.csv" {
+		t.Errorf("file name should be reduced to its base, got %q", upload.FileName)
+	}
+
+	first, rest := content[:10], content[10:]
+	if _, err := store.WriteChunk(upload.ID, 0, strings.NewReader(first), sha(first)); err != nil {
+		t.Fatalf("unexpected error on first chunk: %v", err)
+	}
+
+	// Uma conexão caída faz o cliente reenviar do início; o servidor aponta o offset correto
+	current, err := store.WriteChunk(upload.ID, 0, strings.NewReader(first), "")
+	if !errors.Is(err, ErrOffsetMismatch) || current.Offset != 10 {
+		t.Fatalf("expected offset mismatch at 10, got %v (offset %d)", err, current.Offset)
+	}
+
+	// Retomada a partir de um novo Store, como após reiniciar o servidor
+	store, _ = NewStore(store.dir)
+	current, err = store.Get(upload.ID)
+	if err != nil || current.Offset != 10 {
+		t.Fatalf("expected to resume at offset 10, got %v (offset %d)", err, current.Offset)
+	}
+
+	if _, err := store.WriteChunk(upload.ID, 10, strings.NewReader(rest), "00"); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	current, err = store.WriteChunk(upload.ID, 10, strings.NewReader(rest), sha(rest))
+	if err != nil || current.Offset != current.Size {
+		t.Fatalf("expected complete upload, got %v (offset %d)", err, current.Offset)
+	}
+
+	path, err := store.Assemble(upload.ID)
+	if err != nil {
+		t.Fatalf("unexpected assembly error: %v", err)
+	}
+	assembled, _ := os.ReadFile(path)
+	if string(assembled) != content {
+		t.Errorf("assembled file differs: %q", assembled)
+	}
+
+	if err := store.Finish(upload.ID, models.ImportSummary{FileName: "janeiro.csv", Usages: 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+	final, _ := store.Get(upload.ID)
+	if final.State != StateCompleted || final.Summary == nil || final.Summary.Usages != 2 {
+		t.Errorf("unexpected final state: %+v", final)
+	}
+	if _, err := store.WriteChunk(upload.ID, final.Offset, strings.NewReader("x"), ""); !errors.Is(err, ErrNotWritable) {
+		t.Errorf("completed upload should not accept chunks, got %v", err)
+	}
+}
+
+func TestWriteChunkRejectsOversizedChunk(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	upload, _ := store.Create("a.csv", 4, "")
+
+	if _, err := store.WriteChunk(upload.ID, 0, strings.NewReader("12345"), ""); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	current, _ := store.Get(upload.ID)
+	if current.Offset != 0 {
+		t.Errorf("rejected chunk should not advance the offset, got %d", current.Offset)
+	}
+}
+
+func TestAssembleFailsOnWholeFileChecksum(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	upload, _ := store.Create("a.csv", 4, sha("abcd"))
+
+	if _, err := store.WriteChunk(upload.ID, 0, strings.NewReader("abce"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Assemble(upload.ID); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	current, _ := store.Get(upload.ID)
+	if current.State != StateFailed {
+		t.Errorf("expected failed state, got %s", current.State)
+	}
+}
+
+func TestGetRejectsInvalidID(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	if _, err := store.Get("../../etc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for invalid ID, got %v", err)
+	}
+}
+
+func TestCleanupRemovesStaleUploads(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	stale, _ := store.Create("velho.csv", 10, "")
+	fresh, _ := store.Create("novo.csv", 10, "")
+
+	old, _ := store.Get(stale.ID)
+	old.UpdatedAt = time.Now().Add(-48 * time.Hour)
+	store.save(old)
+
+	removed, err := store.Cleanup(24 * time.Hour)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected 1 removed upload, got %d (%v)", removed, err)
+	}
+	if _, err := store.Get(fresh.ID); err != nil {
+		t.Errorf("fresh upload should remain: %v", err)
+	}
+}
+
+func TestLocksAreReleased(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	upload, _ := store.Create("janeiro.csv", 4, "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Get(upload.ID)
+		}()
+	}
+	wg.Wait()
+	store.Delete(upload.ID)
+
+	if len(store.locks) != 0 {
+		t.Errorf("expected no locks left, got %d", len(store.locks))
+	}
+}
+
+func TestNewStoreFailsInterruptedImports(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewStore(dir)
+	upload, _ := store.Create("janeiro.csv", 4, "")
+	store.WriteChunk(upload.ID, 0, strings.NewReader("abcd"), "")
+	if _, err := store.Assemble(upload.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Um novo processo não tem a importação em andamento e não deve deixar o upload preso
+	restarted, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, _ := restarted.Get(upload.ID)
+	if current.State != StateFailed || current.Error == "" {
+		t.Errorf("expected interrupted import to be failed, got %+v", current)
+	}
+	if _, err := os.Stat(filepath.Join(dir, upload.ID, "file")); !os.IsNotExist(err) {
+		t.Errorf("assembled file should be removed: %v", err)
+	}
+}
+
+func TestCleanupFailsStaleImports(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	upload, _ := store.Create("janeiro.csv", 4, "")
+	store.WriteChunk(upload.ID, 0, strings.NewReader("abcd"), "")
+	store.Assemble(upload.ID)
+
+	stuck, _ := store.Get(upload.ID)
+	stuck.UpdatedAt = time.Now().Add(-48 * time.Hour)
+	store.save(stuck)
+
+	removed, err := store.Cleanup(24 * time.Hour)
+	if err != nil || removed != 0 {
+		t.Fatalf("expected the stale import to be kept for inspection, got %d removed (%v)", removed, err)
+	}
+	current, _ := store.Get(upload.ID)
+	if current.State != StateFailed {
+		t.Errorf("expected stale import to be failed, got %s", current.State)
+	}
+}
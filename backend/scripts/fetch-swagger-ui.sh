@@ -0,0 +1,36 @@
+#!/bin/sh
+# Baixa o swagger-ui-dist na versão fixada do registro npm, confere o tarball com o sha512 fixado
+# abaixo e copia os arquivos usados por GET /docs para api/swaggerui.
+#
+# O hash esperado fica no próprio script, e não nos metadados do registro: um registro (ou espelho)
+# comprometido serviria pacote e integridade adulterados juntos. Ao trocar de versão, atualize
+# SWAGGER_UI_VERSION e SWAGGER_UI_SHA512 no mesmo commit, com o hash conferido em uma fonte
+# independente do registro usado no download.
+set -eu
+
+SWAGGER_UI_VERSION="5.17.14"
+# Integridade do tarball swagger-ui-dist-$SWAGGER_UI_VERSION.tgz, no formato do npm (sha512-<base64>)
+SWAGGER_UI_SHA512=""
+
+REGISTRY="https://registry.npmjs.org/swagger-ui-dist"
+DEST="$(cd "$(dirname "$0")/.." && pwd)/api/swaggerui"
+
+if [ -z "$SWAGGER_UI_SHA512" ]; then
+    echo "SWAGGER_UI_SHA512 não está fixado em $0 para a versão $SWAGGER_UI_VERSION" >&2
+    exit 1
+fi
+
+TMP="$(mktemp -d)"
+trap 'rm -rf "$TMP"' EXIT
+
+echo "Baixando swagger-ui-dist $SWAGGER_UI_VERSION..."
+curl -fsSL "$REGISTRY/-/swagger-ui-dist-$SWAGGER_UI_VERSION.tgz" -o "$TMP/package.tgz"
+ACTUAL="sha512-$(openssl dgst -sha512 -binary "$TMP/package.tgz" | openssl base64 -A)"
+if [ "$ACTUAL" != "$SWAGGER_UI_SHA512" ]; then
+    echo "Integridade do pacote não confere: esperado $SWAGGER_UI_SHA512, obtido $ACTUAL" >&2
+    exit 1
+fi
+
+tar -xzf "$TMP/package.tgz" -C "$TMP" package/swagger-ui.css package/swagger-ui-bundle.js
+cp "$TMP/package/swagger-ui.css" "$TMP/package/swagger-ui-bundle.js" "$DEST/"
+echo "Swagger UI $SWAGGER_UI_VERSION copiado para $DEST"
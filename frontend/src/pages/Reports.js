@@ -1,11 +1,12 @@
 import React, { useState, useEffect } from 'react';
-import { BarChart, Bar, XAxis, YAxis, CartesianGrid, Tooltip, ResponsiveContainer, PieChart, Pie, Cell } from 'recharts';
+import { BarChart, Bar, XAxis, YAxis, CartesianGrid, Tooltip, ResponsiveContainer, PieChart, Pie, Cell, ComposedChart, Line } from 'recharts';
 import api from '../services/api';
 
 function Reports() {
   const [monthlyBilling, setMonthlyBilling] = useState([]);
   const [billingByProduct, setBillingByProduct] = useState([]);
   const [billingByPartner, setBillingByPartner] = useState([]);
+  const [topCustomers, setTopCustomers] = useState(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
 
@@ -18,7 +19,7 @@ function Reports() {
       setLoading(true);
       setError('');
       
-      const [monthlyRes, productRes, partnerRes] = await Promise.all([
+      const [monthlyRes, productRes, partnerRes, topRes] = await Promise.all([
         api.get('/api/reports/billing/monthly').catch(err => {
           console.warn('Erro ao carregar dados mensais:', err);
           return { data: [] };
@@ -30,12 +31,17 @@ function Reports() {
         api.get('/api/reports/billing/by-partner').catch(err => {
           console.warn('Erro ao carregar dados por parceiro:', err);
           return { data: [] };
+        }),
+        api.get('/api/reports/top', { params: { dimension: 'customer', metric: 'sum_billing', n: 10 } }).catch(err => {
+          console.warn('Erro ao carregar ranking de clientes:', err);
+          return { data: null };
         })
       ]);
 
       setMonthlyBilling(monthlyRes.data || []);
       setBillingByProduct(productRes.data || []);
       setBillingByPartner(partnerRes.data || []);
+      setTopCustomers(topRes.data);
       
       // Se não há dados, mostrar mensagem informativa
       if (!monthlyRes.data?.length && !productRes.data?.length && !partnerRes.data?.length) {
@@ -259,6 +265,37 @@ function Reports() {
           </table>
         </div>
       </div>
+
+      {/* Concentração de faturamento por cliente (Pareto) */}
+      {topCustomers && topCustomers.items?.length > 0 && (
+        <div className="chart-container">
+          <h2>🎯 Concentração por Cliente (Pareto)</h2>
+          <p>
+            {topCustomers.pareto.entities_to_threshold} de {topCustomers.entity_count} clientes
+            ({topCustomers.pareto.entity_share_pct.toFixed(1)}%) respondem por {topCustomers.pareto.threshold_pct}% do faturamento.
+          </p>
+          <ResponsiveContainer width="100%" height={400}>
+            <ComposedChart
+              data={[
+                ...topCustomers.items,
+                ...(topCustomers.other ? [{ key: `Outros (${topCustomers.other.entity_count})`, value: topCustomers.other.value, cumulative_share: 100 }] : [])
+              ]}
+            >
+              <CartesianGrid strokeDasharray="3 3" />
+              <XAxis dataKey="key" />
+              <YAxis yAxisId="value" />
+              <YAxis yAxisId="share" orientation="right" domain={[0, 100]} unit="%" />
+              <Tooltip
+                formatter={(value, name) => name === 'Acumulado'
+                  ? [`${value.toFixed(1)}%`, name]
+                  : [formatCurrency(value), name]}
+              />
+              <Bar yAxisId="value" dataKey="value" name="Total" fill="#8884d8" />
+              <Line yAxisId="share" type="monotone" dataKey="cumulative_share" name="Acumulado" stroke="#FF8042" />
+            </ComposedChart>
+          </ResponsiveContainer>
+        </div>
+      )}
     </div>
   );
 }